@@ -41,6 +41,8 @@ sips -z 512 512 "$ICON_SOURCE" --out "$ICONSET_DIR/icon_256x256@2x.png" >/dev/nu
 sips -z 512 512 "$ICON_SOURCE" --out "$ICONSET_DIR/icon_512x512.png" >/dev/null
 cp "$ICON_SOURCE" "$ICONSET_DIR/icon_512x512@2x.png"
 
+cp "$ROOT_DIR/FrameScope.sdef" "$RESOURCES_DIR/FrameScope.sdef"
+
 ICON_FILE_KEY=""
 if iconutil -c icns "$ICONSET_DIR" -o "$ICNS_PATH" >/dev/null 2>&1; then
   cp "$ICNS_PATH" "$RESOURCES_DIR/$APP_NAME.icns"
@@ -80,6 +82,10 @@ ${ICON_FILE_KEY}
     <string>12.0</string>
     <key>NSHighResolutionCapable</key>
     <true/>
+    <key>NSAppleScriptEnabled</key>
+    <true/>
+    <key>OSAScriptingDefinition</key>
+    <string>FrameScope.sdef</string>
 </dict>
 </plist>
 EOF
@@ -13,6 +13,8 @@
 #ifndef MONITOR_CPU_COCOA_BRIDGE_H
 #define MONITOR_CPU_COCOA_BRIDGE_H
 
+#include <stddef.h>
+
 /**
  * SetAppVersion stores the build version string so it can be embedded in the
  * window title when the window is created. Must be called before RunApp().
@@ -28,61 +30,798 @@ void SetAppVersion(const char *version);
 void RunApp(void);
 
 /**
- * UpdateResults delivers a complete UI refresh to the main thread. All four
- * string parameters are tab/newline-separated payloads rendered by Go:
+ * UpdateResults delivers a complete UI refresh to the main thread. The string
+ * parameters are plain text or tab/newline-separated payloads rendered by Go:
  *
- *   status      — plain-text status bar string
- *   tableText   — tab-separated rows for the current-frame table (4 columns)
- *   summaryText — tab-separated rows for the summary table (6 columns)
- *   historyText — newline-separated frame labels for the history popup
+ *   status       — plain-text status bar string
+ *   tableText    — tab-separated rows for the current-frame table, with the
+ *                  columns GoInitialColumns lists (by default 8; rate, a
+ *                  per-tick %CPU, is filled for the live frame only)
+ *   summaryText  — tab-separated rows for the summary table, with the columns
+ *                  GoInitialSummaryColumns lists (by default 7)
+ *   summaryTitle — plain-text header for the summary pane
+ *   statsText    — plain-text session statistics line above the summary
+ *                  tables (empty before the first frame completes)
+ *   commandsText — tab-separated rows for the by-command table (4 columns)
+ *   usersText    — tab-separated rows for the by-user table (4 columns)
+ *   comparisonText — tab-separated rows for the baseline comparison table
+ *                  (5 columns; empty while no baseline is loaded)
+ *   historyText  — newline-separated frame labels for the history popup; a
+ *                  label followed by "\tbusy" is a frame that kept at least
+ *                  half of all cores busy, to be highlighted
  *   selectedIndex — popup item index to select (-1 for none)
+ *   machineCPU   — whole-machine CPU use over the last tick as a percentage
+ *                  of all cores (0–100), for the status bar meter; -1 while
+ *                  unknown (first tick of a run, or stopped)
  *
  * The function dispatches asynchronously to the main queue; it is safe to
  * call from any goroutine.
  */
 void UpdateResults(const char *status, const char *tableText,
-                   const char *summaryText, const char *historyText,
-                   int selectedIndex);
+                   const char *summaryText, const char *summaryTitle,
+                   const char *statsText, const char *commandsText,
+                   const char *usersText, const char *comparisonText,
+                   const char *historyText, int selectedIndex,
+                   double machineCPU);
+
+/**
+ * UpdateResultsBinary is UpdateResults with the current-frame and summary
+ * tables as binary buffers instead of text, used when the app runs with
+ * -binary-ui. Each buffer is laid out as
+ *
+ *   uint32 rows | uint32 columns | rows × columns × (uint32 length | UTF-8 bytes)
+ *
+ * with little-endian integers and fields in row order, not NUL-terminated.
+ * Both buffers are read before the function returns, so the caller keeps
+ * ownership. The other parameters are as in UpdateResults.
+ */
+void UpdateResultsBinary(const char *status,
+                         const unsigned char *table, size_t tableLength,
+                         const unsigned char *summary, size_t summaryLength,
+                         const char *summaryTitle, const char *statsText,
+                         const char *commandsText, const char *usersText,
+                         const char *comparisonText, const char *historyText,
+                         int selectedIndex, double machineCPU);
 
 /**
- * ShowErrorMessage displays an error in the status bar and clears both tables.
+ * ShowErrorMessage displays an error in the status bar and clears all tables.
  * Dispatches asynchronously to the main queue.
  */
 void ShowErrorMessage(const char *message);
 
+/**
+ * CopyToPasteboard replaces the contents of the general pasteboard with text.
+ * The string is copied before returning; the write itself is dispatched
+ * asynchronously to the main queue.
+ */
+void CopyToPasteboard(const char *text);
+
+/**
+ * ShowNotification posts a user notification with a title and body, e.g. the
+ * CPU budget alert. The strings are copied before returning; the notification
+ * is delivered asynchronously on the main queue.
+ */
+void ShowNotification(const char *title, const char *body);
+
+/**
+ * RequestUserAttention bounces the Dock icon once, e.g. when a run completes,
+ * unless the app is already active. It returns at once; the request is made
+ * asynchronously on the main queue.
+ */
+void RequestUserAttention(void);
+
+/**
+ * UpdateStatusItem shows text, e.g. "Xcode 78%", in the menu bar item, creating
+ * it if needed; an empty text removes the item. The string is copied before
+ * returning; the item is updated asynchronously on the main queue.
+ */
+void UpdateStatusItem(const char *text);
+
 /* ── Go → Cocoa callbacks (implemented in controls.go, called from Obj-C) ── */
 
 /** GoStartMonitoring starts a new monitoring run with the given frame length. */
 void GoStartMonitoring(double frameSeconds);
 
-/** GoStopMonitoring cancels the active monitoring run. */
+/**
+ * GoStopMonitoring ends the active monitoring run, discarding the frame in
+ * progress, and returns once sampling has stopped and recorded frames are
+ * flushed.
+ */
 void GoStopMonitoring(void);
 
+/**
+ * GoWillTerminate completes the frame in progress, stops monitoring, and
+ * closes the recording file. Call it once when the app is quitting.
+ */
+void GoWillTerminate(void);
+
 /**
  * GoSetHideSmall enables (enabled != 0) or disables filtering of processes
  * that consumed less than 1 CPU-second in the frame.
  */
 void GoSetHideSmall(int enabled);
 
+/**
+ * GoSetSmallFilter chooses what the hideSmall filter measures: mode 0 hides
+ * processes below 1 CPU-second, mode 1 those below percent %CPU of one core
+ * over the frame, whatever its length. percent must be positive either way.
+ */
+void GoSetSmallFilter(int mode, double percent);
+
 /**
  * GoSetHidePaths enables (enabled != 0) or disables showing only the
  * executable basename instead of the full command line.
  */
 void GoSetHidePaths(int enabled);
 
+/**
+ * GoSetShowSparklines enables (enabled != 0) or disables the per-row trend
+ * sparkline of recent frames in the current-frame table.
+ */
+void GoSetShowSparklines(int enabled);
+
+/**
+ * GoSetShowShare enables (enabled != 0) or disables the column showing each
+ * process's share of all CPU consumed in the frame.
+ */
+void GoSetShowShare(int enabled);
+
+/**
+ * GoSetNoiseIncludesSelf leaves FrameScope's own process out of (enabled != 0)
+ * or counts it in the frame totals behind shares and utilization. It is still
+ * listed either way.
+ */
+void GoSetNoiseIncludesSelf(int enabled);
+
+/**
+ * GoSetShowPercent enables (enabled != 0) or disables the column showing each
+ * process's CPU-seconds as %CPU of one core over the frame, next to the
+ * CPU-seconds themselves.
+ */
+void GoSetShowPercent(int enabled);
+
+/**
+ * GoSetShowLiveDelta enables (enabled != 0) or disables the column comparing
+ * each process in the live frame, projected to the full frame length, with
+ * the last completed frame.
+ */
+void GoSetShowLiveDelta(int enabled);
+
+/**
+ * GoSetShowUserSys enables (enabled != 0) or disables the user and sys
+ * columns, which split each process's CPU-seconds into userland and kernel
+ * time.
+ */
+void GoSetShowUserSys(int enabled);
+
+/**
+ * GoSetMarkTinyValues makes both tables show positive CPU-seconds that round
+ * to 0.0 as "<0.1" (enabled != 0) or as "0.0".
+ */
+void GoSetMarkTinyValues(int enabled);
+
+/**
+ * GoSetShowStatus enables (enabled != 0) or disables the column marking
+ * zombie (Z) and stopped (T) processes.
+ */
+void GoSetShowStatus(int enabled);
+
+/**
+ * GoSetChangedOnly enables (enabled != 0) or disables the view listing only
+ * processes whose CPU changed since the previous completed frame.
+ */
+void GoSetChangedOnly(int enabled);
+
+/**
+ * GoSetFreezeTopN enables (enabled != 0) or disables pinning the live table
+ * to the top processes, in a fixed order, for the rest of each frame.
+ */
+void GoSetFreezeTopN(int enabled);
+
+/**
+ * GoSetMergeReusedPIDs merges (enabled != 0) or separates summary rows of
+ * processes that held the same PID and ran the same command.
+ */
+void GoSetMergeReusedPIDs(int enabled);
+
+/**
+ * GoSetMenuBarItem shows (enabled != 0) or hides the menu bar indicator of
+ * the busiest process while monitoring.
+ */
+void GoSetMenuBarItem(int enabled);
+
+/**
+ * GoSetPauseOnWindowClose pauses monitoring (enabled != 0) while the main
+ * window is hidden, as reported by GoWindowDidClose and GoWindowDidOpen,
+ * unless the menu bar indicator is on.
+ */
+void GoSetPauseOnWindowClose(int enabled);
+
+/**
+ * GoSetResumeMode chooses what becomes of the frames in progress when a
+ * paused run resumes: 0 restarts them from a fresh baseline, leaving the
+ * pause out of their elapsed time and %CPU; 1 continues them against their
+ * old baseline and extends them by the pause, which then counts in their
+ * CPU, elapsed time, and %CPU.
+ */
+void GoSetResumeMode(int mode);
+
+/** GoWindowDidClose reports that the main window was closed, minimized, or covered. */
+void GoWindowDidClose(void);
+
+/** GoWindowDidOpen reports that the main window can be seen again. */
+void GoWindowDidOpen(void);
+
+/**
+ * GoPauseMonitoring stops the active run from taking snapshots until
+ * GoResumeMonitoring; the frames in progress restart when it resumes.
+ */
+void GoPauseMonitoring(void);
+
+/** GoResumeMonitoring lifts a pause, explicit or by a hidden window. */
+void GoResumeMonitoring(void);
+
+/** GoIsPaused returns 1 while the active run is paused, 0 otherwise. */
+int GoIsPaused(void);
+
+/**
+ * GoReplayPlay plays back the loaded history frame by frame at the replay
+ * speed, while monitoring is stopped. Errors are shown in the status bar.
+ */
+void GoReplayPlay(void);
+
+/** GoReplayPause stops playback, keeping the frame on display. */
+void GoReplayPause(void);
+
+/** GoReplayIsPlaying returns 1 while the history is played back, 0 otherwise. */
+int GoReplayIsPlaying(void);
+
+/** GoReplaySetSpeed sets the playback rate in frames per second (at most 20). */
+void GoReplaySetSpeed(double framesPerSecond);
+
+/** GoReplaySetLoop makes playback start over after the last frame (enabled != 0) or stop there. */
+void GoReplaySetLoop(int enabled);
+
+/**
+ * GoSetIncludeKernelTask counts (enabled != 0) or drops kernel_task in every
+ * table and the summary, from the next frame on.
+ */
+void GoSetIncludeKernelTask(int enabled);
+
+/**
+ * GoSetShowOnlyMine limits (enabled != 0) every table and the summary to the
+ * current user's processes, from the next frame on, or lifts the limit.
+ */
+void GoSetShowOnlyMine(int enabled);
+
+/**
+ * GoSetTheme selects the colours of the exported chart: 0 follows the system
+ * appearance, 1 is light, 2 is dark.
+ */
+void GoSetTheme(int theme);
+
+/**
+ * GoSetSystemAppearance reports the application's effective appearance
+ * (1 = dark, 0 = light), which the system chart theme follows.
+ */
+void GoSetSystemAppearance(int dark);
+
+/**
+ * GoSetAverageMode selects the summary average denominator: 0 divides by all
+ * completed frames, 1 by the frames each process appeared in.
+ */
+void GoSetAverageMode(int mode);
+
+/**
+ * GoSetTiebreaker selects how rows with equal CPU are ordered in the
+ * current-frame and summary tables: 0 by PID, 1 by command, 2 oldest process
+ * first.
+ */
+void GoSetTiebreaker(int tiebreak);
+
+/**
+ * GoSetSummarySort orders the summary table by field (0 total, 1 average,
+ * 2 peak, 3 standard deviation, 4 appearances, 5 command, 6 PID), descending
+ * when desc is non-zero.
+ */
+void GoSetSummarySort(int field, int desc);
+
+/**
+ * GoSetGroupMode selects how browser and Electron helper processes are shown
+ * in the current-frame table: 0 lists them individually, 1 collapses each
+ * app's processes into one row, 2 also lists the members below that row.
+ */
+void GoSetGroupMode(int mode);
+
+/**
+ * GoSetSummaryMinTotal sets the minimum total CPU-seconds a process needs
+ * across the history to appear in the summary (0 = show all). It does not
+ * affect the current-frame table's hideSmall filter.
+ */
+void GoSetSummaryMinTotal(double total);
+
+/**
+ * GoSetSpikeRatio marks a summary row spiky when its peak single-frame %CPU
+ * is more than ratio times its average %CPU. ratio must be above 1.
+ */
+void GoSetSpikeRatio(double ratio);
+
+/**
+ * GoSetSummaryMinFrames sets the number of frames a process must have
+ * appeared in to be listed in the summary (1 = show all). Rows must also
+ * reach the minimum total.
+ */
+void GoSetSummaryMinFrames(int frames);
+
+/**
+ * GoSetSecondFrameLength sets a second frame length in seconds, collected
+ * from the same snapshots as the main one from the next start (0 = none).
+ */
+void GoSetSecondFrameLength(double seconds);
+
+/**
+ * GoSetStartDelay sets how long, in seconds, Start waits before the first
+ * frame's baseline snapshot, counting down in the status bar (0 = none).
+ */
+void GoSetStartDelay(double seconds);
+
+/**
+ * GoSetFrameWarmup sets how many seconds at the start of every frame are
+ * discarded before its baseline is taken (0 = none), from the next Start.
+ */
+void GoSetFrameWarmup(double seconds);
+
+/**
+ * GoSetStopAfterFrames makes a run stop by itself once it has completed
+ * frames frames (0 = run until stopped).
+ */
+void GoSetStopAfterFrames(int frames);
+
+/**
+ * GoSetCompletionAlert selects when the end of monitoring is announced with a
+ * notification and a Dock bounce: 0 never, 1 when a run finishes by itself or
+ * a reading is ready, 2 also on Stop.
+ */
+void GoSetCompletionAlert(int alert);
+
+/**
+ * GoSetAnomalySigma flags a completed frame as an outlier when its
+ * utilization is more than sigma standard deviations above the recent
+ * frames. sigma must be positive.
+ */
+void GoSetAnomalySigma(double sigma);
+
+/**
+ * GoSetNotifyAnomalies enables (enabled != 0) or disables a notification for
+ * every outlier frame.
+ */
+void GoSetNotifyAnomalies(int enabled);
+
+/**
+ * GoSetCollectTopN makes completed frames keep only their n busiest
+ * processes (0 = all), making the summary approximate.
+ */
+void GoSetCollectTopN(int n);
+
+/**
+ * GoSetHistoryRetention keeps only the completed frames that ended within
+ * the last seconds (0 = the newest frames by count alone).
+ */
+void GoSetHistoryRetention(double seconds);
+
+/**
+ * GoSetMaxProcessAge limits the current-frame table to processes started
+ * within seconds of the end of the frame shown (0 = any age). Processes whose
+ * start time is unknown are left out while it is set.
+ */
+void GoSetMaxProcessAge(double seconds);
+
+/**
+ * GoSelectFrameLength shows the frames of the given length, the main or the
+ * second one, in every table. Each length keeps its own history and summary.
+ */
+void GoSelectFrameLength(double seconds);
+
+/**
+ * GoAddPattern adds pattern to a pattern list: 0 = exclude, 1 = include-only,
+ * 2 = watch, 3 = noise (left out of the frame totals, not hidden). Patterns
+ * match a command line or executable path by substring, ignoring case; a
+ * number in the noise list names a PID. Duplicates are ignored.
+ */
+void GoAddPattern(int list, char *pattern);
+
+/** GoRemovePattern removes pattern from a pattern list (see GoAddPattern). */
+void GoRemovePattern(int list, char *pattern);
+
+/**
+ * GoSetAlias shows label in place of the command of the processes target
+ * names, in the current-frame and summary tables. A decimal target is a PID,
+ * labelled for this session only; anything else is a command pattern, matched
+ * like the pattern lists and persisted. A PID alias wins over a pattern one.
+ */
+void GoSetAlias(char *target, char *label);
+
+/** GoRemoveAlias removes the alias of a PID or pattern (see GoSetAlias). */
+void GoRemoveAlias(char *target);
+
+/**
+ * GoSetFocusPID monitors only pid and its descendants from the next snapshot
+ * on, until pid exits or GoClearFocusPID is called. Like every function
+ * taking a PID, it takes a long long, so that a PID read from a table with
+ * longLongValue reaches Go unchanged however large; one no process can have
+ * is refused with a note in the status bar rather than truncated into
+ * another process's PID.
+ */
+void GoSetFocusPID(long long pid);
+
+/** GoClearFocusPID monitors every process again (see GoSetFocusPID). */
+void GoClearFocusPID(void);
+
+/**
+ * GoSetColumns sets the columns of the current-frame table payload, in order,
+ * from a comma-separated list of: pid, cpu, duration, share, trend, status,
+ * command, rate. Unknown names are ignored; a list with no known name
+ * restores the default (all of them, in that order). The window lays its
+ * tables out at launch (GoInitialColumns), so a new layout shows there after
+ * a relaunch.
+ */
+void GoSetColumns(char *csv);
+
+/**
+ * GoSetSummaryColumns sets the columns of the summary table payload, in
+ * order, from a comma-separated list of: pid, total, average, total_duration,
+ * average_duration, peak, command. Unknown names are ignored, as in
+ * GoSetColumns.
+ */
+void GoSetSummaryColumns(char *csv);
+
+/**
+ * GoCopyRowField copies a field of the current-frame row for pid to the
+ * pasteboard: field 0 is the PID, 1 the full command line. A PID not shown in
+ * the current view is refused; the outcome is reported in the status bar.
+ */
+void GoCopyRowField(long long pid, int field);
+
 /**
  * GoSelectFrame switches the UI to the frame at selectedIndex in the history
  * popup. Out-of-range indices are ignored.
  */
 void GoSelectFrame(int selectedIndex);
 
+/**
+ * GoMarkSummaryViewed records that the user is looking at the per-process
+ * summary; its Since Viewed column then shows each process's growth since
+ * the previous call.
+ */
+void GoMarkSummaryViewed(void);
+
+/**
+ * GoSetHistoryRangeSelection limits the summary, statistics, and by-command
+ * tables to the completed frames between the history popup items fromIndex
+ * and toIndex (either order, inclusive) and shows the frame at toIndex. A
+ * later GoSelectFrame clears the range.
+ */
+void GoSetHistoryRangeSelection(int fromIndex, int toIndex);
+
+/**
+ * GoSetReferenceFrame locks the completed frame at index in the history popup
+ * as the reference: the current-frame table then shows each process's change
+ * in CPU versus that frame, pro-rated to the length of the frame shown.
+ * Indices other than completed frames are ignored.
+ */
+void GoSetReferenceFrame(int index);
+
+/** GoClearReferenceFrame returns the current-frame table to absolute CPU. */
+void GoClearReferenceFrame(void);
+
+/**
+ * GoExportChart writes a PNG line chart of the heaviest processes' per-frame
+ * CPU across the recorded history to path. Errors are shown in the status bar.
+ */
+void GoExportChart(char *path);
+
+/**
+ * GoSetChartExport saves a chart of the history into dir every time every
+ * frames have completed, for a time-lapse (0 = off).
+ */
+void GoSetChartExport(int every, char *dir);
+
+/**
+ * GoExportHistory writes the recorded frames, with their start and end times,
+ * to path as JSON or CSV (chosen by the extension). Errors are shown in the
+ * status bar.
+ */
+void GoExportHistory(char *path);
+
+/**
+ * GoExportConfig writes every preference to path as a JSON object, for
+ * sharing settings. Errors are shown in the status bar.
+ */
+void GoExportConfig(char *path);
+
+/**
+ * GoImportConfig applies and persists the settings in a file written by
+ * GoExportConfig. An unreadable or invalid file changes nothing and is
+ * reported in the status bar. Returns 1 if the settings were applied.
+ */
+int GoImportConfig(char *path);
+
+/**
+ * GoReloadConfig re-reads the settings file after it was edited outside
+ * FrameScope, keeping an active run going. Returns 1 if any setting changed,
+ * so the settings controls should be refreshed from the GoInitial* functions.
+ */
+int GoReloadConfig(void);
+
+/**
+ * GoScriptStart starts a new monitoring run for the AppleScript "start
+ * monitoring" command, like GoStartMonitoring. Returns the error message, or
+ * "" on success. Never NULL. The caller owns the returned string and must
+ * free() it.
+ */
+char *GoScriptStart(double frameSeconds);
+
+/**
+ * GoScriptExportFrame writes the frame on display to path as JSON, NDJSON, or
+ * CSV (chosen by the extension) for the AppleScript "export frame" command.
+ * Returns the error message, or "" on success. Never NULL. The caller owns
+ * the returned string and must free() it.
+ */
+char *GoScriptExportFrame(char *path);
+
+/**
+ * GoValidateFrameSeconds checks a frame length as it is typed, the way
+ * GoStartMonitoring would treat it. If normalized is not NULL it receives the
+ * length a run would use, or 0 if value is rejected. Returns a message for
+ * the user, or "" if value is used as is. Never NULL. The caller owns the
+ * returned string and must free() it.
+ */
+char *GoValidateFrameSeconds(double value, double *normalized);
+
+/**
+ * GoLoadBaselineSession loads an exported .json or .ndjson history from path
+ * as the baseline for the comparison table. An empty path clears it. Errors
+ * are shown in the status bar.
+ */
+void GoLoadBaselineSession(char *path);
+
+/**
+ * GoExportPidTimeline writes one process's CPU-seconds and %CPU in every
+ * completed frame to path as CSV. Frames the process was absent from are
+ * written as 0 when includeAbsent != 0 and omitted otherwise. Errors, such as
+ * a PID that never appeared, are shown in the status bar.
+ */
+void GoExportPidTimeline(long long pid, char *path, int includeAbsent);
+
+/**
+ * GoSetCPUBudget sets the session CPU budget: a notification is shown once
+ * the processes matching target (a PID or a command pattern) have used more
+ * than seconds CPU-seconds in total. An empty target or seconds <= 0 removes
+ * the budget.
+ */
+void GoSetCPUBudget(char *target, double seconds);
+
+/**
+ * GoGetFrameJSON returns the frame numbered index (completed or in progress)
+ * with every process's attributes as a JSON object, or "{}" if the frame is
+ * unknown or no longer in the history. Never NULL. The caller owns the
+ * returned string and must free() it.
+ */
+char *GoGetFrameJSON(int index);
+
+/**
+ * GoGetHistoryJSON returns every completed frame, then the one in progress,
+ * as a JSON array in the form of the JSON history export, without a file.
+ * Past 8 MB the oldest frames are left out. Never NULL. The caller owns the
+ * returned string and must free() it.
+ */
+char *GoGetHistoryJSON(void);
+
+/**
+ * GoGetSummaryJSON returns the summary as a JSON object with every computed
+ * metric per process (total, average, peak, standard deviation, min/max,
+ * appearances), honouring the summary's range, filters, and sort, without a
+ * row cap. Never NULL. The caller owns the returned string and must free() it.
+ */
+char *GoGetSummaryJSON(void);
+
+/**
+ * GoGetTopProcess returns the busiest row of the current view, under the
+ * table's filters, as a compact JSON object with pid, command, cpu
+ * (CPU-seconds), and percent (of one core), or "{}" when there is none; for a
+ * widget that needs no full update. Never NULL. The caller owns the returned
+ * string and must free() it.
+ */
+char *GoGetTopProcess(void);
+
+/**
+ * GoSnapshotNow takes a one-off reading over seconds (at most a minute) and
+ * returns the heaviest processes as a JSON object, leaving monitoring alone.
+ * Blocks for the whole reading; do not call it on the main thread. Never
+ * NULL. The caller owns the returned string and must free() it.
+ */
+char *GoSnapshotNow(double seconds);
+
+/** GoGetFrameCount returns how many completed frames the history holds. */
+int GoGetFrameCount(void);
+
+/**
+ * GoGetFrameInProgress returns 1 while a run is active, whose frame in
+ * progress the history popup lists after the completed ones, 0 otherwise.
+ */
+int GoGetFrameInProgress(void);
+
+/**
+ * GoGetStatus returns the current status line without triggering a UI
+ * update. Never NULL. The caller owns the returned string and must free() it.
+ */
+char *GoGetStatus(void);
+
+/**
+ * GoGetIconPath returns the .icns path of the application bundle the process
+ * runs from, or "" if it has none (use a generic icon). Never NULL. The caller
+ * owns the returned string and must free() it.
+ */
+char *GoGetIconPath(long long pid);
+
 /** GoInitialHideSmall returns the persisted hideSmall setting (1 = on, 0 = off). */
 int GoInitialHideSmall(void);
 
+/** GoInitialSmallFilterMode returns the persisted small-process filter mode (0 or 1). */
+int GoInitialSmallFilterMode(void);
+
+/** GoInitialSmallFilterPercent returns the persisted small-process filter threshold in %CPU. */
+double GoInitialSmallFilterPercent(void);
+
 /** GoInitialHidePaths returns the persisted hidePaths setting (1 = on, 0 = off). */
 int GoInitialHidePaths(void);
 
+/** GoInitialShowSparklines returns the persisted sparkline setting (1 = on, 0 = off). */
+int GoInitialShowSparklines(void);
+
+/** GoInitialShowShare returns the persisted share-column setting (1 = on, 0 = off). */
+int GoInitialShowShare(void);
+
+/** GoInitialNoiseIncludesSelf returns whether FrameScope is left out of the frame totals (1 = yes, 0 = no). */
+int GoInitialNoiseIncludesSelf(void);
+
+/** GoInitialShowPercent returns the persisted %CPU-column setting (1 = on, 0 = off). */
+int GoInitialShowPercent(void);
+
+/** GoInitialShowLiveDelta returns the persisted live-delta-column setting (1 = on, 0 = off). */
+int GoInitialShowLiveDelta(void);
+
+/** GoInitialShowUserSys returns the persisted user/sys-columns setting (1 = on, 0 = off). */
+int GoInitialShowUserSys(void);
+
+/** GoInitialMarkTinyValues returns the persisted tiny-value setting (1 = on, 0 = off). */
+int GoInitialMarkTinyValues(void);
+
+/** GoInitialShowStatus returns the persisted state-marker setting (1 = on, 0 = off). */
+int GoInitialShowStatus(void);
+
+/** GoInitialChangedOnly returns the persisted changed-only view setting (1 = on, 0 = off). */
+int GoInitialChangedOnly(void);
+
+/** GoInitialFreezeTopN returns the persisted freeze top-N setting (1 = on, 0 = off). */
+int GoInitialFreezeTopN(void);
+
+/** GoInitialMergeReusedPIDs returns the persisted reused-PID merge setting (1 = on, 0 = off). */
+int GoInitialMergeReusedPIDs(void);
+
+/** GoInitialMenuBarItem returns the persisted menu bar indicator setting (1 = on, 0 = off). */
+int GoInitialMenuBarItem(void);
+
+/** GoInitialReplaySpeed returns the persisted replay speed in frames per second. */
+double GoInitialReplaySpeed(void);
+
+/** GoInitialReplayLoop returns the persisted replay loop setting (1 = on, 0 = off). */
+int GoInitialReplayLoop(void);
+
+/** GoInitialPauseOnWindowClose returns the persisted pause-on-window-close setting (1 = on, 0 = off). */
+int GoInitialPauseOnWindowClose(void);
+
+/** GoInitialIncludeKernelTask returns the persisted kernel_task setting (1 = included, 0 = dropped). */
+int GoInitialIncludeKernelTask(void);
+
+/** GoInitialShowOnlyMine returns the persisted own-processes setting (1 = on, 0 = off). */
+int GoInitialShowOnlyMine(void);
+
+/** GoInitialTheme returns the persisted chart theme (0 = system, 1 = light, 2 = dark). */
+int GoInitialTheme(void);
+
+/** GoInitialAverageMode returns the persisted average mode (0 or 1). */
+int GoInitialAverageMode(void);
+
+/** GoInitialTiebreaker returns the persisted equal-row ordering (0, 1, or 2). */
+int GoInitialTiebreaker(void);
+
+/** GoInitialSummarySortField returns the persisted summary sort field (0 to 6). */
+int GoInitialSummarySortField(void);
+
+/** GoInitialSummarySortDescending returns the persisted summary sort direction (1 = descending). */
+int GoInitialSummarySortDescending(void);
+
+/** GoInitialGroupMode returns the persisted app helper grouping (0, 1, or 2). */
+int GoInitialGroupMode(void);
+
+/** GoInitialResumeMode returns the persisted resume mode (0 = rebaseline, 1 = continue). */
+int GoInitialResumeMode(void);
+
+/** GoInitialSummaryMinTotal returns the persisted summary threshold in CPU-seconds. */
+double GoInitialSummaryMinTotal(void);
+
+/** GoInitialSpikeRatio returns the persisted peak-to-average spike ratio. */
+double GoInitialSpikeRatio(void);
+
+/** GoInitialSummaryMinFrames returns the persisted summary appearance threshold (at least 1). */
+int GoInitialSummaryMinFrames(void);
+
+/**
+ * GoInitialPatterns returns the persisted patterns of a list (see
+ * GoAddPattern), newline-separated. Never NULL. The caller owns the returned
+ * string and must free() it.
+ */
+char *GoInitialPatterns(int list);
+
+/**
+ * GoInitialColumns returns the current-frame table's columns (see
+ * GoSetColumns) in payload order, comma-separated. Never NULL. The caller
+ * owns the returned string and must free() it.
+ */
+char *GoInitialColumns(void);
+
+/** GoInitialSummaryColumns is GoInitialColumns for the summary table. */
+char *GoInitialSummaryColumns(void);
+
 /** GoInitialFrameSeconds returns the persisted frame length in seconds. */
 double GoInitialFrameSeconds(void);
 
+/** GoInitialSecondFrameSeconds returns the persisted second frame length (0 = none). */
+double GoInitialSecondFrameSeconds(void);
+
+/** GoInitialStartDelay returns the persisted start delay in seconds (0 = none). */
+double GoInitialStartDelay(void);
+
+/** GoInitialChartExportEvery returns the persisted time-lapse chart interval in frames (0 = off). */
+int GoInitialChartExportEvery(void);
+
+/** GoInitialFrameWarmup returns the persisted per-frame warm-up in seconds (0 = none). */
+double GoInitialFrameWarmup(void);
+
+/** GoInitialStopAfterFrames returns the persisted frame limit of a run (0 = none). */
+int GoInitialStopAfterFrames(void);
+
+/** GoInitialCompletionAlert returns the persisted completion alert (0, 1, or 2). */
+int GoInitialCompletionAlert(void);
+
+/** GoInitialAnomalySigma returns the persisted outlier threshold in standard deviations. */
+double GoInitialAnomalySigma(void);
+
+/** GoInitialNotifyAnomalies returns the persisted outlier notification setting (1 = on, 0 = off). */
+int GoInitialNotifyAnomalies(void);
+
+/** GoInitialCollectTopN returns the persisted number of rows completed frames keep (0 = all). */
+int GoInitialCollectTopN(void);
+
+/** GoInitialHistoryRetention returns the persisted history retention in seconds (0 = by count). */
+double GoInitialHistoryRetention(void);
+
+/** GoInitialMaxProcessAge returns the persisted maximum process age in seconds (0 = any age). */
+double GoInitialMaxProcessAge(void);
+
+/**
+ * GoGetConfig returns every persisted preference as a JSON object, in the
+ * format of the config file. Never NULL. The caller owns the returned string
+ * and must free() it.
+ */
+char *GoGetConfig(void);
+
+/**
+ * GoSetConfig replaces every preference with those in a JSON object as
+ * returned by GoGetConfig, persists them, and refreshes the UI. Invalid input
+ * changes nothing and is reported in the status bar. Returns 1 on success.
+ */
+int GoSetConfig(char *json);
+
 #endif
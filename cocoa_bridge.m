@@ -1,7 +1,9 @@
+//go:build darwin
+
 /*
  * cocoa_bridge.m -- AppKit UI implementation for FrameScope.
  *
- * This file owns the entire native macOS UI: window, toolbar, split view, two
+ * This file owns the entire native macOS UI: window, toolbar, split view, three
  * table views, and the status bar. It communicates with the Go layer through
  * the C functions declared in cocoa_bridge.h:
  *
@@ -22,10 +24,14 @@
  *   +-------------------------------------+
  *   |  Current Frame         (header 22px)|
  *   |  NSTableView (resultsTable)         |  <- frame pane (~58%)
- *   |  PID | Raw(s) | CPU Time | Command  |
+ *   |  PID | Raw(s) | CPU Time | ... | Command  |
  *   +- - - - - - - - - - - - - - - - - - +  <- NSSplitView thin divider
  *   |  Summary -- Totals & Averages       |
- *   |  NSTableView (summaryTable)         |  <- summary pane (~42%)
+ *   |  12 frames · 184.2 CPU-s · ...      |  <- session statistics line
+ *   |  [By Process] [By Command] [By User] |
+ *   |  NSTableView (summaryTable,         |  <- summary pane (~42%)
+ *   |    commandTable, userTable, or      |
+ *   |    comparisonTable)                 |
  *   |  PID | Total | Avg | ... | Command  |
  *   +-------------------------------------+
  *   |  status text                   24px |  <- status bar (fixed, bottom)
@@ -45,18 +51,58 @@ static NSString * const kRecordingItem  = @"RecordingItem";
 static NSString * const kNavigationItem = @"NavigationItem";
 static NSString * const kOptionsItem    = @"OptionsItem";
 
+/**
+ * Splits a comma-separated column list returned by GoInitialColumns or
+ * GoInitialSummaryColumns, and frees it.
+ */
+static NSArray<NSString *> *ColumnList(char *csv) {
+    NSString *list = [NSString stringWithUTF8String:csv ?: ""];
+    free(csv);
+    return [list componentsSeparatedByString:@","];
+}
+
+/** OnOff converts a Go 1 / 0 flag into a menu item state. */
+static NSControlStateValue OnOff(int flag) {
+    return flag ? NSControlStateValueOn : NSControlStateValueOff;
+}
+
+/**
+ * Checks the items of menu that send action and whose representedObject
+ * equals value, and unchecks the others that send it.
+ */
+static void CheckPreset(NSMenu *menu, SEL action, double value) {
+    for (NSMenuItem *item in menu.itemArray) {
+        if (item.action != action) continue;
+        item.state = OnOff([item.representedObject doubleValue] == value);
+    }
+}
+
+/**
+ * Checks the item of menu that sends action and whose tag equals tag, and
+ * unchecks the others that send it.
+ */
+static void CheckTag(NSMenu *menu, SEL action, NSInteger tag) {
+    for (NSMenuItem *item in menu.itemArray) {
+        if (item.action != action) continue;
+        item.state = OnOff(item.tag == tag);
+    }
+}
+
 /**
  * MonitorAppDelegate is the single NSApplicationDelegate for FrameScope.
- * It also acts as NSTableViewDataSource and NSTableViewDelegate for both
+ * It also acts as NSTableViewDataSource and NSTableViewDelegate for all
  * table views, and as NSToolbarDelegate for the main toolbar.
  *
  * Table data is stored as pre-parsed arrays of string arrays (frameRows /
- * summaryRows) populated by applyRowsPayload: / applySummaryPayload: whenever
- * Go pushes a new update. The delegate methods simply index into these arrays.
+ * summaryRows / commandRows / userRows / comparisonRows) populated by
+ * applyRowsPayload: / applySummaryPayload: / applyCommandsPayload: /
+ * applyUsersPayload: / applyComparisonPayload: whenever Go pushes a new update. The delegate methods simply index into these arrays.
  */
 @interface MonitorAppDelegate : NSObject <NSApplicationDelegate,
                                           NSTableViewDataSource,
                                           NSTableViewDelegate,
+                                          NSTabViewDelegate,
+                                          NSTextFieldDelegate,
                                           NSToolbarDelegate>
 
 /* Main window. */
@@ -70,29 +116,97 @@ static NSString * const kOptionsItem    = @"OptionsItem";
 /* OptionsItem controls. */
 @property(nonatomic, strong) NSButton      *settingsButton;
 @property(nonatomic, strong) NSMenuItem    *hideSmallMenuItem;
+@property(nonatomic, strong) NSMenu        *smallFilterMenu;     /* 1 CPU-second, then percent presets */
+@property(nonatomic, assign) double         smallFilterPercent;  /* kept while filtering by CPU-seconds */
 @property(nonatomic, strong) NSMenuItem    *hidePathsMenuItem;
+@property(nonatomic, strong) NSMenuItem    *averageModeMenuItem;
+@property(nonatomic, strong) NSMenuItem    *mergeReusedMenuItem;
+@property(nonatomic, strong) NSMenuItem    *sparklinesMenuItem;
+@property(nonatomic, strong) NSMenuItem    *shareMenuItem;
+@property(nonatomic, strong) NSMenuItem    *noiseSelfMenuItem;
+@property(nonatomic, strong) NSMenuItem    *percentMenuItem;
+@property(nonatomic, strong) NSMenuItem    *liveDeltaMenuItem;
+@property(nonatomic, strong) NSMenuItem    *userSysMenuItem;
+@property(nonatomic, strong) NSMenuItem    *markTinyMenuItem;
+@property(nonatomic, strong) NSMenuItem    *statusMenuItem;
+@property(nonatomic, strong) NSMenuItem    *changedOnlyMenuItem;
+@property(nonatomic, strong) NSMenuItem    *freezeTopNMenuItem;
+@property(nonatomic, strong) NSMenuItem    *kernelTaskMenuItem;
+@property(nonatomic, strong) NSMenuItem    *menuBarMenuItem;
+@property(nonatomic, strong) NSMenuItem    *pauseOnCloseMenuItem;
+@property(nonatomic, strong) NSMenuItem    *pauseMenuItem;
+@property(nonatomic, strong) NSStatusItem  *statusItem;         /* nil unless the indicator is shown */
+@property(nonatomic, strong) NSMenuItem    *onlyMineMenuItem;
+@property(nonatomic, strong) NSMenu        *summaryMinTotalMenu; /* one item per preset */
+@property(nonatomic, strong) NSMenu        *spikeRatioMenu;      /* one item per preset */
+@property(nonatomic, strong) NSMenu        *summaryMinFramesMenu; /* one item per preset */
+@property(nonatomic, strong) NSMenu        *groupModeMenu;       /* Off, Collapsed, Expanded */
+@property(nonatomic, strong) NSMenu        *resumeModeMenu;      /* Restart Frame, Continue Frame */
+@property(nonatomic, strong) NSMenu        *tiebreakerMenu;      /* PID, Command, Start Time */
+@property(nonatomic, strong) NSMenu        *themeMenu;           /* System, Light, Dark */
+@property(nonatomic, strong) NSMenu        *chartExportMenu;     /* Off, one item per preset */
+@property(nonatomic, strong) NSMenu        *replayMenu;          /* Play/Pause, speeds, Loop */
+@property(nonatomic, strong) NSMenu        *summarySortMenu;     /* summary fields, then Descending */
+@property(nonatomic, strong) NSMenuItem    *summaryDescendingItem;
+@property(nonatomic, strong) NSMenu        *secondFrameMenu;     /* Off, one item per preset */
+@property(nonatomic, strong) NSMenu        *startDelayMenu;      /* Off, one item per preset */
+@property(nonatomic, strong) NSMenu        *frameWarmupMenu;     /* Off, one item per preset */
+@property(nonatomic, strong) NSMenu        *retentionMenu;       /* All, one item per preset */
+@property(nonatomic, strong) NSMenu        *maxAgeMenu;          /* Any age, one item per preset */
+@property(nonatomic, strong) NSMenu        *collectTopNMenu;     /* All, one item per preset */
+@property(nonatomic, strong) NSMenu        *stopAfterMenu;       /* Never, one item per preset */
+@property(nonatomic, strong) NSMenu        *completionAlertMenu; /* Never, When Finished, Also on Stop */
+@property(nonatomic, strong) NSMenu        *anomalySigmaMenu;    /* one item per preset */
+@property(nonatomic, strong) NSMenuItem    *notifyAnomaliesMenuItem;
+@property(nonatomic, strong) NSMenuItem    *showSecondFrameMenuItem;
 
 /* NavigationItem controls. */
 @property(nonatomic, strong) NSPopUpButton *historyPopup;
 @property(nonatomic, strong) NSButton      *previousFrameButton;
 @property(nonatomic, strong) NSButton      *nextFrameButton;
+@property(nonatomic, strong) NSTextField   *frameCountLabel;     /* "12 frames" */
 
 /* Status bar label (bottom of content view). */
 @property(nonatomic, strong) NSTextField   *statusLabel;
+@property(nonatomic, strong) NSLevelIndicator *machineMeter;   /* whole-machine %CPU, right of the status */
+@property(nonatomic, strong) NSTextField   *machineLabel;     /* "CPU 42%", or "CPU —" while unknown */
 
 /* Frame pane (top split). */
 @property(nonatomic, strong) NSScrollView  *tableScrollView;
 @property(nonatomic, strong) NSTableView   *resultsTable;
+@property(nonatomic, strong) NSTableColumn *shareColumn;      /* hidden unless share is on */
+@property(nonatomic, strong) NSTableColumn *percentColumn;    /* hidden unless %CPU is on */
+@property(nonatomic, strong) NSTableColumn *userColumn;       /* hidden unless user/sys is on */
+@property(nonatomic, strong) NSTableColumn *sysColumn;        /* hidden unless user/sys is on */
+@property(nonatomic, strong) NSTableColumn *sumPercentColumn; /* hidden unless %CPU is on */
+@property(nonatomic, strong) NSTableColumn *trendColumn;      /* hidden unless sparklines are on */
+@property(nonatomic, strong) NSTableColumn *deltaColumn;
+@property(nonatomic, strong) NSTableColumn *rateColumn;       /* hidden unless the payload has rates */
+@property(nonatomic, copy) NSArray<NSString *> *frameColumnOrder;   /* payload columns (GoInitialColumns) */
+@property(nonatomic, copy) NSArray<NSString *> *summaryColumnOrder; /* payload columns (GoInitialSummaryColumns) */
+@property(nonatomic, strong) NSTableColumn *statusColumn;     /* hidden unless the state marker is on */
 @property(nonatomic, strong) NSTextField   *emptyLabel;       /* shown when no rows */
 
 /* Summary pane (bottom split). */
 @property(nonatomic, strong) NSScrollView  *summaryScrollView;
 @property(nonatomic, strong) NSTableView   *summaryTable;
+@property(nonatomic, strong) NSTabView     *summaryTabs;        /* By Process, By Command, By User, Compare */
 @property(nonatomic, strong) NSTextField   *summaryEmptyLabel;
+@property(nonatomic, strong) NSTextField   *summaryTitleLabel; /* set from Go */
+@property(nonatomic, strong) NSTextField   *summaryStatsLabel; /* set from Go */
+@property(nonatomic, strong) NSScrollView  *commandScrollView;  /* "By Command" tab */
+@property(nonatomic, strong) NSTableView   *commandTable;
+@property(nonatomic, strong) NSScrollView  *userScrollView;     /* "By User" tab */
+@property(nonatomic, strong) NSTableView   *userTable;
+@property(nonatomic, strong) NSScrollView  *comparisonScrollView; /* "Compare" tab */
+@property(nonatomic, strong) NSTableView   *comparisonTable;
 
 /* Table data — arrays of column-value arrays, indexed by row. */
 @property(nonatomic, copy) NSArray<NSArray<NSString *> *> *frameRows;
 @property(nonatomic, copy) NSArray<NSArray<NSString *> *> *summaryRows;
+@property(nonatomic, copy) NSArray<NSArray<NSString *> *> *commandRows;
+@property(nonatomic, copy) NSArray<NSArray<NSString *> *> *userRows;
+@property(nonatomic, copy) NSArray<NSArray<NSString *> *> *comparisonRows;
 
 /* Frame labels displayed in the history popup. */
 @property(nonatomic, copy) NSArray<NSString *> *historyItems;
@@ -103,6 +217,12 @@ static NSString * const kOptionsItem    = @"OptionsItem";
  */
 @property(nonatomic, assign) BOOL updatingHistorySelection;
 
+/**
+ * Popup index of the last entry picked without Shift; a Shift-pick selects
+ * the range from it to the new entry. -1 until the first pick.
+ */
+@property(nonatomic, assign) NSInteger historyRangeAnchor;
+
 @end
 
 @implementation MonitorAppDelegate
@@ -142,9 +262,13 @@ static NSString * const kOptionsItem    = @"OptionsItem";
  * Constructs and returns the NSToolbarItem for the given identifier.
  *
  * RecordingItem  — "Frame (s):" label + text field + Start + Stop buttons.
- * NavigationItem — ‹ Prev button + history popup + Next › button.
+ * NavigationItem — ‹ Prev button + history popup + Next › button + frame
+ *                  count.
  * OptionsItem    — Settings button with a drop-down menu containing the two
- *                  filter toggles (Hide <1s, Show basenames only).
+ *                  display toggles (Hide <1s, Show basenames only, Show
+ *                  trend sparklines, Show share of frame total), the
+ *                  summary average mode and minimum total, and the
+ *                  chart and history export actions.
  *
  * minSize/maxSize are set to fix each item's width. The deprecation warning for
  * those properties is suppressed; they remain the only reliable way to constrain
@@ -167,8 +291,9 @@ static NSString * const kOptionsItem    = @"OptionsItem";
         [c addSubview:lbl];
 
         self.frameField = [[NSTextField alloc] initWithFrame:NSMakeRect(74, 5, 54, 24)];
-        self.frameField.stringValue = [NSString stringWithFormat:@"%.0f", GoInitialFrameSeconds()];
+        self.frameField.stringValue = [NSString stringWithFormat:@"%g", GoInitialFrameSeconds()];
         self.frameField.font = [NSFont systemFontOfSize:13];
+        self.frameField.delegate = self;
         [c addSubview:self.frameField];
 
         self.startButton = [[NSButton alloc] initWithFrame:NSMakeRect(136, 3, 82, 28)];
@@ -195,8 +320,8 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     }
 
     if ([identifier isEqualToString:kNavigationItem]) {
-        // [ ‹ Prev ] [ popup ] [ Next › ]
-        NSView *c = [[NSView alloc] initWithFrame:NSMakeRect(0, 0, 368, 32)];
+        // [ ‹ Prev ] [ popup ] [ Next › ] 12 frames
+        NSView *c = [[NSView alloc] initWithFrame:NSMakeRect(0, 0, 448, 32)];
 
         self.previousFrameButton = [[NSButton alloc] initWithFrame:NSMakeRect(0, 3, 76, 28)];
         self.previousFrameButton.title = @"‹ Prev";
@@ -209,6 +334,7 @@ static NSString * const kOptionsItem    = @"OptionsItem";
         self.historyPopup = [[NSPopUpButton alloc] initWithFrame:NSMakeRect(82, 3, 212, 28) pullsDown:NO];
         self.historyPopup.target = self;
         self.historyPopup.action = @selector(historyChanged:);
+        self.historyRangeAnchor = -1;
         self.historyPopup.enabled = NO;
         [self.historyPopup addItemWithTitle:@"No frames yet"];
         [c addSubview:self.historyPopup];
@@ -221,11 +347,16 @@ static NSString * const kOptionsItem    = @"OptionsItem";
         self.nextFrameButton.enabled = NO;
         [c addSubview:self.nextFrameButton];
 
+        self.frameCountLabel = [self makeLabel:@"No frames" frame:NSMakeRect(374, 8, 74, 17)];
+        self.frameCountLabel.font = [NSFont systemFontOfSize:12];
+        self.frameCountLabel.textColor = [NSColor secondaryLabelColor];
+        [c addSubview:self.frameCountLabel];
+
         item.view = c;
 #pragma clang diagnostic push
 #pragma clang diagnostic ignored "-Wdeprecated-declarations"
-        item.minSize = NSMakeSize(368, 32);
-        item.maxSize = NSMakeSize(368, 32);
+        item.minSize = NSMakeSize(448, 32);
+        item.maxSize = NSMakeSize(448, 32);
 #pragma clang diagnostic pop
         return item;
     }
@@ -246,6 +377,52 @@ static NSString * const kOptionsItem    = @"OptionsItem";
         self.hideSmallMenuItem.state = GoInitialHideSmall() ? NSControlStateValueOn : NSControlStateValueOff;
         [menu addItem:self.hideSmallMenuItem];
 
+        NSMenuItem *smallFilterItem = [[NSMenuItem alloc] initWithTitle:@"Hide threshold"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.smallFilterMenu = [[NSMenu alloc] initWithTitle:@"Hide threshold"];
+        int savedSmallMode = GoInitialSmallFilterMode();
+        self.smallFilterPercent = GoInitialSmallFilterPercent();
+        for (NSNumber *percent in @[@0, @1, @2, @5, @10]) {
+            NSString *title = percent.doubleValue == 0
+                ? @"1 CPU-second"
+                : [NSString stringWithFormat:@"%@%% of a core", percent];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(smallFilterChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = percent;
+            BOOL chosen = percent.doubleValue == 0
+                ? savedSmallMode == 0
+                : (savedSmallMode == 1 && percent.doubleValue == self.smallFilterPercent);
+            preset.state = chosen ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.smallFilterMenu addItem:preset];
+        }
+        smallFilterItem.submenu = self.smallFilterMenu;
+        [menu addItem:smallFilterItem];
+
+        NSMenuItem *maxAgeItem = [[NSMenuItem alloc] initWithTitle:@"Started within"
+                                                            action:nil
+                                                     keyEquivalent:@""];
+        self.maxAgeMenu = [[NSMenu alloc] initWithTitle:@"Started within"];
+        double savedMaxAge = GoInitialMaxProcessAge();
+        for (NSNumber *seconds in @[@0, @30, @60, @300, @900]) {
+            NSString *title = seconds.doubleValue == 0
+                ? @"Any age"
+                : (seconds.intValue < 60
+                    ? [NSString stringWithFormat:@"Last %@ s", seconds]
+                    : [NSString stringWithFormat:@"Last %d min", seconds.intValue / 60]);
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(maxAgeChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = seconds;
+            preset.state = (seconds.doubleValue == savedMaxAge) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.maxAgeMenu addItem:preset];
+        }
+        maxAgeItem.submenu = self.maxAgeMenu;
+        [menu addItem:maxAgeItem];
+
         self.hidePathsMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show basenames only"
                                                             action:@selector(hidePathsToggled:)
                                                      keyEquivalent:@""];
@@ -253,6 +430,558 @@ static NSString * const kOptionsItem    = @"OptionsItem";
         self.hidePathsMenuItem.state = GoInitialHidePaths() ? NSControlStateValueOn : NSControlStateValueOff;
         [menu addItem:self.hidePathsMenuItem];
 
+        self.sparklinesMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show trend sparklines"
+                                                             action:@selector(sparklinesToggled:)
+                                                      keyEquivalent:@""];
+        self.sparklinesMenuItem.target = self;
+        self.sparklinesMenuItem.state = GoInitialShowSparklines() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.sparklinesMenuItem];
+
+        self.shareMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show share of frame total"
+                                                        action:@selector(shareToggled:)
+                                                 keyEquivalent:@""];
+        self.shareMenuItem.target = self;
+        self.shareMenuItem.state = GoInitialShowShare() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.shareMenuItem];
+
+        self.noiseSelfMenuItem = [[NSMenuItem alloc] initWithTitle:@"Leave FrameScope out of totals"
+                                                            action:@selector(noiseSelfToggled:)
+                                                     keyEquivalent:@""];
+        self.noiseSelfMenuItem.target = self;
+        self.noiseSelfMenuItem.state = GoInitialNoiseIncludesSelf() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.noiseSelfMenuItem];
+
+        self.percentMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show %CPU alongside CPU-seconds"
+                                                          action:@selector(percentToggled:)
+                                                   keyEquivalent:@""];
+        self.percentMenuItem.target = self;
+        self.percentMenuItem.state = GoInitialShowPercent() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.percentMenuItem];
+
+        self.liveDeltaMenuItem = [[NSMenuItem alloc] initWithTitle:@"Compare live frame with the last one"
+                                                            action:@selector(liveDeltaToggled:)
+                                                     keyEquivalent:@""];
+        self.liveDeltaMenuItem.target = self;
+        self.liveDeltaMenuItem.state = GoInitialShowLiveDelta() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.liveDeltaMenuItem];
+
+        self.userSysMenuItem = [[NSMenuItem alloc] initWithTitle:@"Split CPU into user and system"
+                                                          action:@selector(userSysToggled:)
+                                                   keyEquivalent:@""];
+        self.userSysMenuItem.target = self;
+        self.userSysMenuItem.state = GoInitialShowUserSys() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.userSysMenuItem];
+
+        self.markTinyMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show <0.1 for tiny CPU values"
+                                                           action:@selector(markTinyToggled:)
+                                                    keyEquivalent:@""];
+        self.markTinyMenuItem.target = self;
+        self.markTinyMenuItem.state = GoInitialMarkTinyValues() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.markTinyMenuItem];
+
+        self.statusMenuItem = [[NSMenuItem alloc] initWithTitle:@"Mark zombie and stopped processes"
+                                                         action:@selector(statusToggled:)
+                                                  keyEquivalent:@""];
+        self.statusMenuItem.target = self;
+        self.statusMenuItem.state = GoInitialShowStatus() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.statusMenuItem];
+
+        self.changedOnlyMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show only changed since last frame"
+                                                              action:@selector(changedOnlyToggled:)
+                                                       keyEquivalent:@""];
+        self.changedOnlyMenuItem.target = self;
+        self.changedOnlyMenuItem.state = GoInitialChangedOnly() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.changedOnlyMenuItem];
+
+        self.freezeTopNMenuItem = [[NSMenuItem alloc] initWithTitle:@"Freeze top rows during each frame"
+                                                             action:@selector(freezeTopNToggled:)
+                                                      keyEquivalent:@""];
+        self.freezeTopNMenuItem.target = self;
+        self.freezeTopNMenuItem.state = GoInitialFreezeTopN() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.freezeTopNMenuItem];
+
+        self.menuBarMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show in menu bar"
+                                                          action:@selector(menuBarToggled:)
+                                                   keyEquivalent:@""];
+        self.menuBarMenuItem.target = self;
+        self.menuBarMenuItem.state = GoInitialMenuBarItem() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.menuBarMenuItem];
+
+        self.pauseOnCloseMenuItem = [[NSMenuItem alloc] initWithTitle:@"Pause while the window is hidden"
+                                                               action:@selector(pauseOnCloseToggled:)
+                                                        keyEquivalent:@""];
+        self.pauseOnCloseMenuItem.target = self;
+        self.pauseOnCloseMenuItem.state = GoInitialPauseOnWindowClose() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.pauseOnCloseMenuItem];
+
+        self.pauseMenuItem = [[NSMenuItem alloc] initWithTitle:@"Pause monitoring"
+                                                        action:@selector(pauseToggled:)
+                                                 keyEquivalent:@""];
+        self.pauseMenuItem.target = self;
+        [menu addItem:self.pauseMenuItem];
+
+        NSMenuItem *resumeItem = [[NSMenuItem alloc] initWithTitle:@"On resume"
+                                                            action:nil
+                                                     keyEquivalent:@""];
+        self.resumeModeMenu = [[NSMenu alloc] initWithTitle:@"On resume"];
+        int savedResumeMode = GoInitialResumeMode();
+        NSArray<NSString *> *resumeTitles = @[@"Restart the frame", @"Continue the frame"];
+        for (NSUInteger mode = 0; mode < resumeTitles.count; mode++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:resumeTitles[mode]
+                                                            action:@selector(resumeModeChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)mode;
+            choice.state = ((int)mode == savedResumeMode) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.resumeModeMenu addItem:choice];
+        }
+        resumeItem.submenu = self.resumeModeMenu;
+        [menu addItem:resumeItem];
+
+        self.kernelTaskMenuItem = [[NSMenuItem alloc] initWithTitle:@"Include kernel_task"
+                                                             action:@selector(kernelTaskToggled:)
+                                                      keyEquivalent:@""];
+        self.kernelTaskMenuItem.target = self;
+        self.kernelTaskMenuItem.state = GoInitialIncludeKernelTask() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.kernelTaskMenuItem];
+
+        self.onlyMineMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show only my processes"
+                                                           action:@selector(onlyMineToggled:)
+                                                    keyEquivalent:@""];
+        self.onlyMineMenuItem.target = self;
+        self.onlyMineMenuItem.state = GoInitialShowOnlyMine() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.onlyMineMenuItem];
+
+        NSMenuItem *groupItem = [[NSMenuItem alloc] initWithTitle:@"Group app helpers"
+                                                           action:nil
+                                                    keyEquivalent:@""];
+        self.groupModeMenu = [[NSMenu alloc] initWithTitle:@"Group app helpers"];
+        int savedGroupMode = GoInitialGroupMode();
+        NSArray<NSString *> *groupTitles = @[@"Off", @"Collapsed", @"Expanded"];
+        for (NSUInteger mode = 0; mode < groupTitles.count; mode++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:groupTitles[mode]
+                                                            action:@selector(groupModeChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)mode;
+            choice.state = ((int)mode == savedGroupMode) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.groupModeMenu addItem:choice];
+        }
+        groupItem.submenu = self.groupModeMenu;
+        [menu addItem:groupItem];
+
+        [menu addItem:[NSMenuItem separatorItem]];
+
+        self.averageModeMenuItem = [[NSMenuItem alloc] initWithTitle:@"Average over frames appeared in"
+                                                              action:@selector(averageModeToggled:)
+                                                       keyEquivalent:@""];
+        self.averageModeMenuItem.target = self;
+        self.averageModeMenuItem.state = GoInitialAverageMode() == 1 ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.averageModeMenuItem];
+
+        self.mergeReusedMenuItem = [[NSMenuItem alloc] initWithTitle:@"Merge reused PIDs"
+                                                              action:@selector(mergeReusedToggled:)
+                                                       keyEquivalent:@""];
+        self.mergeReusedMenuItem.target = self;
+        self.mergeReusedMenuItem.state = GoInitialMergeReusedPIDs() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.mergeReusedMenuItem];
+
+        NSMenuItem *minTotalItem = [[NSMenuItem alloc] initWithTitle:@"Summary minimum total"
+                                                              action:nil
+                                                       keyEquivalent:@""];
+        self.summaryMinTotalMenu = [[NSMenu alloc] initWithTitle:@"Summary minimum total"];
+        double savedMinTotal = GoInitialSummaryMinTotal();
+        for (NSNumber *seconds in @[@0, @1, @5, @30, @60]) {
+            NSString *title = seconds.doubleValue == 0
+                ? @"Show all"
+                : [NSString stringWithFormat:@"%@ CPU-s", seconds];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(summaryMinTotalChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = seconds;
+            preset.state = (seconds.doubleValue == savedMinTotal) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.summaryMinTotalMenu addItem:preset];
+        }
+        minTotalItem.submenu = self.summaryMinTotalMenu;
+        [menu addItem:minTotalItem];
+
+        NSMenuItem *spikeRatioItem = [[NSMenuItem alloc] initWithTitle:@"Spike ratio"
+                                                                action:nil
+                                                         keyEquivalent:@""];
+        self.spikeRatioMenu = [[NSMenu alloc] initWithTitle:@"Spike ratio"];
+        double savedSpikeRatio = GoInitialSpikeRatio();
+        for (NSNumber *ratio in @[@2, @3, @5, @10]) {
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:[NSString stringWithFormat:@"Peak over %@× average", ratio]
+                                                            action:@selector(spikeRatioChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = ratio;
+            preset.state = (ratio.doubleValue == savedSpikeRatio) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.spikeRatioMenu addItem:preset];
+        }
+        spikeRatioItem.submenu = self.spikeRatioMenu;
+        [menu addItem:spikeRatioItem];
+
+        NSMenuItem *minFramesItem = [[NSMenuItem alloc] initWithTitle:@"Summary minimum frames"
+                                                               action:nil
+                                                        keyEquivalent:@""];
+        self.summaryMinFramesMenu = [[NSMenu alloc] initWithTitle:@"Summary minimum frames"];
+        int savedMinFrames = GoInitialSummaryMinFrames();
+        for (NSNumber *frames in @[@1, @2, @3, @5, @10]) {
+            NSString *title = frames.intValue == 1
+                ? @"Show all"
+                : [NSString stringWithFormat:@"%@ frames", frames];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(summaryMinFramesChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = frames;
+            preset.state = (frames.intValue == savedMinFrames) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.summaryMinFramesMenu addItem:preset];
+        }
+        minFramesItem.submenu = self.summaryMinFramesMenu;
+        [menu addItem:minFramesItem];
+
+        NSMenuItem *tiebreakerItem = [[NSMenuItem alloc] initWithTitle:@"Order equal rows by"
+                                                                action:nil
+                                                         keyEquivalent:@""];
+        self.tiebreakerMenu = [[NSMenu alloc] initWithTitle:@"Order equal rows by"];
+        int savedTiebreaker = GoInitialTiebreaker();
+        NSArray<NSString *> *tiebreakerTitles = @[@"PID", @"Command", @"Start Time"];
+        for (NSUInteger tiebreak = 0; tiebreak < tiebreakerTitles.count; tiebreak++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:tiebreakerTitles[tiebreak]
+                                                            action:@selector(tiebreakerChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)tiebreak;
+            choice.state = ((int)tiebreak == savedTiebreaker) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.tiebreakerMenu addItem:choice];
+        }
+        tiebreakerItem.submenu = self.tiebreakerMenu;
+        [menu addItem:tiebreakerItem];
+
+        NSMenuItem *summarySortItem = [[NSMenuItem alloc] initWithTitle:@"Sort summary by"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.summarySortMenu = [[NSMenu alloc] initWithTitle:@"Sort summary by"];
+        int savedSummarySort = GoInitialSummarySortField();
+        NSArray<NSString *> *summarySortTitles = @[@"Total", @"Average", @"Peak %CPU", @"Std Dev",
+                                                   @"Appearances", @"Command", @"PID"];
+        for (NSUInteger field = 0; field < summarySortTitles.count; field++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:summarySortTitles[field]
+                                                            action:@selector(summarySortChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)field;
+            choice.state = ((int)field == savedSummarySort) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.summarySortMenu addItem:choice];
+        }
+        [self.summarySortMenu addItem:[NSMenuItem separatorItem]];
+        self.summaryDescendingItem = [[NSMenuItem alloc] initWithTitle:@"Descending"
+                                                                action:@selector(summaryDescendingToggled:)
+                                                         keyEquivalent:@""];
+        self.summaryDescendingItem.target = self;
+        self.summaryDescendingItem.state = GoInitialSummarySortDescending() ? NSControlStateValueOn : NSControlStateValueOff;
+        [self.summarySortMenu addItem:self.summaryDescendingItem];
+        summarySortItem.submenu = self.summarySortMenu;
+        [menu addItem:summarySortItem];
+
+        [menu addItem:[NSMenuItem separatorItem]];
+
+        NSMenuItem *secondFrameItem = [[NSMenuItem alloc] initWithTitle:@"Second frame length"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.secondFrameMenu = [[NSMenu alloc] initWithTitle:@"Second frame length"];
+        double savedSecondFrame = GoInitialSecondFrameSeconds();
+        for (NSNumber *seconds in @[@0, @5, @60, @300]) {
+            NSString *title = seconds.doubleValue == 0
+                ? @"Off"
+                : [NSString stringWithFormat:@"%@s", seconds];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(secondFrameChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = seconds;
+            preset.state = (seconds.doubleValue == savedSecondFrame) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.secondFrameMenu addItem:preset];
+        }
+        secondFrameItem.submenu = self.secondFrameMenu;
+        [menu addItem:secondFrameItem];
+
+        NSMenuItem *startDelayItem = [[NSMenuItem alloc] initWithTitle:@"Start delay"
+                                                                action:nil
+                                                         keyEquivalent:@""];
+        self.startDelayMenu = [[NSMenu alloc] initWithTitle:@"Start delay"];
+        double savedStartDelay = GoInitialStartDelay();
+        for (NSNumber *seconds in @[@0, @3, @5, @10]) {
+            NSString *title = seconds.doubleValue == 0
+                ? @"Off"
+                : [NSString stringWithFormat:@"%@s", seconds];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(startDelayChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = seconds;
+            preset.state = (seconds.doubleValue == savedStartDelay) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.startDelayMenu addItem:preset];
+        }
+        startDelayItem.submenu = self.startDelayMenu;
+        [menu addItem:startDelayItem];
+
+        NSMenuItem *frameWarmupItem = [[NSMenuItem alloc] initWithTitle:@"Warm-up per frame"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.frameWarmupMenu = [[NSMenu alloc] initWithTitle:@"Warm-up per frame"];
+        double savedFrameWarmup = GoInitialFrameWarmup();
+        for (NSNumber *seconds in @[@0, @1, @2, @5]) {
+            NSString *title = seconds.doubleValue == 0
+                ? @"Off"
+                : [NSString stringWithFormat:@"%@s", seconds];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(frameWarmupChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = seconds;
+            preset.state = (seconds.doubleValue == savedFrameWarmup) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.frameWarmupMenu addItem:preset];
+        }
+        frameWarmupItem.submenu = self.frameWarmupMenu;
+        [menu addItem:frameWarmupItem];
+
+        NSMenuItem *stopAfterItem = [[NSMenuItem alloc] initWithTitle:@"Stop after"
+                                                               action:nil
+                                                        keyEquivalent:@""];
+        self.stopAfterMenu = [[NSMenu alloc] initWithTitle:@"Stop after"];
+        int savedStopAfter = GoInitialStopAfterFrames();
+        for (NSNumber *frames in @[@0, @4, @10, @20, @60]) {
+            NSString *title = frames.intValue == 0
+                ? @"Never"
+                : [NSString stringWithFormat:@"%@ frames", frames];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(stopAfterChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = frames;
+            preset.state = (frames.intValue == savedStopAfter) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.stopAfterMenu addItem:preset];
+        }
+        stopAfterItem.submenu = self.stopAfterMenu;
+        [menu addItem:stopAfterItem];
+
+        NSMenuItem *completionAlertItem = [[NSMenuItem alloc] initWithTitle:@"Alert when monitoring ends"
+                                                                     action:nil
+                                                              keyEquivalent:@""];
+        self.completionAlertMenu = [[NSMenu alloc] initWithTitle:@"Alert when monitoring ends"];
+        int savedCompletionAlert = GoInitialCompletionAlert();
+        NSArray<NSString *> *completionAlertTitles = @[@"Never", @"When Finished", @"Also on Stop"];
+        for (NSUInteger alert = 0; alert < completionAlertTitles.count; alert++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:completionAlertTitles[alert]
+                                                            action:@selector(completionAlertChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)alert;
+            choice.state = ((int)alert == savedCompletionAlert) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.completionAlertMenu addItem:choice];
+        }
+        completionAlertItem.submenu = self.completionAlertMenu;
+        [menu addItem:completionAlertItem];
+
+        NSMenuItem *anomalySigmaItem = [[NSMenuItem alloc] initWithTitle:@"Flag unusual frames"
+                                                                  action:nil
+                                                           keyEquivalent:@""];
+        self.anomalySigmaMenu = [[NSMenu alloc] initWithTitle:@"Flag unusual frames"];
+        double savedAnomalySigma = GoInitialAnomalySigma();
+        for (NSNumber *sigma in @[@1.5, @2, @3, @4]) {
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:[NSString stringWithFormat:@"Over %@σ above recent frames", sigma]
+                                                            action:@selector(anomalySigmaChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = sigma;
+            preset.state = (sigma.doubleValue == savedAnomalySigma) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.anomalySigmaMenu addItem:preset];
+        }
+        anomalySigmaItem.submenu = self.anomalySigmaMenu;
+        [menu addItem:anomalySigmaItem];
+
+        self.notifyAnomaliesMenuItem = [[NSMenuItem alloc] initWithTitle:@"Notify about unusual frames"
+                                                                  action:@selector(notifyAnomaliesToggled:)
+                                                           keyEquivalent:@""];
+        self.notifyAnomaliesMenuItem.target = self;
+        self.notifyAnomaliesMenuItem.state = GoInitialNotifyAnomalies() ? NSControlStateValueOn : NSControlStateValueOff;
+        [menu addItem:self.notifyAnomaliesMenuItem];
+
+        NSMenuItem *retentionItem = [[NSMenuItem alloc] initWithTitle:@"Keep history"
+                                                               action:nil
+                                                        keyEquivalent:@""];
+        self.retentionMenu = [[NSMenu alloc] initWithTitle:@"Keep history"];
+        double savedRetention = GoInitialHistoryRetention();
+        for (NSNumber *minutes in @[@0, @10, @30, @60, @240]) {
+            NSString *title = minutes.doubleValue == 0
+                ? @"All"
+                : (minutes.intValue < 60
+                    ? [NSString stringWithFormat:@"Last %@ min", minutes]
+                    : [NSString stringWithFormat:@"Last %d h", minutes.intValue / 60]);
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(retentionChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = @(minutes.doubleValue * 60);
+            preset.state = (minutes.doubleValue * 60 == savedRetention) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.retentionMenu addItem:preset];
+        }
+        retentionItem.submenu = self.retentionMenu;
+        [menu addItem:retentionItem];
+
+        NSMenuItem *collectTopNItem = [[NSMenuItem alloc] initWithTitle:@"Keep per frame"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.collectTopNMenu = [[NSMenu alloc] initWithTitle:@"Keep per frame"];
+        int savedCollectTopN = GoInitialCollectTopN();
+        for (NSNumber *rows in @[@0, @10, @25, @50, @100]) {
+            NSString *title = rows.intValue == 0
+                ? @"All processes"
+                : [NSString stringWithFormat:@"Top %@", rows];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(collectTopNChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = rows;
+            preset.state = (rows.intValue == savedCollectTopN) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.collectTopNMenu addItem:preset];
+        }
+        collectTopNItem.submenu = self.collectTopNMenu;
+        [menu addItem:collectTopNItem];
+
+        self.showSecondFrameMenuItem = [[NSMenuItem alloc] initWithTitle:@"Show second frame length"
+                                                                  action:@selector(showSecondFrameToggled:)
+                                                           keyEquivalent:@""];
+        self.showSecondFrameMenuItem.target = self;
+        self.showSecondFrameMenuItem.state = NSControlStateValueOff;
+        [menu addItem:self.showSecondFrameMenuItem];
+
+        [menu addItem:[NSMenuItem separatorItem]];
+
+        NSMenuItem *referenceItem = [[NSMenuItem alloc] initWithTitle:@"Compare With This Frame"
+                                                               action:@selector(setReferenceFrame:)
+                                                        keyEquivalent:@""];
+        referenceItem.target = self;
+        [menu addItem:referenceItem];
+
+        NSMenuItem *clearReferenceItem = [[NSMenuItem alloc] initWithTitle:@"Clear Reference Frame"
+                                                                    action:@selector(clearReferenceFrame:)
+                                                             keyEquivalent:@""];
+        clearReferenceItem.target = self;
+        [menu addItem:clearReferenceItem];
+
+        [menu addItem:[NSMenuItem separatorItem]];
+
+        NSMenuItem *exportChartItem = [[NSMenuItem alloc] initWithTitle:@"Export Chart…"
+                                                                 action:@selector(exportChart:)
+                                                          keyEquivalent:@""];
+        exportChartItem.target = self;
+        [menu addItem:exportChartItem];
+
+        NSMenuItem *themeItem = [[NSMenuItem alloc] initWithTitle:@"Chart theme"
+                                                           action:nil
+                                                    keyEquivalent:@""];
+        self.themeMenu = [[NSMenu alloc] initWithTitle:@"Chart theme"];
+        int savedTheme = GoInitialTheme();
+        NSArray<NSString *> *themeTitles = @[@"System", @"Light", @"Dark"];
+        for (NSUInteger theme = 0; theme < themeTitles.count; theme++) {
+            NSMenuItem *choice = [[NSMenuItem alloc] initWithTitle:themeTitles[theme]
+                                                            action:@selector(themeChosen:)
+                                                     keyEquivalent:@""];
+            choice.target = self;
+            choice.tag = (NSInteger)theme;
+            choice.state = ((int)theme == savedTheme) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.themeMenu addItem:choice];
+        }
+        themeItem.submenu = self.themeMenu;
+        [menu addItem:themeItem];
+
+        NSMenuItem *chartExportItem = [[NSMenuItem alloc] initWithTitle:@"Save chart every"
+                                                                 action:nil
+                                                          keyEquivalent:@""];
+        self.chartExportMenu = [[NSMenu alloc] initWithTitle:@"Save chart every"];
+        int savedChartExport = GoInitialChartExportEvery();
+        for (NSNumber *frames in @[@0, @1, @5, @10]) {
+            NSString *title = frames.intValue == 0
+                ? @"Off"
+                : [NSString stringWithFormat:@"%@ frame%@…", frames, frames.intValue == 1 ? @"" : @"s"];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(chartExportChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = frames;
+            preset.state = (frames.intValue == savedChartExport) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.chartExportMenu addItem:preset];
+        }
+        chartExportItem.submenu = self.chartExportMenu;
+        [menu addItem:chartExportItem];
+
+        NSMenuItem *replayItem = [[NSMenuItem alloc] initWithTitle:@"Replay"
+                                                            action:nil
+                                                     keyEquivalent:@""];
+        self.replayMenu = [[NSMenu alloc] initWithTitle:@"Replay"];
+        self.replayMenu.autoenablesItems = NO;
+        NSMenuItem *playItem = [[NSMenuItem alloc] initWithTitle:@"Play"
+                                                          action:@selector(replayPlayToggled:)
+                                                   keyEquivalent:@""];
+        playItem.target = self;
+        [self.replayMenu addItem:playItem];
+        [self.replayMenu addItem:[NSMenuItem separatorItem]];
+        double savedReplaySpeed = GoInitialReplaySpeed();
+        for (NSNumber *speed in @[@0.5, @1, @2, @5]) {
+            NSString *title = [NSString stringWithFormat:@"%@ frame%@ per second", speed, speed.doubleValue == 1 ? @"" : @"s"];
+            NSMenuItem *preset = [[NSMenuItem alloc] initWithTitle:title
+                                                            action:@selector(replaySpeedChosen:)
+                                                     keyEquivalent:@""];
+            preset.target = self;
+            preset.representedObject = speed;
+            preset.state = (speed.doubleValue == savedReplaySpeed) ? NSControlStateValueOn : NSControlStateValueOff;
+            [self.replayMenu addItem:preset];
+        }
+        [self.replayMenu addItem:[NSMenuItem separatorItem]];
+        NSMenuItem *loopItem = [[NSMenuItem alloc] initWithTitle:@"Loop"
+                                                          action:@selector(replayLoopToggled:)
+                                                   keyEquivalent:@""];
+        loopItem.target = self;
+        loopItem.state = GoInitialReplayLoop() ? NSControlStateValueOn : NSControlStateValueOff;
+        [self.replayMenu addItem:loopItem];
+        replayItem.submenu = self.replayMenu;
+        [menu addItem:replayItem];
+
+        NSMenuItem *exportHistoryItem = [[NSMenuItem alloc] initWithTitle:@"Export History…"
+                                                                   action:@selector(exportHistory:)
+                                                            keyEquivalent:@""];
+        exportHistoryItem.target = self;
+        [menu addItem:exportHistoryItem];
+
+        NSMenuItem *loadBaselineItem = [[NSMenuItem alloc] initWithTitle:@"Load Baseline…"
+                                                                  action:@selector(loadBaseline:)
+                                                           keyEquivalent:@""];
+        loadBaselineItem.target = self;
+        [menu addItem:loadBaselineItem];
+
+        NSMenuItem *exportConfigItem = [[NSMenuItem alloc] initWithTitle:@"Export Settings…"
+                                                                  action:@selector(exportConfig:)
+                                                           keyEquivalent:@""];
+        exportConfigItem.target = self;
+        [menu addItem:exportConfigItem];
+
+        NSMenuItem *importConfigItem = [[NSMenuItem alloc] initWithTitle:@"Import Settings…"
+                                                                  action:@selector(importConfig:)
+                                                           keyEquivalent:@""];
+        importConfigItem.target = self;
+        [menu addItem:importConfigItem];
+
+        NSMenuItem *reloadConfigItem = [[NSMenuItem alloc] initWithTitle:@"Reload Settings"
+                                                                  action:@selector(reloadConfig:)
+                                                           keyEquivalent:@""];
+        reloadConfigItem.target = self;
+        [menu addItem:reloadConfigItem];
+
         item.view = self.settingsButton;
 #pragma clang diagnostic push
 #pragma clang diagnostic ignored "-Wdeprecated-declarations"
@@ -274,14 +1003,20 @@ static NSString * const kOptionsItem    = @"OptionsItem";
  *  2. Attaches the NSToolbar.
  *  3. Pins a 24 pt status bar to the bottom of the content view.
  *  4. Fills the remaining space with an NSSplitView containing the frame pane
- *     (top, ~58 %) and the summary pane (bottom, ~42 %).
- *  5. Configures both NSTableViews with their columns and delegates.
+ *     (top, ~58 %) and the summary pane (bottom, ~42 %), whose tabs hold the
+ *     per-process summary and the by-command table.
+ *  5. Configures the NSTableViews with their columns and delegates.
  *  6. Makes the window key and starts monitoring immediately with the last
  *     saved frame length.
  */
 - (void)applicationDidFinishLaunching:(NSNotification *)notification {
     (void)notification;
 
+    [NSApp addObserver:self
+            forKeyPath:@"effectiveAppearance"
+               options:NSKeyValueObservingOptionInitial
+               context:NULL];
+
     self.window = [[NSWindow alloc] initWithContentRect:NSMakeRect(0, 0, 1080, 680)
                                               styleMask:(NSWindowStyleMaskTitled |
                                                          NSWindowStyleMaskClosable |
@@ -292,6 +1027,14 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     [self.window setTitle:[NSString stringWithFormat:@"FrameScope %@", gAppVersion]];
     [self.window center];
     [self.window setMinSize:NSMakeSize(700, 480)];
+    [[NSNotificationCenter defaultCenter] addObserver:self
+                                             selector:@selector(windowOcclusionChanged:)
+                                                 name:NSWindowDidChangeOcclusionStateNotification
+                                               object:self.window];
+    [[NSNotificationCenter defaultCenter] addObserver:self
+                                             selector:@selector(windowWillClose:)
+                                                 name:NSWindowWillCloseNotification
+                                               object:self.window];
 
     NSToolbar *toolbar = [[NSToolbar alloc] initWithIdentifier:@"MainToolbar"];
     toolbar.delegate = self;
@@ -314,12 +1057,34 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     statusSep.autoresizingMask = NSViewWidthSizable | NSViewMinYMargin;
     [statusBar addSubview:statusSep];
 
+    // The machine meter sits at the right end, leaving the rest to the status.
+    CGFloat meterW = 80, meterLabelW = 58;
     self.statusLabel = [self makeLabel:@"Idle. Set a frame length and press Start."
-                                 frame:NSMakeRect(10, 5, W - 20, 15)];
+                                 frame:NSMakeRect(10, 5, W - 30 - meterW - meterLabelW, 15)];
     self.statusLabel.font = [NSFont systemFontOfSize:11];
     self.statusLabel.textColor = [NSColor secondaryLabelColor];
     self.statusLabel.autoresizingMask = NSViewWidthSizable;
     [statusBar addSubview:self.statusLabel];
+
+    self.machineLabel = [self makeLabel:@"CPU —"
+                                  frame:NSMakeRect(W - 10 - meterW - meterLabelW, 5, meterLabelW, 15)];
+    self.machineLabel.font = [NSFont monospacedDigitSystemFontOfSize:11 weight:NSFontWeightRegular];
+    self.machineLabel.textColor = [NSColor secondaryLabelColor];
+    self.machineLabel.alignment = NSTextAlignmentRight;
+    self.machineLabel.autoresizingMask = NSViewMinXMargin;
+    self.machineLabel.toolTip = @"CPU use of the whole machine over the last half second, all cores together";
+    [statusBar addSubview:self.machineLabel];
+
+    self.machineMeter = [[NSLevelIndicator alloc] initWithFrame:NSMakeRect(W - 6 - meterW, 6, meterW, 12)];
+    self.machineMeter.levelIndicatorStyle = NSLevelIndicatorStyleContinuousCapacity;
+    self.machineMeter.minValue = 0;
+    self.machineMeter.maxValue = 100;
+    self.machineMeter.warningValue = 70;
+    self.machineMeter.criticalValue = 90;
+    self.machineMeter.enabled = NO;
+    self.machineMeter.autoresizingMask = NSViewMinXMargin;
+    self.machineMeter.toolTip = self.machineLabel.toolTip;
+    [statusBar addSubview:self.machineMeter];
     [content addSubview:statusBar];
 
     // ── NSSplitView (fills everything above the status bar) ──────────────────
@@ -356,13 +1121,66 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     self.resultsTable.gridStyleMask = NSTableViewSolidVerticalGridLineMask;
     self.resultsTable.dataSource = self;
     self.resultsTable.delegate = self;
-    [self.resultsTable addTableColumn:[self columnWithID:@"pid"     title:@"PID"      width:80  minWidth:60]];
-    [self.resultsTable addTableColumn:[self columnWithID:@"raw"     title:@"Raw (s)"  width:82  minWidth:60]];
-    [self.resultsTable addTableColumn:[self columnWithID:@"cpu"     title:@"CPU Time" width:110 minWidth:90]];
+    /* Column identifiers are the Go column names (see GoSetColumns). */
+    self.frameColumnOrder = ColumnList(GoInitialColumns());
+    [self.resultsTable addTableColumn:[self columnWithID:@"pid"      title:@"PID"      width:80  minWidth:60]];
+    [self.resultsTable addTableColumn:[self columnWithID:@"cpu"      title:@"Raw (s)"  width:82  minWidth:60]];
+    [self.resultsTable addTableColumn:[self columnWithID:@"duration" title:@"CPU Time" width:110 minWidth:90]];
+    self.shareColumn = [self columnWithID:@"share" title:@"Share" width:70 minWidth:56];
+    self.shareColumn.hidden = !GoInitialShowShare();
+    [self.resultsTable addTableColumn:self.shareColumn];
+    self.trendColumn = [self columnWithID:@"trend" title:@"Trend" width:150 minWidth:100];
+    self.trendColumn.hidden = !GoInitialShowSparklines();
+    [self.resultsTable addTableColumn:self.trendColumn];
+    self.statusColumn = [self columnWithID:@"status" title:@"State" width:48 minWidth:40];
+    self.statusColumn.hidden = !GoInitialShowStatus();
+    [self.resultsTable addTableColumn:self.statusColumn];
     NSTableColumn *cmdCol = [self columnWithID:@"command" title:@"Command" width:700 minWidth:200];
     cmdCol.resizingMask = NSTableColumnAutoresizingMask | NSTableColumnUserResizingMask;
     [self.resultsTable addTableColumn:cmdCol];
+    self.rateColumn = [self columnWithID:@"rate" title:@"Now" width:70 minWidth:56];
+    self.rateColumn.hidden = YES;
+    [self.resultsTable addTableColumn:self.rateColumn];
+    self.percentColumn = [self columnWithID:@"percent" title:@"%CPU" width:70 minWidth:56];
+    self.percentColumn.hidden = !GoInitialShowPercent();
+    [self.resultsTable addTableColumn:self.percentColumn];
+    self.userColumn = [self columnWithID:@"user" title:@"User (s)" width:70 minWidth:56];
+    self.userColumn.hidden = !GoInitialShowUserSys();
+    [self.resultsTable addTableColumn:self.userColumn];
+    self.sysColumn = [self columnWithID:@"sys" title:@"Sys (s)" width:70 minWidth:56];
+    self.sysColumn.hidden = !GoInitialShowUserSys();
+    [self.resultsTable addTableColumn:self.sysColumn];
+    self.deltaColumn = [self columnWithID:@"delta" title:@"vs Last" width:70 minWidth:56];
+    self.deltaColumn.hidden = !GoInitialShowLiveDelta();
+    [self.resultsTable addTableColumn:self.deltaColumn];
+    [self arrangeColumnsOfTable:self.resultsTable order:self.frameColumnOrder prefix:@""];
     self.tableScrollView.documentView = self.resultsTable;
+
+    NSMenu *rowMenu = [[NSMenu alloc] initWithTitle:@"Row"];
+    NSMenuItem *copyPidItem = [[NSMenuItem alloc] initWithTitle:@"Copy PID"
+                                                         action:@selector(copyRowField:)
+                                                  keyEquivalent:@""];
+    copyPidItem.target = self;
+    copyPidItem.tag = 0;
+    [rowMenu addItem:copyPidItem];
+    NSMenuItem *copyCommandItem = [[NSMenuItem alloc] initWithTitle:@"Copy Command"
+                                                             action:@selector(copyRowField:)
+                                                      keyEquivalent:@""];
+    copyCommandItem.target = self;
+    copyCommandItem.tag = 1;
+    [rowMenu addItem:copyCommandItem];
+    [rowMenu addItem:[NSMenuItem separatorItem]];
+    NSMenuItem *focusItem = [[NSMenuItem alloc] initWithTitle:@"Focus on This Process and Its Children"
+                                                       action:@selector(focusOnRow:)
+                                                keyEquivalent:@""];
+    focusItem.target = self;
+    [rowMenu addItem:focusItem];
+    NSMenuItem *clearFocusItem = [[NSMenuItem alloc] initWithTitle:@"Monitor All Processes"
+                                                            action:@selector(clearFocus:)
+                                                     keyEquivalent:@""];
+    clearFocusItem.target = self;
+    [rowMenu addItem:clearFocusItem];
+    self.resultsTable.menu = rowMenu;
     [framePane addSubview:self.tableScrollView];
 
     self.emptyLabel = [self makeLabel:@"Press Start to begin."
@@ -382,8 +1200,24 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     summaryHeader.frame = NSMakeRect(0, summaryPaneH - headerH, W, headerH);
     summaryHeader.autoresizingMask = NSViewWidthSizable | NSViewMinYMargin;
     [summaryPane addSubview:summaryHeader];
-
-    self.summaryScrollView = [[NSScrollView alloc] initWithFrame:NSMakeRect(0, 0, W, summaryPaneH - headerH)];
+    // makeSectionHeader adds the title label as its first subview.
+    self.summaryTitleLabel = (NSTextField *)summaryHeader.subviews.firstObject;
+
+    CGFloat statsH = 18;
+    self.summaryStatsLabel = [self makeLabel:@""
+                                       frame:NSMakeRect(10, summaryPaneH - headerH - statsH + 2, W - 20, 15)];
+    self.summaryStatsLabel.font = [NSFont systemFontOfSize:11];
+    self.summaryStatsLabel.textColor = [NSColor secondaryLabelColor];
+    self.summaryStatsLabel.lineBreakMode = NSLineBreakByTruncatingTail;
+    self.summaryStatsLabel.autoresizingMask = NSViewWidthSizable | NSViewMinYMargin;
+    [summaryPane addSubview:self.summaryStatsLabel];
+
+    NSTabView *summaryTabs = [[NSTabView alloc] initWithFrame:NSMakeRect(0, 0, W, summaryPaneH - headerH - statsH)];
+    summaryTabs.controlSize = NSControlSizeSmall;
+    summaryTabs.font = [NSFont systemFontOfSize:11];
+    summaryTabs.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+
+    self.summaryScrollView = [[NSScrollView alloc] initWithFrame:summaryTabs.contentRect];
     self.summaryScrollView.hasVerticalScroller = YES;
     self.summaryScrollView.hasHorizontalScroller = YES;
     self.summaryScrollView.autohidesScrollers = YES;
@@ -398,16 +1232,136 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     self.summaryTable.gridStyleMask = NSTableViewSolidVerticalGridLineMask;
     self.summaryTable.dataSource = self;
     self.summaryTable.delegate = self;
-    [self.summaryTable addTableColumn:[self columnWithID:@"sum_pid"       title:@"PID"       width:80  minWidth:60]];
-    [self.summaryTable addTableColumn:[self columnWithID:@"sum_total"     title:@"Total (s)" width:82  minWidth:60]];
-    [self.summaryTable addTableColumn:[self columnWithID:@"sum_avg"       title:@"Avg (s)"   width:78  minWidth:60]];
-    [self.summaryTable addTableColumn:[self columnWithID:@"sum_total_cpu" title:@"Total CPU" width:100 minWidth:80]];
-    [self.summaryTable addTableColumn:[self columnWithID:@"sum_avg_cpu"   title:@"Avg CPU"   width:100 minWidth:80]];
-    NSTableColumn *sumCmdCol = [self columnWithID:@"sum_command" title:@"Command" width:530 minWidth:180];
+    /* Column identifiers are "sum_" and the Go column names (see GoSetSummaryColumns). */
+    self.summaryColumnOrder = ColumnList(GoInitialSummaryColumns());
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_pid"              title:@"PID"       width:80  minWidth:60]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_total"            title:@"Total (s)" width:82  minWidth:60]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_average"          title:@"Avg (s)"   width:78  minWidth:60]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_total_duration"   title:@"Total CPU" width:100 minWidth:80]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_average_duration" title:@"Avg CPU"   width:100 minWidth:80]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_peak"             title:@"Peak %CPU" width:110 minWidth:90]];
+    NSTableColumn *sumCmdCol = [self columnWithID:@"sum_command" title:@"Command" width:420 minWidth:180];
     sumCmdCol.resizingMask = NSTableColumnAutoresizingMask | NSTableColumnUserResizingMask;
     [self.summaryTable addTableColumn:sumCmdCol];
+    self.sumPercentColumn = [self columnWithID:@"sum_average_percent" title:@"Avg %CPU" width:80 minWidth:60];
+    self.sumPercentColumn.hidden = !GoInitialShowPercent();
+    [self.summaryTable addTableColumn:self.sumPercentColumn];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_since_viewed" title:@"Since Viewed" width:96 minWidth:72]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_stddev"       title:@"Std Dev (s)"  width:84 minWidth:60]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_appearances"  title:@"Frames"       width:64 minWidth:48]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_spiky"        title:@"Spiky"        width:56 minWidth:44]];
+    [self.summaryTable addTableColumn:[self columnWithID:@"sum_processes"    title:@"Processes"    width:72 minWidth:52]];
+    [self arrangeColumnsOfTable:self.summaryTable order:self.summaryColumnOrder prefix:@"sum_"];
     self.summaryScrollView.documentView = self.summaryTable;
-    [summaryPane addSubview:self.summaryScrollView];
+
+    NSMenu *summaryMenu = [[NSMenu alloc] initWithTitle:@"Summary"];
+    NSMenuItem *timelineItem = [[NSMenuItem alloc] initWithTitle:@"Export Timeline…"
+                                                          action:@selector(exportPidTimeline:)
+                                                   keyEquivalent:@""];
+    timelineItem.target = self;
+    timelineItem.tag = 1;
+    [summaryMenu addItem:timelineItem];
+    NSMenuItem *sparseTimelineItem = [[NSMenuItem alloc] initWithTitle:@"Export Timeline (Frames Present Only)…"
+                                                                action:@selector(exportPidTimeline:)
+                                                         keyEquivalent:@""];
+    sparseTimelineItem.target = self;
+    sparseTimelineItem.tag = 0;
+    [summaryMenu addItem:sparseTimelineItem];
+    self.summaryTable.menu = summaryMenu;
+
+    NSTabViewItem *processTab = [[NSTabViewItem alloc] initWithIdentifier:@"processes"];
+    processTab.label = @"By Process";
+    processTab.view = self.summaryScrollView;
+    [summaryTabs addTabViewItem:processTab];
+
+    self.commandScrollView = [[NSScrollView alloc] initWithFrame:summaryTabs.contentRect];
+    self.commandScrollView.hasVerticalScroller = YES;
+    self.commandScrollView.hasHorizontalScroller = YES;
+    self.commandScrollView.autohidesScrollers = YES;
+    self.commandScrollView.borderType = NSNoBorder;
+    self.commandScrollView.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+
+    self.commandTable = [[NSTableView alloc] initWithFrame:self.commandScrollView.bounds];
+    self.commandTable.usesAlternatingRowBackgroundColors = YES;
+    self.commandTable.allowsColumnResizing = YES;
+    self.commandTable.allowsTypeSelect = YES;
+    self.commandTable.rowSizeStyle = NSTableViewRowSizeStyleDefault;
+    self.commandTable.gridStyleMask = NSTableViewSolidVerticalGridLineMask;
+    self.commandTable.dataSource = self;
+    self.commandTable.delegate = self;
+    [self.commandTable addTableColumn:[self columnWithID:@"cmd_total"     title:@"Total (s)" width:82  minWidth:60]];
+    [self.commandTable addTableColumn:[self columnWithID:@"cmd_total_cpu" title:@"Total CPU" width:100 minWidth:80]];
+    [self.commandTable addTableColumn:[self columnWithID:@"cmd_pids"      title:@"PIDs"      width:60  minWidth:44]];
+    NSTableColumn *cmdNameCol = [self columnWithID:@"cmd_command" title:@"Command" width:420 minWidth:180];
+    cmdNameCol.resizingMask = NSTableColumnAutoresizingMask | NSTableColumnUserResizingMask;
+    [self.commandTable addTableColumn:cmdNameCol];
+    self.commandScrollView.documentView = self.commandTable;
+
+    NSTabViewItem *commandTab = [[NSTabViewItem alloc] initWithIdentifier:@"commands"];
+    commandTab.label = @"By Command";
+    commandTab.view = self.commandScrollView;
+    [summaryTabs addTabViewItem:commandTab];
+
+    self.userScrollView = [[NSScrollView alloc] initWithFrame:summaryTabs.contentRect];
+    self.userScrollView.hasVerticalScroller = YES;
+    self.userScrollView.hasHorizontalScroller = YES;
+    self.userScrollView.autohidesScrollers = YES;
+    self.userScrollView.borderType = NSNoBorder;
+    self.userScrollView.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+
+    self.userTable = [[NSTableView alloc] initWithFrame:self.userScrollView.bounds];
+    self.userTable.usesAlternatingRowBackgroundColors = YES;
+    self.userTable.allowsColumnResizing = YES;
+    self.userTable.allowsTypeSelect = YES;
+    self.userTable.rowSizeStyle = NSTableViewRowSizeStyleDefault;
+    self.userTable.gridStyleMask = NSTableViewSolidVerticalGridLineMask;
+    self.userTable.dataSource = self;
+    self.userTable.delegate = self;
+    [self.userTable addTableColumn:[self columnWithID:@"user_total"     title:@"Total (s)" width:82  minWidth:60]];
+    [self.userTable addTableColumn:[self columnWithID:@"user_total_cpu" title:@"Total CPU" width:100 minWidth:80]];
+    [self.userTable addTableColumn:[self columnWithID:@"user_pids"      title:@"PIDs"      width:60  minWidth:44]];
+    NSTableColumn *userNameCol = [self columnWithID:@"user_name" title:@"User" width:240 minWidth:120];
+    userNameCol.resizingMask = NSTableColumnAutoresizingMask | NSTableColumnUserResizingMask;
+    [self.userTable addTableColumn:userNameCol];
+    self.userScrollView.documentView = self.userTable;
+
+    NSTabViewItem *userTab = [[NSTabViewItem alloc] initWithIdentifier:@"users"];
+    userTab.label = @"By User";
+    userTab.view = self.userScrollView;
+    [summaryTabs addTabViewItem:userTab];
+
+    self.comparisonScrollView = [[NSScrollView alloc] initWithFrame:summaryTabs.contentRect];
+    self.comparisonScrollView.hasVerticalScroller = YES;
+    self.comparisonScrollView.hasHorizontalScroller = YES;
+    self.comparisonScrollView.autohidesScrollers = YES;
+    self.comparisonScrollView.borderType = NSNoBorder;
+    self.comparisonScrollView.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+
+    self.comparisonTable = [[NSTableView alloc] initWithFrame:self.comparisonScrollView.bounds];
+    self.comparisonTable.usesAlternatingRowBackgroundColors = YES;
+    self.comparisonTable.allowsColumnResizing = YES;
+    self.comparisonTable.allowsTypeSelect = YES;
+    self.comparisonTable.rowSizeStyle = NSTableViewRowSizeStyleDefault;
+    self.comparisonTable.gridStyleMask = NSTableViewSolidVerticalGridLineMask;
+    self.comparisonTable.dataSource = self;
+    self.comparisonTable.delegate = self;
+    [self.comparisonTable addTableColumn:[self columnWithID:@"cmp_baseline" title:@"Baseline %" width:82 minWidth:60]];
+    [self.comparisonTable addTableColumn:[self columnWithID:@"cmp_current"  title:@"Current %"  width:82 minWidth:60]];
+    [self.comparisonTable addTableColumn:[self columnWithID:@"cmp_change"   title:@"Change"     width:72 minWidth:56]];
+    [self.comparisonTable addTableColumn:[self columnWithID:@"cmp_marker"   title:@""           width:44 minWidth:32]];
+    NSTableColumn *comparisonNameCol = [self columnWithID:@"cmp_command" title:@"Command" width:240 minWidth:120];
+    comparisonNameCol.resizingMask = NSTableColumnAutoresizingMask | NSTableColumnUserResizingMask;
+    [self.comparisonTable addTableColumn:comparisonNameCol];
+    self.comparisonScrollView.documentView = self.comparisonTable;
+
+    NSTabViewItem *comparisonTab = [[NSTabViewItem alloc] initWithIdentifier:@"compare"];
+    comparisonTab.label = @"Compare";
+    comparisonTab.view = self.comparisonScrollView;
+    [summaryTabs addTabViewItem:comparisonTab];
+    /* Set after the tabs are added, so that adding them is not taken for a look. */
+    summaryTabs.delegate = self;
+    self.summaryTabs = summaryTabs;
+    [summaryPane addSubview:summaryTabs];
 
     self.summaryEmptyLabel = [self makeLabel:@"Completed frames will appear here."
                                        frame:NSMakeRect(14, 44, 240, 18)];
@@ -421,6 +1375,7 @@ static NSString * const kOptionsItem    = @"OptionsItem";
 
     self.frameRows    = @[];
     self.summaryRows  = @[];
+    self.commandRows  = @[];
     self.historyItems = @[];
     [self refreshEmptyState];
     [self refreshHistoryControls];
@@ -435,18 +1390,94 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     });
 }
 
-/** Stops monitoring and terminates the app when the last window is closed. */
+/**
+ * Terminates the app when the last window is closed, unless the menu bar
+ * indicator is enabled: it keeps monitoring, and clicking it reopens the window.
+ */
 - (BOOL)applicationShouldTerminateAfterLastWindowClosed:(NSApplication *)sender {
     (void)sender;
-    GoStopMonitoring();
-    return YES;
+    return self.menuBarMenuItem.state != NSControlStateValueOn;
+}
+
+/**
+ * Counts coming back to the app with the By Process tab showing as a look at
+ * the summary (see GoMarkSummaryViewed).
+ */
+- (void)applicationDidBecomeActive:(NSNotification *)notification {
+    (void)notification;
+    if ([self.summaryTabs.selectedTabViewItem.identifier isEqual:@"processes"]) GoMarkSummaryViewed();
+}
+
+/**
+ * Counts selecting the By Process tab as a look at the summary, so that its
+ * Since Viewed column shows what changed since the previous one.
+ */
+- (void)tabView:(NSTabView *)tabView didSelectTabViewItem:(nullable NSTabViewItem *)tabViewItem {
+    (void)tabView;
+    if ([tabViewItem.identifier isEqual:@"processes"]) GoMarkSummaryViewed();
+}
+
+/** Brings the main window back when the menu bar item is clicked. */
+- (void)statusItemClicked:(id)sender {
+    (void)sender;
+    [self.window makeKeyAndOrderFront:nil];
+    [NSApp activateIgnoringOtherApps:YES];
+}
+
+/**
+ * Shows text in the menu bar item, creating the item on first use, or removes
+ * the item when text is empty. Must be called on the main thread.
+ */
+- (void)applyStatusItemText:(NSString *)text {
+    if (text.length == 0) {
+        if (self.statusItem != nil) {
+            [[NSStatusBar systemStatusBar] removeStatusItem:self.statusItem];
+            self.statusItem = nil;
+        }
+        return;
+    }
+    if (self.statusItem == nil) {
+        self.statusItem = [[NSStatusBar systemStatusBar] statusItemWithLength:NSVariableStatusItemLength];
+        self.statusItem.button.font = [NSFont monospacedDigitSystemFontOfSize:0 weight:NSFontWeightRegular];
+        self.statusItem.button.target = self;
+        self.statusItem.button.action = @selector(statusItemClicked:);
+    }
+    self.statusItem.button.title = text;
+    self.statusItem.button.toolTip = self.statusLabel.stringValue;
+}
+
+/**
+ * Shows the whole machine's %CPU in the status bar meter, or empties it while
+ * percent is negative (unknown). Must be called on the main thread.
+ */
+- (void)applyMachineCPU:(double)percent {
+    if (percent < 0) {
+        self.machineMeter.doubleValue = 0;
+        self.machineLabel.stringValue = @"CPU —";
+        return;
+    }
+    self.machineMeter.doubleValue = percent;
+    self.machineLabel.stringValue = [NSString stringWithFormat:@"CPU %.0f%%", percent];
+}
+
+/**
+ * Finishes the frame in progress and flushes the recording before quitting,
+ * whether by closing the window or by Quit.
+ */
+- (void)applicationWillTerminate:(NSNotification *)notification {
+    (void)notification;
+    GoWillTerminate();
 }
 
 #pragma mark - Actions
 
-/** Reads the current frame-field value and starts a new monitoring run. */
+/**
+ * Reads the current frame-field value and starts a new monitoring run, which
+ * shows the main frame length.
+ */
 - (void)startPressed:(id)sender {
     (void)sender;
+    self.showSecondFrameMenuItem.state = NSControlStateValueOff;
     GoStartMonitoring(self.frameField.doubleValue);
 }
 
@@ -478,180 +1509,1073 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     GoSetHidePaths(self.hidePathsMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
-/** Pops the Settings drop-down menu directly below the Settings button. */
-- (void)showSettingsMenu:(id)sender {
-    NSButton *button = (NSButton *)sender;
-    if (button.menu == nil) return;
-    [button.menu popUpMenuPositioningItem:nil
-                               atLocation:NSMakePoint(0, NSHeight(button.bounds) + 4)
-                                   inView:button];
+/**
+ * Toggles the "Show trend sparklines" menu item state, shows or hides the
+ * Trend column, and propagates the change to Go.
+ */
+- (void)sparklinesToggled:(id)sender {
+    (void)sender;
+    self.sparklinesMenuItem.state =
+        (self.sparklinesMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.sparklinesMenuItem.state == NSControlStateValueOn);
+    self.trendColumn.hidden = !on;
+    GoSetShowSparklines(on ? 1 : 0);
 }
 
 /**
- * Called when the user picks an item in the history popup. The guard flag
- * updatingHistorySelection prevents this from firing during programmatic
- * selection changes made by applyHistoryPayload:.
+ * Toggles the "Show share of frame total" menu item state, shows or hides the
+ * Share column, and propagates the change to Go.
  */
-- (void)historyChanged:(id)sender {
+- (void)shareToggled:(id)sender {
     (void)sender;
-    if (self.updatingHistorySelection) return;
-    GoSelectFrame((int)self.historyPopup.indexOfSelectedItem);
+    self.shareMenuItem.state =
+        (self.shareMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.shareMenuItem.state == NSControlStateValueOn);
+    self.shareColumn.hidden = !on;
+    GoSetShowShare(on ? 1 : 0);
 }
 
-/** Moves selection one step earlier in the history popup. */
-- (void)previousFrame:(id)sender {
+/** Toggles the "Leave FrameScope out of totals" item and propagates the change to Go. */
+- (void)noiseSelfToggled:(NSMenuItem *)sender {
+    sender.state = (sender.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetNoiseIncludesSelf(sender.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Toggles the "Show %CPU alongside CPU-seconds" menu item state, shows or
+ * hides the %CPU and Avg %CPU columns, and propagates the change to Go.
+ */
+- (void)percentToggled:(id)sender {
     (void)sender;
-    NSInteger index = self.historyPopup.indexOfSelectedItem;
-    if (index > 0) {
-        [self.historyPopup selectItemAtIndex:index - 1];
-        [self historyChanged:self.historyPopup];
-    }
+    self.percentMenuItem.state =
+        (self.percentMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.percentMenuItem.state == NSControlStateValueOn);
+    self.percentColumn.hidden = !on;
+    self.sumPercentColumn.hidden = !on;
+    GoSetShowPercent(on ? 1 : 0);
 }
 
-/** Moves selection one step later in the history popup. */
-- (void)nextFrame:(id)sender {
+/**
+ * Toggles the "Compare live frame with the last one" menu item state, shows
+ * or hides the vs Last column, and propagates the change to Go.
+ */
+- (void)liveDeltaToggled:(id)sender {
     (void)sender;
-    NSInteger index = self.historyPopup.indexOfSelectedItem;
-    if (index >= 0 && index + 1 < self.historyPopup.numberOfItems) {
-        [self.historyPopup selectItemAtIndex:index + 1];
-        [self historyChanged:self.historyPopup];
-    }
+    self.liveDeltaMenuItem.state =
+        (self.liveDeltaMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.liveDeltaMenuItem.state == NSControlStateValueOn);
+    self.deltaColumn.hidden = !on;
+    GoSetShowLiveDelta(on ? 1 : 0);
 }
 
-#pragma mark - NSTableViewDataSource / Delegate
+/**
+ * Toggles the "Split CPU into user and system" menu item state, shows or
+ * hides the User and Sys columns, and propagates the change to Go.
+ */
+- (void)userSysToggled:(id)sender {
+    (void)sender;
+    self.userSysMenuItem.state =
+        (self.userSysMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.userSysMenuItem.state == NSControlStateValueOn);
+    self.userColumn.hidden = !on;
+    self.sysColumn.hidden = !on;
+    GoSetShowUserSys(on ? 1 : 0);
+}
 
-/** Returns the number of rows for the given table view. */
-- (NSInteger)numberOfRowsInTableView:(NSTableView *)tableView {
-    return (tableView == self.summaryTable)
-        ? (NSInteger)self.summaryRows.count
-        : (NSInteger)self.frameRows.count;
+/**
+ * Toggles the "Show <0.1 for tiny CPU values" menu item state and propagates
+ * the change to Go, which re-renders both tables.
+ */
+- (void)markTinyToggled:(id)sender {
+    (void)sender;
+    self.markTinyMenuItem.state =
+        (self.markTinyMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetMarkTinyValues(self.markTinyMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
 /**
- * Returns a cell view for the given table/column/row combination. Cells are
- * reused from the table's view pool. Each cell is a non-editable, selectable
- * NSTextField using the system monospaced font at 12 pt. The full cell value
- * is also set as the tooltip so truncated content remains readable.
+ * Toggles the "Mark zombie and stopped processes" menu item state, shows or
+ * hides the State column, and propagates the change to Go.
  */
-- (nullable NSView *)tableView:(NSTableView *)tableView
-            viewForTableColumn:(NSTableColumn *)tableColumn
-                           row:(NSInteger)row {
-    NSString *identifier = tableColumn.identifier;
-    NSTextField *cell = [tableView makeViewWithIdentifier:identifier owner:self];
-    if (!cell) {
-        cell = [[NSTextField alloc] initWithFrame:NSZeroRect];
-        cell.identifier = identifier;
-        cell.bezeled = NO;
-        cell.drawsBackground = NO;
-        cell.editable = NO;
-        cell.selectable = YES;
-        cell.lineBreakMode = NSLineBreakByTruncatingMiddle;
-        cell.font = [NSFont monospacedSystemFontOfSize:12 weight:NSFontWeightRegular];
-    }
-    NSArray<NSArray<NSString *> *> *rows = (tableView == self.summaryTable)
-        ? self.summaryRows : self.frameRows;
-    NSArray<NSString *> *rowValues = rows[(NSUInteger)row];
-    NSUInteger col = [tableView.tableColumns indexOfObject:tableColumn];
-    cell.stringValue = col < rowValues.count ? rowValues[col] : @"";
-    cell.toolTip = cell.stringValue;
-    return cell;
+- (void)statusToggled:(id)sender {
+    (void)sender;
+    self.statusMenuItem.state =
+        (self.statusMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    BOOL on = (self.statusMenuItem.state == NSControlStateValueOn);
+    self.statusColumn.hidden = !on;
+    GoSetShowStatus(on ? 1 : 0);
 }
 
-#pragma mark - Data Updates
+/**
+ * Toggles the "Show only changed since last frame" menu item state and
+ * propagates the change to Go.
+ */
+- (void)changedOnlyToggled:(id)sender {
+    (void)sender;
+    self.changedOnlyMenuItem.state =
+        (self.changedOnlyMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetChangedOnly(self.changedOnlyMenuItem.state == NSControlStateValueOn ? 1 : 0);
+}
 
 /**
- * Parses a tab-separated, newline-delimited payload string into a 2-D array
- * of strings suitable for direct use by the table data source. Each row is
- * padded with empty strings to guarantee at least n columns.
+ * Toggles the "Freeze top rows during each frame" menu item state and
+ * propagates the change to Go.
  */
-- (NSArray<NSArray<NSString *> *> *)parseRows:(NSString *)payload columns:(NSUInteger)n {
-    NSMutableArray *result = [NSMutableArray array];
-    for (NSString *line in [payload componentsSeparatedByCharactersInSet:
-                             [NSCharacterSet newlineCharacterSet]]) {
-        if (!line.length) continue;
-        NSMutableArray *row = [[line componentsSeparatedByString:@"\t"] mutableCopy];
-        while (row.count < n) [row addObject:@""];
-        [result addObject:row];
-    }
-    return result;
+- (void)freezeTopNToggled:(id)sender {
+    (void)sender;
+    self.freezeTopNMenuItem.state =
+        (self.freezeTopNMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetFreezeTopN(self.freezeTopNMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
 /**
- * Replaces the frame table data with the parsed payload and reloads the table.
- * Must be called on the main thread.
+ * Toggles the "Show in menu bar" menu item state and propagates the change to
+ * Go, which shows or clears the indicator with its next update.
  */
-- (void)applyRowsPayload:(NSString *)payload {
-    self.frameRows = [self parseRows:payload columns:4];
-    [self.resultsTable reloadData];
-    [self refreshEmptyState];
+- (void)menuBarToggled:(id)sender {
+    (void)sender;
+    self.menuBarMenuItem.state =
+        (self.menuBarMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetMenuBarItem(self.menuBarMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
 /**
- * Replaces the summary table data with the parsed payload and reloads the
- * table. Must be called on the main thread.
+ * Toggles the "Pause while the window is hidden" menu item state and
+ * propagates the change to Go.
  */
-- (void)applySummaryPayload:(NSString *)payload {
-    self.summaryRows = [self parseRows:payload columns:6];
-    [self.summaryTable reloadData];
-    [self refreshEmptyState];
+- (void)pauseOnCloseToggled:(id)sender {
+    (void)sender;
+    self.pauseOnCloseMenuItem.state =
+        (self.pauseOnCloseMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetPauseOnWindowClose(self.pauseOnCloseMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
 /**
- * Rebuilds the history popup items from the newline-separated payload and
- * selects the item at selectedIndex (-1 leaves the selection unchanged).
- * Sets updatingHistorySelection to suppress the historyChanged: callback
- * while modifying the popup programmatically. Must be called on the main thread.
+ * Pauses the active run, or resumes it if it is paused for any reason. The
+ * checkmark is refreshed from Go whenever the menu opens, since Start, Stop,
+ * and the window can change the state too.
  */
-- (void)applyHistoryPayload:(NSString *)payload selectedIndex:(NSInteger)selectedIndex {
-    NSMutableArray<NSString *> *items = [NSMutableArray array];
-    for (NSString *s in [payload componentsSeparatedByCharactersInSet:
-                          [NSCharacterSet newlineCharacterSet]]) {
-        if (s.length) [items addObject:s];
-    }
-    self.historyItems = items;
-    self.updatingHistorySelection = YES;
-    [self.historyPopup removeAllItems];
-    if (items.count > 0) {
-        [self.historyPopup addItemsWithTitles:items];
-        if (selectedIndex >= 0 && selectedIndex < (NSInteger)items.count) {
-            [self.historyPopup selectItemAtIndex:selectedIndex];
-        }
+- (void)pauseToggled:(id)sender {
+    (void)sender;
+    if (GoIsPaused()) {
+        GoResumeMonitoring();
     } else {
-        [self.historyPopup addItemWithTitle:@"No frames yet"];
+        GoPauseMonitoring();
     }
-    self.updatingHistorySelection = NO;
-    [self refreshHistoryControls];
 }
 
 /**
- * Shows or hides the "empty" placeholder labels based on whether the
- * corresponding table has any rows. Must be called on the main thread.
+ * Reports the main window's visibility to Go whenever it changes, so that
+ * monitoring can pause while the window is minimized or covered (see
+ * GoSetPauseOnWindowClose).
  */
-- (void)refreshEmptyState {
-    self.emptyLabel.hidden = (self.frameRows.count != 0);
-    self.summaryEmptyLabel.hidden = (self.summaryRows.count != 0);
+- (void)windowOcclusionChanged:(NSNotification *)notification {
+    (void)notification;
+    if (self.window.occlusionState & NSWindowOcclusionStateVisible) {
+        GoWindowDidOpen();
+    } else {
+        GoWindowDidClose();
+    }
+}
+
+/** Reports closing the main window to Go, like an occluded window. */
+- (void)windowWillClose:(NSNotification *)notification {
+    (void)notification;
+    GoWindowDidClose();
 }
 
 /**
- * Enables or disables the Prev/Next buttons and the history popup depending on
- * whether any frames are available and which item is currently selected.
- * Must be called on the main thread.
+ * Toggles the "Include kernel_task" menu item state and propagates the change
+ * to Go.
  */
-- (void)refreshHistoryControls {
-    BOOL has = (self.historyItems.count > 0);
-    self.historyPopup.enabled = has;
-    NSInteger sel = self.historyPopup.indexOfSelectedItem;
-    self.previousFrameButton.enabled = has && sel > 0;
-    self.nextFrameButton.enabled = has && sel >= 0 && sel + 1 < (NSInteger)self.historyItems.count;
+- (void)kernelTaskToggled:(id)sender {
+    (void)sender;
+    self.kernelTaskMenuItem.state =
+        (self.kernelTaskMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetIncludeKernelTask(self.kernelTaskMenuItem.state == NSControlStateValueOn ? 1 : 0);
 }
 
-#pragma mark - Helpers
-
 /**
- * Creates a standard read-only, non-editable NSTextField label pre-filled with
- * value, sized and positioned by frame.
+ * Toggles the "Show only my processes" menu item state and propagates the
+ * change to Go.
  */
-- (NSTextField *)makeLabel:(NSString *)value frame:(NSRect)frame {
+- (void)onlyMineToggled:(id)sender {
+    (void)sender;
+    self.onlyMineMenuItem.state =
+        (self.onlyMineMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetShowOnlyMine(self.onlyMineMenuItem.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Toggles the "Average over frames appeared in" menu item state and
+ * propagates the change to Go (1 = appeared frames, 0 = all frames).
+ */
+- (void)averageModeToggled:(id)sender {
+    (void)sender;
+    self.averageModeMenuItem.state =
+        (self.averageModeMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetAverageMode(self.averageModeMenuItem.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Toggles the "Merge reused PIDs" menu item state and propagates the change
+ * to Go.
+ */
+- (void)mergeReusedToggled:(id)sender {
+    (void)sender;
+    self.mergeReusedMenuItem.state =
+        (self.mergeReusedMenuItem.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetMergeReusedPIDs(self.mergeReusedMenuItem.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Applies the chosen resume behaviour (the item's tag is the Go ResumeMode)
+ * and moves the checkmark to it.
+ */
+- (void)resumeModeChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.resumeModeMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetResumeMode((int)sender.tag);
+}
+
+/**
+ * Applies the chosen app helper grouping (the item's tag is the Go GroupMode)
+ * and moves the checkmark to it.
+ */
+- (void)groupModeChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.groupModeMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetGroupMode((int)sender.tag);
+}
+
+/**
+ * Applies the chosen ordering of equal-CPU rows (the item's tag is the Go
+ * Tiebreaker) and moves the checkmark to it.
+ */
+- (void)tiebreakerChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.tiebreakerMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetTiebreaker((int)sender.tag);
+}
+
+/**
+ * Applies the chosen summary sort field (the item's tag is the Go
+ * SummarySortField), keeping the current direction, and moves the checkmark
+ * to it.
+ */
+- (void)summarySortChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.summarySortMenu.itemArray) {
+        if (item == self.summaryDescendingItem || item.isSeparatorItem) continue;
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetSummarySort((int)sender.tag, self.summaryDescendingItem.state == NSControlStateValueOn);
+}
+
+/** Flips the summary sort direction, keeping the chosen field. */
+- (void)summaryDescendingToggled:(NSMenuItem *)sender {
+    BOOL descending = (sender.state != NSControlStateValueOn);
+    sender.state = descending ? NSControlStateValueOn : NSControlStateValueOff;
+    int field = 0;
+    for (NSMenuItem *item in self.summarySortMenu.itemArray) {
+        if (item != sender && item.state == NSControlStateValueOn) field = (int)item.tag;
+    }
+    GoSetSummarySort(field, descending);
+}
+
+/**
+ * Applies the chosen second frame length preset and moves the checkmark to
+ * it. The length is collected from the next start.
+ */
+- (void)secondFrameChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.secondFrameMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetSecondFrameLength([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen start delay preset and moves the checkmark to it. The
+ * delay applies from the next start.
+ */
+- (void)startDelayChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.startDelayMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetStartDelay([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen per-frame warm-up preset and moves the checkmark to it.
+ * The warm-up applies from the next start.
+ */
+- (void)frameWarmupChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.frameWarmupMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetFrameWarmup([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen frame limit preset and moves the checkmark to it. A run
+ * in progress stops at its next frame boundary once it has reached the limit.
+ */
+- (void)stopAfterChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.stopAfterMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetStopAfterFrames([sender.representedObject intValue]);
+}
+
+/**
+ * Applies the chosen completion alert (the item's tag is the Go
+ * CompletionAlert) and moves the checkmark to it.
+ */
+- (void)completionAlertChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.completionAlertMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetCompletionAlert((int)sender.tag);
+}
+
+/**
+ * Applies the chosen outlier threshold preset and moves the checkmark to it.
+ */
+- (void)anomalySigmaChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.anomalySigmaMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetAnomalySigma([sender.representedObject doubleValue]);
+}
+
+/** Toggles the "Notify about unusual frames" item and propagates the change to Go. */
+- (void)notifyAnomaliesToggled:(NSMenuItem *)sender {
+    sender.state = (sender.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoSetNotifyAnomalies(sender.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Applies the chosen per-frame row limit preset and moves the checkmark to it.
+ * It applies from the next completed frame.
+ */
+- (void)collectTopNChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.collectTopNMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetCollectTopN([sender.representedObject intValue]);
+}
+
+/**
+ * Applies the chosen maximum process age preset and moves the checkmark to it.
+ */
+- (void)maxAgeChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.maxAgeMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetMaxProcessAge([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen history retention preset and moves the checkmark to it.
+ * Older frames are dropped as the next frame completes.
+ */
+- (void)retentionChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.retentionMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetHistoryRetention([sender.representedObject doubleValue]);
+}
+
+/**
+ * Toggles the "Show second frame length" menu item and switches the tables
+ * between the main and the second frame length.
+ */
+- (void)showSecondFrameToggled:(id)sender {
+    (void)sender;
+    BOOL showSecond = self.showSecondFrameMenuItem.state != NSControlStateValueOn;
+    self.showSecondFrameMenuItem.state = showSecond ? NSControlStateValueOn : NSControlStateValueOff;
+    GoSelectFrameLength(showSecond ? GoInitialSecondFrameSeconds() : GoInitialFrameSeconds());
+}
+
+/**
+ * Applies the chosen small-process threshold and moves the checkmark to it.
+ * The "1 CPU-second" item (represented by 0) keeps the last percent chosen,
+ * so that picking a percent preset later starts from it.
+ */
+- (void)smallFilterChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.smallFilterMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    double percent = [sender.representedObject doubleValue];
+    if (percent == 0) {
+        GoSetSmallFilter(0, self.smallFilterPercent);
+        return;
+    }
+    self.smallFilterPercent = percent;
+    GoSetSmallFilter(1, percent);
+}
+
+/**
+ * Applies the chosen spike ratio preset and moves the checkmark to it.
+ */
+- (void)spikeRatioChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.spikeRatioMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetSpikeRatio([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen summary threshold preset and moves the checkmark to it.
+ * The current-frame "Hide processes below 1s" toggle is independent.
+ */
+- (void)summaryMinTotalChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.summaryMinTotalMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetSummaryMinTotal([sender.representedObject doubleValue]);
+}
+
+/**
+ * Applies the chosen summary appearance threshold preset and moves the
+ * checkmark to it. Rows must also pass the minimum total.
+ */
+- (void)summaryMinFramesChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.summaryMinFramesMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetSummaryMinFrames([sender.representedObject intValue]);
+}
+
+/**
+ * Applies the chosen chart theme (the item's tag is the Go Theme) and moves
+ * the checkmark to it.
+ */
+- (void)themeChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.themeMenu.itemArray) {
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoSetTheme((int)sender.tag);
+}
+
+/**
+ * Plays back the loaded history, or pauses playback if it is running. The
+ * item's title is refreshed from Go whenever the settings menu opens, since
+ * playback stops by itself at the end.
+ */
+- (void)replayPlayToggled:(NSMenuItem *)sender {
+    if (GoReplayIsPlaying()) {
+        GoReplayPause();
+    } else {
+        GoReplayPlay();
+    }
+    sender.title = GoReplayIsPlaying() ? @"Pause" : @"Play";
+}
+
+/** Applies the chosen replay speed and moves the checkmark to it. */
+- (void)replaySpeedChosen:(NSMenuItem *)sender {
+    for (NSMenuItem *item in self.replayMenu.itemArray) {
+        if (item.representedObject == nil) continue;
+        item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+    }
+    GoReplaySetSpeed([sender.representedObject doubleValue]);
+}
+
+/** Toggles the replay "Loop" item and propagates the change to Go. */
+- (void)replayLoopToggled:(NSMenuItem *)sender {
+    sender.state = (sender.state == NSControlStateValueOn) ? NSControlStateValueOff : NSControlStateValueOn;
+    GoReplaySetLoop(sender.state == NSControlStateValueOn ? 1 : 0);
+}
+
+/**
+ * Reports the application's effective appearance to Go, which the system
+ * chart theme follows. Called at launch and on every change.
+ */
+- (void)observeValueForKeyPath:(NSString *)keyPath
+                      ofObject:(id)object
+                        change:(NSDictionary<NSKeyValueChangeKey, id> *)change
+                       context:(void *)context {
+    (void)object;
+    (void)change;
+    (void)context;
+    if (![keyPath isEqualToString:@"effectiveAppearance"]) return;
+    NSAppearanceName name = [NSApp.effectiveAppearance
+        bestMatchFromAppearancesWithNames:@[NSAppearanceNameAqua, NSAppearanceNameDarkAqua]];
+    GoSetSystemAppearance([name isEqualToString:NSAppearanceNameDarkAqua] ? 1 : 0);
+}
+
+/**
+ * Applies the chosen time-lapse chart interval and moves the checkmark to it.
+ * Any preset but Off first asks for the folder to save the charts in with an
+ * NSOpenPanel, and leaves the setting unchanged if it is cancelled.
+ */
+- (void)chartExportChosen:(NSMenuItem *)sender {
+    int every = [sender.representedObject intValue];
+    void (^apply)(const char *) = ^(const char *dir) {
+        for (NSMenuItem *item in self.chartExportMenu.itemArray) {
+            item.state = (item == sender) ? NSControlStateValueOn : NSControlStateValueOff;
+        }
+        GoSetChartExport(every, (char *)dir);
+    };
+    if (every == 0) {
+        apply("");
+        return;
+    }
+    NSOpenPanel *panel = [NSOpenPanel openPanel];
+    panel.canChooseFiles = NO;
+    panel.canChooseDirectories = YES;
+    panel.canCreateDirectories = YES;
+    panel.allowsMultipleSelection = NO;
+    panel.prompt = @"Save Charts Here";
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        apply(panel.URL.path.fileSystemRepresentation);
+    }];
+}
+
+/**
+ * Asks for a destination with an NSSavePanel and hands the chosen path to Go,
+ * which renders the history chart as a PNG.
+ */
+- (void)exportChart:(id)sender {
+    (void)sender;
+    NSSavePanel *panel = [NSSavePanel savePanel];
+    panel.nameFieldStringValue = @"FrameScope Chart.png";
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"png"];
+#pragma clang diagnostic pop
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        GoExportChart((char *)panel.URL.path.fileSystemRepresentation);
+    }];
+}
+
+/**
+ * Locks the frame selected in the history popup as the reference the
+ * current-frame table is compared against. Go ignores the frame in progress.
+ */
+- (void)setReferenceFrame:(id)sender {
+    (void)sender;
+    GoSetReferenceFrame((int)self.historyPopup.indexOfSelectedItem);
+}
+
+/** Returns the current-frame table to absolute CPU. */
+- (void)clearReferenceFrame:(id)sender {
+    (void)sender;
+    GoClearReferenceFrame();
+}
+
+/**
+ * Asks for a destination with an NSSavePanel and hands the chosen path to Go,
+ * which writes the frame history as JSON or CSV depending on the extension.
+ */
+- (void)exportHistory:(id)sender {
+    (void)sender;
+    NSSavePanel *panel = [NSSavePanel savePanel];
+    panel.nameFieldStringValue = @"FrameScope History.json";
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"json", @"ndjson", @"csv"];
+#pragma clang diagnostic pop
+    panel.allowsOtherFileTypes = NO;
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        GoExportHistory((char *)panel.URL.path.fileSystemRepresentation);
+    }];
+}
+
+/**
+ * Asks for an exported history with an NSOpenPanel and hands the chosen path
+ * to Go, which loads it as the baseline shown in the "Compare" tab.
+ */
+- (void)loadBaseline:(id)sender {
+    (void)sender;
+    NSOpenPanel *panel = [NSOpenPanel openPanel];
+    panel.canChooseDirectories = NO;
+    panel.allowsMultipleSelection = NO;
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"json", @"ndjson"];
+#pragma clang diagnostic pop
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        GoLoadBaselineSession((char *)panel.URL.path.fileSystemRepresentation);
+    }];
+}
+
+/**
+ * Asks for a destination with an NSSavePanel and hands the chosen path to Go,
+ * which writes the current settings there as JSON for sharing.
+ */
+- (void)exportConfig:(id)sender {
+    (void)sender;
+    NSSavePanel *panel = [NSSavePanel savePanel];
+    panel.nameFieldStringValue = @"FrameScope Settings.json";
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"json"];
+#pragma clang diagnostic pop
+    panel.allowsOtherFileTypes = NO;
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        GoExportConfig((char *)panel.URL.path.fileSystemRepresentation);
+    }];
+}
+
+/**
+ * Asks for a shared settings file with an NSOpenPanel and hands the chosen
+ * path to Go, which applies it, then brings the settings controls up to date.
+ */
+- (void)importConfig:(id)sender {
+    (void)sender;
+    NSOpenPanel *panel = [NSOpenPanel openPanel];
+    panel.canChooseDirectories = NO;
+    panel.allowsMultipleSelection = NO;
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"json"];
+#pragma clang diagnostic pop
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        if (GoImportConfig((char *)panel.URL.path.fileSystemRepresentation)) {
+            [self refreshSettingsControls];
+        }
+    }];
+}
+
+/**
+ * Re-reads the settings file after it was edited outside FrameScope and, if
+ * anything changed, brings the settings controls up to date.
+ */
+- (void)reloadConfig:(id)sender {
+    (void)sender;
+    if (GoReloadConfig()) {
+        [self refreshSettingsControls];
+    }
+}
+
+/**
+ * Sets every settings control from the GoInitial* functions, as at launch,
+ * after the settings changed behind the controls' back. The column layouts
+ * are left as they are; they apply from the next launch.
+ */
+- (void)refreshSettingsControls {
+    self.frameField.stringValue = [NSString stringWithFormat:@"%g", GoInitialFrameSeconds()];
+
+    self.hideSmallMenuItem.state = OnOff(GoInitialHideSmall());
+    self.hidePathsMenuItem.state = OnOff(GoInitialHidePaths());
+    self.sparklinesMenuItem.state = OnOff(GoInitialShowSparklines());
+    self.shareMenuItem.state = OnOff(GoInitialShowShare());
+    self.noiseSelfMenuItem.state = OnOff(GoInitialNoiseIncludesSelf());
+    self.percentMenuItem.state = OnOff(GoInitialShowPercent());
+    self.liveDeltaMenuItem.state = OnOff(GoInitialShowLiveDelta());
+    self.userSysMenuItem.state = OnOff(GoInitialShowUserSys());
+    self.markTinyMenuItem.state = OnOff(GoInitialMarkTinyValues());
+    self.statusMenuItem.state = OnOff(GoInitialShowStatus());
+    self.changedOnlyMenuItem.state = OnOff(GoInitialChangedOnly());
+    self.freezeTopNMenuItem.state = OnOff(GoInitialFreezeTopN());
+    self.kernelTaskMenuItem.state = OnOff(GoInitialIncludeKernelTask());
+    self.onlyMineMenuItem.state = OnOff(GoInitialShowOnlyMine());
+    self.menuBarMenuItem.state = OnOff(GoInitialMenuBarItem());
+    self.pauseOnCloseMenuItem.state = OnOff(GoInitialPauseOnWindowClose());
+    self.averageModeMenuItem.state = OnOff(GoInitialAverageMode() == 1);
+    self.mergeReusedMenuItem.state = OnOff(GoInitialMergeReusedPIDs());
+    self.summaryDescendingItem.state = OnOff(GoInitialSummarySortDescending());
+
+    self.trendColumn.hidden = !GoInitialShowSparklines();
+    self.shareColumn.hidden = !GoInitialShowShare();
+    self.percentColumn.hidden = !GoInitialShowPercent();
+    self.sumPercentColumn.hidden = !GoInitialShowPercent();
+    self.deltaColumn.hidden = !GoInitialShowLiveDelta();
+    self.userColumn.hidden = !GoInitialShowUserSys();
+    self.sysColumn.hidden = !GoInitialShowUserSys();
+    self.statusColumn.hidden = !GoInitialShowStatus();
+
+    self.smallFilterPercent = GoInitialSmallFilterPercent();
+    CheckPreset(self.smallFilterMenu, @selector(smallFilterChosen:),
+                GoInitialSmallFilterMode() == 0 ? 0 : self.smallFilterPercent);
+    CheckPreset(self.maxAgeMenu, @selector(maxAgeChosen:), GoInitialMaxProcessAge());
+    CheckPreset(self.summaryMinTotalMenu, @selector(summaryMinTotalChosen:), GoInitialSummaryMinTotal());
+    CheckPreset(self.spikeRatioMenu, @selector(spikeRatioChosen:), GoInitialSpikeRatio());
+    CheckPreset(self.summaryMinFramesMenu, @selector(summaryMinFramesChosen:), GoInitialSummaryMinFrames());
+    CheckPreset(self.secondFrameMenu, @selector(secondFrameChosen:), GoInitialSecondFrameSeconds());
+    CheckPreset(self.startDelayMenu, @selector(startDelayChosen:), GoInitialStartDelay());
+    CheckPreset(self.frameWarmupMenu, @selector(frameWarmupChosen:), GoInitialFrameWarmup());
+    CheckPreset(self.stopAfterMenu, @selector(stopAfterChosen:), GoInitialStopAfterFrames());
+    CheckPreset(self.collectTopNMenu, @selector(collectTopNChosen:), GoInitialCollectTopN());
+    CheckPreset(self.retentionMenu, @selector(retentionChosen:), GoInitialHistoryRetention());
+    CheckPreset(self.chartExportMenu, @selector(chartExportChosen:), GoInitialChartExportEvery());
+    CheckPreset(self.replayMenu, @selector(replaySpeedChosen:), GoInitialReplaySpeed());
+    for (NSMenuItem *item in self.replayMenu.itemArray) {
+        if (item.action == @selector(replayLoopToggled:)) item.state = OnOff(GoInitialReplayLoop());
+    }
+    CheckTag(self.groupModeMenu, @selector(groupModeChosen:), GoInitialGroupMode());
+    CheckTag(self.resumeModeMenu, @selector(resumeModeChosen:), GoInitialResumeMode());
+    CheckTag(self.tiebreakerMenu, @selector(tiebreakerChosen:), GoInitialTiebreaker());
+    CheckTag(self.summarySortMenu, @selector(summarySortChosen:), GoInitialSummarySortField());
+    CheckTag(self.completionAlertMenu, @selector(completionAlertChosen:), GoInitialCompletionAlert());
+    CheckPreset(self.anomalySigmaMenu, @selector(anomalySigmaChosen:), GoInitialAnomalySigma());
+    self.notifyAnomaliesMenuItem.state = OnOff(GoInitialNotifyAnomalies());
+    CheckTag(self.themeMenu, @selector(themeChosen:), GoInitialTheme());
+}
+
+/**
+ * Exports the timeline of the summary row that was right-clicked. The menu
+ * item's tag is passed as includeAbsent: 1 writes absent frames as 0, 0 omits
+ * them.
+ */
+- (void)exportPidTimeline:(NSMenuItem *)sender {
+    NSInteger row = self.summaryTable.clickedRow;
+    if (row < 0 || row >= (NSInteger)self.summaryRows.count) return;
+    NSUInteger pidColumn = [self.summaryColumnOrder indexOfObject:@"pid"];
+    if (pidColumn == NSNotFound) return;
+    long long pid = self.summaryRows[(NSUInteger)row][pidColumn].longLongValue;
+    int includeAbsent = (int)sender.tag;
+    NSSavePanel *panel = [NSSavePanel savePanel];
+    panel.nameFieldStringValue = [NSString stringWithFormat:@"FrameScope PID %lld.csv", pid];
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+    panel.allowedFileTypes = @[@"csv"];
+#pragma clang diagnostic pop
+    [panel beginSheetModalForWindow:self.window completionHandler:^(NSModalResponse result) {
+        if (result != NSModalResponseOK || panel.URL == nil) return;
+        GoExportPidTimeline(pid, (char *)panel.URL.path.fileSystemRepresentation, includeAbsent);
+    }];
+}
+
+/**
+ * Copies the PID (tag 0) or full command line (tag 1) of the current-frame
+ * row that was right-clicked. Go checks the PID against the displayed rows.
+ */
+- (void)copyRowField:(NSMenuItem *)sender {
+    NSInteger row = self.resultsTable.clickedRow;
+    NSUInteger pidColumn = [self.frameColumnOrder indexOfObject:@"pid"];
+    if (row < 0 || row >= (NSInteger)self.frameRows.count || pidColumn == NSNotFound) return;
+    GoCopyRowField(self.frameRows[(NSUInteger)row][pidColumn].longLongValue, (int)sender.tag);
+}
+
+/**
+ * Monitors only the process of the current-frame row that was right-clicked
+ * and its descendants, until it exits or the focus is cleared.
+ */
+- (void)focusOnRow:(id)sender {
+    (void)sender;
+    NSInteger row = self.resultsTable.clickedRow;
+    NSUInteger pidColumn = [self.frameColumnOrder indexOfObject:@"pid"];
+    if (row < 0 || row >= (NSInteger)self.frameRows.count || pidColumn == NSNotFound) return;
+    GoSetFocusPID(self.frameRows[(NSUInteger)row][pidColumn].longLongValue);
+}
+
+/** Ends the focus set with focusOnRow: so every process is monitored again. */
+- (void)clearFocus:(id)sender {
+    (void)sender;
+    GoClearFocusPID();
+}
+
+/** Pops the Settings drop-down menu directly below the Settings button. */
+- (void)showSettingsMenu:(id)sender {
+    NSButton *button = (NSButton *)sender;
+    if (button.menu == nil) return;
+    self.pauseMenuItem.state = GoIsPaused() ? NSControlStateValueOn : NSControlStateValueOff;
+    self.replayMenu.itemArray.firstObject.title = GoReplayIsPlaying() ? @"Pause" : @"Play";
+    [button.menu popUpMenuPositioningItem:nil
+                               atLocation:NSMakePoint(0, NSHeight(button.bounds) + 4)
+                                   inView:button];
+}
+
+/**
+ * Called when the user picks an item in the history popup. The guard flag
+ * updatingHistorySelection prevents this from firing during programmatic
+ * selection changes made by applyHistoryPayload:. Holding Shift limits the
+ * summary to the frames between the previous pick and this one; a plain pick
+ * clears that range and becomes the new anchor.
+ */
+- (void)historyChanged:(id)sender {
+    (void)sender;
+    if (self.updatingHistorySelection) return;
+    NSInteger index = self.historyPopup.indexOfSelectedItem;
+    BOOL shift = (NSApp.currentEvent.modifierFlags & NSEventModifierFlagShift) != 0;
+    if (shift && self.historyRangeAnchor >= 0) {
+        GoSetHistoryRangeSelection((int)self.historyRangeAnchor, (int)index);
+        return;
+    }
+    self.historyRangeAnchor = index;
+    GoSelectFrame((int)index);
+}
+
+/** Moves selection one step earlier in the history popup. */
+- (void)previousFrame:(id)sender {
+    (void)sender;
+    NSInteger index = self.historyPopup.indexOfSelectedItem;
+    if (index > 0) {
+        [self.historyPopup selectItemAtIndex:index - 1];
+        [self historyChanged:self.historyPopup];
+    }
+}
+
+/** Moves selection one step later in the history popup. */
+- (void)nextFrame:(id)sender {
+    (void)sender;
+    NSInteger index = self.historyPopup.indexOfSelectedItem;
+    if (index >= 0 && index + 1 < self.historyPopup.numberOfItems) {
+        [self.historyPopup selectItemAtIndex:index + 1];
+        [self historyChanged:self.historyPopup];
+    }
+}
+
+#pragma mark - NSTextFieldDelegate
+
+/**
+ * controlTextDidChange: checks the frame length as it is typed, showing why
+ * it would be rejected or clamped in the field's tooltip and in red text, so
+ * the user need not press Start to find out.
+ */
+- (void)controlTextDidChange:(NSNotification *)note {
+    if (note.object != self.frameField) return;
+    char *message = GoValidateFrameSeconds(self.frameField.doubleValue, NULL);
+    NSString *text = [NSString stringWithUTF8String:message];
+    free(message);
+    self.frameField.toolTip = text.length ? text : nil;
+    self.frameField.textColor = text.length ? [NSColor systemRedColor] : [NSColor controlTextColor];
+}
+
+#pragma mark - NSTableViewDataSource / Delegate
+
+/** Returns the parsed row data backing the given table view. */
+- (NSArray<NSArray<NSString *> *> *)rowsForTableView:(NSTableView *)tableView {
+    if (tableView == self.summaryTable) return self.summaryRows;
+    if (tableView == self.commandTable) return self.commandRows;
+    if (tableView == self.userTable) return self.userRows;
+    if (tableView == self.comparisonTable) return self.comparisonRows;
+    return self.frameRows;
+}
+
+/** Returns the number of rows for the given table view. */
+- (NSInteger)numberOfRowsInTableView:(NSTableView *)tableView {
+    return (NSInteger)[self rowsForTableView:tableView].count;
+}
+
+/**
+ * Returns a cell view for the given table/column/row combination. Cells are
+ * reused from the table's view pool. Each cell is a non-editable, selectable
+ * NSTextField using the system monospaced font at 12 pt. The full cell value
+ * is also set as the tooltip so truncated content remains readable.
+ */
+- (nullable NSView *)tableView:(NSTableView *)tableView
+            viewForTableColumn:(NSTableColumn *)tableColumn
+                           row:(NSInteger)row {
+    NSString *identifier = tableColumn.identifier;
+    NSTextField *cell = [tableView makeViewWithIdentifier:identifier owner:self];
+    if (!cell) {
+        cell = [[NSTextField alloc] initWithFrame:NSZeroRect];
+        cell.identifier = identifier;
+        cell.bezeled = NO;
+        cell.drawsBackground = NO;
+        cell.editable = NO;
+        cell.selectable = YES;
+        cell.lineBreakMode = NSLineBreakByTruncatingMiddle;
+        cell.font = [NSFont monospacedSystemFontOfSize:12 weight:NSFontWeightRegular];
+    }
+    NSArray<NSArray<NSString *> *> *rows = [self rowsForTableView:tableView];
+    NSArray<NSString *> *rowValues = rows[(NSUInteger)row];
+    NSUInteger col = [tableView.tableColumns indexOfObject:tableColumn];
+    cell.stringValue = col < rowValues.count ? rowValues[col] : @"";
+    cell.toolTip = cell.stringValue;
+    /* Regressions against the baseline (marker column) are shown in red, and
+       the summary's biggest growers since it was last viewed in orange. */
+    BOOL regression = tableView == self.comparisonTable && rowValues.count > 3 &&
+                      [rowValues[3] isEqualToString:@"▲"];
+    NSUInteger sinceColumn = [self.summaryColumnOrder indexOfObject:@"since_viewed"];
+    BOOL grower = tableView == self.summaryTable && sinceColumn < rowValues.count &&
+                  [rowValues[sinceColumn] hasSuffix:@"▲"];
+    cell.textColor = regression ? [NSColor systemRedColor]
+                   : grower     ? [NSColor systemOrangeColor]
+                                : [NSColor labelColor];
+    return cell;
+}
+
+#pragma mark - Data Updates
+
+/**
+ * Parses a tab-separated, newline-delimited payload string into a 2-D array
+ * of strings suitable for direct use by the table data source. Each row is
+ * padded with empty strings to guarantee at least n columns.
+ */
+- (NSArray<NSArray<NSString *> *> *)parseRows:(NSString *)payload columns:(NSUInteger)n {
+    NSMutableArray *result = [NSMutableArray array];
+    for (NSString *line in [payload componentsSeparatedByCharactersInSet:
+                             [NSCharacterSet newlineCharacterSet]]) {
+        if (!line.length) continue;
+        NSMutableArray *row = [[line componentsSeparatedByString:@"\t"] mutableCopy];
+        while (row.count < n) [row addObject:@""];
+        [result addObject:row];
+    }
+    return result;
+}
+
+/**
+ * Replaces the frame table data with the parsed payload and reloads the table.
+ * The Now column is shown only while the rows carry a per-tick rate, which Go
+ * sends for the live frame alone. Must be called on the main thread.
+ */
+- (void)applyRowsPayload:(NSString *)payload {
+    [self applyFrameRows:[self parseRows:payload columns:self.frameColumnOrder.count]];
+}
+
+/**
+ * Replaces the frame table data with rows already split into fields, as
+ * decoded from a binary payload, and reloads the table. Must be called on
+ * the main thread.
+ */
+- (void)applyFrameRows:(NSArray<NSArray<NSString *> *> *)rows {
+    self.frameRows = rows;
+    NSUInteger rateColumn = [self.frameColumnOrder indexOfObject:@"rate"];
+    BOOL hasRates = NO;
+    for (NSArray<NSString *> *row in self.frameRows) {
+        if (rateColumn != NSNotFound && row[rateColumn].length > 0) {
+            hasRates = YES;
+            break;
+        }
+    }
+    self.rateColumn.hidden = !hasRates;
+    [self.resultsTable reloadData];
+    [self refreshEmptyState];
+}
+
+/**
+ * Replaces the summary table data with the parsed payload and reloads the
+ * table. Must be called on the main thread.
+ */
+- (void)applySummaryPayload:(NSString *)payload {
+    [self applySummaryRows:[self parseRows:payload columns:self.summaryColumnOrder.count]];
+}
+
+/**
+ * Replaces the summary table data with rows already split into fields and
+ * reloads the table. Must be called on the main thread.
+ */
+- (void)applySummaryRows:(NSArray<NSArray<NSString *> *> *)rows {
+    self.summaryRows = rows;
+    [self.summaryTable reloadData];
+    [self refreshEmptyState];
+}
+
+/**
+ * Replaces the by-command table data with the parsed payload and reloads the
+ * table. Must be called on the main thread.
+ */
+- (void)applyCommandsPayload:(NSString *)payload {
+    self.commandRows = [self parseRows:payload columns:4];
+    [self.commandTable reloadData];
+    [self refreshEmptyState];
+}
+
+/**
+ * Replaces the by-user table data with the parsed payload and reloads the
+ * table. Must be called on the main thread.
+ */
+- (void)applyUsersPayload:(NSString *)payload {
+    self.userRows = [self parseRows:payload columns:4];
+    [self.userTable reloadData];
+}
+
+/**
+ * Replaces the baseline comparison table data with the parsed payload and
+ * reloads the table. Must be called on the main thread.
+ */
+- (void)applyComparisonPayload:(NSString *)payload {
+    self.comparisonRows = [self parseRows:payload columns:5];
+    [self.comparisonTable reloadData];
+}
+
+/**
+ * Rebuilds the history popup items from the newline-separated payload and
+ * selects the item at selectedIndex (-1 leaves the selection unchanged).
+ * Sets updatingHistorySelection to suppress the historyChanged: callback
+ * while modifying the popup programmatically. Must be called on the main thread.
+ */
+- (void)applyHistoryPayload:(NSString *)payload selectedIndex:(NSInteger)selectedIndex {
+    NSMutableArray<NSString *> *items = [NSMutableArray array];
+    NSMutableIndexSet *busy = [NSMutableIndexSet indexSet];
+    NSMutableIndexSet *outliers = [NSMutableIndexSet indexSet];
+    for (NSString *s in [payload componentsSeparatedByCharactersInSet:
+                          [NSCharacterSet newlineCharacterSet]]) {
+        if (!s.length) continue;
+        NSArray<NSString *> *fields = [s componentsSeparatedByString:@"\t"];
+        for (NSUInteger i = 1; i < fields.count; i++) {
+            if ([fields[i] isEqualToString:@"busy"]) [busy addIndex:items.count];
+            if ([fields[i] isEqualToString:@"outlier"]) [outliers addIndex:items.count];
+        }
+        [items addObject:fields[0]];
+    }
+    self.historyItems = items;
+    self.updatingHistorySelection = YES;
+    [self.historyPopup removeAllItems];
+    if (items.count > 0) {
+        [self.historyPopup addItemsWithTitles:items];
+        [busy enumerateIndexesUsingBlock:^(NSUInteger idx, BOOL *stop) {
+            (void)stop;
+            NSMenuItem *item = [self.historyPopup itemAtIndex:(NSInteger)idx];
+            item.attributedTitle = [[NSAttributedString alloc]
+                initWithString:item.title
+                    attributes:@{NSForegroundColorAttributeName: [NSColor systemOrangeColor]}];
+        }];
+        [outliers enumerateIndexesUsingBlock:^(NSUInteger idx, BOOL *stop) {
+            (void)stop;
+            NSMenuItem *item = [self.historyPopup itemAtIndex:(NSInteger)idx];
+            item.attributedTitle = [[NSAttributedString alloc]
+                initWithString:item.title
+                    attributes:@{NSForegroundColorAttributeName: [NSColor systemRedColor]}];
+            item.toolTip = @"Unusually busy compared with the recent frames";
+        }];
+        if (selectedIndex >= 0 && selectedIndex < (NSInteger)items.count) {
+            [self.historyPopup selectItemAtIndex:selectedIndex];
+        }
+    } else {
+        [self.historyPopup addItemWithTitle:@"No frames yet"];
+    }
+    self.updatingHistorySelection = NO;
+    [self refreshHistoryControls];
+}
+
+/**
+ * Shows or hides the "empty" placeholder labels based on whether the
+ * corresponding table has any rows. Must be called on the main thread.
+ */
+- (void)refreshEmptyState {
+    self.emptyLabel.hidden = (self.frameRows.count != 0);
+    self.summaryEmptyLabel.hidden = (self.summaryRows.count != 0 || self.commandRows.count != 0);
+}
+
+/**
+ * Enables or disables the Prev/Next buttons and the history popup depending on
+ * whether any frames are available and which item is currently selected, and
+ * shows the number of completed frames next to them. Must be called on the
+ * main thread.
+ */
+- (void)refreshHistoryControls {
+    int completed = GoGetFrameCount();
+    BOOL inProgress = GoGetFrameInProgress() != 0;
+    self.frameCountLabel.stringValue = completed == 0 ? @"No frames"
+        : [NSString stringWithFormat:@"%d frame%@", completed, completed == 1 ? @"" : @"s"];
+    self.frameCountLabel.toolTip = inProgress ? @"Completed frames; another is in progress" : @"Completed frames";
+    BOOL has = (completed > 0 || inProgress) && self.historyItems.count > 0;
+    self.historyPopup.enabled = has;
+    NSInteger sel = self.historyPopup.indexOfSelectedItem;
+    self.previousFrameButton.enabled = has && sel > 0;
+    self.nextFrameButton.enabled = has && sel >= 0 && sel + 1 < (NSInteger)self.historyItems.count;
+}
+
+#pragma mark - Helpers
+
+/**
+ * Creates a standard read-only, non-editable NSTextField label pre-filled with
+ * value, sized and positioned by frame.
+ */
+- (NSTextField *)makeLabel:(NSString *)value frame:(NSRect)frame {
     NSTextField *lbl = [[NSTextField alloc] initWithFrame:frame];
     lbl.stringValue = value;
     lbl.bezeled = NO;
@@ -699,6 +2623,25 @@ static NSString * const kOptionsItem    = @"OptionsItem";
     return col;
 }
 
+/**
+ * Orders the columns of table like its payload: the columns whose identifier
+ * is prefix followed by a name in order move to the front in that order, and
+ * the rest are removed, so that a column's index is its field's index.
+ */
+- (void)arrangeColumnsOfTable:(NSTableView *)table
+                        order:(NSArray<NSString *> *)order
+                       prefix:(NSString *)prefix {
+    for (NSTableColumn *col in [table.tableColumns copy]) {
+        NSString *name = [col.identifier substringFromIndex:prefix.length];
+        if (![order containsObject:name]) [table removeTableColumn:col];
+    }
+    [order enumerateObjectsUsingBlock:^(NSString *name, NSUInteger i, BOOL *stop) {
+        (void)stop;
+        NSInteger from = [table columnWithIdentifier:[prefix stringByAppendingString:name]];
+        if (from >= 0 && from != (NSInteger)i) [table moveColumn:from toColumn:(NSInteger)i];
+    }];
+}
+
 @end
 
 #pragma mark - C interface
@@ -706,6 +2649,80 @@ static NSString * const kOptionsItem    = @"OptionsItem";
 /** Singleton delegate; set once in RunApp() and never changed. */
 static MonitorAppDelegate *delegate;
 
+#pragma mark - AppleScript
+
+/*
+ * The commands of FrameScope.sdef. Cocoa scripting runs them on the main
+ * thread, like the UI actions they mirror, and the Go functions they call
+ * take the monitor's lock themselves.
+ */
+
+/**
+ * Reports err, a message returned by a GoScript function, as the script
+ * error of command unless it is empty, and frees it. Returns YES when the
+ * command succeeded.
+ */
+static BOOL FinishScriptCommand(NSScriptCommand *command, char *err) {
+    NSString *message = [NSString stringWithUTF8String:err ?: ""];
+    free(err);
+    if (!message.length) return YES;
+    command.scriptErrorNumber = -2700; /* errOSAGeneralError */
+    command.scriptErrorString = message;
+    return NO;
+}
+
+/**
+ * Implements "start monitoring [with frame length seconds]". Without a frame
+ * length the value in the toolbar field is used; with one, the field is
+ * updated to show it. Like Start, it shows the main frame length.
+ */
+@interface FSStartMonitoringCommand : NSScriptCommand
+@end
+
+@implementation FSStartMonitoringCommand
+- (id)performDefaultImplementation {
+    NSNumber *length = self.evaluatedArguments[@"FrameLength"];
+    double seconds = length ? length.doubleValue : delegate.frameField.doubleValue;
+    if (FinishScriptCommand(self, GoScriptStart(seconds))) {
+        delegate.showSecondFrameMenuItem.state = NSControlStateValueOff;
+        if (length) delegate.frameField.stringValue = [NSString stringWithFormat:@"%g", GoInitialFrameSeconds()];
+    }
+    return nil;
+}
+@end
+
+/** Implements "stop monitoring", which discards the frame in progress like Stop. */
+@interface FSStopMonitoringCommand : NSScriptCommand
+@end
+
+@implementation FSStopMonitoringCommand
+- (id)performDefaultImplementation {
+    GoStopMonitoring();
+    return nil;
+}
+@end
+
+/**
+ * Implements "export frame to path", which writes the frame on display as
+ * JSON, NDJSON, or CSV depending on the extension of path.
+ */
+@interface FSExportFrameCommand : NSScriptCommand
+@end
+
+@implementation FSExportFrameCommand
+- (id)performDefaultImplementation {
+    id target = self.evaluatedArguments[@"Path"];
+    NSString *path = [target isKindOfClass:[NSURL class]] ? [(NSURL *)target path] : [target description];
+    if (!path.length) {
+        self.scriptErrorNumber = -1701; /* errAEDescNotFound: missing parameter */
+        self.scriptErrorString = @"export frame needs a destination path.";
+        return nil;
+    }
+    FinishScriptCommand(self, GoScriptExportFrame((char *)path.fileSystemRepresentation));
+    return nil;
+}
+@end
+
 /**
  * SetAppVersion stores version in gAppVersion. Must be called before RunApp()
  * so the string is available when applicationDidFinishLaunching: sets the
@@ -737,30 +2754,185 @@ void RunApp(void) {
  * table/popup/status updates asynchronously onto the main queue.
  */
 void UpdateResults(const char *status, const char *tableText,
-                   const char *summaryText, const char *historyText,
-                   int selectedIndex) {
-    NSString *statusStr  = [NSString stringWithUTF8String:status      ?: ""];
-    NSString *tableStr   = [NSString stringWithUTF8String:tableText   ?: ""];
-    NSString *summaryStr = [NSString stringWithUTF8String:summaryText ?: ""];
-    NSString *historyStr = [NSString stringWithUTF8String:historyText ?: ""];
+                   const char *summaryText, const char *summaryTitle,
+                   const char *statsText, const char *commandsText,
+                   const char *usersText, const char *comparisonText,
+                   const char *historyText, int selectedIndex,
+                   double machineCPU) {
+    NSString *statusStr   = [NSString stringWithUTF8String:status       ?: ""];
+    NSString *tableStr    = [NSString stringWithUTF8String:tableText    ?: ""];
+    NSString *summaryStr  = [NSString stringWithUTF8String:summaryText  ?: ""];
+    NSString *titleStr    = [NSString stringWithUTF8String:summaryTitle ?: ""];
+    NSString *statsStr    = [NSString stringWithUTF8String:statsText    ?: ""];
+    NSString *commandsStr = [NSString stringWithUTF8String:commandsText ?: ""];
+    NSString *usersStr    = [NSString stringWithUTF8String:usersText    ?: ""];
+    NSString *compareStr  = [NSString stringWithUTF8String:comparisonText ?: ""];
+    NSString *historyStr  = [NSString stringWithUTF8String:historyText  ?: ""];
     dispatch_async(dispatch_get_main_queue(), ^{
         delegate.statusLabel.stringValue = statusStr;
+        if (titleStr.length) delegate.summaryTitleLabel.stringValue = titleStr;
+        delegate.summaryStatsLabel.stringValue = statsStr;
+        delegate.summaryStatsLabel.toolTip = statsStr;
         [delegate applyRowsPayload:tableStr];
         [delegate applySummaryPayload:summaryStr];
+        [delegate applyCommandsPayload:commandsStr];
+        [delegate applyUsersPayload:usersStr];
+        [delegate applyComparisonPayload:compareStr];
         [delegate applyHistoryPayload:historyStr selectedIndex:selectedIndex];
+        [delegate applyMachineCPU:machineCPU];
+    });
+}
+
+/**
+ * decodeRows reads a binary table payload (see UpdateResultsBinary in
+ * cocoa_bridge.h) into rows of fields. A truncated buffer yields the rows
+ * read in full before it. Safe to call from any thread.
+ */
+static NSArray<NSMutableArray<NSString *> *> *decodeRows(const unsigned char *buf, size_t length) {
+    NSMutableArray *result = [NSMutableArray array];
+    if (!buf || length < 8) return result;
+    __block size_t offset = 0;
+    uint32_t (^next)(void) = ^uint32_t(void) {
+        uint32_t value = 0;
+        memcpy(&value, buf + offset, sizeof value);
+        offset += sizeof value;
+        return CFSwapInt32LittleToHost(value);
+    };
+    uint32_t rows = next(), columns = next();
+    for (uint32_t r = 0; r < rows; r++) {
+        NSMutableArray *row = [NSMutableArray arrayWithCapacity:columns];
+        for (uint32_t c = 0; c < columns; c++) {
+            if (length - offset < 4) return result;
+            uint32_t fieldLength = next();
+            if (length - offset < fieldLength) return result;
+            NSString *field = [[NSString alloc] initWithBytes:buf + offset length:fieldLength encoding:NSUTF8StringEncoding];
+            [row addObject:field ?: @""];
+            offset += fieldLength;
+        }
+        [result addObject:row];
+    }
+    return result;
+}
+
+/**
+ * padRows pads every row of decodeRows with empty strings to at least n
+ * fields, as parseRows:columns: does. Must be called on the main thread,
+ * where the column counts change.
+ */
+static NSArray<NSArray<NSString *> *> *padRows(NSArray<NSMutableArray<NSString *> *> *rows, NSUInteger n) {
+    for (NSMutableArray<NSString *> *row in rows) {
+        while (row.count < n) [row addObject:@""];
+    }
+    return rows;
+}
+
+/**
+ * UpdateResultsBinary is called from Go (ui_bridge_darwin.go) in place of
+ * UpdateResults when the app runs with -binary-ui. Both tables are decoded
+ * before returning, as the buffers belong to Go, and applied with the rest of
+ * the refresh on the main queue.
+ */
+void UpdateResultsBinary(const char *status,
+                         const unsigned char *table, size_t tableLength,
+                         const unsigned char *summary, size_t summaryLength,
+                         const char *summaryTitle, const char *statsText,
+                         const char *commandsText, const char *usersText,
+                         const char *comparisonText, const char *historyText,
+                         int selectedIndex, double machineCPU) {
+    NSString *statusStr   = [NSString stringWithUTF8String:status       ?: ""];
+    NSString *titleStr    = [NSString stringWithUTF8String:summaryTitle ?: ""];
+    NSString *statsStr    = [NSString stringWithUTF8String:statsText    ?: ""];
+    NSString *commandsStr = [NSString stringWithUTF8String:commandsText ?: ""];
+    NSString *usersStr    = [NSString stringWithUTF8String:usersText    ?: ""];
+    NSString *compareStr  = [NSString stringWithUTF8String:comparisonText ?: ""];
+    NSString *historyStr  = [NSString stringWithUTF8String:historyText  ?: ""];
+    NSArray *frameRows    = decodeRows(table, tableLength);
+    NSArray *summaryRows  = decodeRows(summary, summaryLength);
+    dispatch_async(dispatch_get_main_queue(), ^{
+        delegate.statusLabel.stringValue = statusStr;
+        if (titleStr.length) delegate.summaryTitleLabel.stringValue = titleStr;
+        delegate.summaryStatsLabel.stringValue = statsStr;
+        delegate.summaryStatsLabel.toolTip = statsStr;
+        [delegate applyFrameRows:padRows(frameRows, delegate.frameColumnOrder.count)];
+        [delegate applySummaryRows:padRows(summaryRows, delegate.summaryColumnOrder.count)];
+        [delegate applyCommandsPayload:commandsStr];
+        [delegate applyUsersPayload:usersStr];
+        [delegate applyComparisonPayload:compareStr];
+        [delegate applyHistoryPayload:historyStr selectedIndex:selectedIndex];
+        [delegate applyMachineCPU:machineCPU];
+    });
+}
+
+/**
+ * CopyToPasteboard is called from Go (controls_darwin.go) to put text on the
+ * general pasteboard. Dispatches to the main queue.
+ */
+void CopyToPasteboard(const char *text) {
+    NSString *str = [NSString stringWithUTF8String:text ?: ""];
+    dispatch_async(dispatch_get_main_queue(), ^{
+        NSPasteboard *pasteboard = [NSPasteboard generalPasteboard];
+        [pasteboard clearContents];
+        [pasteboard setString:str forType:NSPasteboardTypeString];
+    });
+}
+
+/**
+ * ShowNotification is called from Go (ui_bridge_darwin.go) to post a user
+ * notification, such as the CPU budget alert. Dispatches to the main queue.
+ * NSUserNotification needs neither an entitlement nor an authorization
+ * prompt, which the unbundled binary could not request.
+ */
+void ShowNotification(const char *title, const char *body) {
+    NSString *titleText = [NSString stringWithUTF8String:title ?: ""];
+    NSString *bodyText = [NSString stringWithUTF8String:body ?: ""];
+    dispatch_async(dispatch_get_main_queue(), ^{
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+        NSUserNotification *note = [[NSUserNotification alloc] init];
+        note.title = titleText;
+        note.informativeText = bodyText;
+        [[NSUserNotificationCenter defaultUserNotificationCenter] deliverNotification:note];
+#pragma clang diagnostic pop
+    });
+}
+
+/**
+ * RequestUserAttention is called from Go (ui_bridge_darwin.go) when a run
+ * completes, to bounce the Dock icon once. AppKit ignores the request while
+ * the app is active. Dispatches to the main queue.
+ */
+void RequestUserAttention(void) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        [NSApp requestUserAttention:NSInformationalRequest];
+    });
+}
+
+/**
+ * UpdateStatusItem is called from Go (ui_bridge_darwin.go) with the menu bar
+ * indicator text after each UI update. Dispatches to the main queue.
+ */
+void UpdateStatusItem(const char *text) {
+    NSString *str = [NSString stringWithUTF8String:text ?: ""];
+    dispatch_async(dispatch_get_main_queue(), ^{
+        [delegate applyStatusItemText:str];
     });
 }
 
 /**
  * ShowErrorMessage is called from Go (ui_bridge.go) to display an error in the
- * status bar and clear both tables. Dispatches to the main queue.
+ * status bar and clear all tables. Dispatches to the main queue.
  */
 void ShowErrorMessage(const char *message) {
     NSString *text = [NSString stringWithUTF8String:message ?: "Unknown error"];
     dispatch_async(dispatch_get_main_queue(), ^{
         delegate.statusLabel.stringValue = text;
+        delegate.summaryStatsLabel.stringValue = @"";
         [delegate applyRowsPayload:@""];
         [delegate applySummaryPayload:@""];
+        [delegate applyCommandsPayload:@""];
+        [delegate applyUsersPayload:@""];
+        [delegate applyComparisonPayload:@""];
         [delegate applyHistoryPayload:@"" selectedIndex:-1];
+        [delegate applyMachineCPU:-1];
     });
 }
@@ -0,0 +1,1297 @@
+package main
+
+/*
+#include <stdlib.h>
+#include "cocoa_bridge.h"
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+
+	"monitor_cpu/framescope"
+)
+
+// monitor is the application's single Monitor, driven by the Cocoa UI.
+var monitor = framescope.NewMonitor(cocoaSink{})
+
+// The functions in this file are exported via cgo and called from Cocoa in
+// response to user actions. Each one is a thin adapter that converts C
+// arguments and delegates to the package-level monitor.
+
+// GoStartMonitoring is called from Cocoa when the user presses Start. It
+// cancels any in-progress monitoring run, resets all frame state, and launches
+// a new run. frameSeconds is the desired frame length; values ≤ 0 are
+// rejected with an error message.
+//
+//export GoStartMonitoring
+func GoStartMonitoring(frameSeconds C.double) {
+	monitor.Start(float64(frameSeconds))
+}
+
+// GoStopMonitoring is called from Cocoa when the user presses Stop. It
+// ends the active monitoring run, discarding the frame in progress, flushes
+// the -record file, and updates state so the UI shows the last completed
+// frame. It returns once the sampling goroutine has.
+//
+//export GoStopMonitoring
+func GoStopMonitoring() {
+	monitor.Stop()
+}
+
+// GoScriptStart is called from the AppleScript "start monitoring" command. It
+// starts a new run like GoStartMonitoring and returns the reason it could not,
+// or an empty string on success, for the script to raise. The returned string
+// is allocated with malloc and must be freed by the caller.
+//
+//export GoScriptStart
+func GoScriptStart(frameSeconds C.double) *C.char {
+	return scriptResult(monitor.Start(float64(frameSeconds)))
+}
+
+// GoScriptExportFrame is called from the AppleScript "export frame" command.
+// It writes the frame on display to path as JSON, NDJSON, or CSV (chosen by
+// the extension) and returns the error, or an empty string on success. The
+// returned string is allocated with malloc and must be freed by the caller.
+//
+//export GoScriptExportFrame
+func GoScriptExportFrame(path *C.char) *C.char {
+	return scriptResult(monitor.ExportFrame(C.GoString(path)))
+}
+
+// GoValidateFrameSeconds checks a frame length as the user types it, the way
+// GoStartMonitoring would treat it (see framescope.ValidateFrameSeconds). The
+// length a run would use is stored in *normalized, if it is not NULL: 0 if
+// value is rejected. It returns a message for the user, or an empty string if
+// value is used as is. The returned string is allocated with malloc and must
+// be freed by the caller.
+//
+//export GoValidateFrameSeconds
+func GoValidateFrameSeconds(value C.double, normalized *C.double) *C.char {
+	seconds, message := framescope.ValidateFrameSeconds(float64(value))
+	if normalized != nil {
+		*normalized = C.double(seconds)
+	}
+	return C.CString(message)
+}
+
+// scriptResult converts the outcome of a scripting command to the C string
+// the GoScript functions return.
+func scriptResult(err error) *C.char {
+	if err != nil {
+		return C.CString(err.Error())
+	}
+	return C.CString("")
+}
+
+// GoWillTerminate is called from Cocoa when the app is about to quit. Unlike
+// GoStopMonitoring it completes the frame in progress, so that the -record
+// file holds everything measured, then closes that file. It is safe to call
+// after monitoring has stopped.
+//
+//export GoWillTerminate
+func GoWillTerminate() {
+	monitor.Shutdown(true)
+	if recorder != nil {
+		recorder.Close()
+	}
+}
+
+// GoSetHideSmall is called from Cocoa when the user toggles the "Hide <1s"
+// option. enabled is non-zero for on, zero for off. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetHideSmall
+func GoSetHideSmall(enabled C.int) {
+	monitor.SetHideSmall(enabled != 0)
+}
+
+// GoSetSmallFilter is called from Cocoa when the user picks an entry from the
+// "Hide threshold" submenu. mode is 0 to hide processes below 1 CPU-second,
+// 1 to hide those below percent %CPU of one core over the frame. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetSmallFilter
+func GoSetSmallFilter(mode C.int, percent C.double) {
+	monitor.SetSmallFilter(framescope.SmallFilterMode(mode), float64(percent))
+}
+
+// GoSetHidePaths is called from Cocoa when the user toggles the "Basename
+// only" option. enabled is non-zero for on, zero for off. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetHidePaths
+func GoSetHidePaths(enabled C.int) {
+	monitor.SetHidePaths(enabled != 0)
+}
+
+// GoSetShowSparklines is called from Cocoa when the user toggles the "Show
+// trend sparklines" option. enabled is non-zero for on, zero for off. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetShowSparklines
+func GoSetShowSparklines(enabled C.int) {
+	monitor.SetShowSparklines(enabled != 0)
+}
+
+// GoSetShowShare is called from Cocoa when the user toggles the "Show share
+// of frame total" option. enabled is non-zero for on, zero for off. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetShowShare
+func GoSetShowShare(enabled C.int) {
+	monitor.SetShowShare(enabled != 0)
+}
+
+// GoSetNoiseIncludesSelf is called from Cocoa when the user toggles the
+// "Leave FrameScope out of totals" option. enabled is non-zero to leave
+// FrameScope's own CPU out of shares and utilization. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetNoiseIncludesSelf
+func GoSetNoiseIncludesSelf(enabled C.int) {
+	monitor.SetNoiseIncludesSelf(enabled != 0)
+}
+
+// GoSetShowPercent is called from Cocoa when the user toggles the "Show %CPU
+// alongside CPU-seconds" option. enabled is non-zero for on, zero for off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetShowPercent
+func GoSetShowPercent(enabled C.int) {
+	monitor.SetShowPercent(enabled != 0)
+}
+
+// GoSetShowLiveDelta is called from Cocoa when the user toggles the "Compare
+// live frame with the last one" option. enabled is non-zero for on, zero for
+// off. The new setting is persisted to disk immediately.
+//
+//export GoSetShowLiveDelta
+func GoSetShowLiveDelta(enabled C.int) {
+	monitor.SetShowLiveDelta(enabled != 0)
+}
+
+// GoSetShowUserSys is called from Cocoa when the user toggles the "Split CPU
+// into user and system" option. enabled is non-zero for on, zero for off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetShowUserSys
+func GoSetShowUserSys(enabled C.int) {
+	monitor.SetShowUserSys(enabled != 0)
+}
+
+// GoSetMarkTinyValues is called from Cocoa when the user toggles the "Show
+// <0.1 for tiny CPU values" option. enabled is non-zero for on, zero for off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetMarkTinyValues
+func GoSetMarkTinyValues(enabled C.int) {
+	monitor.SetMarkTinyValues(enabled != 0)
+}
+
+// GoSetShowStatus is called from Cocoa when the user toggles the "Mark zombie
+// and stopped processes" option. enabled is non-zero for on, zero for off. The
+// new setting is persisted to disk immediately.
+//
+//export GoSetShowStatus
+func GoSetShowStatus(enabled C.int) {
+	monitor.SetShowStatus(enabled != 0)
+}
+
+// GoSetChangedOnly is called from Cocoa when the user toggles the "Show only
+// changed since last frame" option. enabled is non-zero for on, zero for off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetChangedOnly
+func GoSetChangedOnly(enabled C.int) {
+	monitor.SetChangedOnly(enabled != 0)
+}
+
+// GoSetFreezeTopN is called from Cocoa when the user toggles the "Freeze top
+// rows during each frame" option. enabled is non-zero for on, zero for off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetFreezeTopN
+func GoSetFreezeTopN(enabled C.int) {
+	monitor.SetFreezeTopN(enabled != 0)
+}
+
+// GoSetMergeReusedPIDs is called from Cocoa when the user toggles the "Merge
+// reused PIDs" summary option. enabled is non-zero to merge, zero to keep one
+// row per process. The new setting is persisted to disk immediately.
+//
+//export GoSetMergeReusedPIDs
+func GoSetMergeReusedPIDs(enabled C.int) {
+	monitor.SetMergeReusedPIDs(enabled != 0)
+}
+
+// GoSetMenuBarItem is called from Cocoa when the user toggles the "Show in
+// menu bar" option. enabled is non-zero to show the indicator, zero to hide
+// it. The new setting is persisted to disk immediately.
+//
+//export GoSetMenuBarItem
+func GoSetMenuBarItem(enabled C.int) {
+	monitor.SetMenuBarItem(enabled != 0)
+}
+
+// GoSetPauseOnWindowClose is called from Cocoa when the user toggles the
+// "Pause while the window is hidden" option. enabled is non-zero to pause
+// monitoring while the window cannot be seen, zero to keep sampling. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetPauseOnWindowClose
+func GoSetPauseOnWindowClose(enabled C.int) {
+	monitor.SetPauseOnWindowClose(enabled != 0)
+}
+
+// GoWindowDidClose is called from Cocoa when the main window is closed,
+// minimized, or entirely covered, which pauses monitoring if the user asked
+// for it (see framescope.Monitor.SetPauseOnWindowClose).
+//
+//export GoWindowDidClose
+func GoWindowDidClose() {
+	monitor.SetWindowVisible(false)
+}
+
+// GoWindowDidOpen is called from Cocoa when the main window can be seen
+// again, resuming monitoring paused by GoWindowDidClose.
+//
+//export GoWindowDidOpen
+func GoWindowDidOpen() {
+	monitor.SetWindowVisible(true)
+}
+
+// GoPauseMonitoring is called from Cocoa when the user picks "Pause
+// monitoring": the active run takes no snapshots until GoResumeMonitoring.
+//
+//export GoPauseMonitoring
+func GoPauseMonitoring() {
+	monitor.Pause()
+}
+
+// GoResumeMonitoring is called from Cocoa to lift a pause, whether made with
+// GoPauseMonitoring or by a hidden window. The frames in progress restart.
+//
+//export GoResumeMonitoring
+func GoResumeMonitoring() {
+	monitor.Resume()
+}
+
+// GoIsPaused returns 1 while the active run is paused, 0 otherwise, for the
+// "Pause monitoring" menu item's checkmark.
+//
+//export GoIsPaused
+func GoIsPaused() C.int {
+	return cBool(monitor.Paused())
+}
+
+// GoReplayPlay is called from Cocoa when the user picks "Play" in the Replay
+// menu: the loaded history is played back frame by frame at the replay speed
+// (see framescope.Monitor.ReplayPlay). Why it cannot play is shown in the
+// status bar.
+//
+//export GoReplayPlay
+func GoReplayPlay() {
+	monitor.ReplayPlay()
+}
+
+// GoReplayPause is called from Cocoa when the user picks "Pause" in the
+// Replay menu, keeping the frame on display.
+//
+//export GoReplayPause
+func GoReplayPause() {
+	monitor.ReplayPause()
+}
+
+// GoReplayIsPlaying returns 1 while the history is played back, 0
+// otherwise, for the Replay menu's Play/Pause item.
+//
+//export GoReplayIsPlaying
+func GoReplayIsPlaying() C.int {
+	return cBool(monitor.ReplayPlaying())
+}
+
+// GoReplaySetSpeed is called from Cocoa when the user picks a replay speed in
+// frames per second. The new setting is persisted to disk immediately.
+//
+//export GoReplaySetSpeed
+func GoReplaySetSpeed(framesPerSecond C.double) {
+	monitor.SetReplaySpeed(float64(framesPerSecond))
+}
+
+// GoReplaySetLoop is called from Cocoa when the user toggles "Loop" in the
+// Replay menu. enabled is non-zero to start over after the last frame, zero
+// to stop there. The new setting is persisted to disk immediately.
+//
+//export GoReplaySetLoop
+func GoReplaySetLoop(enabled C.int) {
+	monitor.SetReplayLoop(enabled != 0)
+}
+
+// GoSetIncludeKernelTask is called from Cocoa when the user toggles the
+// "Include kernel_task" option. enabled is non-zero to count kernel_task, zero
+// to drop it. The new setting is persisted to disk immediately.
+//
+//export GoSetIncludeKernelTask
+func GoSetIncludeKernelTask(enabled C.int) {
+	monitor.SetIncludeKernelTask(enabled != 0)
+}
+
+// GoSetShowOnlyMine is called from Cocoa when the user toggles the "Show only
+// my processes" option. enabled is non-zero for on, zero for off. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetShowOnlyMine
+func GoSetShowOnlyMine(enabled C.int) {
+	monitor.SetShowOnlyMine(enabled != 0)
+}
+
+// GoSetTheme is called from Cocoa when the user picks an entry from the
+// "Chart theme" submenu. theme is 0 to follow the system appearance, 1 for
+// light, 2 for dark. The new setting is persisted to disk immediately.
+//
+//export GoSetTheme
+func GoSetTheme(theme C.int) {
+	monitor.SetTheme(framescope.Theme(theme))
+}
+
+// GoSetSystemAppearance is called from Cocoa at launch and whenever the
+// application's effective appearance changes. dark is non-zero for a dark
+// appearance, which the system chart theme follows.
+//
+//export GoSetSystemAppearance
+func GoSetSystemAppearance(dark C.int) {
+	monitor.SetSystemAppearance(dark != 0)
+}
+
+// GoSetAverageMode is called from Cocoa when the user toggles the "Average
+// over frames appeared in" option. mode is 0 for all completed frames, 1 for
+// only the frames each process appeared in. The new setting is persisted to
+// disk immediately.
+//
+//export GoSetAverageMode
+func GoSetAverageMode(mode C.int) {
+	monitor.SetAverageMode(framescope.AverageMode(mode))
+}
+
+// GoSetTiebreaker is called from Cocoa when the user picks an entry from the
+// "Order equal rows by" submenu. tiebreak is 0 for PID, 1 for command, 2 for
+// process start time. The new setting is persisted to disk immediately.
+//
+//export GoSetTiebreaker
+func GoSetTiebreaker(tiebreak C.int) {
+	monitor.SetTiebreaker(framescope.Tiebreaker(tiebreak))
+}
+
+// GoSetSummarySort is called from Cocoa when the user picks a field or flips
+// the direction in the "Sort summary by" submenu. field is 0 for total, 1
+// average, 2 peak, 3 standard deviation, 4 appearances, 5 command, 6 PID; desc
+// is non-zero for descending. The new setting is persisted to disk immediately.
+//
+//export GoSetSummarySort
+func GoSetSummarySort(field C.int, desc C.int) {
+	monitor.SetSummarySort(framescope.SummarySortField(field), desc != 0)
+}
+
+// GoSetResumeMode is called from Cocoa when the user picks an entry from the
+// "On resume" submenu. mode is 0 to restart the frames in progress from a
+// fresh baseline, 1 to continue them with the pause counted in them. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetResumeMode
+func GoSetResumeMode(mode C.int) {
+	monitor.SetResumeMode(framescope.ResumeMode(mode))
+}
+
+// GoSetGroupMode is called from Cocoa when the user picks an entry from the
+// "Group app helpers" submenu. mode is 0 for off, 1 for collapsed, 2 for
+// expanded. The new setting is persisted to disk immediately.
+//
+//export GoSetGroupMode
+func GoSetGroupMode(mode C.int) {
+	monitor.SetGroupMode(framescope.GroupMode(mode))
+}
+
+// GoSetSpikeRatio is called from Cocoa when the user picks an entry from the
+// "Spike ratio" submenu. A summary row is marked spiky when its peak %CPU is
+// more than ratio times its average; ratios of 1 or less are ignored. The
+// new setting is persisted to disk immediately.
+//
+//export GoSetSpikeRatio
+func GoSetSpikeRatio(ratio C.double) {
+	monitor.SetSpikeRatio(float64(ratio))
+}
+
+// GoSetSummaryMinTotal is called from Cocoa when the user picks a threshold
+// from the "Summary minimum total" submenu. total is in CPU-seconds; 0 shows
+// every process. The new setting is persisted to disk immediately.
+//
+//export GoSetSummaryMinTotal
+func GoSetSummaryMinTotal(total C.double) {
+	monitor.SetSummaryMinTotal(float64(total))
+}
+
+// GoSetSummaryMinFrames is called from Cocoa when the user picks a threshold
+// from the "Summary minimum frames" submenu. frames is the number of frames a
+// process must have appeared in; 1 shows every process. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetSummaryMinFrames
+func GoSetSummaryMinFrames(frames C.int) {
+	monitor.SetSummaryMinFrames(int(frames))
+}
+
+// GoSetSecondFrameLength is called from Cocoa when the user picks an entry
+// from the "Second frame length" submenu. seconds is the length collected
+// alongside the main one from the next Start; 0 turns it off. The new setting
+// is persisted to disk immediately.
+//
+//export GoSetSecondFrameLength
+func GoSetSecondFrameLength(seconds C.double) {
+	monitor.SetSecondFrameLength(float64(seconds))
+}
+
+// GoSetStartDelay is called from Cocoa when the user picks an entry from the
+// "Start delay" submenu. seconds is how long Start waits before the first
+// frame's baseline snapshot; 0 turns the delay off. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetStartDelay
+func GoSetStartDelay(seconds C.double) {
+	monitor.SetStartDelay(float64(seconds))
+}
+
+// GoSetFrameWarmup is called from Cocoa when the user picks an entry from the
+// "Warm-up per frame" submenu. seconds is how much of the start of every
+// frame is discarded before its baseline is taken; 0 turns the warm-up off.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetFrameWarmup
+func GoSetFrameWarmup(seconds C.double) {
+	monitor.SetFrameWarmup(float64(seconds))
+}
+
+// GoSetStopAfterFrames is called from Cocoa when the user picks an entry from
+// the "Stop after" submenu. frames is how many frames a run completes before
+// it stops by itself; 0 runs until stopped. The new setting is persisted to
+// disk immediately.
+//
+//export GoSetStopAfterFrames
+func GoSetStopAfterFrames(frames C.int) {
+	monitor.SetStopAfterFrames(int(frames))
+}
+
+// GoSetCompletionAlert is called from Cocoa when the user picks an entry from
+// the "Alert when monitoring ends" submenu. alert is 0 for never, 1 when a run
+// finishes by itself, 2 also on Stop. The new setting is persisted to disk
+// immediately.
+//
+//export GoSetCompletionAlert
+func GoSetCompletionAlert(alert C.int) {
+	monitor.SetCompletionAlert(framescope.CompletionAlert(alert))
+}
+
+// GoSetAnomalySigma is called from Cocoa when the user picks an entry from
+// the "Flag unusual frames" submenu. A completed frame is flagged as an
+// outlier when its utilization is more than sigma standard deviations above
+// the recent frames; non-positive values are ignored. The new setting is
+// persisted to disk immediately.
+//
+//export GoSetAnomalySigma
+func GoSetAnomalySigma(sigma C.double) {
+	monitor.SetAnomalySigma(float64(sigma))
+}
+
+// GoSetNotifyAnomalies is called from Cocoa when the user toggles the "Notify
+// about unusual frames" menu item. enabled is non-zero to post a notification
+// for every outlier frame. The new setting is persisted to disk immediately.
+//
+//export GoSetNotifyAnomalies
+func GoSetNotifyAnomalies(enabled C.int) {
+	monitor.SetNotifyAnomalies(enabled != 0)
+}
+
+// GoSetCollectTopN is called from Cocoa when the user picks an entry from the
+// "Keep per frame" submenu. n is how many of the busiest processes completed
+// frames keep; 0 keeps them all. The new setting is persisted to disk
+// immediately.
+//
+//export GoSetCollectTopN
+func GoSetCollectTopN(n C.int) {
+	monitor.SetCollectTopN(int(n))
+}
+
+// GoSetHistoryRetention is called from Cocoa when the user picks an entry
+// from the "Keep history" submenu. seconds is how long completed frames are
+// kept after they end; 0 keeps the newest frames by count alone. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetHistoryRetention
+func GoSetHistoryRetention(seconds C.double) {
+	monitor.SetHistoryRetention(float64(seconds))
+}
+
+// GoSetMaxProcessAge is called from Cocoa when the user picks an entry from
+// the "Started within" submenu. seconds is the maximum age of the processes
+// the current-frame table shows; 0 shows processes of any age. The new
+// setting is persisted to disk immediately.
+//
+//export GoSetMaxProcessAge
+func GoSetMaxProcessAge(seconds C.double) {
+	monitor.SetMaxProcessAge(float64(seconds))
+}
+
+// GoSelectFrameLength is called from Cocoa when the user switches between the
+// frame lengths being collected. seconds is the length to display; an
+// unknown length is reported in the status bar.
+//
+//export GoSelectFrameLength
+func GoSelectFrameLength(seconds C.double) {
+	monitor.SelectFrameLength(float64(seconds))
+}
+
+// GoAddPattern adds pattern to a pattern list: list is 0 for exclude, 1 for
+// include-only, 2 for watch, 3 for noise left out of the frame totals (see
+// framescope.PatternList). The new setting is persisted to disk immediately.
+//
+//export GoAddPattern
+func GoAddPattern(list C.int, pattern *C.char) {
+	monitor.AddPattern(framescope.PatternList(list), C.GoString(pattern))
+}
+
+// GoRemovePattern removes pattern from a pattern list (see GoAddPattern). The
+// new setting is persisted to disk immediately.
+//
+//export GoRemovePattern
+func GoRemovePattern(list C.int, pattern *C.char) {
+	monitor.RemovePattern(framescope.PatternList(list), C.GoString(pattern))
+}
+
+// GoSetAlias shows label in place of the command of the processes target
+// names: a PID if target is a decimal number, for this session only,
+// otherwise a command pattern, which is persisted to disk immediately (see
+// framescope.Monitor.SetAlias).
+//
+//export GoSetAlias
+func GoSetAlias(target, label *C.char) {
+	monitor.SetAlias(C.GoString(target), C.GoString(label))
+}
+
+// GoRemoveAlias removes the alias of target, a PID or a command pattern (see
+// GoSetAlias).
+//
+//export GoRemoveAlias
+func GoRemoveAlias(target *C.char) {
+	monitor.RemoveAlias(C.GoString(target))
+}
+
+// GoSetFocusPID is called from Cocoa when the user picks "Focus on This
+// Process and Its Children" on a current-frame row. Only pid and its
+// descendants are monitored from the next snapshot on, until it exits or
+// GoClearFocusPID is called (see framescope.Monitor.SetFocusPID). PIDs cross
+// the bridge as C.longlong rather than C.int, here and in the other functions
+// taking one, so that none is truncated on the way: int is 64 bits on every
+// platform FrameScope builds for, and the monitor refuses a PID too large for
+// a pid_t itself.
+//
+//export GoSetFocusPID
+func GoSetFocusPID(pid C.longlong) {
+	monitor.SetFocusPID(int(pid))
+}
+
+// GoClearFocusPID is called from Cocoa when the user picks "Monitor All
+// Processes", ending the focus set with GoSetFocusPID.
+//
+//export GoClearFocusPID
+func GoClearFocusPID() {
+	monitor.ClearFocusPID()
+}
+
+// GoSetColumns sets the columns of the current-frame table payload, in order,
+// from a comma-separated list of framescope.FrameColumnNames, e.g.
+// "pid,command,share". Unknown names are ignored, and a list with no known
+// name restores the default layout. The new setting is persisted to disk
+// immediately.
+//
+//export GoSetColumns
+func GoSetColumns(csv *C.char) {
+	monitor.SetColumns(strings.Split(C.GoString(csv), ","))
+}
+
+// GoSetSummaryColumns sets the columns of the summary table payload from a
+// comma-separated list of framescope.SummaryColumnNames, as GoSetColumns does
+// for the current-frame table.
+//
+//export GoSetSummaryColumns
+func GoSetSummaryColumns(csv *C.char) {
+	monitor.SetSummaryColumns(strings.Split(C.GoString(csv), ","))
+}
+
+// GoCopyRowField is called from Cocoa when the user picks "Copy PID" or
+// "Copy Command" on a current-frame row. field is 0 for the PID, 1 for the
+// full command line. The text goes to the pasteboard only if pid is shown in
+// the current view; either way the status bar reports the outcome.
+//
+//export GoCopyRowField
+func GoCopyRowField(pid C.longlong, field C.int) {
+	text, err := monitor.CopyRowField(int(pid), framescope.RowField(field))
+	if err != nil {
+		return
+	}
+	cText := C.CString(text)
+	C.CopyToPasteboard(cText)
+	C.free(unsafe.Pointer(cText))
+}
+
+// GoSelectFrame is called from Cocoa when the user picks an entry from the
+// history popup or clicks Prev / Next. selectedIndex is the popup item index;
+// see Monitor.SelectFrame for how it is resolved.
+//
+//export GoSelectFrame
+func GoSelectFrame(selectedIndex C.int) {
+	monitor.SelectFrame(int(selectedIndex))
+}
+
+// GoMarkSummaryViewed is called from Cocoa when the user looks at the summary:
+// its By Process tab is selected, or the app becomes active with that tab
+// showing. From then on the summary's Since Viewed column shows how much each
+// process grew since the previous look (see
+// framescope.Monitor.MarkSummaryViewed).
+//
+//export GoMarkSummaryViewed
+func GoMarkSummaryViewed() {
+	monitor.MarkSummaryViewed()
+}
+
+// GoSetHistoryRangeSelection is called from Cocoa when the user Shift-picks
+// an entry in the history popup. fromIndex is the previously picked item and
+// toIndex the new one; see Monitor.SetHistoryRange for how they resolve.
+//
+//export GoSetHistoryRangeSelection
+func GoSetHistoryRangeSelection(fromIndex, toIndex C.int) {
+	monitor.SetHistoryRange(int(fromIndex), int(toIndex))
+}
+
+// GoSetReferenceFrame is called from Cocoa when the user picks "Compare With
+// This Frame". index is the history popup item index of a completed frame;
+// see Monitor.SetReferenceFrame.
+//
+//export GoSetReferenceFrame
+func GoSetReferenceFrame(index C.int) {
+	monitor.SetReferenceFrame(int(index))
+}
+
+// GoClearReferenceFrame is called from Cocoa when the user picks "Clear
+// Reference Frame".
+//
+//export GoClearReferenceFrame
+func GoClearReferenceFrame() {
+	monitor.ClearReferenceFrame()
+}
+
+// GoExportChart is called from Cocoa when the user picks a destination in the
+// "Export Chart…" save panel. path is the PNG file to write; errors are shown
+// in the status bar.
+//
+//export GoExportChart
+func GoExportChart(path *C.char) {
+	monitor.ExportChart(C.GoString(path))
+}
+
+// GoSetChartExport is called from Cocoa when the user picks an entry from the
+// "Save chart every" submenu and, unless it is Off, a folder. A chart of the
+// history is then saved into dir every time every frames have completed;
+// every 0 turns it off. The new setting is persisted to disk immediately.
+//
+//export GoSetChartExport
+func GoSetChartExport(every C.int, dir *C.char) {
+	monitor.SetChartExport(int(every), C.GoString(dir))
+}
+
+// GoExportHistory is called from Cocoa when the user picks a destination in
+// the "Export History…" save panel. path ends in .json or .csv, which selects
+// the format; errors are shown in the status bar.
+//
+//export GoExportHistory
+func GoExportHistory(path *C.char) {
+	monitor.ExportHistory(C.GoString(path))
+}
+
+// GoLoadBaselineSession is called from Cocoa when the user picks a file in the
+// "Load Baseline…" open panel. path is an exported .json or .ndjson history;
+// an empty path clears the baseline. Errors are shown in the status bar.
+//
+//export GoLoadBaselineSession
+func GoLoadBaselineSession(path *C.char) {
+	monitor.LoadBaselineSession(C.GoString(path))
+}
+
+// GoExportPidTimeline is called from Cocoa when the user picks a destination
+// for "Export Timeline…" on a summary row. It writes pid's CPU in every
+// completed frame to path as CSV; includeAbsent is non-zero to write frames
+// the process was absent from as 0, zero to omit them. Errors are shown in
+// the status bar.
+//
+//export GoExportPidTimeline
+func GoExportPidTimeline(pid C.longlong, path *C.char, includeAbsent C.int) {
+	monitor.ExportPidTimeline(int(pid), C.GoString(path), includeAbsent != 0)
+}
+
+// GoSetCPUBudget sets the session CPU budget (see
+// framescope.Monitor.SetCPUBudget): target is a PID or a command pattern, and
+// a notification is shown once its processes have used more than seconds
+// CPU-seconds in total. An empty target or seconds <= 0 removes the budget.
+// The new setting is persisted to disk immediately.
+//
+//export GoSetCPUBudget
+func GoSetCPUBudget(target *C.char, seconds C.double) {
+	monitor.SetCPUBudget(C.GoString(target), float64(seconds))
+}
+
+// GoGetFrameJSON returns every process of one frame as a JSON object (see
+// framescope.Monitor.FrameJSON), for an inspector panel or a script that needs
+// more than the table shows. index is the frame number, FrameRecord.Index, or
+// the number of the frame in progress; an unknown or evicted frame yields
+// "{}". The returned string is allocated with malloc and must be freed by the
+// caller.
+//
+//export GoGetFrameJSON
+func GoGetFrameJSON(index C.int) *C.char {
+	return C.CString(monitor.FrameJSON(int(index)))
+}
+
+// GoGetHistoryJSON returns the whole in-memory history, completed frames and
+// the one in progress, as a JSON array of the frames the JSON history export
+// writes (see framescope.Monitor.HistoryJSON), for a consumer that wants the
+// data without a file. Past 8 MB the oldest frames are left out. The returned
+// string is allocated with malloc and must be freed by the caller.
+//
+//export GoGetHistoryJSON
+func GoGetHistoryJSON() *C.char {
+	return C.CString(monitor.HistoryJSON())
+}
+
+// GoGetSummaryJSON returns the summary, with the rows and order the summary
+// table shows but every metric as a number and no row cap, as a JSON object
+// (see framescope.Monitor.SummaryJSON). The returned string is allocated with
+// malloc and must be freed by the caller.
+//
+//export GoGetSummaryJSON
+func GoGetSummaryJSON() *C.char {
+	return C.CString(monitor.SummaryJSON())
+}
+
+// GoGetTopProcess returns the row of the current view that used the most CPU,
+// among those the table shows, as a JSON object with its pid, command, cpu,
+// and percent (see framescope.Monitor.TopProcessJSON), or "{}" when there is
+// none; for a widget or Touch Bar item that shows only the busiest process.
+// The returned string is allocated with malloc and must be freed by the
+// caller.
+//
+//export GoGetTopProcess
+func GoGetTopProcess() *C.char {
+	return C.CString(monitor.TopProcessJSON())
+}
+
+// GoSnapshotNow takes a one-off reading of seconds and returns the processes
+// that used the most CPU over it as a JSON object (see
+// framescope.Monitor.SnapshotNow), without starting, stopping, or otherwise
+// affecting monitoring. It blocks for the whole reading, at most a minute, so
+// call it off the main thread. The returned string is allocated with malloc
+// and must be freed by the caller.
+//
+//export GoSnapshotNow
+func GoSnapshotNow(seconds C.double) *C.char {
+	return C.CString(monitor.SnapshotNow(float64(seconds)))
+}
+
+// GoGetFrameCount returns how many completed frames the history holds (see
+// framescope.Monitor.FrameCount), so that Cocoa can show the count and
+// enable navigation correctly with an empty or one-frame history.
+//
+//export GoGetFrameCount
+func GoGetFrameCount() C.int {
+	completed, _ := monitor.FrameCount()
+	return C.int(completed)
+}
+
+// GoGetFrameInProgress returns 1 if a frame is in progress, that is while a
+// run is active, 0 otherwise. The history popup lists it after the
+// GoGetFrameCount completed frames.
+//
+//export GoGetFrameInProgress
+func GoGetFrameInProgress() C.int {
+	_, inProgress := monitor.FrameCount()
+	return cBool(inProgress)
+}
+
+// GoGetStatus returns the current status line, e.g. for a status item
+// tooltip, without the cost of a full UI update. The returned string is
+// allocated with malloc and must be freed by the caller.
+//
+//export GoGetStatus
+func GoGetStatus() *C.char {
+	return C.CString(monitor.Status())
+}
+
+// GoGetIconPath returns the .icns path of the application bundle that pid
+// runs from, or an empty string when none is found so the caller can fall
+// back to a generic icon. Lookups are cached by executable path. The returned
+// string is allocated with malloc and must be freed by the caller.
+//
+//export GoGetIconPath
+func GoGetIconPath(pid C.longlong) *C.char {
+	return C.CString(monitor.IconPath(int(pid)))
+}
+
+// GoInitialHideSmall is called from Cocoa during startup to read the persisted
+// hideSmall preference so the toolbar checkbox can be initialised correctly.
+// Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialHideSmall
+func GoInitialHideSmall() C.int {
+	return cBool(monitor.HideSmall())
+}
+
+// GoInitialSmallFilterMode is called from Cocoa during startup to read the
+// persisted small-process filter mode (0 = CPU-seconds, 1 = percent).
+//
+//export GoInitialSmallFilterMode
+func GoInitialSmallFilterMode() C.int {
+	mode, _ := monitor.SmallFilter()
+	return C.int(mode)
+}
+
+// GoInitialSmallFilterPercent is called from Cocoa during startup to read the
+// persisted %CPU threshold of the small-process filter so the submenu can
+// check the matching item.
+//
+//export GoInitialSmallFilterPercent
+func GoInitialSmallFilterPercent() C.double {
+	_, percent := monitor.SmallFilter()
+	return C.double(percent)
+}
+
+// GoInitialHidePaths is called from Cocoa during startup to read the persisted
+// hidePaths preference so the toolbar checkbox can be initialised correctly.
+// Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialHidePaths
+func GoInitialHidePaths() C.int {
+	return cBool(monitor.HidePaths())
+}
+
+// GoInitialShowSparklines is called from Cocoa during startup to read the
+// persisted sparkline preference so the menu item and Trend column visibility
+// can be initialised correctly. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowSparklines
+func GoInitialShowSparklines() C.int {
+	return cBool(monitor.ShowSparklines())
+}
+
+// GoInitialShowPercent is called from Cocoa during startup to read the
+// persisted %CPU-column preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowPercent
+func GoInitialShowPercent() C.int {
+	return cBool(monitor.ShowPercent())
+}
+
+// GoInitialShowLiveDelta is called from Cocoa during startup to read the
+// persisted live-delta-column preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowLiveDelta
+func GoInitialShowLiveDelta() C.int {
+	return cBool(monitor.ShowLiveDelta())
+}
+
+// GoInitialShowUserSys is called from Cocoa during startup to read the
+// persisted user/sys-columns preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowUserSys
+func GoInitialShowUserSys() C.int {
+	return cBool(monitor.ShowUserSys())
+}
+
+// GoInitialMarkTinyValues is called from Cocoa during startup to read the
+// persisted tiny-value preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialMarkTinyValues
+func GoInitialMarkTinyValues() C.int {
+	return cBool(monitor.MarkTinyValues())
+}
+
+// GoInitialShowShare is called from Cocoa during startup to read the persisted
+// share-column preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowShare
+func GoInitialShowShare() C.int {
+	return cBool(monitor.ShowShare())
+}
+
+// GoInitialNoiseIncludesSelf is called from Cocoa during startup to read
+// whether FrameScope leaves itself out of the frame totals. Returns 1 if
+// enabled, 0 otherwise.
+//
+//export GoInitialNoiseIncludesSelf
+func GoInitialNoiseIncludesSelf() C.int {
+	return cBool(monitor.NoiseIncludesSelf())
+}
+
+// GoInitialShowStatus is called from Cocoa during startup to read the
+// persisted state-marker preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowStatus
+func GoInitialShowStatus() C.int {
+	return cBool(monitor.ShowStatus())
+}
+
+// GoInitialChangedOnly is called from Cocoa during startup to read the
+// persisted changed-only view preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialChangedOnly
+func GoInitialChangedOnly() C.int {
+	return cBool(monitor.ChangedOnly())
+}
+
+// GoInitialFreezeTopN is called from Cocoa during startup to read the
+// persisted freeze top-N preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialFreezeTopN
+func GoInitialFreezeTopN() C.int {
+	return cBool(monitor.FreezeTopN())
+}
+
+// GoInitialMergeReusedPIDs is called from Cocoa during startup to read the
+// persisted reused-PID merge preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialMergeReusedPIDs
+func GoInitialMergeReusedPIDs() C.int {
+	return cBool(monitor.MergeReusedPIDs())
+}
+
+// GoInitialMenuBarItem is called from Cocoa during startup to read the
+// persisted menu bar indicator preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialMenuBarItem
+func GoInitialMenuBarItem() C.int {
+	return cBool(monitor.MenuBarItem())
+}
+
+// GoInitialPauseOnWindowClose is called from Cocoa during startup to read the
+// persisted pause-on-window-close preference. Returns 1 if enabled, 0
+// otherwise.
+//
+//export GoInitialPauseOnWindowClose
+func GoInitialPauseOnWindowClose() C.int {
+	return cBool(monitor.PauseOnWindowClose())
+}
+
+// GoInitialReplaySpeed is called from Cocoa during startup to read the
+// persisted replay speed in frames per second.
+//
+//export GoInitialReplaySpeed
+func GoInitialReplaySpeed() C.double {
+	return C.double(monitor.ReplaySpeed())
+}
+
+// GoInitialReplayLoop is called from Cocoa during startup to read the
+// persisted replay loop preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialReplayLoop
+func GoInitialReplayLoop() C.int {
+	return cBool(monitor.ReplayLoop())
+}
+
+// GoInitialIncludeKernelTask is called from Cocoa during startup to read the
+// persisted kernel_task preference. Returns 1 if included, 0 otherwise.
+//
+//export GoInitialIncludeKernelTask
+func GoInitialIncludeKernelTask() C.int {
+	return cBool(monitor.IncludeKernelTask())
+}
+
+// GoInitialShowOnlyMine is called from Cocoa during startup to read the
+// persisted own-processes preference. Returns 1 if enabled, 0 otherwise.
+//
+//export GoInitialShowOnlyMine
+func GoInitialShowOnlyMine() C.int {
+	return cBool(monitor.ShowOnlyMine())
+}
+
+// GoInitialTheme is called from Cocoa during startup to read the persisted
+// chart theme (0 = system, 1 = light, 2 = dark).
+//
+//export GoInitialTheme
+func GoInitialTheme() C.int {
+	return C.int(monitor.Theme())
+}
+
+// GoInitialAverageMode is called from Cocoa during startup to read the
+// persisted summary average mode (0 = all frames, 1 = appeared frames).
+//
+//export GoInitialAverageMode
+func GoInitialAverageMode() C.int {
+	return C.int(monitor.AverageMode())
+}
+
+// GoInitialTiebreaker is called from Cocoa during startup to read the
+// persisted ordering of equal-CPU rows (0 = PID, 1 = command, 2 = start time).
+//
+//export GoInitialTiebreaker
+func GoInitialTiebreaker() C.int {
+	return C.int(monitor.Tiebreaker())
+}
+
+// GoInitialSummarySortField is called from Cocoa during startup to read the
+// persisted summary sort field (see GoSetSummarySort).
+//
+//export GoInitialSummarySortField
+func GoInitialSummarySortField() C.int {
+	field, _ := monitor.SummarySort()
+	return C.int(field)
+}
+
+// GoInitialSummarySortDescending is called from Cocoa during startup to read
+// the persisted summary sort direction (1 = descending, 0 = ascending).
+//
+//export GoInitialSummarySortDescending
+func GoInitialSummarySortDescending() C.int {
+	_, descending := monitor.SummarySort()
+	return cBool(descending)
+}
+
+// GoInitialResumeMode is called from Cocoa during startup to read the
+// persisted resume mode (0 = rebaseline, 1 = continue).
+//
+//export GoInitialResumeMode
+func GoInitialResumeMode() C.int {
+	return C.int(monitor.ResumeMode())
+}
+
+// GoInitialGroupMode is called from Cocoa during startup to read the persisted
+// app helper grouping (0 = off, 1 = collapsed, 2 = expanded).
+//
+//export GoInitialGroupMode
+func GoInitialGroupMode() C.int {
+	return C.int(monitor.GroupMode())
+}
+
+// GoInitialSpikeRatio is called from Cocoa during startup to read the
+// persisted spike ratio so the submenu can check the matching item.
+//
+//export GoInitialSpikeRatio
+func GoInitialSpikeRatio() C.double {
+	return C.double(monitor.SpikeRatio())
+}
+
+// GoInitialSummaryMinTotal is called from Cocoa during startup to read the
+// persisted summary threshold in CPU-seconds so the submenu can check the
+// matching item.
+//
+//export GoInitialSummaryMinTotal
+func GoInitialSummaryMinTotal() C.double {
+	return C.double(monitor.SummaryMinTotal())
+}
+
+// GoInitialSummaryMinFrames is called from Cocoa during startup to read the
+// persisted summary appearance threshold so the submenu can check the
+// matching item.
+//
+//export GoInitialSummaryMinFrames
+func GoInitialSummaryMinFrames() C.int {
+	return C.int(monitor.SummaryMinFrames())
+}
+
+// GoInitialPatterns is called from Cocoa to read a persisted pattern list
+// (see GoAddPattern) as newline-separated patterns. The returned string is
+// allocated with malloc and must be freed by the caller.
+//
+//export GoInitialPatterns
+func GoInitialPatterns(list C.int) *C.char {
+	return C.CString(strings.Join(monitor.Patterns(framescope.PatternList(list)), "\n"))
+}
+
+// GoInitialColumns is called from Cocoa during startup to lay out the
+// current-frame table like its payload: the columns, in order, as a
+// comma-separated list. The returned string is allocated with malloc and must
+// be freed by the caller.
+//
+//export GoInitialColumns
+func GoInitialColumns() *C.char {
+	return C.CString(strings.Join(monitor.Columns(), ","))
+}
+
+// GoInitialSummaryColumns is GoInitialColumns for the summary table.
+//
+//export GoInitialSummaryColumns
+func GoInitialSummaryColumns() *C.char {
+	return C.CString(strings.Join(monitor.SummaryColumns(), ","))
+}
+
+// GoInitialFrameSeconds is called from Cocoa during startup to populate the
+// frame-length text field with the persisted value.
+//
+//export GoInitialFrameSeconds
+func GoInitialFrameSeconds() C.double {
+	return C.double(monitor.FrameSeconds())
+}
+
+// GoInitialSecondFrameSeconds is called from Cocoa during startup to read the
+// persisted second frame length in seconds (0 = none) so the submenu can
+// check the matching item.
+//
+//export GoInitialSecondFrameSeconds
+func GoInitialSecondFrameSeconds() C.double {
+	return C.double(monitor.SecondFrameLength())
+}
+
+// GoInitialStartDelay is called from Cocoa during startup to read the
+// persisted start delay in seconds (0 = none) so the submenu can check the
+// matching item.
+//
+//export GoInitialStartDelay
+func GoInitialStartDelay() C.double {
+	return C.double(monitor.StartDelay())
+}
+
+// GoInitialChartExportEvery is called from Cocoa during startup to read the
+// persisted time-lapse chart interval in frames (0 = off) so the submenu can
+// check the matching preset.
+//
+//export GoInitialChartExportEvery
+func GoInitialChartExportEvery() C.int {
+	every, _ := monitor.ChartExport()
+	return C.int(every)
+}
+
+// GoInitialFrameWarmup is called from Cocoa during startup to read the
+// persisted per-frame warm-up in seconds (0 = none) so the submenu can check
+// the matching item.
+//
+//export GoInitialFrameWarmup
+func GoInitialFrameWarmup() C.double {
+	return C.double(monitor.FrameWarmup())
+}
+
+// GoInitialStopAfterFrames is called from Cocoa during startup to read the
+// persisted frame limit (0 = none) so the submenu can check the matching
+// item.
+//
+//export GoInitialStopAfterFrames
+func GoInitialStopAfterFrames() C.int {
+	return C.int(monitor.StopAfterFrames())
+}
+
+// GoInitialCompletionAlert is called from Cocoa during startup to read the
+// persisted completion alert (0 = never, 1 = when finished, 2 = also on Stop).
+//
+//export GoInitialCompletionAlert
+func GoInitialCompletionAlert() C.int {
+	return C.int(monitor.CompletionAlert())
+}
+
+// GoInitialAnomalySigma is called from Cocoa during startup to read the
+// persisted outlier threshold so the submenu can check the matching item.
+//
+//export GoInitialAnomalySigma
+func GoInitialAnomalySigma() C.double {
+	return C.double(monitor.AnomalySigma())
+}
+
+// GoInitialNotifyAnomalies is called from Cocoa during startup to read the
+// persisted outlier notification setting.
+//
+//export GoInitialNotifyAnomalies
+func GoInitialNotifyAnomalies() C.int {
+	return cBool(monitor.NotifyAnomalies())
+}
+
+// GoInitialCollectTopN is called from Cocoa during startup to read the
+// persisted number of rows completed frames keep (0 = all) so the submenu can
+// check the matching item.
+//
+//export GoInitialCollectTopN
+func GoInitialCollectTopN() C.int {
+	return C.int(monitor.CollectTopN())
+}
+
+// GoInitialMaxProcessAge is called from Cocoa during startup to read the
+// persisted maximum process age in seconds (0 = any age) so the submenu can
+// check the matching item.
+//
+//export GoInitialMaxProcessAge
+func GoInitialMaxProcessAge() C.double {
+	return C.double(monitor.MaxProcessAge())
+}
+
+// GoInitialHistoryRetention is called from Cocoa during startup to read the
+// persisted history retention in seconds (0 = by count) so the submenu can
+// check the matching item.
+//
+//export GoInitialHistoryRetention
+func GoInitialHistoryRetention() C.double {
+	return C.double(monitor.HistoryRetention())
+}
+
+// GoGetConfig returns every persisted preference as a JSON object (see
+// framescope.Monitor.ConfigJSON), so a settings window can read them in one
+// call instead of through the GoInitial* functions. The returned string is
+// allocated with malloc and must be freed by the caller.
+//
+//export GoGetConfig
+func GoGetConfig() *C.char {
+	data, _ := monitor.ConfigJSON()
+	return C.CString(data)
+}
+
+// GoSetConfig replaces every preference with those in json, an object in the
+// form GoGetConfig returns. Invalid input changes nothing and is reported in
+// the status bar. Returns 1 if the settings were applied, 0 otherwise.
+//
+//export GoSetConfig
+func GoSetConfig(json *C.char) C.int {
+	return cBool(monitor.SetConfigJSON(C.GoString(json)) == nil)
+}
+
+// GoExportConfig is called from Cocoa when the user picks a destination in
+// the "Export Settings…" save panel. It writes every preference to path as
+// the JSON object GoGetConfig returns, for sharing; errors are shown in the
+// status bar.
+//
+//export GoExportConfig
+func GoExportConfig(path *C.char) {
+	monitor.ExportConfig(C.GoString(path))
+}
+
+// GoImportConfig is called from Cocoa when the user picks a file in the
+// "Import Settings…" open panel. The settings in path are validated, applied,
+// and persisted; an invalid file changes nothing and is reported in the
+// status bar. Returns 1 if the settings were applied, 0 otherwise.
+//
+//export GoImportConfig
+func GoImportConfig(path *C.char) C.int {
+	return cBool(monitor.ImportConfig(C.GoString(path)) == nil)
+}
+
+// GoReloadConfig is called from Cocoa for the "Reload Settings" menu item,
+// after the settings file was edited outside FrameScope. The file is re-read
+// and applied without disturbing an active run; a file that cannot be read
+// or parsed changes nothing and is reported in the status bar. Returns 1 if
+// any setting changed, so Cocoa can refresh its controls, 0 otherwise.
+//
+//export GoReloadConfig
+func GoReloadConfig() C.int {
+	changed, _ := monitor.ReloadConfig()
+	return cBool(changed)
+}
+
+// cBool converts a Go bool into the 1 / 0 convention used across the bridge.
+func cBool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+// Headless captures a fixed number of frames with the framescope library and
+// prints each completed frame as CSV, without any UI:
+//
+//	go run ./examples/headless -frame 5 -frames 3
+//
+// It never reads or writes the FrameScope settings file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"monitor_cpu/framescope"
+)
+
+// stderrSink reports monitor errors on stderr and ignores UI refreshes; the
+// frames are read back with Monitor.Frames instead.
+type stderrSink struct{}
+
+func (stderrSink) UpdateResults(u framescope.UIUpdate) {}
+
+func (stderrSink) ShowErrorMessage(message string) {
+	fmt.Fprintln(os.Stderr, message)
+}
+
+func main() {
+	frameSeconds := flag.Float64("frame", 5, "frame length in seconds")
+	frameCount := flag.Int("frames", 3, "number of frames to capture")
+	all := flag.Bool("all", false, "include processes below 1 CPU-second")
+	flag.Parse()
+
+	m := framescope.NewMonitor(stderrSink{})
+	if err := m.Start(*frameSeconds); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer m.Stop()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	printed := 0
+	for printed < *frameCount {
+		select {
+		case <-interrupt:
+			return
+		case <-ticker.C:
+		}
+		frames := m.Frames()
+		for ; printed < len(frames) && printed < *frameCount; printed++ {
+			frame := frames[printed]
+			fmt.Printf("# frame %d (%.1fs)\n", frame.Index, frame.Duration.Seconds())
+			fmt.Print(framescope.RenderTable(frame.Rows, framescope.TableOptions{HideSmall: !*all}, framescope.CSVFormatter{}))
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package framescope
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// aliasTable holds the user's friendly labels for processes (see SetAlias).
+// Both maps are replaced, never modified in place, so a copy may be shared
+// with a render after mu is released.
+type aliasTable struct {
+	// byPattern labels the processes whose command line or executable path
+	// contains the key, ignoring case, as in the pattern lists. It outlives
+	// any one process and is persisted.
+	byPattern map[string]string
+
+	// byPID labels one process for the rest of the session only, since the
+	// PID may name another process after a restart.
+	byPID map[int]string
+}
+
+// label returns the alias for row, or "" if it has none. A PID alias takes
+// precedence over any pattern alias. Of several matching patterns the
+// longest, being the most specific, wins; equally long ones are compared
+// ignoring case so the choice does not depend on map order.
+func (a aliasTable) label(row ResultRow) string {
+	if label, ok := a.byPID[row.PID]; ok {
+		return label
+	}
+	best := ""
+	for pattern := range a.byPattern {
+		if !matchesAny(row, []string{pattern}) {
+			continue
+		}
+		if best == "" || len(pattern) > len(best) || (len(pattern) == len(best) && strings.ToLower(pattern) < strings.ToLower(best)) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return a.byPattern[best]
+}
+
+// aliasCommand returns the command column text for a process labelled
+// label: the label, followed by the executable name of command in
+// parentheses so the process stays recognisable.
+func aliasCommand(label, command string) string {
+	if base := baseCommand(command); base != "" {
+		return fmt.Sprintf("%s (%s)", label, base)
+	}
+	return label
+}
+
+// aliasPID returns the PID target names, if target is a decimal number.
+func aliasPID(target string) (int, bool) {
+	pid, err := strconv.Atoi(target)
+	return pid, err == nil
+}
+
+// normalizeAliases returns the pattern aliases in aliases with surrounding
+// space trimmed and entries with an empty pattern or label, or a PID as
+// pattern, dropped. Patterns equal ignoring case keep the first in sorted
+// order. It returns nil if none remain.
+func normalizeAliases(aliases map[string]string) map[string]string {
+	patterns := make([]string, 0, len(aliases))
+	for pattern := range aliases {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var out map[string]string
+	seen := make(map[string]bool, len(aliases))
+	for _, raw := range patterns {
+		pattern, label := strings.TrimSpace(raw), strings.TrimSpace(aliases[raw])
+		key := strings.ToLower(pattern)
+		if _, isPID := aliasPID(pattern); pattern == "" || label == "" || isPID || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[pattern] = label
+	}
+	return out
+}
+
+// SetAlias shows label in place of the command of the processes target
+// names, in both the current-frame table and the summary, with the
+// executable name kept alongside in parentheses. target is a PID if it is a
+// decimal number, which labels that process for this session only;
+// otherwise it is a command pattern, matched like the pattern lists, whose
+// label applies to every matching process and is persisted to disk
+// immediately. A PID alias takes precedence over a pattern alias. Setting a
+// target that already has an alias, compared ignoring case and surrounding
+// space, replaces its label. An empty target or label is ignored; use
+// RemoveAlias to remove one.
+func (m *Monitor) SetAlias(target, label string) {
+	target, label = strings.TrimSpace(target), strings.TrimSpace(label)
+	if target == "" || label == "" {
+		return
+	}
+	if pid, ok := aliasPID(target); ok {
+		m.mu.Lock()
+		byPID := make(map[int]string, len(m.aliases.byPID)+1)
+		for p, l := range m.aliases.byPID {
+			byPID[p] = l
+		}
+		byPID[pid] = label
+		m.aliases.byPID = byPID
+		m.mu.Unlock()
+		m.pushUI(0)
+		return
+	}
+
+	m.mu.Lock()
+	byPattern := map[string]string{target: label}
+	for p, l := range m.aliases.byPattern {
+		if !strings.EqualFold(p, target) {
+			byPattern[p] = l
+		}
+	}
+	m.aliases.byPattern = byPattern
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// RemoveAlias removes the alias of target, a PID or a command pattern as in
+// SetAlias, compared ignoring case and surrounding space. Removing a pattern
+// alias is persisted to disk immediately.
+func (m *Monitor) RemoveAlias(target string) {
+	target = strings.TrimSpace(target)
+	if pid, ok := aliasPID(target); ok {
+		m.mu.Lock()
+		var byPID map[int]string
+		for p, l := range m.aliases.byPID {
+			if p == pid {
+				continue
+			}
+			if byPID == nil {
+				byPID = make(map[int]string)
+			}
+			byPID[p] = l
+		}
+		m.aliases.byPID = byPID
+		m.mu.Unlock()
+		m.pushUI(0)
+		return
+	}
+
+	m.mu.Lock()
+	var byPattern map[string]string
+	for p, l := range m.aliases.byPattern {
+		if strings.EqualFold(p, target) {
+			continue
+		}
+		if byPattern == nil {
+			byPattern = make(map[string]string)
+		}
+		byPattern[p] = l
+	}
+	m.aliases.byPattern = byPattern
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// Aliases returns the aliases as target → label, PIDs written in decimal
+// alongside the command patterns (see SetAlias).
+func (m *Monitor) Aliases() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]string, len(m.aliases.byPattern)+len(m.aliases.byPID))
+	for pattern, label := range m.aliases.byPattern {
+		out[pattern] = label
+	}
+	for pid, label := range m.aliases.byPID {
+		out[strconv.Itoa(pid)] = label
+	}
+	return out
+}
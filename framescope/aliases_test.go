@@ -0,0 +1,92 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAliasLabelPrecedence(t *testing.T) {
+	aliases := aliasTable{
+		byPattern: map[string]string{
+			"helper":            "Some helper",
+			"Helper --type=gpu": "Chrome GPU",
+		},
+		byPID: map[int]string{7: "My build"},
+	}
+	tests := []struct {
+		row  ResultRow
+		want string
+	}{
+		{ResultRow{PID: 1, Command: "/Apps/Chrome.app/Helper --type=renderer"}, "Some helper"},
+		{ResultRow{PID: 2, Command: "/Apps/Chrome.app/Helper --type=gpu"}, "Chrome GPU"}, // longest pattern
+		{ResultRow{PID: 3, Exe: "/Apps/Slack.app/Slack Helper"}, "Some helper"},          // matched on the executable
+		{ResultRow{PID: 7, Command: "/Apps/Chrome.app/Helper --type=gpu"}, "My build"},   // PID over pattern
+		{ResultRow{PID: 8, Command: "/usr/bin/make"}, ""},
+	}
+	for _, tt := range tests {
+		if got := aliases.label(tt.row); got != tt.want {
+			t.Errorf("label of PID %d = %q, want %q", tt.row.PID, got, tt.want)
+		}
+	}
+}
+
+func TestAliasesShownInBothTables(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetAlias("worker", "Queue worker")
+	m.SetAlias("2", "Ignored")
+	m.SetAlias("1", "Init")
+	m.RemoveAlias(" 2 ")
+	rows := []ResultRow{
+		{PID: 1, Diff: 5, Command: "/sbin/launchd"},
+		{PID: 2, Diff: 4, Command: "/usr/local/bin/worker --queue=mail"},
+		{PID: 3, Diff: 3, Command: "/usr/bin/make"},
+	}
+
+	m.mu.Lock()
+	opts := m.tableOptionsLocked()
+	aliases := m.aliases
+	m.mu.Unlock()
+	got := RenderTable(rows, opts, TabFormatter{Columns: []string{"pid", "command"}})
+	want := "1\tInit (launchd)\n2\tQueue worker (worker)\n3\t/usr/bin/make\n"
+	if got != want {
+		t.Errorf("frame table = %q, want %q", got, want)
+	}
+
+	history := []FrameRecord{{Index: 1, Duration: time.Second, Rows: rows}}
+	summary := SummaryOptions{HidePaths: true, Sort: SummarySortPID, Ascending: true, Columns: []string{"pid", "command"}, aliases: aliases}
+	got = RenderSummaryTable(history, summary)
+	want = "1\tInit (launchd)\n2\tQueue worker (worker)\n3\tmake\n"
+	if got != want {
+		t.Errorf("summary table = %q, want %q", got, want)
+	}
+}
+
+func TestPatternAliasesPersistButPIDAliasesDoNot(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetAlias("chrome", "Browser")
+	m.SetAlias(" CHROME ", "Web")
+	m.SetAlias("python", "Scripts")
+	m.SetAlias("42", "Session only")
+	m.RemoveAlias("Python")
+
+	cfg, err := m.ConfigJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(cfg, "Session only") {
+		t.Errorf("config stores a PID alias: %s", cfg)
+	}
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	got := loaded.Aliases()
+	if len(got) != 1 || got["CHROME"] != "Web" {
+		t.Errorf("aliases loaded as %v, want CHROME: Web", got)
+	}
+
+	if err := m.SetConfigJSON(`{"frame_seconds": 5, "aliases": {"42": "PID"}}`); err == nil {
+		t.Error("SetConfigJSON accepted an alias by PID")
+	}
+}
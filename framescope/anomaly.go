@@ -0,0 +1,142 @@
+package framescope
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// defaultAnomalySigma is how many standard deviations above the rolling
+	// mean a frame's utilization must be for the frame to be flagged as an
+	// outlier, until SetAnomalySigma chooses another.
+	defaultAnomalySigma = 2.0
+
+	// anomalyWindow is how many of the most recent completed frames the
+	// rolling statistics cover.
+	anomalyWindow = 30
+
+	// minAnomalyFrames is how many frames the rolling statistics must cover
+	// before any frame is flagged, so that the first few frames of a run,
+	// which have little to go by, are not.
+	minAnomalyFrames = 5
+
+	// minAnomalyStdDev is the least standard deviation, in percentage points
+	// of utilization, a frame is measured against. A perfectly flat series
+	// has none, and would otherwise flag a frame a fraction of a point above
+	// it.
+	minAnomalyStdDev = 1.0
+)
+
+// outlierMarker tags an outlier frame in the history popup payload (see
+// historyPayloadLocked).
+const outlierMarker = "outlier"
+
+// rollingStats keeps the mean and standard deviation of the last
+// anomalyWindow values added, updated incrementally as each is added rather
+// than recomputed over the window.
+type rollingStats struct {
+	values          []float64 // ring buffer, oldest at next once full
+	next            int
+	sum, sumSquares float64
+}
+
+// add adds v to the window, dropping the oldest value if it is full.
+func (r *rollingStats) add(v float64) {
+	if len(r.values) < anomalyWindow {
+		r.values = append(r.values, v)
+	} else {
+		old := r.values[r.next]
+		r.sum -= old
+		r.sumSquares -= old * old
+		r.values[r.next] = v
+		r.next = (r.next + 1) % anomalyWindow
+	}
+	r.sum += v
+	r.sumSquares += v * v
+}
+
+// meanStdDev returns the mean and population standard deviation of the
+// values in the window, and how many there are.
+func (r *rollingStats) meanStdDev() (mean, stddev float64, n int) {
+	n = len(r.values)
+	if n == 0 {
+		return 0, 0, 0
+	}
+	mean = r.sum / float64(n)
+	// Rounding in the running sums can leave a flat series slightly negative.
+	return mean, math.Sqrt(max(r.sumSquares/float64(n)-mean*mean, 0)), n
+}
+
+// outlier reports whether v stands more than sigma standard deviations
+// above the mean of the window, once it covers minAnomalyFrames values.
+func (r *rollingStats) outlier(v, sigma float64) bool {
+	mean, stddev, n := r.meanStdDev()
+	return n >= minAnomalyFrames && v > mean+sigma*max(stddev, minAnomalyStdDev)
+}
+
+// flagOutlierLocked sets frame.Outlier, a frame of v's length that has just
+// completed, by comparing its utilization with v's rolling statistics of the
+// frames before it, then adds it to them. Must be called with m.mu held.
+func (m *Monitor) flagOutlierLocked(v *frameView, frame *FrameRecord) {
+	frame.Outlier = v.utilizationStats.outlier(frame.Utilization, m.anomalySigma)
+	v.utilizationStats.add(frame.Utilization)
+}
+
+// outlierAlertLocked returns the notification for frame if it is an outlier
+// and SetNotifyAnomalies is on, or ok = false. Must be called with m.mu held.
+func (m *Monitor) outlierAlertLocked(frame FrameRecord) (title, body string, ok bool) {
+	if !frame.Outlier || !m.notifyAnomalies {
+		return "", "", false
+	}
+	return "Unusually busy frame",
+		fmt.Sprintf("Frame %d kept %.0f%% of all cores busy, over %g standard deviations above the recent frames.",
+			frame.Index, frame.Utilization, m.anomalySigma),
+		true
+}
+
+// validAnomalySigma reports whether sigma is a usable outlier threshold.
+func validAnomalySigma(sigma float64) bool {
+	return sigma > 0 && !math.IsInf(sigma, 0)
+}
+
+// SetAnomalySigma sets how many standard deviations above the rolling mean of
+// the last 30 completed frames a frame's utilization must be for the frame to
+// be flagged as an outlier: marked in the history popup and, with
+// SetNotifyAnomalies, notified. No frame is flagged until five have
+// completed. It applies to frames completed from then on. Non-positive or
+// non-finite values are ignored. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetAnomalySigma(sigma float64) {
+	if !validAnomalySigma(sigma) {
+		return
+	}
+	m.mu.Lock()
+	m.anomalySigma = sigma
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// AnomalySigma returns how many standard deviations above the rolling mean a
+// frame's utilization must be for the frame to be flagged as an outlier.
+func (m *Monitor) AnomalySigma() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.anomalySigma
+}
+
+// SetNotifyAnomalies shows a notification for every frame of the main frame
+// length flagged as an outlier (see SetAnomalySigma), for long unattended
+// runs. The new setting is persisted to disk immediately.
+func (m *Monitor) SetNotifyAnomalies(enabled bool) {
+	m.mu.Lock()
+	m.notifyAnomalies = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// NotifyAnomalies reports whether outlier frames are notified.
+func (m *Monitor) NotifyAnomalies() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.notifyAnomalies
+}
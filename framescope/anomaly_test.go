@@ -0,0 +1,84 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutlierFrameFlagged(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.cores = 1
+	m.running = true
+	m.frameView = newFrameView(10)
+	m.frameStart = clock.Now()
+
+	// Ten-second frames keep one core 20% busy, but for a spike to 60% in
+	// frame 8 and a slight rise to 20.5% in frame 10.
+	cpu := 0.0
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: map[int]ProcessSample{1: {CPUSeconds: 0, Command: "/bin/build"}}, start: clock.Now()}
+	for index := 1; index <= 12; index++ {
+		switch index {
+		case 8:
+			cpu += 6
+		case 10:
+			cpu += 2.05
+		default:
+			cpu += 2
+		}
+		clock.Advance(10 * time.Second)
+		frame, completed := m.advanceTrack(track, map[int]ProcessSample{1: {CPUSeconds: cpu, Command: "/bin/build"}}, clock.Now(), false, TiebreakPID)
+		if !completed {
+			t.Fatalf("frame %d did not complete", index)
+		}
+		if want := index == 8; frame.Outlier != want {
+			t.Errorf("frame %d at %.1f%%: outlier = %v, want %v", index, frame.Utilization, frame.Outlier, want)
+		}
+	}
+
+	m.mu.Lock()
+	payload, _ := m.historyPayloadLocked()
+	_, _, notified := m.outlierAlertLocked(m.history[7])
+	m.mu.Unlock()
+	items := strings.Split(payload, "\n")
+	if !strings.HasSuffix(items[7], "\t"+outlierMarker) || strings.Contains(items[6], outlierMarker) {
+		t.Errorf("history items = %q, want only frame 8 marked", items)
+	}
+	if notified {
+		t.Error("outlier notified with notifications off")
+	}
+
+	m.SetNotifyAnomalies(true)
+	m.mu.Lock()
+	title, body, notified := m.outlierAlertLocked(m.history[7])
+	m.mu.Unlock()
+	if !notified || !strings.Contains(body, "Frame 8 kept 60% of all cores busy") {
+		t.Errorf("notification = %q: %q, %v; want frame 8 named", title, body, notified)
+	}
+}
+
+func TestRollingStatsWindow(t *testing.T) {
+	var r rollingStats
+	for range anomalyWindow {
+		r.add(100)
+	}
+	// Once the window is full, each new value pushes out the oldest.
+	for range anomalyWindow {
+		r.add(10)
+	}
+	if mean, stddev, n := r.meanStdDev(); mean != 10 || stddev != 0 || n != anomalyWindow {
+		t.Errorf("meanStdDev() = %v, %v, %d; want 10, 0, %d", mean, stddev, n, anomalyWindow)
+	}
+	if r.outlier(10.5, defaultAnomalySigma) || !r.outlier(13, defaultAnomalySigma) {
+		t.Error("flat window: want only values beyond the minimum deviation flagged")
+	}
+
+	m, _ := newTestMonitor(t)
+	m.SetAnomalySigma(-1)
+	m.SetAnomalySigma(3)
+	if got := m.AnomalySigma(); got != 3 {
+		t.Errorf("AnomalySigma() = %v, want 3 with the negative value ignored", got)
+	}
+}
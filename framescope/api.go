@@ -0,0 +1,105 @@
+package framescope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// apiHandler returns the HTTP API routes for m:
+//
+//	GET  /ws    — WebSocket stream of every UI refresh (see handleStream)
+//	POST /start — start a new run (see handleStart)
+//	POST /stop  — stop the active run (see handleStop)
+func (m *Monitor) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ws", m.handleStream)
+	mux.HandleFunc("POST /start", m.handleStart)
+	mux.HandleFunc("POST /stop", m.handleStop)
+	return mux
+}
+
+// apiState is the JSON body returned by the control endpoints.
+type apiState struct {
+	Running      bool    `json:"running"`
+	FrameSeconds float64 `json:"frame_seconds"`
+	FrameIndex   int     `json:"frame_index"`
+}
+
+// startRequest is the JSON body accepted by POST /start. FrameSeconds may be
+// omitted to reuse the configured frame length.
+type startRequest struct {
+	FrameSeconds *float64 `json:"frame_seconds"`
+}
+
+// handleStart starts a new monitoring run through Monitor.Start, exactly as
+// the Start button does. A malformed body or a frame length ≤ 0 is rejected
+// with 400; otherwise the new state is returned with 200.
+func (m *Monitor) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req startRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	frameSeconds := m.FrameSeconds()
+	if req.FrameSeconds != nil {
+		frameSeconds = *req.FrameSeconds
+	}
+
+	if err := m.Start(frameSeconds); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.writeAPIState(w)
+}
+
+// handleStop stops the active run through Monitor.Stop. Stopping when no run
+// is active is a successful no-op, so the endpoint is idempotent.
+func (m *Monitor) handleStop(w http.ResponseWriter, r *http.Request) {
+	if m.Running() {
+		m.Stop()
+	}
+	m.writeAPIState(w)
+}
+
+// writeAPIState responds with the monitor's current apiState.
+func (m *Monitor) writeAPIState(w http.ResponseWriter) {
+	m.mu.Lock()
+	st := apiState{
+		Running:      m.running,
+		FrameSeconds: m.frameSeconds,
+		FrameIndex:   m.frameIndex,
+	}
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// ServeAPI runs the HTTP API on addr until ctx is cancelled, then shuts the
+// server down and disconnects any WebSocket clients. It returns nil after a
+// clean shutdown.
+func (m *Monitor) ServeAPI(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           m.apiHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+		m.streams.closeAll()
+	}()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package framescope
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// postJSON sends body to path on h and returns the recorded response.
+func postJSON(h http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIStartAndStop(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	h := m.apiHandler()
+
+	rec := postJSON(h, "/start", `{"frame_seconds": 30}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /start = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var st apiState
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if !st.Running || st.FrameSeconds != 30 || st.FrameIndex != 1 {
+		t.Fatalf("state after start = %+v", st)
+	}
+
+	for i := 0; i < 2; i++ {
+		rec = postJSON(h, "/stop", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /stop #%d = %d, want 200", i+1, rec.Code)
+		}
+		if m.Running() {
+			t.Fatalf("still running after stop #%d", i+1)
+		}
+	}
+}
+
+func TestAPIStartRejectsBadInput(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	h := m.apiHandler()
+
+	for _, body := range []string{`{"frame_seconds": 0}`, `{"frame_seconds": -3}`, `{not json`} {
+		if rec := postJSON(h, "/start", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /start %s = %d, want 400", body, rec.Code)
+		}
+	}
+	if m.Running() {
+		t.Fatal("rejected start left the monitor running")
+	}
+}
+
+func TestAPIStartDefaultsToConfiguredLength(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.frameSeconds = 12
+	defer m.Stop()
+
+	if rec := postJSON(m.apiHandler(), "/start", ""); rec.Code != http.StatusOK {
+		t.Fatalf("POST /start with empty body = %d, want 200", rec.Code)
+	}
+	if got := m.FrameSeconds(); got != 12 {
+		t.Errorf("frame seconds = %v, want 12", got)
+	}
+}
+
+func TestAPIConcurrentStartsLeaveOneRun(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	h := m.apiHandler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			postJSON(h, "/start", `{"frame_seconds": 20}`)
+		}()
+	}
+	wg.Wait()
+	defer m.Stop()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running || m.runID != 8 || m.cancel == nil {
+		t.Fatalf("after concurrent starts: running=%v runID=%d", m.running, m.runID)
+	}
+}
@@ -0,0 +1,111 @@
+package framescope
+
+import (
+	"errors"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// errBatchUnavailable is returned by batchSnapshot where the batched path is
+// not built: on platforms other than macOS and in builds without cgo.
+var errBatchUnavailable = errors.New("batched process snapshots are not available on this platform")
+
+// BatchSource is a ProcessSource that reads every process in a single pass
+// through libproc instead of several gopsutil calls per process: one call
+// crosses into C and returns the CPU times, parent, owner, state, start time,
+// and executable of the whole process table. The samples have the same shape
+// as SystemSource's, with two differences: sleeping processes are never
+// reported as idle, and TimedOut is never set, since none of these reads can
+// block on the process.
+//
+// Where the batched path is unavailable it falls back to Fallback, or to
+// SystemSource{} if Fallback is nil.
+type BatchSource struct {
+	Fallback ProcessSource
+}
+
+// Snapshot reads every running process through the batched path, or through
+// the fallback source if the batched path is unavailable. Processes whose CPU
+// times cannot be read are reported in a *SkippedError alongside the samples,
+// as by SystemSource.
+func (s BatchSource) Snapshot() (map[int]ProcessSample, error) {
+	samples, err := batchSnapshot()
+	if errors.Is(err, errBatchUnavailable) {
+		fallback := s.Fallback
+		if fallback == nil {
+			fallback = SystemSource{}
+		}
+		return fallback.Snapshot()
+	}
+	return samples, err
+}
+
+// batchEntry is one process as read by the batched path, before it is turned
+// into a ProcessSample.
+type batchEntry struct {
+	PID           int
+	Err           error // why the CPU times could not be read; nil on success
+	CPUSeconds    float64
+	UserSeconds   float64
+	SystemSeconds float64
+	HasInfo       bool // PPID, UID, Status, and CreateTime were read
+	PPID          int
+	UID           int
+	Status        int // BSD process state (see bsdStatus)
+	CreateTime    int64
+	Exe           string
+}
+
+// batchSamples converts the entries of a batched read into samples keyed by
+// PID, with the same skip accounting as SystemSource.collect. The returned
+// *SkippedError is nil if every listed process was read.
+func batchSamples(entries []batchEntry) (map[int]ProcessSample, *SkippedError) {
+	samples := make(map[int]ProcessSample, len(entries))
+	skipped := &SkippedError{}
+	for _, e := range entries {
+		if e.PID < 0 {
+			continue
+		}
+		skipped.Listed++
+		if e.Err != nil {
+			skipped.Skipped++
+			if permissionDenied(e.Err) {
+				skipped.Denied++
+			}
+			if skipped.Err == nil {
+				skipped.Err = e.Err
+			}
+			continue
+		}
+		sample := ProcessSample{CPUSeconds: e.CPUSeconds, UserSeconds: e.UserSeconds, SystemSeconds: e.SystemSeconds, Exe: e.Exe}
+		if e.HasInfo {
+			sample.PPID = e.PPID
+			sample.CreateTime = e.CreateTime
+			sample.Status = bsdStatus(e.Status)
+			sample.User, sample.UID = usernames.name(int32(e.UID)), e.UID
+		}
+		samples[e.PID] = sample
+	}
+	if skipped.Skipped == 0 {
+		return samples, nil
+	}
+	return samples, skipped
+}
+
+// bsdStatus maps a BSD process state (SIDL 1, SRUN 2, SSLEEP 3, SSTOP 4,
+// SZOMB 5) to the status strings gopsutil reports. Unknown states are empty.
+func bsdStatus(state int) string {
+	switch state {
+	case 1:
+		return process.Idle
+	case 2:
+		return process.Running
+	case 3:
+		return process.Sleep
+	case 4:
+		return process.Stop
+	case 5:
+		return process.Zombie
+	}
+	return ""
+}
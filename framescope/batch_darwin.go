@@ -0,0 +1,151 @@
+//go:build darwin && cgo
+
+package framescope
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <mach/mach_time.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/proc_info.h>
+#include <sys/resource.h>
+
+// fs_proc is one process of a batched snapshot.
+typedef struct {
+	int pid;
+	int err;          // errno of the CPU time read; 0 on success
+	double cpu_seconds;
+	double user_seconds;
+	double system_seconds;
+	int has_info;     // the fields below were read
+	int ppid;
+	int uid;          // effective UID
+	int status;       // pbi_status
+	long long start_ms;
+	char *exe;        // malloc'd; NULL if unknown
+} fs_proc;
+
+// fs_snapshot reads every process in one pass. It returns a malloc'd array of
+// *count entries, to be released with fs_free, or NULL with *list_err set if
+// the processes could not be listed.
+static fs_proc *fs_snapshot(int *count, int *list_err) {
+	*count = 0;
+	*list_err = 0;
+	int n = proc_listallpids(NULL, 0);
+	if (n <= 0) {
+		*list_err = errno;
+		return NULL;
+	}
+	n += 64; // room for processes started since the count
+	pid_t *pids = calloc((size_t)n, sizeof(pid_t));
+	if (pids == NULL) {
+		*list_err = ENOMEM;
+		return NULL;
+	}
+	n = proc_listallpids(pids, n * (int)sizeof(pid_t));
+	if (n <= 0) {
+		*list_err = errno;
+		free(pids);
+		return NULL;
+	}
+	fs_proc *out = calloc((size_t)n, sizeof(fs_proc));
+	if (out == NULL) {
+		*list_err = ENOMEM;
+		free(pids);
+		return NULL;
+	}
+
+	// rusage times are in Mach absolute time units, which are nanoseconds
+	// only on Intel.
+	mach_timebase_info_data_t timebase;
+	mach_timebase_info(&timebase);
+
+	for (int i = 0; i < n; i++) {
+		fs_proc *p = &out[i];
+		p->pid = pids[i];
+
+		struct rusage_info_v2 usage;
+		if (proc_pid_rusage(p->pid, RUSAGE_INFO_V2, (rusage_info_t *)&usage) != 0) {
+			p->err = errno;
+			continue;
+		}
+		double scale = (double)timebase.numer / timebase.denom / 1e9;
+		p->user_seconds = (double)usage.ri_user_time * scale;
+		p->system_seconds = (double)usage.ri_system_time * scale;
+		p->cpu_seconds = p->user_seconds + p->system_seconds;
+
+		struct proc_bsdinfo info;
+		if (proc_pidinfo(p->pid, PROC_PIDTBSDINFO, 0, &info, sizeof(info)) == (int)sizeof(info)) {
+			p->has_info = 1;
+			p->ppid = (int)info.pbi_ppid;
+			p->uid = (int)info.pbi_uid;
+			p->status = (int)info.pbi_status;
+			p->start_ms = (long long)info.pbi_start_tvsec * 1000 + (long long)info.pbi_start_tvusec / 1000;
+		}
+
+		char path[PROC_PIDPATHINFO_MAXSIZE];
+		if (proc_pidpath(p->pid, path, sizeof(path)) > 0) {
+			p->exe = strdup(path);
+		}
+	}
+	free(pids);
+	*count = n;
+	return out;
+}
+
+static void fs_free(fs_proc *procs, int count) {
+	for (int i = 0; i < count; i++) {
+		free(procs[i].exe);
+	}
+	free(procs);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// batchSnapshot reads the whole process table through libproc in one cgo
+// call. A process whose CPU times cannot be read, usually another user's
+// without root, is counted in the *SkippedError returned alongside the
+// samples.
+func batchSnapshot() (map[int]ProcessSample, error) {
+	var count, listErr C.int
+	procs := C.fs_snapshot(&count, &listErr)
+	if procs == nil {
+		return nil, fmt.Errorf("listing processes: %w", syscall.Errno(listErr))
+	}
+	defer C.fs_free(procs, count)
+
+	raw := unsafe.Slice(procs, int(count))
+	entries := make([]batchEntry, len(raw))
+	for i, p := range raw {
+		e := batchEntry{PID: int(p.pid)}
+		if p.err != 0 {
+			e.Err = syscall.Errno(p.err)
+		}
+		e.CPUSeconds = float64(p.cpu_seconds)
+		e.UserSeconds, e.SystemSeconds = float64(p.user_seconds), float64(p.system_seconds)
+		if p.has_info != 0 {
+			e.HasInfo = true
+			e.PPID = int(p.ppid)
+			e.UID = int(p.uid)
+			e.Status = int(p.status)
+			e.CreateTime = int64(p.start_ms)
+		}
+		if p.exe != nil {
+			e.Exe = C.GoString(p.exe)
+		}
+		entries[i] = e
+	}
+
+	samples, skipped := batchSamples(entries)
+	if skipped != nil {
+		return samples, skipped
+	}
+	return samples, nil
+}
@@ -0,0 +1,9 @@
+//go:build !darwin || !cgo
+
+package framescope
+
+// batchSnapshot always fails with errBatchUnavailable: the batched path needs
+// libproc, so BatchSource uses its fallback.
+func batchSnapshot() (map[int]ProcessSample, error) {
+	return nil, errBatchUnavailable
+}
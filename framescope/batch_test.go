@@ -0,0 +1,133 @@
+package framescope
+
+import (
+	"errors"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func TestBatchSamples(t *testing.T) {
+	uid := os.Getuid()
+	entries := []batchEntry{
+		{PID: 1, CPUSeconds: 2.5, HasInfo: true, PPID: 0, UID: uid, Status: 3, CreateTime: 1000, Exe: "/sbin/launchd"},
+		{PID: 2, CPUSeconds: 1, Exe: "/bin/partial"},
+		{PID: 3, Err: syscall.EPERM},
+		{PID: 4, Err: syscall.ESRCH},
+		{PID: -1, CPUSeconds: 9},
+	}
+
+	samples, skipped := batchSamples(entries)
+	if len(samples) != 2 {
+		t.Fatalf("samples = %+v, want PIDs 1 and 2", samples)
+	}
+	want := ProcessSample{CPUSeconds: 2.5, CreateTime: 1000, Exe: "/sbin/launchd", Status: process.Sleep,
+		User: usernames.name(int32(uid)), UID: uid}
+	if got := samples[1]; got != want {
+		t.Errorf("sample 1 = %+v, want %+v", got, want)
+	}
+	if got := samples[2]; got.User != "" || got.Status != "" || got.CPUSeconds != 1 {
+		t.Errorf("sample without info = %+v, want CPU and executable only", got)
+	}
+	if skipped == nil || skipped.Listed != 4 || skipped.Skipped != 2 || skipped.Denied != 1 || !errors.Is(skipped, syscall.EPERM) {
+		t.Errorf("skipped = %+v, want 2 of 4 with 1 denied", skipped)
+	}
+
+	if _, skipped := batchSamples(entries[:2]); skipped != nil {
+		t.Errorf("skipped = %+v, want nil when every process was read", skipped)
+	}
+}
+
+func TestBatchSourceFallsBack(t *testing.T) {
+	if _, err := batchSnapshot(); !errors.Is(err, errBatchUnavailable) {
+		t.Skip("the batched path is available")
+	}
+	fallback := &fakeSource{}
+	samples, err := BatchSource{Fallback: fallback}.Snapshot()
+	if err != nil || fallback.calls != 1 || len(samples) != 2 {
+		t.Errorf("Snapshot() = %d samples, %v after %d fallback calls; want the fallback's", len(samples), err, fallback.calls)
+	}
+}
+
+// TestBatchSourceMatchesSystemSource checks the batched path against gopsutil
+// where it is built: the same processes, with the same attributes, and CPU
+// times between those of a gopsutil snapshot before and after.
+func TestBatchSourceMatchesSystemSource(t *testing.T) {
+	if _, err := batchSnapshot(); errors.Is(err, errBatchUnavailable) {
+		t.Skip(err)
+	}
+	snapshot := func(source ProcessSource) map[int]ProcessSample {
+		samples, err := source.Snapshot()
+		if err != nil && !errors.As(err, new(*SkippedError)) {
+			t.Fatal(err)
+		}
+		return samples
+	}
+	before := snapshot(SystemSource{})
+	batched := snapshot(BatchSource{})
+	after := snapshot(SystemSource{})
+
+	// Processes come and go between snapshots, but nearly all should be in
+	// both.
+	common := 0
+	for pid := range batched {
+		if _, ok := before[pid]; ok {
+			common++
+		}
+	}
+	if common < len(before)*9/10 {
+		t.Errorf("only %d of %d processes are in both snapshots", common, len(before))
+	}
+
+	pid := os.Getpid()
+	self, ok := batched[pid]
+	if !ok {
+		t.Fatalf("own process %d missing from the batched snapshot", pid)
+	}
+	// Allow for rounding between the two clocks.
+	const slack = 0.01
+	if self.CPUSeconds < before[pid].CPUSeconds-slack || self.CPUSeconds > after[pid].CPUSeconds+slack {
+		t.Errorf("CPU = %v, want between %v and %v", self.CPUSeconds, before[pid].CPUSeconds, after[pid].CPUSeconds)
+	}
+	want := before[pid]
+	if self.PPID != want.PPID || self.Exe != want.Exe || self.UID != want.UID || self.User != want.User {
+		t.Errorf("batched = %+v, gopsutil = %+v", self, want)
+	}
+	if math.Abs(float64(self.CreateTime-want.CreateTime)) > 1000 {
+		t.Errorf("create time = %d, gopsutil = %d", self.CreateTime, want.CreateTime)
+	}
+	if self.Status != process.Running && self.Status != process.Sleep {
+		t.Errorf("status = %q, want running or sleeping", self.Status)
+	}
+}
+
+// BenchmarkSnapshotSources compares a full snapshot through gopsutil with
+// one through the batched path, where it is built.
+func BenchmarkSnapshotSources(b *testing.B) {
+	sources := []struct {
+		name   string
+		source ProcessSource
+	}{
+		{"gopsutil", SystemSource{}},
+		{"batched", BatchSource{}},
+	}
+	for _, tt := range sources {
+		b.Run(tt.name, func(b *testing.B) {
+			if _, err := batchSnapshot(); tt.name == "batched" && errors.Is(err, errBatchUnavailable) {
+				b.Skip(err)
+			}
+			processes := 0
+			for b.Loop() {
+				samples, err := tt.source.Snapshot()
+				if err != nil && !errors.As(err, new(*SkippedError)) {
+					b.Fatal(err)
+				}
+				processes = len(samples)
+			}
+			b.ReportMetric(float64(processes), "processes")
+		})
+	}
+}
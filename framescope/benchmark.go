@@ -0,0 +1,95 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// BenchmarkReport summarises a RunBenchmark run: how fast source can be
+// sampled and what sampling cost this process.
+type BenchmarkReport struct {
+	Snapshots int           // snapshots taken
+	Processes int           // processes in the last snapshot
+	Elapsed   time.Duration // wall-clock time of the whole run
+	Mean      time.Duration // average latency of one snapshot
+	Median    time.Duration
+	Max       time.Duration
+	SelfCPU   float64 // CPU-seconds this process consumed during the run; 0 if unavailable
+}
+
+// PerSecond returns the snapshot rate.
+func (r BenchmarkReport) PerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Snapshots) / r.Elapsed.Seconds()
+}
+
+// String renders the report as a few lines for the terminal.
+func (r BenchmarkReport) String() string {
+	return fmt.Sprintf("%d snapshots of %d processes in %.1fs (%.1f/s)\n"+
+		"latency: avg %v, median %v, max %v\n"+
+		"self CPU: %.2f CPU-s (%.1f%% of one core)\n",
+		r.Snapshots, r.Processes, r.Elapsed.Seconds(), r.PerSecond(),
+		r.Mean.Round(time.Microsecond), r.Median.Round(time.Microsecond), r.Max.Round(time.Microsecond),
+		r.SelfCPU, framePercent(r.SelfCPU, r.Elapsed))
+}
+
+// RunBenchmark takes snapshots from source back to back for duration, or until
+// ctx is cancelled, and reports their latency and the CPU this process used
+// meanwhile. It measures the observer effect of sampling in isolation: the
+// Monitor itself snapshots only twice per second. At least one snapshot is
+// always taken; the first snapshot error other than a *SkippedError ends the
+// run and is returned.
+func RunBenchmark(ctx context.Context, source ProcessSource, duration time.Duration) (BenchmarkReport, error) {
+	var report BenchmarkReport
+	cpuBefore, cpuErr := selfCPUSeconds()
+	start := time.Now()
+
+	var latencies []time.Duration
+	for len(latencies) == 0 || (time.Since(start) < duration && ctx.Err() == nil) {
+		began := time.Now()
+		samples, err := source.Snapshot()
+		if err != nil && !errors.As(err, new(*SkippedError)) {
+			return report, err
+		}
+		latencies = append(latencies, time.Since(began))
+		report.Processes = len(samples)
+	}
+
+	report.Elapsed = time.Since(start)
+	if cpuAfter, err := selfCPUSeconds(); err == nil && cpuErr == nil {
+		report.SelfCPU = cpuAfter - cpuBefore
+	}
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.Snapshots = len(latencies)
+	report.Mean = total / time.Duration(len(latencies))
+	report.Median = latencies[len(latencies)/2]
+	report.Max = latencies[len(latencies)-1]
+	return report, nil
+}
+
+// selfCPUSeconds returns the user+system CPU-seconds this process has
+// consumed so far.
+func selfCPUSeconds() (float64, error) {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, err
+	}
+	times, err := proc.Times()
+	if err != nil {
+		return 0, err
+	}
+	return times.User + times.System, nil
+}
@@ -0,0 +1,75 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource returns the same small process table on every snapshot.
+type fakeSource struct {
+	calls int
+	err   error
+}
+
+func (s *fakeSource) Snapshot() (map[int]ProcessSample, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return map[int]ProcessSample{
+		1: {CPUSeconds: 1, Command: "/sbin/launchd"},
+		2: {CPUSeconds: 2, Command: "/bin/worker"},
+	}, nil
+}
+
+func TestRunBenchmarkReportsFields(t *testing.T) {
+	source := &fakeSource{}
+	report, err := RunBenchmark(context.Background(), source, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Snapshots != source.calls || report.Snapshots < 2 {
+		t.Errorf("snapshots = %d, source called %d times", report.Snapshots, source.calls)
+	}
+	if report.Processes != 2 {
+		t.Errorf("processes = %d, want 2", report.Processes)
+	}
+	if report.Elapsed < 20*time.Millisecond || report.PerSecond() <= 0 {
+		t.Errorf("elapsed = %v, rate = %v", report.Elapsed, report.PerSecond())
+	}
+	if report.Max < report.Median || report.Max < report.Mean || report.Max <= 0 {
+		t.Errorf("latency avg %v median %v max %v", report.Mean, report.Median, report.Max)
+	}
+	if report.SelfCPU < 0 {
+		t.Errorf("self CPU = %v", report.SelfCPU)
+	}
+	out := report.String()
+	for _, want := range []string{"snapshots of 2 processes", "latency: avg", "self CPU:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestRunBenchmarkStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source := &fakeSource{}
+	report, err := RunBenchmark(ctx, source, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Snapshots != 1 {
+		t.Errorf("snapshots = %d, want exactly one after cancellation", report.Snapshots)
+	}
+}
+
+func TestRunBenchmarkReturnsSnapshotError(t *testing.T) {
+	source := &fakeSource{err: errors.New("no access")}
+	if _, err := RunBenchmark(context.Background(), source, time.Hour); err == nil || source.calls != 1 {
+		t.Errorf("err = %v after %d calls, want the first snapshot's error", err, source.calls)
+	}
+}
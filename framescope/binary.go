@@ -0,0 +1,56 @@
+package framescope
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// encodeTablePayload converts a tab-separated table payload, as carried by
+// the UIUpdate Table and Summary fields, into the compact layout
+// BinaryTableUpdater receives, so that the UI can read each field in place
+// instead of splitting strings:
+//
+//	uint32 rows | uint32 columns | rows × columns × (uint32 length | UTF-8 bytes)
+//
+// Integers are little-endian, fields are in row order and not
+// NUL-terminated, and columns is the widest row's field count: shorter rows
+// are padded with empty fields. Empty lines are skipped, as the text path
+// skips them, so an empty payload is 8 zero bytes.
+func encodeTablePayload(payload string) []byte {
+	var rows [][]string
+	columns, size := 0, 8
+	for _, line := range strings.Split(payload, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		columns = max(columns, len(fields))
+		size += len(line) - len(fields) + 1
+		rows = append(rows, fields)
+	}
+	size += 4 * len(rows) * columns
+
+	buf := make([]byte, 0, size)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(rows)))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(columns))
+	for _, fields := range rows {
+		for i := range columns {
+			field := ""
+			if i < len(fields) {
+				field = fields[i]
+			}
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(len(field)))
+			buf = append(buf, field...)
+		}
+	}
+	return buf
+}
+
+// SetBinaryPayload makes the monitor deliver the current-frame and summary
+// tables as binary buffers (see BinaryTableUpdater) when its UISink accepts
+// them, sparing the UI from parsing large text payloads. Sinks that do not
+// keep receiving text, which is also the default. It must be called before
+// the first Start.
+func (m *Monitor) SetBinaryPayload(enabled bool) {
+	m.binaryPayload = enabled
+}
@@ -0,0 +1,120 @@
+package framescope
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// decodeTablePayload reads a buffer written by encodeTablePayload back into
+// rows of fields, as the Cocoa side does.
+func decodeTablePayload(buf []byte) ([][]string, error) {
+	next := func() (uint32, error) {
+		if len(buf) < 4 {
+			return 0, errors.New("truncated length")
+		}
+		n := binary.LittleEndian.Uint32(buf)
+		buf = buf[4:]
+		return n, nil
+	}
+	rows, err := next()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := next()
+	if err != nil {
+		return nil, err
+	}
+	var out [][]string
+	for range rows {
+		row := make([]string, columns)
+		for i := range row {
+			n, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if uint32(len(buf)) < n {
+				return nil, errors.New("truncated field")
+			}
+			row[i], buf = string(buf[:n]), buf[n:]
+		}
+		out = append(out, row)
+	}
+	if len(buf) != 0 {
+		return nil, errors.New("trailing bytes")
+	}
+	return out, nil
+}
+
+func TestEncodeTablePayloadRoundTrip(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 1, Diff: 5, Command: "/usr/bin/make"},
+		{PID: 2, Diff: 2.5, Command: "/usr/bin/old", ExecCommand: "/usr/bin/new"},
+	}
+	payload := RenderTable(rows, TableOptions{}, TabFormatter{})
+	got, err := decodeTablePayload(encodeTablePayload(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := splitPayload(payload)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded rows = %q, want %q", got, want)
+	}
+
+	got, err = decodeTablePayload(encodeTablePayload("a\tb\tc\n\nd\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a", "b", "c"}, {"d", "", ""}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ragged rows decoded as %q, want %q", got, want)
+	}
+
+	if buf := encodeTablePayload(""); len(buf) != 8 || binary.LittleEndian.Uint64(buf) != 0 {
+		t.Errorf("empty payload encoded as %v, want 8 zero bytes", buf)
+	}
+}
+
+// binarySink is a recordingSink that also accepts binary tables.
+type binarySink struct {
+	recordingSink
+	binaryMu sync.Mutex
+	tables   [][]byte
+}
+
+func (s *binarySink) UpdateResultsBinary(u UIUpdate, table, summary []byte) {
+	s.binaryMu.Lock()
+	defer s.binaryMu.Unlock()
+	s.tables = append(s.tables, table, summary)
+	s.UpdateResults(u)
+}
+
+func TestBinaryPayloadOnlyWhenEnabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sink := &binarySink{}
+	m := NewMonitor(sink)
+	m.history = []FrameRecord{{Index: 1, Duration: time.Second, Rows: []ResultRow{{PID: 7, Diff: 3, Command: "/bin/busy"}}}}
+
+	m.pushUI(0)
+	if len(sink.tables) != 0 || sink.updates[0].Table == "" {
+		t.Fatalf("text update by default: %d binary tables, table %q", len(sink.tables), sink.updates[0].Table)
+	}
+
+	m.SetBinaryPayload(true)
+	m.pushUI(0)
+	u := sink.updates[1]
+	if len(sink.tables) != 2 || u.Table != "" || u.Summary != "" {
+		t.Fatalf("binary update: %d binary tables, text table %q, summary %q", len(sink.tables), u.Table, u.Summary)
+	}
+	for i, text := range []string{sink.updates[0].Table, sink.updates[0].Summary} {
+		got, err := decodeTablePayload(sink.tables[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := splitPayload(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("binary table %d = %q, want %q", i, got, want)
+		}
+	}
+}
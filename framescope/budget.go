@@ -0,0 +1,95 @@
+package framescope
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cpuBudget is a session CPU budget: an alert fires once the processes
+// matching target have used more than seconds CPU-seconds in total across the
+// completed frames of the history.
+type cpuBudget struct {
+	target  string
+	seconds float64
+	fired   bool // the alert has been shown for this budget and run
+}
+
+// budgetMatches reports whether a summary row belongs to a budget target: a
+// PID if target is a decimal number, otherwise a case-insensitive command
+// pattern as in the pattern lists (see matchesAny).
+func budgetMatches(target string, row aggregateRow) bool {
+	if pid, err := strconv.Atoi(target); err == nil {
+		return row.PID == pid
+	}
+	return matchesAny(ResultRow{Command: row.Command}, []string{target})
+}
+
+// budgetUsage returns the CPU-seconds the processes matching target used in
+// history, summed over every matching PID, and the number of those PIDs. It
+// uses the summary's aggregation, so it agrees with the summary table.
+func budgetUsage(history []FrameRecord, target string) (total float64, pids int) {
+	for _, row := range aggregateHistory(history, AverageAllFrames, false) {
+		if budgetMatches(target, row) {
+			total += row.Total
+			pids++
+		}
+	}
+	return total, pids
+}
+
+// checkBudgetLocked returns the alert for the CPU budget if history, the
+// completed frames of the run's main frame length, has just crossed it, or
+// ok = false. The alert fires at most once per budget and run. Must be called
+// with m.mu held.
+func (m *Monitor) checkBudgetLocked(history []FrameRecord) (title, body string, ok bool) {
+	b := &m.budget
+	if b.target == "" || b.fired {
+		return "", "", false
+	}
+	used, pids := budgetUsage(history, b.target)
+	if used <= b.seconds {
+		return "", "", false
+	}
+	b.fired = true
+
+	title = "CPU budget exceeded"
+	body = fmt.Sprintf("%s has used %s of CPU this session, over its budget of %s.",
+		b.target, FormatDuration(used), FormatDuration(b.seconds))
+	if pids > 1 {
+		body = fmt.Sprintf("%s (%d processes) has used %s of CPU this session, over its budget of %s.",
+			b.target, pids, FormatDuration(used), FormatDuration(b.seconds))
+	}
+	return title, body, true
+}
+
+// SetCPUBudget sets a session CPU budget: once the processes matching target,
+// a PID or a command pattern, have used more than seconds CPU-seconds in total
+// across the completed frames, a notification is shown, once per run. Several
+// matching processes are summed. An empty target or seconds <= 0 removes the
+// budget; non-finite seconds are ignored. Setting a budget re-arms the alert,
+// which is checked whenever a frame completes. The new setting is persisted
+// to disk immediately.
+func (m *Monitor) SetCPUBudget(target string, seconds float64) {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	target = strings.TrimSpace(target)
+	if target == "" || seconds <= 0 {
+		target, seconds = "", 0
+	}
+	m.mu.Lock()
+	m.budget = cpuBudget{target: target, seconds: seconds}
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// CPUBudget returns the session CPU budget's target and CPU-seconds; the
+// target is empty if no budget is set.
+func (m *Monitor) CPUBudget() (target string, seconds float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.budget.target, m.budget.seconds
+}
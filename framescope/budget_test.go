@@ -0,0 +1,115 @@
+package framescope
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBudgetUsageSumsMatchingProcesses(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Duration: time.Second, Rows: []ResultRow{
+			{PID: 10, Diff: 2, Command: "/usr/bin/ffmpeg -i a.mov"},
+			{PID: 11, Diff: 1, Command: "/usr/bin/FFmpeg -i b.mov"},
+			{PID: 12, Diff: 5, Command: "/bin/other"},
+		}},
+		{Index: 2, Duration: time.Second, Rows: []ResultRow{
+			{PID: 10, Diff: 3, Command: "/usr/bin/ffmpeg -i a.mov"},
+		}},
+	}
+	for _, tc := range []struct {
+		target string
+		total  float64
+		pids   int
+	}{
+		{"ffmpeg", 6, 2},
+		{"10", 5, 1},
+		{"missing", 0, 0},
+	} {
+		if total, pids := budgetUsage(history, tc.target); total != tc.total || pids != tc.pids {
+			t.Errorf("budgetUsage(%q) = %g, %d; want %g, %d", tc.target, total, pids, tc.total, tc.pids)
+		}
+	}
+}
+
+// busySource reports one process that uses a CPU-second per snapshot.
+type busySource struct {
+	calls atomic.Int32
+}
+
+func (s *busySource) Snapshot() (map[int]ProcessSample, error) {
+	n := float64(s.calls.Add(1))
+	return map[int]ProcessSample{7: {CPUSeconds: n, Command: "/bin/encoder"}}, nil
+}
+
+func TestCPUBudgetAlertFiresOnce(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetCPUBudget("encoder", 1.5)
+
+	// waitForStart waits for the baseline and first update of a run, so that
+	// the frame start is read from the clock before it is advanced.
+	waitForStart := func() {
+		t.Helper()
+		calls := source.calls.Load()
+		deadline := time.Now().Add(5 * time.Second)
+		for source.calls.Load() < calls+2 {
+			if time.Now().After(deadline) {
+				m.Stop()
+				t.Fatal("run did not start")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	m.Start(5)
+	waitForStart()
+	for frames := 1; frames <= 4; frames++ {
+		clock.Advance(5 * time.Second)
+		waitForFrames(t, m, frames, 5*time.Second)
+	}
+	m.Stop()
+
+	// Every frame adds at least one CPU-second, so the budget is crossed in
+	// the second frame at the latest and stays crossed afterwards.
+	notes := rec.snapshotNotifications()
+	if len(notes) != 1 {
+		t.Fatalf("notifications = %q, want exactly one", notes)
+	}
+	if !strings.HasPrefix(notes[0], "CPU budget exceeded: encoder has used") {
+		t.Errorf("notification = %q", notes[0])
+	}
+
+	// A new run re-arms the alert.
+	m.Start(5)
+	waitForStart()
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 1, 5*time.Second)
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 2, 5*time.Second)
+	m.Stop()
+	if notes := rec.snapshotNotifications(); len(notes) != 2 {
+		t.Errorf("notifications after a second run = %q, want two", notes)
+	}
+}
+
+func TestSetCPUBudgetClearsAndPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetCPUBudget("  ffmpeg ", 600)
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if target, seconds := loaded.CPUBudget(); target != "ffmpeg" || seconds != 600 {
+		t.Errorf("loaded budget = %q, %g; want ffmpeg, 600", target, seconds)
+	}
+
+	m.SetCPUBudget("ffmpeg", 0)
+	if target, seconds := m.CPUBudget(); target != "" || seconds != 0 {
+		t.Errorf("budget after clearing = %q, %g", target, seconds)
+	}
+}
@@ -0,0 +1,82 @@
+package framescope
+
+import (
+	"math"
+	"sort"
+)
+
+// defaultChangedMinDelta is the initial threshold, in CPU-seconds, for a
+// process to count as changed in the changed-only view.
+const defaultChangedMinDelta = 0.5
+
+// hideSmallThreshold is the display threshold: with Hide <1s on, rows below
+// it are left out of the current-frame table to reduce clutter. It says
+// nothing about whether a process is doing anything; see
+// defaultActivityThreshold.
+const hideSmallThreshold = 1.0
+
+// defaultActivityThreshold is the initial activity threshold, in CPU-seconds
+// per frame: a process below it is idle noise and does not count as a process
+// that started or exited in the changed-only view, however it is displayed.
+// It is deliberately well below hideSmallThreshold, so a quiet process can be
+// active but hidden, or, with Hide <1s off, shown but inactive.
+const defaultActivityThreshold = 0.1
+
+// active reports whether a process that used cpuSeconds in a frame counts as
+// activity under threshold.
+func active(cpuSeconds, threshold float64) bool {
+	return cpuSeconds >= threshold
+}
+
+// changedRows returns the rows that moved versus previous, the rows of the
+// prior completed frame: processes whose Diff differs by at least minDelta,
+// processes absent from previous (new), and processes of previous absent from
+// rows (gone), which are returned with a Diff of 0. New and gone processes
+// are listed only if they were active (see active) under minActivity. The
+// result is sorted by the size of the change, largest first, with PID as a
+// tiebreaker.
+func changedRows(rows, previous []ResultRow, minDelta, minActivity float64) []ResultRow {
+	before := make(map[int]ResultRow, len(previous))
+	for _, row := range previous {
+		before[row.PID] = row
+	}
+
+	type change struct {
+		row   ResultRow
+		delta float64
+	}
+	var changes []change
+	seen := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		seen[row.PID] = true
+		prev, ok := before[row.PID]
+		if !ok {
+			if active(row.Diff, minActivity) {
+				changes = append(changes, change{row, row.Diff})
+			}
+			continue
+		}
+		if delta := math.Abs(row.Diff - prev.Diff); delta >= minDelta {
+			changes = append(changes, change{row, delta})
+		}
+	}
+	for _, prev := range previous {
+		if !seen[prev.PID] && active(prev.Diff, minActivity) {
+			gone := prev
+			gone.Diff = 0
+			changes = append(changes, change{gone, prev.Diff})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].delta == changes[j].delta {
+			return changes[i].row.PID < changes[j].row.PID
+		}
+		return changes[i].delta > changes[j].delta
+	})
+	out := make([]ResultRow, len(changes))
+	for i, c := range changes {
+		out[i] = c.row
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package framescope
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// twoFrames returns the rows of two consecutive frames: PID 1 holds steady,
+// PID 2 jumps, PID 3 drops slightly, PID 4 exits, and PID 5 starts.
+func twoFrames() (previous, current []ResultRow) {
+	previous = []ResultRow{
+		{PID: 2, Diff: 1, Command: "/bin/jump"},
+		{PID: 1, Diff: 5, Command: "/bin/steady"},
+		{PID: 3, Diff: 2, Command: "/bin/dip"},
+		{PID: 4, Diff: 0.8, Command: "/bin/gone"},
+	}
+	current = []ResultRow{
+		{PID: 2, Diff: 7, Command: "/bin/jump"},
+		{PID: 1, Diff: 5.2, Command: "/bin/steady"},
+		{PID: 3, Diff: 1.8, Command: "/bin/dip"},
+		{PID: 5, Diff: 0.1, Command: "/bin/new"},
+	}
+	return previous, current
+}
+
+func TestChangedRowsKeepsOnlyChanges(t *testing.T) {
+	previous, current := twoFrames()
+	got := changedRows(current, previous, 0.5, 0)
+
+	// PID 1 and PID 3 moved by less than 0.5 and are dropped; the new and
+	// gone processes count as changed by their whole usage.
+	wantPIDs := []int{2, 4, 5}
+	if len(got) != len(wantPIDs) {
+		t.Fatalf("got %+v, want PIDs %v", got, wantPIDs)
+	}
+	for i, pid := range wantPIDs {
+		if got[i].PID != pid {
+			t.Errorf("row %d PID = %d, want %d", i, got[i].PID, pid)
+		}
+	}
+	if got[0].Diff != 7 {
+		t.Errorf("PID 2 Diff = %v, want its current 7", got[0].Diff)
+	}
+	if got[1].Diff != 0 || got[1].Command != "/bin/gone" {
+		t.Errorf("gone row = %+v, want Diff 0 with its last command", got[1])
+	}
+
+	if got := changedRows(current, previous, 0.1, 0); len(got) != 5 {
+		t.Errorf("with a 0.1 threshold got %d rows, want all 5", len(got))
+	}
+}
+
+func TestRenderTableChangedOnlyIgnoresHideSmall(t *testing.T) {
+	previous, current := twoFrames()
+	opts := TableOptions{HideSmall: true, ChangedOnly: true, Previous: previous, MinChange: 0.5}
+	rows := splitPayload(RenderTable(current, opts, TabFormatter{}))
+	if len(rows) != 3 || rows[0][0] != "2" || rows[1][0] != "4" || rows[2][0] != "5" {
+		t.Errorf("table = %q, want PIDs 2, 4, 5", rows)
+	}
+}
+
+func TestChangedOnlyComparesSelectedFrameWithItsPredecessor(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	previous, current := twoFrames()
+	m.history = []FrameRecord{
+		{Index: 1, Duration: time.Second, Rows: previous},
+		{Index: 2, Duration: time.Second, Rows: current},
+	}
+	m.selectedHistoryIdx = 1
+	m.SetHideSmall(false)
+	m.SetChangedOnly(true)
+
+	updates, _ := rec.snapshotUpdates()
+	if rows := splitPayload(updates[len(updates)-1].Table); len(rows) != 3 {
+		t.Errorf("table = %q, want the three changed rows", rows)
+	}
+
+	// The first frame has no predecessor, so every process in it is new.
+	m.SelectFrame(0)
+	updates, _ = rec.snapshotUpdates()
+	if rows := splitPayload(updates[len(updates)-1].Table); len(rows) != 4 {
+		t.Errorf("table = %q, want every row of frame 1", rows)
+	}
+}
+
+func TestConfigPersistsChangedOnly(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetChangedOnly(true)
+	m.SetChangedMinDelta(2)
+	m.SetChangedMinDelta(-1)
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if !loaded.ChangedOnly() || loaded.ChangedMinDelta() != 2 {
+		t.Errorf("loaded changedOnly=%v changedMinDelta=%v, want true and 2",
+			loaded.ChangedOnly(), loaded.ChangedMinDelta())
+	}
+}
+
+func TestSubActivityProcessIsDisplayedButNotActive(t *testing.T) {
+	previous := []ResultRow{{PID: 1, Diff: 2, Command: "/bin/busy"}}
+	current := []ResultRow{
+		{PID: 1, Diff: 2, Command: "/bin/busy"},
+		{PID: 2, Diff: 0.05, Command: "/bin/blip"},
+	}
+
+	// With Hide <1s off the blip is displayed like any other row...
+	rows := splitPayload(RenderTable(current, TableOptions{}, TabFormatter{}))
+	if len(rows) != 2 || rows[1][0] != "2" {
+		t.Errorf("table = %q, want the blip shown", rows)
+	}
+
+	// ...but it is below the activity threshold, so it is not a new process.
+	opts := TableOptions{ChangedOnly: true, Previous: previous, MinChange: 0.5, MinActivity: defaultActivityThreshold}
+	if rows := splitPayload(RenderTable(current, opts, TabFormatter{})); len(rows) != 0 {
+		t.Errorf("changed-only table = %q, want no rows", rows)
+	}
+	opts.MinActivity = 0.01
+	if rows := splitPayload(RenderTable(current, opts, TabFormatter{})); len(rows) != 1 || rows[0][0] != "2" {
+		t.Errorf("changed-only table with a lower threshold = %q, want the blip", rows)
+	}
+}
+
+func TestActivityThresholdPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetActivityThreshold(0.25)
+	m.SetActivityThreshold(math.NaN())
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if got := loaded.ActivityThreshold(); got != 0.25 {
+		t.Errorf("activity threshold = %v, want 0.25", got)
+	}
+}
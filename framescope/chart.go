@@ -0,0 +1,357 @@
+package framescope
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Chart geometry, in pixels. The plot area is the image minus the margins;
+// the legend sits in the right margin.
+const (
+	chartWidth        = 960
+	chartHeight       = 540
+	chartMarginLeft   = 70
+	chartMarginRight  = 220
+	chartMarginTop    = 30
+	chartMarginBottom = 50
+	chartTopN         = 8 // number of processes plotted
+	chartTextScale    = 2 // each font pixel is drawn as a 2×2 block
+)
+
+// chartColors are the colours of one chart theme. series assigns one colour
+// per plotted series in rank order, so the heaviest process is always drawn
+// in the first colour.
+type chartColors struct {
+	background, axis, grid color.RGBA
+	series                 []color.RGBA
+}
+
+var (
+	lightChart = chartColors{
+		background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		axis:       color.RGBA{0x33, 0x33, 0x33, 0xff},
+		grid:       color.RGBA{0xe6, 0xe6, 0xe6, 0xff},
+		series: []color.RGBA{
+			{0x1f, 0x77, 0xb4, 0xff},
+			{0xff, 0x7f, 0x0e, 0xff},
+			{0x2c, 0xa0, 0x2c, 0xff},
+			{0xd6, 0x27, 0x28, 0xff},
+			{0x94, 0x67, 0xbd, 0xff},
+			{0x8c, 0x56, 0x4b, 0xff},
+			{0xe3, 0x77, 0xc2, 0xff},
+			{0x7f, 0x7f, 0x7f, 0xff},
+		},
+	}
+
+	// darkChart lightens the series colours to keep them legible on the
+	// dark background.
+	darkChart = chartColors{
+		background: color.RGBA{0x1e, 0x1e, 0x1e, 0xff},
+		axis:       color.RGBA{0xdd, 0xdd, 0xdd, 0xff},
+		grid:       color.RGBA{0x3a, 0x3a, 0x3a, 0xff},
+		series: []color.RGBA{
+			{0x4e, 0xa8, 0xe6, 0xff},
+			{0xff, 0xa5, 0x4d, 0xff},
+			{0x5c, 0xd0, 0x5c, 0xff},
+			{0xff, 0x6b, 0x6b, 0xff},
+			{0xbb, 0x94, 0xe0, 0xff},
+			{0xc4, 0x8e, 0x7f, 0xff},
+			{0xf5, 0xa3, 0xdb, 0xff},
+			{0xb0, 0xb0, 0xb0, 0xff},
+		},
+	}
+)
+
+// chartSeries is one plotted process: its per-frame CPU-seconds, aligned with
+// the history slice the chart was built from.
+type chartSeries struct {
+	PID     int
+	Command string
+	Values  []float64
+}
+
+// ExportChart renders a line chart of the heaviest processes' per-frame CPU
+// across the completed history and writes it to path as a PNG, in the colours
+// of the theme (see SetTheme). An empty or
+// single-frame history still produces a valid image. Failures are reported
+// via postError and returned; on success the status line names the file.
+func (m *Monitor) ExportChart(path string) error {
+	m.mu.Lock()
+	history := append([]FrameRecord(nil), m.history...)
+	colors := lightChart
+	if m.darkLocked() {
+		colors = darkChart
+	}
+	m.mu.Unlock()
+
+	err := writeChartPNG(path, renderChart(history, colors))
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Chart export failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Chart exported to %s.", path)
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
+
+// writeChartPNG encodes img as a PNG at path, reporting close errors so that
+// a truncated file is never treated as a success.
+func writeChartPNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// topChartSeries picks the n processes with the highest total CPU across
+// history (PID as tiebreaker) and returns their per-frame values.
+func topChartSeries(history []FrameRecord, n int) []chartSeries {
+	totals := make(map[int]float64)
+	commands := make(map[int]string)
+	for _, frame := range history {
+		for _, row := range frame.Rows {
+			totals[row.PID] += row.Diff
+			if commands[row.PID] == "" {
+				commands[row.PID] = row.Command
+			}
+		}
+	}
+
+	pids := make([]int, 0, len(totals))
+	for pid := range totals {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		if totals[pids[i]] == totals[pids[j]] {
+			return pids[i] < pids[j]
+		}
+		return totals[pids[i]] > totals[pids[j]]
+	})
+	if len(pids) > n {
+		pids = pids[:n]
+	}
+
+	series := make([]chartSeries, len(pids))
+	index := make(map[int]int, len(pids))
+	for i, pid := range pids {
+		series[i] = chartSeries{PID: pid, Command: commands[pid], Values: make([]float64, len(history))}
+		index[pid] = i
+	}
+	for f, frame := range history {
+		for _, row := range frame.Rows {
+			if i, ok := index[row.PID]; ok {
+				series[i].Values[f] = row.Diff
+			}
+		}
+	}
+	return series
+}
+
+// renderChart draws the chart image for history in colors. The y axis is
+// CPU-seconds per frame, the x axis is the frame number.
+func renderChart(history []FrameRecord, colors chartColors) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fillRect(img, img.Bounds(), colors.background)
+
+	left, top := chartMarginLeft, chartMarginTop
+	right, bottom := chartWidth-chartMarginRight, chartHeight-chartMarginBottom
+	series := topChartSeries(history, chartTopN)
+
+	yMax := 1.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			yMax = math.Max(yMax, v)
+		}
+	}
+	// Round up to a multiple of 4 so the quarter gridlines land on whole seconds.
+	yMax = math.Ceil(yMax/4) * 4
+
+	// Horizontal grid lines and y labels at quarters of the range.
+	for i := 0; i <= 4; i++ {
+		y := bottom - (bottom-top)*i/4
+		drawLine(img, left, y, right, y, colors.grid)
+		label := fmt.Sprintf("%.0fS", yMax*float64(i)/4)
+		drawText(img, left-8-textWidth(label), y-chartTextScale*5/2, label, colors.axis)
+	}
+	drawText(img, 8, top-chartTextScale*5-8, "CPU-S PER FRAME", colors.axis)
+
+	// xAt maps a history position to a pixel column. A single frame is drawn
+	// in the middle of the plot.
+	count := len(history)
+	xAt := func(i int) int {
+		if count <= 1 {
+			return (left + right) / 2
+		}
+		return left + (right-left)*i/(count-1)
+	}
+	yAt := func(v float64) int {
+		return bottom - int(float64(bottom-top)*v/yMax)
+	}
+
+	// X tick labels: at most ~10, always including the first and last frame.
+	step := 1
+	if count > 10 {
+		step = (count + 9) / 10
+	}
+	for i := 0; i < count; i += step {
+		drawXTick(img, xAt(i), bottom, history[i].Index, colors.axis)
+	}
+	if count > 1 && (count-1)%step != 0 {
+		drawXTick(img, xAt(count-1), bottom, history[count-1].Index, colors.axis)
+	}
+	drawText(img, (left+right)/2-textWidth("FRAME")/2, chartHeight-chartTextScale*5-6, "FRAME", colors.axis)
+
+	drawLine(img, left, top, left, bottom, colors.axis)
+	drawLine(img, left, bottom, right, bottom, colors.axis)
+
+	if count == 0 {
+		msg := "NO FRAMES RECORDED"
+		drawText(img, (left+right)/2-textWidth(msg)/2, (top+bottom)/2, msg, colors.axis)
+	}
+
+	for i, s := range series {
+		c := colors.series[i%len(colors.series)]
+		for f := range s.Values {
+			x, y := xAt(f), yAt(s.Values[f])
+			fillRect(img, image.Rect(x-2, y-2, x+3, y+3), c)
+			if f > 0 {
+				drawThickLine(img, xAt(f-1), yAt(s.Values[f-1]), x, y, c)
+			}
+		}
+
+		// Legend entry: colour swatch followed by "PID basename"; the margin is
+		// too narrow for full command lines.
+		ly := top + i*(chartTextScale*5+10)
+		fillRect(img, image.Rect(right+16, ly, right+28, ly+chartTextScale*5), c)
+		label := fmt.Sprintf("%d %s", s.PID, baseCommand(s.Command))
+		if len(label) > 22 {
+			label = label[:21] + "_"
+		}
+		drawText(img, right+34, ly, label, colors.axis)
+	}
+
+	return img
+}
+
+// drawXTick draws a short tick in c below the x axis at column x labelled
+// with the frame number.
+func drawXTick(img *image.RGBA, x, bottom, frameIndex int, c color.RGBA) {
+	drawLine(img, x, bottom, x, bottom+5, c)
+	label := fmt.Sprint(frameIndex)
+	drawText(img, x-textWidth(label)/2, bottom+10, label, c)
+}
+
+// fillRect paints r (clipped to the image) in c.
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a one-pixel line from (x0, y0) to (x1, y1) using
+// Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		if image.Pt(x0, y0).In(img.Bounds()) {
+			img.SetRGBA(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawThickLine draws a two-pixel-wide line for data series.
+func drawThickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	drawLine(img, x0, y0, x1, y1, c)
+	drawLine(img, x0, y0+1, x1, y1+1, c)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// chartFont is a 3×5 bitmap font covering digits, upper-case letters, and the
+// punctuation used in chart labels. Each row is a 3-bit mask, MSB on the left.
+// Lower-case input is drawn upper-case; anything else is drawn as '?'.
+var chartFont = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7}, '4': {5, 5, 7, 1, 1}, '5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7}, '7': {7, 1, 1, 1, 1}, '8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'A': {2, 5, 7, 5, 5}, 'B': {6, 5, 6, 5, 6}, 'C': {3, 4, 4, 4, 3},
+	'D': {6, 5, 5, 5, 6}, 'E': {7, 4, 6, 4, 7}, 'F': {7, 4, 6, 4, 4},
+	'G': {3, 4, 5, 5, 3}, 'H': {5, 5, 7, 5, 5}, 'I': {7, 2, 2, 2, 7},
+	'J': {1, 1, 1, 5, 2}, 'K': {5, 5, 6, 5, 5}, 'L': {4, 4, 4, 4, 7},
+	'M': {5, 7, 7, 5, 5}, 'N': {6, 5, 5, 5, 5}, 'O': {2, 5, 5, 5, 2},
+	'P': {6, 5, 6, 4, 4}, 'Q': {2, 5, 5, 6, 3}, 'R': {6, 5, 6, 5, 5},
+	'S': {3, 4, 2, 1, 6}, 'T': {7, 2, 2, 2, 2}, 'U': {5, 5, 5, 5, 7},
+	'V': {5, 5, 5, 5, 2}, 'W': {5, 5, 7, 7, 5}, 'X': {5, 5, 2, 5, 5},
+	'Y': {5, 5, 2, 2, 2}, 'Z': {7, 1, 2, 4, 7},
+	' ': {0, 0, 0, 0, 0}, '.': {0, 0, 0, 0, 2}, '-': {0, 0, 7, 0, 0},
+	'_': {0, 0, 0, 0, 7}, '/': {1, 1, 2, 4, 4}, ':': {0, 2, 0, 2, 0},
+	'%': {5, 1, 2, 4, 5}, '?': {7, 1, 2, 0, 2},
+}
+
+// textWidth returns the rendered width of s in pixels.
+func textWidth(s string) int {
+	return len([]rune(s)) * 4 * chartTextScale
+}
+
+// drawText renders s with chartFont with its top-left corner at (x, y).
+func drawText(img *image.RGBA, x, y int, s string, c color.RGBA) {
+	for _, r := range strings.ToUpper(s) {
+		glyph, ok := chartFont[r]
+		if !ok {
+			glyph = chartFont['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				px, py := x+col*chartTextScale, y+row*chartTextScale
+				fillRect(img, image.Rect(px, py, px+chartTextScale, py+chartTextScale), c)
+			}
+		}
+		x += 4 * chartTextScale
+	}
+}
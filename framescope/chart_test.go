@@ -0,0 +1,117 @@
+package framescope
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderChartHandlesSmallHistories(t *testing.T) {
+	histories := map[string][]FrameRecord{
+		"empty":  nil,
+		"single": {{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 2, Command: "/bin/a"}}}},
+		"many": {
+			{Index: 4, Rows: []ResultRow{{PID: 1, Diff: 2, Command: "/bin/a"}, {PID: 2, Diff: 0.5, Command: "/bin/b"}}},
+			{Index: 5, Rows: []ResultRow{{PID: 1, Diff: 3, Command: "/bin/a"}}},
+			{Index: 6, Rows: []ResultRow{{PID: 2, Diff: 7, Command: "/bin/b"}}},
+		},
+	}
+	for name, history := range histories {
+		img := renderChart(history, lightChart)
+		if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+			t.Errorf("%s: image size = %v, want %dx%d", name, b, chartWidth, chartHeight)
+		}
+	}
+}
+
+func TestTopChartSeriesAlignsValuesWithFrames(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 2}, {PID: 2, Diff: 1}, {PID: 3, Diff: 0.1}}},
+		{Index: 2, Rows: []ResultRow{{PID: 2, Diff: 4}}},
+	}
+
+	series := topChartSeries(history, 2)
+	if len(series) != 2 {
+		t.Fatalf("got %d series, want 2", len(series))
+	}
+	if series[0].PID != 2 || series[1].PID != 1 {
+		t.Fatalf("series order = [%d %d], want [2 1]", series[0].PID, series[1].PID)
+	}
+	if v := series[1].Values; v[0] != 2 || v[1] != 0 {
+		t.Errorf("PID 1 values = %v, want [2 0]", v)
+	}
+}
+
+func TestExportChartWritesPNG(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 2, Command: "/bin/a"}}}}
+	path := filepath.Join(t.TempDir(), "chart.png")
+
+	if err := m.ExportChart(path); err != nil {
+		t.Fatalf("ExportChart: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := png.Decode(f); err != nil {
+		t.Fatalf("exported file is not a PNG: %v", err)
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestExportChartReportsWriteError(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	path := filepath.Join(t.TempDir(), "missing", "chart.png")
+
+	if err := m.ExportChart(path); err == nil {
+		t.Fatal("ExportChart into a missing directory succeeded")
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 1 {
+		t.Errorf("got %d posted errors, want 1", len(errs))
+	}
+}
+
+func TestExportChartFollowsTheme(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 2, Command: "/bin/a"}}}}
+	background := func() color.Color {
+		path := filepath.Join(t.TempDir(), "chart.png")
+		if err := m.ExportChart(path); err != nil {
+			t.Fatalf("ExportChart: %v", err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		img, err := png.Decode(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img.At(0, 0)
+	}
+
+	tests := []struct {
+		theme      Theme
+		systemDark bool
+		want       color.RGBA
+	}{
+		{ThemeSystem, false, lightChart.background},
+		{ThemeDark, false, darkChart.background},
+		{ThemeLight, true, lightChart.background},
+		{ThemeSystem, true, darkChart.background},
+	}
+	for _, tt := range tests {
+		m.SetTheme(tt.theme)
+		m.SetSystemAppearance(tt.systemDark)
+		if got := color.RGBAModel.Convert(background()); got != tt.want {
+			t.Errorf("theme %d, system dark %v: background %v, want %v", tt.theme, tt.systemDark, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package framescope
+
+import "fmt"
+
+// keepTopRows returns the first n of rows, which are sorted by CPU
+// descending, and how many were left out; n <= 0 keeps them all.
+func keepTopRows(rows []ResultRow, n int) ([]ResultRow, int) {
+	if n <= 0 || len(rows) <= n {
+		return rows, 0
+	}
+	return rows[:n], len(rows) - n
+}
+
+// collectionNote returns the summary title suffix warning that frames of
+// history were stored with only their top rows (see SetCollectTopN), so that
+// totals and averages leave out processes outside the top of a frame. It
+// names the smallest number of rows kept by any such frame, and is empty if
+// every frame kept all its rows.
+func collectionNote(history []FrameRecord) string {
+	kept := 0
+	for _, frame := range history {
+		if frame.DroppedRows > 0 && (kept == 0 || len(frame.Rows) < kept) {
+			kept = len(frame.Rows)
+		}
+	}
+	if kept == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" · Top %d per frame only", kept)
+}
+
+// SetCollectTopN makes completed frames keep only their n busiest processes,
+// discarding the rest, to bound memory in very long sessions; 0 keeps every
+// row. The summary and other history tables then add up only the rows kept,
+// so their totals are approximate, and the summary title says so. The frame
+// in progress and each frame's utilization still count every process. It
+// applies from the next completed frame. Negative values are ignored. The new
+// setting is persisted to disk immediately.
+func (m *Monitor) SetCollectTopN(n int) {
+	if n < 0 {
+		return
+	}
+	m.mu.Lock()
+	m.collectTopN = n
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// CollectTopN returns how many rows completed frames keep; 0 means all.
+func (m *Monitor) CollectTopN() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.collectTopN
+}
@@ -0,0 +1,81 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectTopNKeepsBusiestRows(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.cores = 1
+	m.SetCollectTopN(2)
+	m.SetCollectTopN(-1)
+	if got := m.CollectTopN(); got != 2 {
+		t.Fatalf("CollectTopN() = %d, want the negative value ignored", got)
+	}
+	m.running = true
+	m.frameView = newFrameView(10)
+
+	baseline := map[int]ProcessSample{
+		1: {Command: "/bin/a"}, 2: {Command: "/bin/b"}, 3: {Command: "/bin/c"}, 4: {Command: "/bin/d"},
+	}
+	current := map[int]ProcessSample{
+		1: {CPUSeconds: 1, Command: "/bin/a"},
+		2: {CPUSeconds: 4, Command: "/bin/b"},
+		3: {CPUSeconds: 2, Command: "/bin/c"},
+		4: {CPUSeconds: 3, Command: "/bin/d"},
+	}
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: baseline, start: clock.Now()}
+	clock.Advance(10 * time.Second)
+	frame, completed := m.advanceTrack(track, current, clock.Now(), false, TiebreakPID)
+	if !completed {
+		t.Fatal("frame did not complete")
+	}
+	if len(frame.Rows) != 2 || frame.Rows[0].PID != 2 || frame.Rows[1].PID != 4 || frame.DroppedRows != 2 {
+		t.Fatalf("frame rows = %+v, dropped %d; want PIDs 2 and 4 with 2 dropped", frame.Rows, frame.DroppedRows)
+	}
+	if frame.Utilization != 100 {
+		t.Errorf("utilization = %v, want 100 from every process", frame.Utilization)
+	}
+
+	m.pushUI(0)
+	updates, _ := rec.snapshotUpdates()
+	if title := updates[len(updates)-1].SummaryTitle; !strings.HasSuffix(title, " · Top 2 per frame only") {
+		t.Errorf("summary title = %q, want the top-N warning", title)
+	}
+
+	// Lifting the limit keeps every row of later frames.
+	m.SetCollectTopN(0)
+	track.baseline = baseline
+	clock.Advance(10 * time.Second)
+	if frame, _ = m.advanceTrack(track, current, clock.Now(), false, TiebreakPID); len(frame.Rows) != 4 || frame.DroppedRows != 0 {
+		t.Errorf("frame after lifting the limit = %+v", frame)
+	}
+}
+
+func TestCollectionNote(t *testing.T) {
+	full := FrameRecord{Rows: make([]ResultRow, 8)}
+	top5 := FrameRecord{Rows: make([]ResultRow, 5), DroppedRows: 3}
+	top3 := FrameRecord{Rows: make([]ResultRow, 3), DroppedRows: 1}
+	if got := collectionNote([]FrameRecord{full, full}); got != "" {
+		t.Errorf("note without dropped rows = %q, want none", got)
+	}
+	if got := collectionNote([]FrameRecord{full, top5, top3}); got != " · Top 3 per frame only" {
+		t.Errorf("note = %q, want the smallest kept count", got)
+	}
+}
+
+func TestSetCollectTopNPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetCollectTopN(25)
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if got := reloaded.CollectTopN(); got != 25 {
+		t.Errorf("reloaded CollectTopN() = %d, want 25", got)
+	}
+}
@@ -0,0 +1,230 @@
+package framescope
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameColumnNames names every column of the current-frame table payload
+// (TabFormatter), in the default order:
+//
+//	pid       process ID
+//	cpu       CPU-seconds consumed during the frame
+//	duration  the same as HH:MM:SS
+//	share     percentage of the frame's total CPU (TableOptions.ShowShare)
+//	trend     sparkline (TableOptions.Sparks)
+//	status    zombie/stopped marker (TableOptions.ShowStatus)
+//	command   command line
+//	rate      %CPU over the last tick (TableOptions.Rates)
+//	percent   cpu as %CPU of one core over the frame (TableOptions.ShowPercent)
+//	user      CPU-seconds spent in userland (TableOptions.ShowUserSys)
+//	sys       CPU-seconds spent in the kernel (TableOptions.ShowUserSys)
+//	delta     projected change versus the last completed frame
+//	          (TableOptions.LivePrevious)
+//
+// Optional columns are empty unless requested, so a layout that lists them
+// keeps its shape whether or not they are enabled. Columns added later come
+// last, so that existing payloads keep their layout.
+var FrameColumnNames = []string{
+	"pid", "cpu", "duration", "share", "trend", "status", "command", "rate",
+	"percent", "user", "sys", "delta",
+}
+
+// SummaryColumnNames names every column of the summary table payload
+// (RenderSummaryTable), in the default order:
+//
+//	pid               process ID
+//	total             CPU-seconds across the summarized frames
+//	average           CPU-seconds per frame (see AverageMode)
+//	total_duration    total as HH:MM:SS
+//	average_duration  average as HH:MM:SS
+//	peak              highest %CPU of any one frame, e.g. "184.3% @5"
+//	command           command line
+//	average_percent   total as %CPU of one core over the averaged frames
+//	since_viewed      growth of total since the summary was last viewed
+//	                  (see MarkSummaryViewed)
+//	stddev            standard deviation of CPU-seconds per frame, over the
+//	                  averaged frames
+//	appearances       number of frames the process appeared in
+//	spiky             "spiky" if the peak is far above the average (see spiky)
+//	processes         distinct processes whose CPU the total includes (see
+//	                  aggregateRow.Instances)
+var SummaryColumnNames = []string{
+	"pid", "total", "average", "total_duration", "average_duration", "peak",
+	"command", "average_percent", "since_viewed", "stddev", "appearances",
+	"spiky", "processes",
+}
+
+// selectColumns returns the names in requested that are in known, in the
+// requested order. Names are trimmed and compared ignoring case; unknown
+// names and repeats are skipped. Returns nil, meaning the default layout, if
+// no name is left.
+func selectColumns(requested, known []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] || !containsString(known, name) {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// columnsOrDefault returns columns, or known (the default layout) if columns
+// is empty.
+func columnsOrDefault(columns, known []string) []string {
+	if len(columns) == 0 {
+		return known
+	}
+	return columns
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// frameColumn returns the value of the named FrameColumnNames column for r,
+// as written by TabFormatter. CPU-seconds are written by formatSeconds with
+// markTiny. Unknown names are empty.
+func frameColumn(r TableRecord, name string, markTiny bool) string {
+	switch name {
+	case "pid":
+		return fmt.Sprint(r.PID)
+	case "cpu":
+		return formatSeconds(r.CPU, markTiny)
+	case "percent":
+		if r.HasPercent {
+			return fmt.Sprintf("%.1f%%", r.Percent)
+		}
+	case "user":
+		if r.HasUserSys {
+			return formatSeconds(r.User, markTiny)
+		}
+	case "sys":
+		if r.HasUserSys {
+			return formatSeconds(r.System, markTiny)
+		}
+	case "duration":
+		return FormatDuration(r.CPU)
+	case "share":
+		if r.HasShare {
+			return fmt.Sprintf("%.1f%%", r.Share)
+		}
+	case "trend":
+		return r.Trend
+	case "status":
+		return r.Status
+	case "command":
+		command := sanitizeCommand(r.Command, false)
+		if r.Exec != "" {
+			command += " ⇢ " + sanitizeCommand(r.Exec, false)
+		}
+		if r.Depth > 0 {
+			command = "  ↳ " + command
+		}
+		return command
+	case "delta":
+		return r.Delta
+	case "rate":
+		if r.HasRate {
+			return fmt.Sprintf("%.1f%%", r.Rate)
+		}
+	}
+	return ""
+}
+
+// summaryColumn returns the value of the named SummaryColumnNames column for
+// row, whose command, since_viewed, and spiky columns have already been
+// rendered as command, since, and spike. CPU-seconds are written by
+// formatSeconds with markTiny. Unknown names are empty.
+func summaryColumn(row aggregateRow, command, since, spike, name string, markTiny bool) string {
+	switch name {
+	case "pid":
+		return fmt.Sprint(row.PID)
+	case "total":
+		return formatSeconds(row.Total, markTiny)
+	case "average":
+		return formatSeconds(row.Average, markTiny)
+	case "average_percent":
+		return fmt.Sprintf("%.1f%%", row.AveragePercent)
+	case "total_duration":
+		return FormatDuration(row.Total)
+	case "average_duration":
+		return FormatDuration(row.Average)
+	case "peak":
+		return formatPeak(row.PeakPercent, row.PeakFrame)
+	case "command":
+		return command
+	case "since_viewed":
+		return since
+	case "stddev":
+		return formatSeconds(row.StdDev, markTiny)
+	case "appearances":
+		return fmt.Sprint(row.Frames)
+	case "spiky":
+		return spike
+	case "processes":
+		return fmt.Sprint(row.Instances)
+	}
+	return ""
+}
+
+// writeColumns writes one tab-separated payload line of the named columns,
+// using field to render each.
+func writeColumns(b *strings.Builder, columns []string, field func(name string) string) {
+	for i, name := range columns {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+		b.WriteString(field(name))
+	}
+	b.WriteByte('\n')
+}
+
+// SetColumns sets the columns of the current-frame table payload and their
+// order, from FrameColumnNames. Names are trimmed and matched ignoring case;
+// unknown names and repeats are skipped, and a list with no known name
+// restores the default layout. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetColumns(names []string) {
+	columns := selectColumns(names, FrameColumnNames)
+	m.mu.Lock()
+	m.columns = columns
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// Columns returns the current-frame table's columns, in payload order.
+func (m *Monitor) Columns() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), columnsOrDefault(m.columns, FrameColumnNames)...)
+}
+
+// SetSummaryColumns sets the columns of the summary table payload and their
+// order, from SummaryColumnNames, as SetColumns does for the current-frame
+// table.
+func (m *Monitor) SetSummaryColumns(names []string) {
+	columns := selectColumns(names, SummaryColumnNames)
+	m.mu.Lock()
+	m.summaryColumns = columns
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SummaryColumns returns the summary table's columns, in payload order.
+func (m *Monitor) SummaryColumns() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), columnsOrDefault(m.summaryColumns, SummaryColumnNames)...)
+}
@@ -0,0 +1,124 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTabFormatterColumns(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		columns []string
+		want    []string // first row
+	}{
+		{"default", nil, []string{"7", "3.0", "00:00:03", "75.0%", "▁▇", "", "/usr/bin/make -j8, all quiet", "", "", "", "", ""}},
+		{"reordered", []string{"command", "pid", "share"}, []string{"/usr/bin/make -j8, all quiet", "7", "75.0%"}},
+		{"unknown skipped", []string{"PID", "memory", " cpu ", "pid"}, []string{"7", "3.0"}},
+		{"none known", []string{"threads"}, []string{"7", "3.0", "00:00:03", "75.0%", "▁▇", "", "/usr/bin/make -j8, all quiet", "", "", "", "", ""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := splitPayload(RenderTable(formatterRows, formatterOpts, TabFormatter{Columns: tc.columns}))
+			if len(got) != 2 {
+				t.Fatalf("got %d rows, want 2: %q", len(got), got)
+			}
+			if strings.Join(got[0], "|") != strings.Join(tc.want, "|") {
+				t.Errorf("row = %q, want %q", got[0], tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderSummaryTableColumns(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 5, Command: "/bin/worker"}}},
+	}
+	rows := splitPayload(RenderSummaryTable(history, SummaryOptions{HidePaths: true, Columns: []string{"peak", "bogus", "command", "total"}}))
+	want := []string{"50.0% @1", "worker", "5.0"}
+	if len(rows) != 1 || strings.Join(rows[0], "|") != strings.Join(want, "|") {
+		t.Errorf("rows = %q, want [%q]", rows, want)
+	}
+}
+
+func TestSecondsAndPercentSideBySide(t *testing.T) {
+	rows := []ResultRow{{PID: 7, Diff: 3, Command: "/bin/encoder"}, {PID: 8, Diff: 1.5, Command: "/bin/sh"}}
+	opts := TableOptions{ShowPercent: true, Elapsed: 10 * time.Second}
+	got := splitPayload(RenderTable(rows, opts, TabFormatter{Columns: []string{"pid", "cpu", "percent"}}))
+	if want := "7 3.0 30.0%|8 1.5 15.0%"; joinRows(got) != want {
+		t.Errorf("frame table = %q, want %q", joinRows(got), want)
+	}
+	opts.ShowPercent = false
+	if got := splitPayload(RenderTable(rows, opts, TabFormatter{})); got[0][len(got[0])-1] != "" {
+		t.Errorf("%%CPU column with ShowPercent off = %q, want empty", got[0])
+	}
+
+	// 6 CPU-seconds over 30s of frames is 20% of a core on average, or 40%
+	// over the 15s frame the process appeared in.
+	history := []FrameRecord{
+		{Index: 1, Duration: 15 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 6, Command: "/bin/worker"}}},
+		{Index: 2, Duration: 15 * time.Second},
+	}
+	columns := []string{"total", "average_percent"}
+	if got := splitPayload(RenderSummaryTable(history, SummaryOptions{Columns: columns})); joinRows(got) != "6.0 20.0%" {
+		t.Errorf("summary over all frames = %q, want 6.0 20.0%%", joinRows(got))
+	}
+	if got := splitPayload(RenderSummaryTable(history, SummaryOptions{AverageMode: AverageAppearedFrames, Columns: columns})); joinRows(got) != "6.0 40.0%" {
+		t.Errorf("summary over appeared frames = %q, want 6.0 40.0%%", joinRows(got))
+	}
+}
+
+func TestSetColumns(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetColumns([]string{"Command", "rss", "pid", "command"})
+	if got := m.Columns(); strings.Join(got, ",") != "command,pid" {
+		t.Errorf("Columns() = %q, want command,pid", got)
+	}
+	m.mu.Lock()
+	m.liveRows = []ResultRow{{PID: 3, Diff: 2, Command: "/bin/sh"}}
+	m.mu.Unlock()
+	m.pushUI(0)
+	updates, _ := rec.snapshotUpdates()
+	if len(updates) == 0 {
+		t.Fatal("no UI update")
+	}
+	if got := splitPayload(updates[len(updates)-1].Table); len(got) != 1 || strings.Join(got[0], "|") != "/bin/sh|3" {
+		t.Errorf("table = %q, want the command, then the PID", got)
+	}
+
+	// A list without a known column restores the default layout.
+	m.SetColumns([]string{"fds"})
+	if got := m.Columns(); strings.Join(got, ",") != strings.Join(FrameColumnNames, ",") {
+		t.Errorf("Columns() = %q, want the default", got)
+	}
+}
+
+func TestFormatSecondsMarksTinyValues(t *testing.T) {
+	tests := []struct {
+		seconds  float64
+		markTiny bool
+		want     string
+	}{
+		{0.04, true, "<0.1"},
+		{0.04, false, "0.0"},
+		{0, true, "0.0"},
+		{0.05, true, "0.1"},
+		{-0.04, true, "-0.0"},
+		{12.34, true, "12.3"},
+	}
+	for _, tt := range tests {
+		if got := formatSeconds(tt.seconds, tt.markTiny); got != tt.want {
+			t.Errorf("formatSeconds(%v, %v) = %q, want %q", tt.seconds, tt.markTiny, got, tt.want)
+		}
+	}
+
+	rows := []ResultRow{{PID: 1, Diff: 0.04, Command: "/bin/tiny"}, {PID: 2, Command: "/bin/idle"}}
+	frame := TabFormatter{Columns: []string{"pid", "cpu"}, MarkTiny: true}
+	if got := joinRows(splitPayload(RenderTable(rows, TableOptions{}, frame))); got != "1 <0.1|2 0.0" {
+		t.Errorf("frame table = %q", got)
+	}
+	history := []FrameRecord{{Index: 1, Duration: time.Second, Rows: rows}}
+	got := RenderSummaryTable(history, SummaryOptions{Sort: SummarySortPID, Ascending: true, Columns: []string{"pid", "total"}, MarkTiny: true})
+	if got := joinRows(splitPayload(got)); got != "1 <0.1|2 0.0" {
+		t.Errorf("summary table = %q", got)
+	}
+}
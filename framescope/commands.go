@@ -0,0 +1,128 @@
+package framescope
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// commandRefreshInterval is how long a cached command is trusted. A process
+// that execs into another binary keeps its PID and create time, so its cached
+// command is re-read this often to catch the change.
+const commandRefreshInterval = time.Minute
+
+// unknownCommand is the command given to a process whose command line and
+// name cannot be read.
+const unknownCommand = "<unknown>"
+
+// commandKey identifies one process across PID reuse.
+type commandKey struct {
+	pid        int
+	createTime int64
+}
+
+// commandCache resolves process command lines on demand. SystemSource leaves
+// ProcessSample.Command empty because reading every command line on every
+// tick is the bulk of a snapshot's syscalls, while only the displayed rows
+// need one; the monitor fills those in through the cache instead. Entries are
+// keyed by PID and create time, so a reused PID never inherits a command, and
+// are re-read after commandRefreshInterval.
+type commandCache struct {
+	mu       sync.Mutex
+	commands map[commandKey]cachedCommand
+
+	// resolve reads the command of a running process, or "" if it cannot
+	// be read, and now returns the current time. Both are set once by
+	// newCommandCache; tests substitute fakes.
+	resolve func(pid int) string
+	now     func() time.Time
+}
+
+// cachedCommand is a resolved command and when it was read. previous is the
+// command a refresh found replaced, and changedAt when that was noticed.
+type cachedCommand struct {
+	command    string
+	resolvedAt time.Time
+	previous   string
+	changedAt  time.Time
+}
+
+func newCommandCache() *commandCache {
+	return &commandCache{
+		commands: make(map[commandKey]cachedCommand),
+		resolve:  resolveCommand,
+		now:      time.Now,
+	}
+}
+
+// resolveCommand prefers the full command line and falls back to the process
+// name. It returns "" for a PID no process can have (see checkPID), such as
+// one from an imported recording.
+func resolveCommand(pid int) string {
+	if checkPID(pid) != nil {
+		return ""
+	}
+	proc := &process.Process{Pid: int32(pid)}
+	command, err := proc.Cmdline()
+	if err != nil || command == "" {
+		command, _ = proc.Name()
+	}
+	return command
+}
+
+// fill sets the Command of every row that has none. Commands are resolved,
+// or re-resolved once older than commandRefreshInterval, only for rows where
+// want(pid) is true, or for all rows when want is nil; other rows get the
+// cached command, stale or not, if there is one. A command that cannot be
+// read becomes unknownCommand and is cached like any other.
+//
+// When a refresh finds that a process exec'd into another command, rows
+// filled from then on that belong to a frame started at or before since get
+// the old command in Command and the new one in ExecCommand; later frames get
+// only the new command.
+func (c *commandCache) fill(rows []ResultRow, want func(pid int) bool, since time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for i := range rows {
+		row := &rows[i]
+		if row.Command != "" {
+			continue
+		}
+		key := commandKey{row.PID, row.CreateTime}
+		cached, ok := c.commands[key]
+		if !ok || now.Sub(cached.resolvedAt) >= commandRefreshInterval {
+			if want == nil || want(row.PID) {
+				command := c.resolve(row.PID)
+				if command == "" {
+					command = unknownCommand
+				}
+				if ok && command != cached.command {
+					cached.previous, cached.changedAt = cached.command, now
+				}
+				cached.command, cached.resolvedAt = command, now
+				c.commands[key] = cached
+				ok = true
+			}
+		}
+		if !ok {
+			continue
+		}
+		row.Command = cached.command
+		if cached.previous != "" && !cached.changedAt.Before(since) {
+			row.Command, row.ExecCommand = cached.previous, cached.command
+		}
+	}
+}
+
+// prune forgets the commands of processes that are not in live.
+func (c *commandCache) prune(live map[int]ProcessSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.commands {
+		if sample, ok := live[key.pid]; !ok || sample.CreateTime != key.createTime {
+			delete(c.commands, key)
+		}
+	}
+}
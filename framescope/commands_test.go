@@ -0,0 +1,173 @@
+package framescope
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingResolver returns a commandCache whose resolver records each call.
+func countingResolver(calls *int) *commandCache {
+	c := newCommandCache()
+	c.resolve = func(pid int) string {
+		*calls++
+		if pid == 3 {
+			return ""
+		}
+		return fmt.Sprintf("/bin/p%d", pid)
+	}
+	return c
+}
+
+func TestCommandCacheFillsWantedRowsOnly(t *testing.T) {
+	var calls int
+	c := countingResolver(&calls)
+	rows := []ResultRow{
+		{PID: 1, Diff: 5, CreateTime: 10},
+		{PID: 2, Diff: 0.1, CreateTime: 20},
+		{PID: 3, Diff: 2, CreateTime: 30},
+		{PID: 4, Diff: 1, Command: "/bin/given"},
+	}
+	c.fill(rows, func(pid int) bool { return pid != 2 }, time.Time{})
+
+	got := []string{rows[0].Command, rows[1].Command, rows[2].Command, rows[3].Command}
+	want := []string{"/bin/p1", "", "<unknown>", "/bin/given"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+	if calls != 2 {
+		t.Errorf("resolver called %d times, want 2", calls)
+	}
+
+	// A cached command is filled in even for rows that are not wanted, and
+	// is not resolved again.
+	next := []ResultRow{{PID: 1, CreateTime: 10}, {PID: 2, CreateTime: 20}}
+	c.fill(next, func(int) bool { return false }, time.Time{})
+	if next[0].Command != "/bin/p1" || next[1].Command != "" || calls != 2 {
+		t.Errorf("second fill = %+v after %d calls", next, calls)
+	}
+}
+
+func TestCommandCacheKeysOnCreateTime(t *testing.T) {
+	var calls int
+	c := countingResolver(&calls)
+	c.fill([]ResultRow{{PID: 1, CreateTime: 10}}, nil, time.Time{})
+
+	// PID 1 was reused by a process started later.
+	c.prune(map[int]ProcessSample{1: {CreateTime: 99}})
+	rows := []ResultRow{{PID: 1, CreateTime: 99}}
+	c.fill(rows, nil, time.Time{})
+	if calls != 2 || len(c.commands) != 1 {
+		t.Errorf("resolver called %d times with %d cached, want a fresh lookup replacing the old entry", calls, len(c.commands))
+	}
+}
+
+func TestCommandCacheHits(t *testing.T) {
+	var calls int
+	c := countingResolver(&calls)
+	for range 5 {
+		rows := []ResultRow{{PID: 1, CreateTime: 10}, {PID: 2, CreateTime: 20}}
+		c.fill(rows, nil, time.Time{})
+		if rows[0].Command != "/bin/p1" || rows[1].Command != "/bin/p2" {
+			t.Fatalf("rows = %+v", rows)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("resolver called %d times over 5 ticks, want once per process", calls)
+	}
+}
+
+func TestCommandCacheRefreshesAfterInterval(t *testing.T) {
+	var calls int
+	c := countingResolver(&calls)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	c.now = clock.Now
+
+	c.fill([]ResultRow{{PID: 1, CreateTime: 10}}, nil, time.Time{})
+
+	// The process exec'd into a new binary: same PID, same create time.
+	c.resolve = func(int) string { calls++; return "/bin/exec'd" }
+	clock.now = clock.now.Add(commandRefreshInterval - time.Second)
+	rows := []ResultRow{{PID: 1, CreateTime: 10}}
+	c.fill(rows, nil, time.Time{})
+	if rows[0].Command != "/bin/p1" || calls != 1 {
+		t.Errorf("before the interval: %q after %d calls, want the cached command", rows[0].Command, calls)
+	}
+
+	// Stale entries are only re-read for wanted rows.
+	clock.now = clock.now.Add(time.Second)
+	rows = []ResultRow{{PID: 1, CreateTime: 10}}
+	c.fill(rows, func(int) bool { return false }, time.Time{})
+	if rows[0].Command != "/bin/p1" || calls != 1 {
+		t.Errorf("unwanted stale row: %q after %d calls, want the stale command", rows[0].Command, calls)
+	}
+	frameStart := clock.now.Add(-5 * time.Second)
+	rows = []ResultRow{{PID: 1, CreateTime: 10}}
+	c.fill(rows, nil, frameStart)
+	if rows[0].Command != "/bin/p1" || rows[0].ExecCommand != "/bin/exec'd" || calls != 2 {
+		t.Errorf("after the interval: %+v after %d calls, want a fresh read flagged as exec", rows[0], calls)
+	}
+
+	// The next frame starts after the change and sees only the new command.
+	clock.now = clock.now.Add(time.Second)
+	rows = []ResultRow{{PID: 1, CreateTime: 10}}
+	c.fill(rows, nil, clock.now)
+	if rows[0].Command != "/bin/exec'd" || rows[0].ExecCommand != "" || calls != 2 {
+		t.Errorf("next frame: %+v after %d calls", rows[0], calls)
+	}
+}
+
+func TestDisplayedPIDsIncludesGroupMembers(t *testing.T) {
+	rows := chromeRows()
+	got := displayedPIDs(rows, TableOptions{HideSmall: true, Group: GroupAppHelpers})
+	// The Chrome group (6.5 s) is shown, so all its members need commands for
+	// the expanded view; the 0.5 s crashpad handler is hidden.
+	for _, pid := range []int{100, 101, 102, 200} {
+		if !got[pid] {
+			t.Errorf("PID %d not displayed", pid)
+		}
+	}
+	if got[103] {
+		t.Error("hidden PID 103 displayed")
+	}
+}
+
+// tickRows models one tick on a busy machine: 900 processes, of which the 60
+// busiest used at least a CPU-second.
+func tickRows() []ResultRow {
+	rows := make([]ResultRow, 900)
+	for i := range rows {
+		diff := 0.01
+		if i < 60 {
+			diff = 5
+		}
+		rows[i] = ResultRow{PID: i + 1, Diff: diff, CreateTime: int64(i)}
+	}
+	return rows
+}
+
+// BenchmarkCommandsPerTick compares how many command lines one tick reads
+// when every row is resolved (as snapshot did before) with resolving only
+// the displayed rows of a fresh cache. Steady-state ticks read none at all.
+func BenchmarkCommandsPerTick(b *testing.B) {
+	for _, lazy := range []bool{false, true} {
+		name := "eager"
+		if lazy {
+			name = "displayed"
+		}
+		b.Run(name, func(b *testing.B) {
+			var calls int
+			for b.Loop() {
+				c := countingResolver(&calls)
+				rows := tickRows()
+				var want func(int) bool
+				if lazy {
+					displayed := displayedPIDs(rows, TableOptions{HideSmall: true})
+					want = func(pid int) bool { return displayed[pid] }
+				}
+				c.fill(rows, want, time.Time{})
+			}
+			b.ReportMetric(float64(calls)/float64(b.N), "cmdlines/op")
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package framescope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// regressionThreshold is the change in average %CPU, in points of one core,
+// from which a comparison row is marked as a regression or an improvement.
+const regressionThreshold = 1.0
+
+// comparisonRow is one command of a baseline comparison. Rates are average
+// %CPU over the whole session (100 = one core busy throughout); a command
+// absent from one session has a zero rate and the matching only flag set.
+type comparisonRow struct {
+	Command      string
+	Baseline     float64
+	Current      float64
+	BaselineOnly bool
+	CurrentOnly  bool
+}
+
+// Delta is the change in average %CPU from the baseline to the current session.
+func (r comparisonRow) Delta() float64 {
+	return r.Current - r.Baseline
+}
+
+// marker is the comparison table's verdict for the row: "new" or "gone" for a
+// command seen in only one session, "▲" for a regression and "▼" for an
+// improvement of at least regressionThreshold, otherwise empty.
+func (r comparisonRow) marker() string {
+	switch {
+	case r.CurrentOnly:
+		return "new"
+	case r.BaselineOnly:
+		return "gone"
+	case r.Delta() >= regressionThreshold:
+		return "▲"
+	case r.Delta() <= -regressionThreshold:
+		return "▼"
+	}
+	return ""
+}
+
+// commandRates returns the average %CPU of each command in frames, keyed by
+// baseCommand as in the by-command table: PIDs differ from run to run, so the
+// executable is what identifies a process across sessions. The rate divides
+// the command's CPU-seconds by the summed frame durations, so sessions of
+// different lengths compare fairly. Returns nil if no frame has a duration.
+func commandRates(frames []FrameRecord) map[string]float64 {
+	var elapsed float64
+	totals := make(map[string]float64)
+	for _, frame := range frames {
+		elapsed += frame.Duration.Seconds()
+		for _, row := range frame.Rows {
+			totals[baseCommand(row.Command)] += row.Diff
+		}
+	}
+	if elapsed <= 0 {
+		return nil
+	}
+	rates := make(map[string]float64, len(totals))
+	for command, total := range totals {
+		rates[command] = total / elapsed * 100
+	}
+	return rates
+}
+
+// compareSessions matches the commands of baseline and current and returns
+// one row per command seen in either, sorted by Delta descending (the worst
+// regressions first), then command.
+func compareSessions(baseline, current []FrameRecord) []comparisonRow {
+	before := commandRates(baseline)
+	after := commandRates(current)
+
+	rows := make([]comparisonRow, 0, len(after))
+	for command, rate := range after {
+		prev, ok := before[command]
+		rows = append(rows, comparisonRow{Command: command, Baseline: prev, Current: rate, CurrentOnly: !ok})
+	}
+	for command, rate := range before {
+		if _, ok := after[command]; !ok {
+			rows = append(rows, comparisonRow{Command: command, Baseline: rate, BaselineOnly: true})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Delta() == rows[j].Delta() {
+			return rows[i].Command < rows[j].Command
+		}
+		return rows[i].Delta() > rows[j].Delta()
+	})
+	return rows
+}
+
+// renderComparisonTable compares current with the baseline session and
+// returns a tab-separated payload for the comparison table. Each line
+// contains:
+//
+//	baseline-% \t current-% \t change \t marker \t command
+//
+// change is signed, e.g. "+12.5". Rows follow compareSessions and are capped
+// at maxRows. Returns an empty string if no baseline is loaded.
+func renderComparisonTable(baseline, current []FrameRecord) string {
+	if len(baseline) == 0 {
+		return ""
+	}
+	rows := compareSessions(baseline, current)
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%.1f\t%.1f\t%+.1f\t%s\t%s\n",
+			row.Baseline, row.Current, row.Delta(), row.marker(), sanitizeCommand(row.Command, false))
+	}
+	return b.String()
+}
+
+// readSession reads the completed frames of a session exported by
+// ExportHistory. The format follows the file extension: ".json" or ".ndjson";
+// malformed NDJSON lines and in-progress frames are skipped.
+func readSession(path string) ([]FrameRecord, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ndjson":
+		frames, _, err := readNDJSONFile(path)
+		return frames, err
+	case ".json":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc exportDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var frames []FrameRecord
+		for _, in := range doc.Frames {
+			frame, err := importFrame(in)
+			if err != nil {
+				continue
+			}
+			if frame.Index == 0 {
+				frame.Index = len(frames) + 1
+			}
+			frames = append(frames, frame)
+		}
+		return frames, nil
+	default:
+		return nil, fmt.Errorf("unsupported session format %q (use .json or .ndjson)", ext)
+	}
+}
+
+// LoadBaselineSession reads a session exported by ExportHistory (".json" or
+// ".ndjson") as the baseline for the comparison table, which then shows how
+// the average CPU of each command in the summarised frames differs from it.
+// The baseline is kept across Start and Stop until another is loaded; an
+// empty path clears it. Failures are reported via postError and returned,
+// leaving any previous baseline in place; on success the status line names
+// the file.
+func (m *Monitor) LoadBaselineSession(path string) error {
+	var frames []FrameRecord
+	if path != "" {
+		var err error
+		frames, err = readSession(path)
+		if err == nil && len(frames) == 0 {
+			err = errors.New("no completed frames found")
+		}
+		if err != nil {
+			m.postError(0, fmt.Sprintf("Loading the baseline failed: %v", err))
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.baseline = frames
+	if path == "" {
+		m.status = "Baseline cleared."
+	} else {
+		m.status = fmt.Sprintf("Comparing with %d frames from %s.", len(frames), path)
+	}
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
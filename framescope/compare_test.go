@@ -0,0 +1,143 @@
+package framescope
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareSessionsMatchesByCommand(t *testing.T) {
+	// The baseline ran for 20s, the current session for 10s; PIDs differ.
+	baseline := []FrameRecord{
+		{Duration: 10 * time.Second, Rows: []ResultRow{
+			{PID: 10, Diff: 2, Command: "/usr/bin/encoder --fast"},
+			{PID: 11, Diff: 1, Command: "/bin/idle"},
+			{PID: 12, Diff: 4, Command: "/opt/old/indexer"},
+		}},
+		{Duration: 10 * time.Second, Rows: []ResultRow{
+			{PID: 13, Diff: 2, Command: "/usr/local/bin/encoder"},
+			{PID: 11, Diff: 1, Command: "/bin/idle"},
+		}},
+	}
+	current := []FrameRecord{
+		{Duration: 10 * time.Second, Rows: []ResultRow{
+			{PID: 20, Diff: 3, Command: "/usr/bin/encoder"},
+			{PID: 21, Diff: 2, Command: "/usr/bin/encoder --slow"},
+			{PID: 22, Diff: 1, Command: "/bin/idle"},
+			{PID: 23, Diff: 0.5, Command: "/bin/newcomer"},
+		}},
+	}
+
+	rows := compareSessions(baseline, current)
+	want := []struct {
+		command           string
+		baseline, current float64
+		marker            string
+	}{
+		{"encoder", 20, 50, "▲"},
+		{"newcomer", 0, 5, "new"},
+		{"idle", 10, 10, ""},
+		{"indexer", 20, 0, "gone"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %d commands", rows, len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Command != w.command || r.Baseline != w.baseline || r.Current != w.current || r.marker() != w.marker {
+			t.Errorf("row %d = %+v (marker %q), want %+v", i, r, r.marker(), w)
+		}
+	}
+	if rows[0].Delta() != 30 {
+		t.Errorf("encoder delta = %v, want 30", rows[0].Delta())
+	}
+}
+
+func TestComparisonMarkers(t *testing.T) {
+	tests := []struct {
+		row  comparisonRow
+		want string
+	}{
+		{comparisonRow{Baseline: 10, Current: 11}, "▲"},
+		{comparisonRow{Baseline: 10, Current: 10.5}, ""},
+		{comparisonRow{Baseline: 10, Current: 9}, "▼"},
+		{comparisonRow{Current: 0.1, CurrentOnly: true}, "new"},
+		{comparisonRow{Baseline: 0.1, BaselineOnly: true}, "gone"},
+	}
+	for _, tt := range tests {
+		if got := tt.row.marker(); got != tt.want {
+			t.Errorf("marker(%+v) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestRenderComparisonTable(t *testing.T) {
+	if got := renderComparisonTable(nil, []FrameRecord{{Duration: time.Second}}); got != "" {
+		t.Errorf("without a baseline = %q, want empty", got)
+	}
+	baseline := []FrameRecord{{Duration: 10 * time.Second, Rows: []ResultRow{{PID: 1, Diff: 5, Command: "/bin/a"}}}}
+	current := []FrameRecord{{Duration: 10 * time.Second, Rows: []ResultRow{{PID: 2, Diff: 2, Command: "/bin/a"}}}}
+	if got, want := renderComparisonTable(baseline, current), "50.0\t20.0\t-30.0\t▼\ta\n"; got != want {
+		t.Errorf("payload = %q, want %q", got, want)
+	}
+	// No current frames yet: every baseline command is gone.
+	if got, want := renderComparisonTable(baseline, nil), "50.0\t0.0\t-50.0\tgone\ta\n"; got != want {
+		t.Errorf("payload = %q, want %q", got, want)
+	}
+}
+
+func TestLoadBaselineSession(t *testing.T) {
+	exported, _ := newExportMonitor(t)
+	dir := t.TempDir()
+	for _, name := range []string{"base.json", "base.ndjson"} {
+		path := filepath.Join(dir, name)
+		if err := exported.ExportHistory(path); err != nil {
+			t.Fatal(err)
+		}
+
+		m, rec := newTestMonitor(t)
+		m.history = []FrameRecord{{Index: 1, Duration: 15 * time.Second, Rows: []ResultRow{{PID: 9, Diff: 6, Command: "/bin/a, b"}}}}
+		if err := m.LoadBaselineSession(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// The in-progress frame is not part of the baseline.
+		if len(m.baseline) != 1 || m.baseline[0].Rows[0].Diff != 4 {
+			t.Errorf("%s: baseline = %+v", name, m.baseline)
+		}
+		updates, _ := rec.snapshotUpdates()
+		last := updates[len(updates)-1]
+		if want := "26.7\t40.0\t+13.3\t▲\ta,\n"; last.Comparison != want {
+			t.Errorf("%s: comparison = %q, want %q", name, last.Comparison, want)
+		}
+		if !strings.Contains(last.Status, name) {
+			t.Errorf("%s: status = %q", name, last.Status)
+		}
+
+		if err := m.LoadBaselineSession(""); err != nil || m.baseline != nil {
+			t.Errorf("%s: clearing = %v, baseline %+v", name, err, m.baseline)
+		}
+	}
+}
+
+func TestLoadBaselineSessionRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"frames":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, rec := newTestMonitor(t)
+	m.baseline = []FrameRecord{{Index: 1}}
+	for _, path := range []string{empty, filepath.Join(dir, "missing.ndjson"), filepath.Join(dir, "session.zip")} {
+		if err := m.LoadBaselineSession(path); err == nil {
+			t.Errorf("LoadBaselineSession(%s) succeeded", path)
+		}
+	}
+	if len(m.baseline) != 1 {
+		t.Errorf("baseline = %+v, want the previous one kept", m.baseline)
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 3 {
+		t.Errorf("errors = %q, want one per failure", errs)
+	}
+}
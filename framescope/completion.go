@@ -0,0 +1,115 @@
+package framescope
+
+import "fmt"
+
+// completionTitle is the title of every run completion notification.
+const completionTitle = "FrameScope"
+
+// finishRun ends the run runID after its frame limit was reached, from the
+// sampling goroutine, which returns right after. It does what Shutdown does for
+// a Stop — release the run, flush the recorder, select the last frame,
+// disconnect stream clients — without waiting for the goroutine, then alerts
+// the user as SetCompletionAlert selects. It is a no-op if a newer run has
+// started.
+func (m *Monitor) finishRun(runID int64, frames int) {
+	m.mu.Lock()
+	if m.runID != runID {
+		m.mu.Unlock()
+		return
+	}
+	cancel := m.cancel
+	m.cancel = nil
+	m.stopRequest, m.runDone = nil, nil
+	m.running = false
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	var err error
+	if m.recorder != nil {
+		err = m.recorder.Flush()
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Monitoring stopped after %d frames.", frames)
+	if err != nil {
+		m.status += fmt.Sprintf(" Saving the recording failed: %v", err)
+	}
+	if len(m.history) > 0 && m.autoFollowLatestComplete {
+		m.viewingCurrent = false
+		m.selectedHistoryIdx = len(m.history) - 1
+	}
+	m.mu.Unlock()
+
+	m.pushUI(runID)
+	m.streams.closeAll()
+	m.alertCompletion(runID, false, fmt.Sprintf("Monitoring finished after %d frames.", frames))
+}
+
+// alertCompletion tells the user a run or reading has ended with a
+// notification and a request for attention, if SetCompletionAlert asks for
+// it; manual marks an end the user chose, which only CompletionAlertAlways
+// announces.
+func (m *Monitor) alertCompletion(runID int64, manual bool, body string) {
+	m.mu.Lock()
+	alert := m.completionAlert
+	m.mu.Unlock()
+	if alert == CompletionAlertOff || (manual && alert != CompletionAlertAlways) {
+		return
+	}
+	m.postNotification(runID, completionTitle, body)
+	m.postAttention(runID)
+}
+
+// SetStopAfterFrames makes a run stop by itself once it has completed n
+// frames, e.g. to measure a fixed stretch unattended; 0 runs until stopped.
+// With a second frame length, frames of the first length are counted. A
+// running run stops at its next frame boundary if it has already completed n
+// frames. Negative values are ignored. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetStopAfterFrames(n int) {
+	if n < 0 {
+		return
+	}
+	m.mu.Lock()
+	m.stopAfterFrames = n
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// StopAfterFrames returns the frame limit of a run; 0 means none.
+func (m *Monitor) StopAfterFrames() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopAfterFrames
+}
+
+// SetCompletionAlert selects when the user is told that monitoring has ended:
+// a notification plus, where the UI supports it, a bounce of the Dock icon
+// (see AttentionRequester). Runs end by themselves on reaching the frame limit
+// (see SetStopAfterFrames), and SnapshotNow readings count as well; a manual
+// Stop is only announced with CompletionAlertAlways. Unknown values are
+// ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetCompletionAlert(alert CompletionAlert) {
+	if !validCompletionAlert(alert) {
+		return
+	}
+	m.mu.Lock()
+	m.completionAlert = alert
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// CompletionAlert returns when the end of monitoring is announced.
+func (m *Monitor) CompletionAlert() CompletionAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.completionAlert
+}
+
+// validCompletionAlert reports whether a is one of the CompletionAlert
+// constants.
+func validCompletionAlert(a CompletionAlert) bool {
+	return a == CompletionAlertOff || a == CompletionAlertFinished || a == CompletionAlertAlways
+}
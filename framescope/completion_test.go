@@ -0,0 +1,101 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletionAlertFiresOnFrameLimitNotOnStop(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetCompletionAlert(CompletionAlertFinished)
+
+	// waitForStart waits for the baseline and first update of a run, so that
+	// the frame start is read from the clock before it is advanced.
+	waitForStart := func() {
+		t.Helper()
+		calls := source.calls.Load()
+		deadline := time.Now().Add(5 * time.Second)
+		for source.calls.Load() < calls+2 {
+			if time.Now().After(deadline) {
+				m.Stop()
+				t.Fatal("run did not start")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	attention := func() int {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return rec.attention
+	}
+
+	// A manual Stop is the user's own doing and is not announced.
+	m.Start(5)
+	waitForStart()
+	m.Stop()
+	if notes := rec.snapshotNotifications(); len(notes) != 0 || attention() != 0 {
+		t.Fatalf("after Stop: notifications = %q, attention = %d; want none", notes, attention())
+	}
+
+	m.SetStopAfterFrames(2)
+	m.Start(5)
+	waitForStart()
+	for frames := 1; frames <= 2; frames++ {
+		clock.Advance(5 * time.Second)
+		waitForFrames(t, m, frames, 5*time.Second)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Running() {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("run did not stop at its frame limit")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	notes := rec.snapshotNotifications()
+	if len(notes) != 1 || !strings.Contains(notes[0], "after 2 frames") {
+		t.Fatalf("notifications = %q, want one for the limit", notes)
+	}
+	if got := attention(); got != 1 {
+		t.Errorf("attention requests = %d, want 1", got)
+	}
+	if status := m.Status(); !strings.Contains(status, "stopped after 2 frames") {
+		t.Errorf("status = %q, want the frame limit noted", status)
+	}
+
+	// Stopping a run that already ended by itself adds nothing; with
+	// CompletionAlertAlways a manual Stop is announced too.
+	m.Stop()
+	m.SetStopAfterFrames(0)
+	m.SetCompletionAlert(CompletionAlertAlways)
+	m.Start(5)
+	waitForStart()
+	m.Stop()
+	if notes := rec.snapshotNotifications(); len(notes) != 2 || notes[1] != completionTitle+": Monitoring stopped." {
+		t.Errorf("notifications = %q, want the Stop announced", notes)
+	}
+}
+
+func TestSetCompletionSettingsPersist(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetStopAfterFrames(10)
+	m.SetStopAfterFrames(-1)
+	m.SetCompletionAlert(CompletionAlertAlways)
+	m.SetCompletionAlert(CompletionAlert(7))
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if got := reloaded.StopAfterFrames(); got != 10 {
+		t.Errorf("reloaded StopAfterFrames() = %d, want 10", got)
+	}
+	if got := reloaded.CompletionAlert(); got != CompletionAlertAlways {
+		t.Errorf("reloaded CompletionAlert() = %d, want %d", got, CompletionAlertAlways)
+	}
+}
@@ -0,0 +1,135 @@
+package framescope
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ComputeResults diffs two process snapshots and returns one ResultRow per
+// process that was present in both. Processes that exited between the two
+// snapshots (absent from current) are omitted.
+//
+// A PID whose CreateTime differs between the snapshots was reused: the
+// original process exited and an unrelated one started mid-frame. The two are
+// treated as distinct processes, so, like any other process that exited or
+// started during the frame, neither is reported. When either create time is
+// unknown (0) reuse cannot be detected this way; negative diffs, which such a
+// reuse can produce, are discarded as a fallback.
+//
+// A process whose command differs between the two snapshots exec'd into
+// another binary during the frame (same PID and create time). Its row keeps
+// the baseline command and records the new one in ExecCommand, since neither
+// alone describes where the frame's CPU went. Samples without a command, as
+// SystemSource produces, are never compared; the Monitor detects such changes
+// through its command cache instead.
+//
+// Diff is also split into UserDiff and SystemDiff, each floored at 0, from
+// the samples' UserSeconds and SystemSeconds.
+//
+// The returned slice is sorted by CPU consumption descending, with tiebreak
+// ordering processes that used the same CPU.
+func ComputeResults(initial, current map[int]ProcessSample, tiebreak Tiebreaker) []ResultRow {
+	rows := make([]ResultRow, 0, len(initial))
+	for pid, before := range initial {
+		after, ok := current[pid]
+		if !ok || reusedPID(before, after) {
+			continue
+		}
+
+		diff := after.CPUSeconds - before.CPUSeconds
+		if diff < 0 {
+			continue
+		}
+
+		row := ResultRow{
+			PID:        pid,
+			Diff:       diff,
+			Command:    before.Command,
+			PPID:       before.PPID,
+			CreateTime: before.CreateTime,
+			Exe:        before.Exe,
+			Status:     after.Status,
+			User:       before.User,
+			UserDiff:   max(after.UserSeconds-before.UserSeconds, 0),
+			SystemDiff: max(after.SystemSeconds-before.SystemSeconds, 0),
+		}
+		if before.Command != "" && after.Command != "" && before.Command != after.Command {
+			row.ExecCommand = after.Command
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Diff == rows[j].Diff {
+			return tiebreak.less(rows[i].tieKey(), rows[j].tieKey())
+		}
+		return rows[i].Diff > rows[j].Diff
+	})
+
+	return rows
+}
+
+// tieKey is what a Tiebreaker compares: the identity of a current-frame row
+// or of a summary row.
+type tieKey struct {
+	PID        int
+	Command    string
+	CreateTime int64
+}
+
+func (r ResultRow) tieKey() tieKey    { return tieKey{r.PID, r.Command, r.CreateTime} }
+func (r aggregateRow) tieKey() tieKey { return tieKey{r.PID, r.Command, r.CreateTime} }
+
+// less reports whether a sorts before b among rows with equal CPU. Every
+// tiebreaker falls back to PID, then, for a reused PID, to start time and
+// command, so the order is total. Unknown tiebreakers behave like TiebreakPID.
+func (t Tiebreaker) less(a, b tieKey) bool {
+	switch t {
+	case TiebreakCommand:
+		if ca, cb := strings.ToLower(a.Command), strings.ToLower(b.Command); ca != cb {
+			return ca < cb
+		}
+	case TiebreakCreateTime:
+		if a.CreateTime != b.CreateTime {
+			if a.CreateTime == 0 || b.CreateTime == 0 {
+				return b.CreateTime == 0
+			}
+			return a.CreateTime < b.CreateTime
+		}
+	}
+	if a.PID != b.PID {
+		return a.PID < b.PID
+	}
+	if a.CreateTime != b.CreateTime {
+		return a.CreateTime < b.CreateTime
+	}
+	return a.Command < b.Command
+}
+
+// tickRates returns the %CPU of one core each process used between two
+// consecutive snapshots taken interval apart, keyed by PID. Unlike a frame's
+// cumulative CPU-seconds, which only ever grow, it shows how busy a process
+// is right now. As in ComputeResults, processes missing from either snapshot
+// or whose PID was reused are omitted, and so are negative diffs. Returns nil
+// when there is no previous snapshot or interval is not positive.
+func tickRates(previous, current map[int]ProcessSample, interval time.Duration) map[int]float64 {
+	if previous == nil || interval <= 0 {
+		return nil
+	}
+	rates := make(map[int]float64, len(current))
+	for pid, after := range current {
+		before, ok := previous[pid]
+		if !ok || reusedPID(before, after) || after.CPUSeconds < before.CPUSeconds {
+			continue
+		}
+		rates[pid] = framePercent(after.CPUSeconds-before.CPUSeconds, interval)
+	}
+	return rates
+}
+
+// reusedPID reports whether two samples of the same PID belong to different
+// processes, judged by their create times. Unknown (zero) times never match.
+func reusedPID(before, after ProcessSample) bool {
+	return before.CreateTime != 0 && after.CreateTime != 0 && before.CreateTime != after.CreateTime
+}
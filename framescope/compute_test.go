@@ -0,0 +1,205 @@
+package framescope
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeResultsSortsByDiff(t *testing.T) {
+	initial := map[int]ProcessSample{
+		1: {CPUSeconds: 10, Command: "/bin/a"},
+		2: {CPUSeconds: 5, Command: "/bin/b"},
+		3: {CPUSeconds: 1, Command: "/bin/c"},
+		4: {CPUSeconds: 1, Command: "/bin/exited"},
+	}
+	current := map[int]ProcessSample{
+		1: {CPUSeconds: 11, Command: "/bin/a"},
+		2: {CPUSeconds: 8, Command: "/bin/b"},
+		3: {CPUSeconds: 2, Command: "/bin/c"},
+		5: {CPUSeconds: 9, Command: "/bin/started"},
+	}
+
+	rows := ComputeResults(initial, current, TiebreakPID)
+	want := []ResultRow{{PID: 2, Diff: 3, Command: "/bin/b"}, {PID: 1, Diff: 1, Command: "/bin/a"}, {PID: 3, Diff: 1, Command: "/bin/c"}}
+	if len(rows) != len(want) {
+		t.Fatalf("got %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+// tiedSamples returns snapshots in which every process used 2 CPU-seconds.
+// PID order, command order, and start-time order all differ; PID 6 has an
+// unknown start time and shares PID 4's command.
+func tiedSamples() (initial, current map[int]ProcessSample) {
+	initial = map[int]ProcessSample{
+		3: {CPUSeconds: 1, Command: "/bin/zsh", CreateTime: 300},
+		4: {CPUSeconds: 1, Command: "/bin/Bash", CreateTime: 500},
+		5: {CPUSeconds: 1, Command: "/bin/cat", CreateTime: 100},
+		6: {CPUSeconds: 1, Command: "/bin/bash"},
+	}
+	current = make(map[int]ProcessSample, len(initial))
+	for pid, s := range initial {
+		s.CPUSeconds += 2
+		current[pid] = s
+	}
+	return initial, current
+}
+
+func TestComputeResultsTiebreakers(t *testing.T) {
+	for _, tc := range []struct {
+		tiebreak Tiebreaker
+		want     []int
+	}{
+		{TiebreakPID, []int{3, 4, 5, 6}},
+		{TiebreakCommand, []int{4, 6, 5, 3}},
+		{TiebreakCreateTime, []int{5, 3, 4, 6}},
+	} {
+		initial, current := tiedSamples()
+		// Sort several times: map iteration order must not leak through.
+		for range 5 {
+			var got []int
+			for _, row := range ComputeResults(initial, current, tc.tiebreak) {
+				got = append(got, row.PID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("tiebreaker %d: PIDs = %v, want %v", tc.tiebreak, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestComputeResultsDetectsPIDReuse(t *testing.T) {
+	initial := map[int]ProcessSample{
+		7: {CPUSeconds: 1, Command: "/bin/old", CreateTime: 1000},
+		8: {CPUSeconds: 1, Command: "/bin/same", CreateTime: 2000},
+		9: {CPUSeconds: 1, Command: "/bin/unknown"},
+	}
+	current := map[int]ProcessSample{
+		// PID 7 was reused by a process that has already used more CPU than
+		// the original, which a plain diff would misreport as 4 CPU-seconds.
+		7: {CPUSeconds: 5, Command: "/bin/new", CreateTime: 1500},
+		8: {CPUSeconds: 3, Command: "/bin/same", CreateTime: 2000},
+		9: {CPUSeconds: 2, Command: "/bin/unknown", CreateTime: 3000},
+	}
+
+	rows := ComputeResults(initial, current, TiebreakPID)
+	got := map[int]float64{}
+	for _, row := range rows {
+		got[row.PID] = row.Diff
+	}
+	if _, ok := got[7]; ok {
+		t.Errorf("reused PID 7 reported: %+v", rows)
+	}
+	if got[8] != 2 {
+		t.Errorf("PID 8 diff = %v, want 2", got[8])
+	}
+	// An unknown create time cannot prove reuse, so the row is kept.
+	if got[9] != 1 {
+		t.Errorf("PID 9 diff = %v, want 1", got[9])
+	}
+}
+
+func TestComputeResultsFlagsExecMidFrame(t *testing.T) {
+	before := map[int]ProcessSample{
+		1: {CPUSeconds: 1, Command: "/bin/sh -c ./build.sh", CreateTime: 500},
+		2: {CPUSeconds: 1, Command: "/bin/steady", CreateTime: 600},
+		3: {CPUSeconds: 1, CreateTime: 700},
+	}
+	after := map[int]ProcessSample{
+		1: {CPUSeconds: 4, Command: "/usr/bin/clang -c main.c", CreateTime: 500},
+		2: {CPUSeconds: 2, Command: "/bin/steady", CreateTime: 600},
+		3: {CPUSeconds: 2, Command: "/bin/late", CreateTime: 700},
+	}
+
+	rows := ComputeResults(before, after, TiebreakPID)
+	byPID := make(map[int]ResultRow)
+	for _, row := range rows {
+		byPID[row.PID] = row
+	}
+	if got := byPID[1]; got.Command != "/bin/sh -c ./build.sh" || got.ExecCommand != "/usr/bin/clang -c main.c" {
+		t.Errorf("exec'd row = %+v", got)
+	}
+	if byPID[2].ExecCommand != "" || byPID[3].ExecCommand != "" {
+		t.Errorf("unchanged or unknown commands flagged: %+v, %+v", byPID[2], byPID[3])
+	}
+
+	table := splitPayload(RenderTable(rows, TableOptions{HidePaths: true}, TabFormatter{}))
+	if got, want := table[0][6], "sh ⇢ clang"; got != want {
+		t.Errorf("rendered command = %q, want %q", got, want)
+	}
+
+	m := NewMonitor(DiscardSink{})
+	start := time.Now()
+	if status := m.buildStatusLocked(15, start, start, rows); !strings.Contains(status, "| 1 exec'd mid-frame |") {
+		t.Errorf("status = %q, want the exec count", status)
+	}
+}
+
+func TestTickRates(t *testing.T) {
+	// Two consecutive mid-frame snapshots half a second apart.
+	previous := map[int]ProcessSample{
+		1: {CPUSeconds: 10, CreateTime: 100},
+		2: {CPUSeconds: 4, CreateTime: 200},
+		3: {CPUSeconds: 7, CreateTime: 300},
+		4: {CPUSeconds: 2},
+	}
+	current := map[int]ProcessSample{
+		1: {CPUSeconds: 10.25, CreateTime: 100}, // 0.25s of 0.5s
+		2: {CPUSeconds: 4.8, CreateTime: 200},   // 0.8s of 0.5s: two cores
+		3: {CPUSeconds: 1, CreateTime: 900},     // reused PID
+		4: {CPUSeconds: 1},                      // counter went backwards
+		5: {CPUSeconds: 3},                      // started since the last tick
+	}
+
+	rates := tickRates(previous, current, 500*time.Millisecond)
+	want := map[int]float64{1: 50, 2: 160}
+	if len(rates) != len(want) {
+		t.Fatalf("rates = %v, want %v", rates, want)
+	}
+	for pid, rate := range want {
+		if got := rates[pid]; got < rate-1e-9 || got > rate+1e-9 {
+			t.Errorf("rate of %d = %v, want %v", pid, got, rate)
+		}
+	}
+
+	if rates := tickRates(nil, current, time.Second); rates != nil {
+		t.Errorf("rates without a previous snapshot = %v, want nil", rates)
+	}
+	if rates := tickRates(previous, current, 0); rates != nil {
+		t.Errorf("rates over no interval = %v, want nil", rates)
+	}
+}
+
+func TestComputeResultsSplitsUserAndSystem(t *testing.T) {
+	sample := func(user, system float64) ProcessSample {
+		return ProcessSample{CPUSeconds: user + system, UserSeconds: user, SystemSeconds: system, Command: "/bin/job"}
+	}
+	initial := map[int]ProcessSample{1: sample(10, 2), 2: sample(1, 1)}
+	current := map[int]ProcessSample{1: sample(16, 2.5), 2: sample(1.5, 4)}
+
+	rows := ComputeResults(initial, current, TiebreakPID)
+	want := map[int][2]float64{1: {6, 0.5}, 2: {0.5, 3}}
+	for _, row := range rows {
+		split := want[row.PID]
+		if row.UserDiff != split[0] || row.SystemDiff != split[1] {
+			t.Errorf("PID %d: user %v, sys %v, want %v", row.PID, row.UserDiff, row.SystemDiff, split)
+		}
+		if row.UserDiff+row.SystemDiff != row.Diff {
+			t.Errorf("PID %d: user+sys = %v, want the combined %v", row.PID, row.UserDiff+row.SystemDiff, row.Diff)
+		}
+	}
+
+	columns := TabFormatter{Columns: []string{"pid", "cpu", "user", "sys"}}
+	if got := joinRows(splitPayload(RenderTable(rows, TableOptions{ShowUserSys: true}, columns))); got != "1 6.5 6.0 0.5|2 3.5 0.5 3.0" {
+		t.Errorf("split columns = %q", got)
+	}
+	if got := joinRows(splitPayload(RenderTable(rows, TableOptions{}, columns))); got != "1 6.5  |2 3.5  " {
+		t.Errorf("split columns off = %q, want them empty", got)
+	}
+}
@@ -0,0 +1,663 @@
+package framescope
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// configPath returns the absolute path to the application's JSON config file:
+//
+//	~/Library/Application Support/FrameScope/config.json
+//
+// The directory is not guaranteed to exist; callers that write must create it.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "FrameScope", "config.json"), nil
+}
+
+// appConfig is the serialised form of user preferences persisted to disk.
+type appConfig struct {
+	HideSmall    bool        `json:"hide_small"`
+	HidePaths    bool        `json:"hide_paths"`
+	FrameSeconds float64     `json:"frame_seconds"`
+	AverageMode  AverageMode `json:"average_mode"`
+	Tiebreaker   Tiebreaker  `json:"tiebreaker"`
+	Sparklines   bool        `json:"show_sparklines"`
+	Share        bool        `json:"show_share"`
+	Percent      bool        `json:"show_percent"`
+	LiveDelta    bool        `json:"show_live_delta"`
+	UserSys      bool        `json:"show_user_sys"`
+	MarkTiny     bool        `json:"mark_tiny_values"`
+	Status       bool        `json:"show_status"`
+	GroupMode    GroupMode   `json:"group_mode"`
+	ChangedOnly  bool        `json:"changed_only"`
+	KernelTask   bool        `json:"include_kernel_task"`
+	OnlyMine     bool        `json:"show_only_mine"`
+	FreezeTopN   bool        `json:"freeze_top_n"`
+
+	// SmallFilterMode selects what HideSmall measures rows against, and
+	// SmallFilterPercent is its %CPU threshold (see SetSmallFilter),
+	// omitted at the default.
+	SmallFilterMode    SmallFilterMode `json:"small_filter_mode"`
+	SmallFilterPercent float64         `json:"small_filter_percent,omitempty"`
+
+	// SpikeRatio is the peak-to-average ratio above which the summary marks
+	// a process spiky (see SetSpikeRatio), omitted at the default.
+	SpikeRatio float64 `json:"spike_ratio,omitempty"`
+
+	// AnomalySigma is the outlier threshold in standard deviations (see
+	// SetAnomalySigma), omitted at the default, and NotifyAnomalies whether
+	// outlier frames are notified.
+	AnomalySigma    float64 `json:"anomaly_sigma,omitempty"`
+	NotifyAnomalies bool    `json:"notify_anomalies"`
+
+	// Theme selects the colours of the rendered chart (see SetTheme).
+	Theme Theme `json:"theme"`
+
+	// MergeReusedPIDs merges summary rows of a PID reused for the same
+	// command (see SetMergeReusedPIDs).
+	MergeReusedPIDs bool `json:"merge_reused_pids"`
+
+	// MenuBarItem enables the menu bar indicator (see SetMenuBarItem).
+	MenuBarItem bool `json:"menu_bar_item"`
+
+	// PauseOnWindowClose pauses monitoring while the window is hidden (see
+	// SetPauseOnWindowClose).
+	PauseOnWindowClose bool `json:"pause_on_window_close"`
+
+	// ResumeMode decides what becomes of the frames in progress when a
+	// paused run resumes (see SetResumeMode).
+	ResumeMode ResumeMode `json:"resume_mode"`
+
+	// SecondFrameSeconds is the second frame length (see
+	// SetSecondFrameLength), omitted when none is set.
+	SecondFrameSeconds float64 `json:"second_frame_seconds,omitempty"`
+
+	// StartDelaySeconds is the wait before a run's baseline snapshot (see
+	// SetStartDelay), omitted when there is none.
+	StartDelaySeconds float64 `json:"start_delay_seconds,omitempty"`
+
+	// FrameWarmupSeconds is how much of each frame is discarded (see
+	// SetFrameWarmup), omitted when there is no warm-up.
+	FrameWarmupSeconds float64 `json:"frame_warmup_seconds,omitempty"`
+
+	// HistoryRetentionSeconds is how long completed frames are kept (see
+	// SetHistoryRetention), omitted when frames are kept by count alone.
+	HistoryRetentionSeconds float64 `json:"history_retention_seconds,omitempty"`
+
+	// MaxProcessAgeSeconds limits the current-frame table to recently
+	// started processes (see SetMaxProcessAge), omitted when off.
+	MaxProcessAgeSeconds float64 `json:"max_process_age_seconds,omitempty"`
+
+	// BudgetTarget and BudgetSeconds are the session CPU budget (see
+	// SetCPUBudget); both are omitted when no budget is set.
+	BudgetTarget  string  `json:"budget_target,omitempty"`
+	BudgetSeconds float64 `json:"budget_seconds,omitempty"`
+
+	// ChartExportEvery and ChartExportDir save a time-lapse chart every so
+	// many frames (see SetChartExport); both are omitted when off.
+	ChartExportEvery int    `json:"chart_export_every,omitempty"`
+	ChartExportDir   string `json:"chart_export_dir,omitempty"`
+
+	// ReplaySpeed is the playback rate of the history in frames per second
+	// (see SetReplaySpeed), omitted at the default. ReplayLoop starts
+	// playback over after the last frame (see SetReplayLoop).
+	ReplaySpeed float64 `json:"replay_speed,omitempty"`
+	ReplayLoop  bool    `json:"replay_loop"`
+
+	// StopAfterFrames is the frame limit of a run (see SetStopAfterFrames),
+	// omitted when runs go on until stopped. CompletionAlert selects when the
+	// end of a run is announced (see SetCompletionAlert).
+	StopAfterFrames int             `json:"stop_after_frames,omitempty"`
+	CompletionAlert CompletionAlert `json:"completion_alert"`
+
+	// SummarySort and SummarySortDescending order the summary table (see
+	// SetSummarySort). The direction is a pointer so that config files
+	// written before the setting existed keep the descending default.
+	SummarySort           SummarySortField `json:"summary_sort"`
+	SummarySortDescending *bool            `json:"summary_sort_descending,omitempty"`
+
+	// CollectTopN is how many rows completed frames keep (see
+	// SetCollectTopN), omitted when they keep every row.
+	CollectTopN int `json:"collect_top_n,omitempty"`
+
+	// SummaryMinFrames is the summary's minimum appearance count (see
+	// SetSummaryMinFrames). Files written before it existed read as 0,
+	// which, like 1, filters nothing.
+	SummaryMinFrames int `json:"summary_min_frames,omitempty"`
+
+	// SummaryMinTotal, ChangedMinDelta, and ActivityThreshold are pointers
+	// so that config files written before the settings existed keep the
+	// defaults rather than reading as 0.
+	SummaryMinTotal   *float64 `json:"summary_min_total,omitempty"`
+	ChangedMinDelta   *float64 `json:"changed_min_delta,omitempty"`
+	ActivityThreshold *float64 `json:"activity_threshold,omitempty"`
+
+	// The pattern lists (see PatternList). Watches are stored as command
+	// patterns, not PIDs, which would name other processes after a restart.
+	Exclude []string `json:"exclude,omitempty"`
+	Include []string `json:"include,omitempty"`
+	Watch   []string `json:"watch,omitempty"`
+	Noise   []string `json:"noise,omitempty"`
+
+	// NoiseSelf leaves FrameScope itself out of the frame totals along with
+	// Noise (see SetNoiseIncludesSelf).
+	NoiseSelf bool `json:"noise_self"`
+
+	// Aliases maps command patterns to the labels shown for matching
+	// processes (see SetAlias). Aliases by PID last a session and are not
+	// stored.
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Columns and SummaryColumns are the table layouts (see SetColumns),
+	// omitted for the default.
+	Columns        []string `json:"columns,omitempty"`
+	SummaryColumns []string `json:"summary_columns,omitempty"`
+}
+
+// LoadConfig loads persisted settings from disk and applies them to the
+// monitor before the UI starts, and makes later preference changes persist.
+// Monitors that never call it leave the settings file untouched. Errors are
+// silently ignored — missing or malformed config files are treated as "use
+// defaults".
+func (m *Monitor) LoadConfig() {
+	m.mu.Lock()
+	m.persistConfig = true
+	m.mu.Unlock()
+
+	cfg, _, err := readConfigFile()
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.applyConfigLocked(cfg)
+	m.mu.Unlock()
+}
+
+// readConfigFile reads and decodes the settings file, returning its path.
+// Unknown fields are ignored and values are not validated: applyConfigLocked
+// skips the invalid ones.
+func readConfigFile() (appConfig, string, error) {
+	path, err := configPath()
+	if err != nil {
+		return appConfig{}, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return appConfig{}, path, err
+	}
+	var cfg appConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return appConfig{}, path, err
+	}
+	return cfg, path, nil
+}
+
+// ReloadConfig re-reads the settings file and applies it as LoadConfig does,
+// for a file edited while FrameScope is open. An active run goes on with its
+// frames and history; a new frame length applies from the next Start. It
+// reports whether any setting changed, so that a UI can refresh its controls.
+// A file that cannot be read or parsed changes nothing; the failure is
+// reported via postError and returned. On success the status line says
+// whether the file changed anything, and the UI is refreshed.
+func (m *Monitor) ReloadConfig() (bool, error) {
+	cfg, path, err := readConfigFile()
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Settings reload failed: %v", err))
+		return false, err
+	}
+
+	m.mu.Lock()
+	before := m.configLocked()
+	m.status = ""
+	m.applyConfigLocked(cfg)
+	changed := !reflect.DeepEqual(before, m.configLocked())
+	// A note from applyConfigLocked, such as a clamped frame length, wins.
+	switch {
+	case m.status != "":
+	case changed:
+		m.status = fmt.Sprintf("Settings reloaded from %s.", path)
+	default:
+		m.status = fmt.Sprintf("Settings in %s unchanged.", path)
+	}
+	m.mu.Unlock()
+	m.pushUI(0)
+	return changed, nil
+}
+
+// ConfigJSON returns every persisted preference as the JSON object written to
+// the config file, so a settings UI can read them in one call.
+func (m *Monitor) ConfigJSON() (string, error) {
+	m.mu.Lock()
+	cfg := m.configLocked()
+	m.mu.Unlock()
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SetConfigJSON replaces every persisted preference with those in data, a JSON
+// object in the form ConfigJSON returns. Unlike LoadConfig, which skips bad
+// values in a config file, it applies nothing if data is malformed, has unknown
+// fields, or holds an invalid value; the error is also shown in the status bar.
+// Fields left out read as false, empty, or, for the thresholds and the summary
+// sort direction, unchanged. A frame length out of range is clamped rather than
+// rejected. On success the settings are persisted and the UI refreshed; a new
+// frame length takes effect at the next Start.
+func (m *Monitor) SetConfigJSON(data string) error {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Invalid settings: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.applyConfigLocked(cfg)
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+	return nil
+}
+
+// ExportConfig writes every persisted preference to path as the JSON object
+// ConfigJSON returns, for sharing settings between machines; unlike the
+// automatically managed config file, path is wherever the user chose.
+// Failures are reported via postError and returned; on success the status
+// line names the file.
+func (m *Monitor) ExportConfig(path string) error {
+	data, err := m.ConfigJSON()
+	if err == nil {
+		err = os.WriteFile(path, []byte(data+"\n"), 0644)
+	}
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Settings export failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Settings exported to %s.", path)
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
+
+// ImportConfig replaces every persisted preference with those in the file at
+// path, as written by ExportConfig, validating it as SetConfigJSON does: a
+// file that cannot be read, is malformed, or holds an invalid value changes
+// nothing. Failures are reported via postError and returned. On success the
+// settings are persisted, the status line names the file unless a frame
+// length had to be clamped, and the UI is refreshed.
+func (m *Monitor) ImportConfig(path string) error {
+	data, err := os.ReadFile(path)
+	var cfg appConfig
+	if err == nil {
+		cfg, err = parseConfig(string(data))
+	}
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Settings import failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Settings imported from %s.", path)
+	m.applyConfigLocked(cfg)
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+	return nil
+}
+
+// parseConfig decodes a single JSON object into an appConfig and validates it.
+func parseConfig(data string) (appConfig, error) {
+	var cfg appConfig
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return appConfig{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return appConfig{}, errors.New("unexpected data after the settings object")
+	}
+	return cfg, cfg.validate()
+}
+
+// validate reports the first value in cfg that the matching setter would
+// ignore.
+func (cfg appConfig) validate() error {
+	if !(cfg.FrameSeconds > 0) || math.IsInf(cfg.FrameSeconds, 0) {
+		return fmt.Errorf("frame_seconds must be greater than zero, got %v", cfg.FrameSeconds)
+	}
+	if cfg.AverageMode != AverageAllFrames && cfg.AverageMode != AverageAppearedFrames {
+		return fmt.Errorf("unknown average_mode %d", cfg.AverageMode)
+	}
+	if !validTiebreaker(cfg.Tiebreaker) {
+		return fmt.Errorf("unknown tiebreaker %d", cfg.Tiebreaker)
+	}
+	if !validTheme(cfg.Theme) {
+		return fmt.Errorf("unknown theme %d", cfg.Theme)
+	}
+	if !validSummarySortField(cfg.SummarySort) {
+		return fmt.Errorf("unknown summary_sort %d", cfg.SummarySort)
+	}
+	if cfg.GroupMode != GroupNone && cfg.GroupMode != GroupAppHelpers && cfg.GroupMode != GroupAppHelpersExpanded {
+		return fmt.Errorf("unknown group_mode %d", cfg.GroupMode)
+	}
+	if !validSmallFilterMode(cfg.SmallFilterMode) {
+		return fmt.Errorf("unknown small_filter_mode %d", cfg.SmallFilterMode)
+	}
+	if !validResumeMode(cfg.ResumeMode) {
+		return fmt.Errorf("unknown resume_mode %d", cfg.ResumeMode)
+	}
+	if cfg.SmallFilterPercent < 0 || math.IsInf(cfg.SmallFilterPercent, 0) {
+		return fmt.Errorf("small_filter_percent must not be negative, got %v", cfg.SmallFilterPercent)
+	}
+	if cfg.SpikeRatio != 0 && !validSpikeRatio(cfg.SpikeRatio) {
+		return fmt.Errorf("spike_ratio must be greater than 1, got %v", cfg.SpikeRatio)
+	}
+	if cfg.AnomalySigma < 0 || math.IsInf(cfg.AnomalySigma, 0) {
+		return fmt.Errorf("anomaly_sigma must not be negative, got %v", cfg.AnomalySigma)
+	}
+	if cfg.SecondFrameSeconds < 0 || math.IsInf(cfg.SecondFrameSeconds, 0) {
+		return fmt.Errorf("second_frame_seconds must not be negative, got %v", cfg.SecondFrameSeconds)
+	}
+	if cfg.StartDelaySeconds < 0 || math.IsInf(cfg.StartDelaySeconds, 0) {
+		return fmt.Errorf("start_delay_seconds must not be negative, got %v", cfg.StartDelaySeconds)
+	}
+	if cfg.FrameWarmupSeconds < 0 || math.IsInf(cfg.FrameWarmupSeconds, 0) {
+		return fmt.Errorf("frame_warmup_seconds must not be negative, got %v", cfg.FrameWarmupSeconds)
+	}
+	if cfg.HistoryRetentionSeconds < 0 || math.IsInf(cfg.HistoryRetentionSeconds, 0) {
+		return fmt.Errorf("history_retention_seconds must not be negative, got %v", cfg.HistoryRetentionSeconds)
+	}
+	if cfg.MaxProcessAgeSeconds < 0 || math.IsInf(cfg.MaxProcessAgeSeconds, 0) {
+		return fmt.Errorf("max_process_age_seconds must not be negative, got %v", cfg.MaxProcessAgeSeconds)
+	}
+	if cfg.BudgetSeconds < 0 || math.IsInf(cfg.BudgetSeconds, 0) {
+		return fmt.Errorf("budget_seconds must not be negative, got %v", cfg.BudgetSeconds)
+	}
+	if cfg.ChartExportEvery < 0 {
+		return fmt.Errorf("chart_export_every must not be negative, got %d", cfg.ChartExportEvery)
+	}
+	if cfg.ReplaySpeed < 0 || math.IsInf(cfg.ReplaySpeed, 0) {
+		return fmt.Errorf("replay_speed must not be negative, got %v", cfg.ReplaySpeed)
+	}
+	if cfg.StopAfterFrames < 0 {
+		return fmt.Errorf("stop_after_frames must not be negative, got %d", cfg.StopAfterFrames)
+	}
+	if !validCompletionAlert(cfg.CompletionAlert) {
+		return fmt.Errorf("unknown completion_alert %d", cfg.CompletionAlert)
+	}
+	if cfg.CollectTopN < 0 {
+		return fmt.Errorf("collect_top_n must not be negative, got %d", cfg.CollectTopN)
+	}
+	if cfg.SummaryMinFrames < 0 {
+		return fmt.Errorf("summary_min_frames must not be negative, got %d", cfg.SummaryMinFrames)
+	}
+	for pattern, label := range cfg.Aliases {
+		if _, isPID := aliasPID(strings.TrimSpace(pattern)); isPID {
+			return fmt.Errorf("aliases cannot be stored by PID, got %q", pattern)
+		}
+		if strings.TrimSpace(pattern) == "" || strings.TrimSpace(label) == "" {
+			return fmt.Errorf("aliases need a pattern and a label, got %q: %q", pattern, label)
+		}
+	}
+	for _, threshold := range []struct {
+		name  string
+		value *float64
+	}{
+		{"summary_min_total", cfg.SummaryMinTotal},
+		{"changed_min_delta", cfg.ChangedMinDelta},
+		{"activity_threshold", cfg.ActivityThreshold},
+	} {
+		if v := threshold.value; v != nil && (*v < 0 || math.IsInf(*v, 0)) {
+			return fmt.Errorf("%s must not be negative, got %v", threshold.name, *v)
+		}
+	}
+	return nil
+}
+
+// applyConfigLocked copies the settings in cfg to the monitor, keeping the
+// current value wherever cfg's is invalid. A frame length outside the
+// supported range is clamped, and the status line says so. m.mu must be held.
+func (m *Monitor) applyConfigLocked(cfg appConfig) {
+	m.hideSmall = cfg.HideSmall
+	m.hidePaths = cfg.HidePaths
+	m.showSparklines = cfg.Sparklines
+	m.showShare = cfg.Share
+	m.showPercent = cfg.Percent
+	m.showLiveDelta = cfg.LiveDelta
+	m.showUserSys = cfg.UserSys
+	m.markTiny = cfg.MarkTiny
+	m.showStatus = cfg.Status
+	m.includeKernelTask = cfg.KernelTask
+	m.showOnlyMine = cfg.OnlyMine
+	m.freezeTopN = cfg.FreezeTopN
+	m.mergeReusedPIDs = cfg.MergeReusedPIDs
+	m.menuBarItem = cfg.MenuBarItem
+	m.pauseOnWindowClose = cfg.PauseOnWindowClose
+	if validResumeMode(cfg.ResumeMode) {
+		m.resumeMode = cfg.ResumeMode
+	}
+	m.loopReplay = cfg.ReplayLoop
+	if cfg.ReplaySpeed >= 0 && !math.IsInf(cfg.ReplaySpeed, 0) {
+		m.replaySpeed = min(cfg.ReplaySpeed, maxReplaySpeed)
+	}
+	m.wakeRunLocked()
+	budget := cpuBudget{target: strings.TrimSpace(cfg.BudgetTarget), seconds: cfg.BudgetSeconds}
+	if budget.target == "" || !(budget.seconds > 0) {
+		budget = cpuBudget{}
+	}
+	if budget.target != m.budget.target || budget.seconds != m.budget.seconds {
+		m.budget = budget
+	}
+	m.changedOnly = cfg.ChangedOnly
+	m.secondFrameSeconds = 0
+	if cfg.SecondFrameSeconds > 0 {
+		m.secondFrameSeconds, _ = clampFrameSeconds(cfg.SecondFrameSeconds)
+	}
+	if cfg.StartDelaySeconds >= 0 && !math.IsInf(cfg.StartDelaySeconds, 0) {
+		m.startDelaySeconds = cfg.StartDelaySeconds
+	}
+	if cfg.FrameWarmupSeconds >= 0 && !math.IsInf(cfg.FrameWarmupSeconds, 0) {
+		m.frameWarmup = time.Duration(cfg.FrameWarmupSeconds * float64(time.Second))
+	}
+	if cfg.HistoryRetentionSeconds >= 0 && !math.IsInf(cfg.HistoryRetentionSeconds, 0) {
+		m.historyRetention = time.Duration(cfg.HistoryRetentionSeconds * float64(time.Second))
+	}
+	if cfg.MaxProcessAgeSeconds >= 0 && !math.IsInf(cfg.MaxProcessAgeSeconds, 0) {
+		m.showOnlyStartedWithin = time.Duration(cfg.MaxProcessAgeSeconds * float64(time.Second))
+	}
+	if seconds, note := ValidateFrameSeconds(cfg.FrameSeconds); seconds > 0 {
+		m.frameSeconds = seconds
+		if note != "" {
+			m.status = note
+		}
+	}
+	if cfg.SummaryMinTotal != nil && *cfg.SummaryMinTotal >= 0 {
+		m.summaryMinTotal = *cfg.SummaryMinTotal
+	}
+	if cfg.ChartExportEvery >= 0 {
+		m.chartExportEvery, m.chartExportDir = cfg.ChartExportEvery, strings.TrimSpace(cfg.ChartExportDir)
+	}
+	if cfg.StopAfterFrames >= 0 {
+		m.stopAfterFrames = cfg.StopAfterFrames
+	}
+	if validCompletionAlert(cfg.CompletionAlert) {
+		m.completionAlert = cfg.CompletionAlert
+	}
+	if cfg.CollectTopN >= 0 {
+		m.collectTopN = cfg.CollectTopN
+	}
+	if cfg.SummaryMinFrames >= 0 {
+		m.summaryMinFrames = max(cfg.SummaryMinFrames, 1)
+	}
+	if cfg.ChangedMinDelta != nil && *cfg.ChangedMinDelta >= 0 {
+		m.changedMinDelta = *cfg.ChangedMinDelta
+	}
+	if cfg.ActivityThreshold != nil && *cfg.ActivityThreshold >= 0 {
+		m.activityThreshold = *cfg.ActivityThreshold
+	}
+	m.patterns[ExcludeList] = normalizePatterns(cfg.Exclude)
+	m.patterns[IncludeList] = normalizePatterns(cfg.Include)
+	m.patterns[WatchList] = normalizePatterns(cfg.Watch)
+	m.patterns[NoiseList] = normalizePatterns(cfg.Noise)
+	m.noiseIncludesSelf = cfg.NoiseSelf
+	m.aliases.byPattern = normalizeAliases(cfg.Aliases)
+	m.columns = selectColumns(cfg.Columns, FrameColumnNames)
+	m.summaryColumns = selectColumns(cfg.SummaryColumns, SummaryColumnNames)
+	if cfg.GroupMode == GroupNone || cfg.GroupMode == GroupAppHelpers || cfg.GroupMode == GroupAppHelpersExpanded {
+		m.groupMode = cfg.GroupMode
+	}
+	if cfg.AverageMode == AverageAllFrames || cfg.AverageMode == AverageAppearedFrames {
+		m.averageMode = cfg.AverageMode
+	}
+	if validTiebreaker(cfg.Tiebreaker) {
+		m.tiebreaker = cfg.Tiebreaker
+	}
+	if validTheme(cfg.Theme) {
+		m.theme = cfg.Theme
+	}
+	if validSmallFilterMode(cfg.SmallFilterMode) {
+		m.smallFilterMode = cfg.SmallFilterMode
+	}
+	switch {
+	case cfg.SpikeRatio == 0:
+		m.spikeRatio = defaultSpikeRatio
+	case validSpikeRatio(cfg.SpikeRatio):
+		m.spikeRatio = cfg.SpikeRatio
+	}
+	switch {
+	case cfg.AnomalySigma == 0:
+		m.anomalySigma = defaultAnomalySigma
+	case validAnomalySigma(cfg.AnomalySigma):
+		m.anomalySigma = cfg.AnomalySigma
+	}
+	m.notifyAnomalies = cfg.NotifyAnomalies
+	switch {
+	case cfg.SmallFilterPercent == 0:
+		m.smallFilterPercent = defaultSmallFilterPercent
+	case validSmallFilterPercent(cfg.SmallFilterPercent):
+		m.smallFilterPercent = cfg.SmallFilterPercent
+	}
+	if validSummarySortField(cfg.SummarySort) {
+		m.summarySortField = cfg.SummarySort
+	}
+	if cfg.SummarySortDescending != nil {
+		m.summarySortDescending = *cfg.SummarySortDescending
+	}
+}
+
+// saveConfig writes the current user preferences to disk as JSON if LoadConfig
+// has enabled persistence. The config directory is created if it does not
+// already exist. Write errors are silently ignored — a failed save does not
+// affect the running session.
+func (m *Monitor) saveConfig() {
+	m.mu.Lock()
+	if !m.persistConfig {
+		m.mu.Unlock()
+		return
+	}
+	cfg := m.configLocked()
+	m.mu.Unlock()
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
+
+// configLocked returns the current preferences in their persisted form.
+// m.mu must be held.
+func (m *Monitor) configLocked() appConfig {
+	cfg := appConfig{
+		HideSmall:     m.hideSmall,
+		HidePaths:     m.hidePaths,
+		FrameSeconds:  m.frameSeconds,
+		AverageMode:   m.averageMode,
+		Tiebreaker:    m.tiebreaker,
+		Sparklines:    m.showSparklines,
+		Share:         m.showShare,
+		Percent:       m.showPercent,
+		LiveDelta:     m.showLiveDelta,
+		UserSys:       m.showUserSys,
+		MarkTiny:      m.markTiny,
+		Status:        m.showStatus,
+		KernelTask:    m.includeKernelTask,
+		OnlyMine:      m.showOnlyMine,
+		FreezeTopN:    m.freezeTopN,
+		BudgetTarget:  m.budget.target,
+		BudgetSeconds: m.budget.seconds,
+		GroupMode:     m.groupMode,
+		ChangedOnly:   m.changedOnly,
+		Exclude:       m.patterns[ExcludeList],
+		Include:       m.patterns[IncludeList],
+		Watch:         m.patterns[WatchList],
+		Noise:         m.patterns[NoiseList],
+		NoiseSelf:     m.noiseIncludesSelf,
+	}
+	cfg.SecondFrameSeconds = m.secondFrameSeconds
+	cfg.StartDelaySeconds = m.startDelaySeconds
+	cfg.FrameWarmupSeconds = m.frameWarmup.Seconds()
+	cfg.HistoryRetentionSeconds = m.historyRetention.Seconds()
+	cfg.MaxProcessAgeSeconds = m.showOnlyStartedWithin.Seconds()
+	cfg.Theme = m.theme
+	cfg.SmallFilterMode = m.smallFilterMode
+	if m.smallFilterPercent != defaultSmallFilterPercent {
+		cfg.SmallFilterPercent = m.smallFilterPercent
+	}
+	if m.spikeRatio != defaultSpikeRatio {
+		cfg.SpikeRatio = m.spikeRatio
+	}
+	if m.anomalySigma != defaultAnomalySigma {
+		cfg.AnomalySigma = m.anomalySigma
+	}
+	cfg.NotifyAnomalies = m.notifyAnomalies
+	cfg.MergeReusedPIDs = m.mergeReusedPIDs
+	cfg.MenuBarItem = m.menuBarItem
+	cfg.PauseOnWindowClose = m.pauseOnWindowClose
+	cfg.ResumeMode = m.resumeMode
+	cfg.ReplaySpeed, cfg.ReplayLoop = m.replaySpeed, m.loopReplay
+	cfg.Aliases = m.aliases.byPattern
+	cfg.Columns = m.columns
+	cfg.SummaryColumns = m.summaryColumns
+	cfg.SummarySort = m.summarySortField
+	summarySortDescending := m.summarySortDescending
+	cfg.SummarySortDescending = &summarySortDescending
+	summaryMinTotal := m.summaryMinTotal
+	cfg.SummaryMinTotal = &summaryMinTotal
+	cfg.SummaryMinFrames = m.summaryMinFrames
+	cfg.CollectTopN = m.collectTopN
+	cfg.StopAfterFrames = m.stopAfterFrames
+	cfg.ChartExportEvery, cfg.ChartExportDir = m.chartExportEvery, m.chartExportDir
+	cfg.CompletionAlert = m.completionAlert
+	changedMinDelta := m.changedMinDelta
+	cfg.ChangedMinDelta = &changedMinDelta
+	activityThreshold := m.activityThreshold
+	cfg.ActivityThreshold = &activityThreshold
+	return cfg
+}
@@ -0,0 +1,277 @@
+package framescope
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	src, _ := newTestMonitor(t)
+	src.SetHideSmall(true)
+	src.SetShowOnlyMine(true)
+	src.SetGroupMode(GroupAppHelpersExpanded)
+	src.SetAverageMode(AverageAppearedFrames)
+	src.SetSummaryMinTotal(2.5)
+	src.SetActivityThreshold(0.4)
+	src.AddPattern(WatchList, "ffmpeg")
+	src.SetColumns([]string{"command", "pid"})
+	src.SetHistoryRetention(1800)
+	src.SetShowPercent(true)
+	src.SetSmallFilter(SmallFilterPercent, 5)
+	want, err := src.ConfigJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dst persists, so the settings must also survive a reload from disk.
+	dst, rec := newTestMonitor(t)
+	dst.LoadConfig()
+	if err := dst.SetConfigJSON(want); err != nil {
+		t.Fatalf("SetConfigJSON: %v", err)
+	}
+	if got, _ := dst.ConfigJSON(); got != want {
+		t.Errorf("round trip changed the config:\ngot  %s\nwant %s", got, want)
+	}
+	if updates, _ := rec.snapshotUpdates(); len(updates) == 0 {
+		t.Error("applying the config did not refresh the UI")
+	}
+
+	reloaded := NewMonitor(&recordingSink{})
+	reloaded.LoadConfig()
+	if got, _ := reloaded.ConfigJSON(); got != want {
+		t.Errorf("reloaded config:\ngot  %s\nwant %s", got, want)
+	}
+
+	// Setting the defaults back must reset modes, not just set them.
+	defaults, _ := newTestMonitor(t)
+	reset, _ := defaults.ConfigJSON()
+	if err := dst.SetConfigJSON(reset); err != nil {
+		t.Fatal(err)
+	}
+	if dst.GroupMode() != GroupNone || dst.AverageMode() != AverageAllFrames || dst.ShowOnlyMine() {
+		t.Errorf("defaults not restored: group %d, average %d, only mine %v",
+			dst.GroupMode(), dst.AverageMode(), dst.ShowOnlyMine())
+	}
+	if mode, percent := dst.SmallFilter(); mode != SmallFilterSeconds || percent != defaultSmallFilterPercent {
+		t.Errorf("small filter not restored: mode %d, %v%%", mode, percent)
+	}
+}
+
+func TestSetConfigJSONRejectsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name, data, want string
+	}{
+		{"malformed", `{"hide_small": true`, "unexpected EOF"},
+		{"not an object", `[1, 2]`, "cannot unmarshal"},
+		{"wrong type", `{"frame_seconds": "15"}`, "cannot unmarshal"},
+		{"unknown field", `{"frame_seconds": 15, "hide_smal": true}`, "unknown field"},
+		{"trailing data", `{"frame_seconds": 15} {}`, "after the settings object"},
+		{"zero frame", `{"frame_seconds": 0, "hide_small": true}`, "frame_seconds"},
+		{"group mode", `{"frame_seconds": 15, "group_mode": 7}`, "group_mode"},
+		{"average mode", `{"frame_seconds": 15, "average_mode": -1}`, "average_mode"},
+		{"tiebreaker", `{"frame_seconds": 15, "tiebreaker": 3}`, "tiebreaker"},
+		{"negative threshold", `{"frame_seconds": 15, "activity_threshold": -0.5}`, "activity_threshold"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, rec := newTestMonitor(t)
+			before, _ := m.ConfigJSON()
+			err := m.SetConfigJSON(tc.data)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+			if after, _ := m.ConfigJSON(); after != before {
+				t.Errorf("rejected config was applied:\n%s", after)
+			}
+			if _, errs := rec.snapshotUpdates(); len(errs) != 1 {
+				t.Errorf("errors = %q, want one", errs)
+			}
+		})
+	}
+}
+
+func TestExportImportConfig(t *testing.T) {
+	src, _ := newTestMonitor(t)
+	src.SetGroupMode(GroupAppHelpers)
+	src.SetSummaryMinTotal(30)
+	src.AddPattern(ExcludeList, "backupd")
+	src.SetColumns([]string{"pid", "share", "command"})
+	src.SetSmallFilter(SmallFilterPercent, 5)
+	src.SetResumeMode(ResumeContinue)
+	src.AddPattern(NoiseList, "mds_stores")
+	src.SetNoiseIncludesSelf(true)
+	path := filepath.Join(t.TempDir(), "team.json")
+	if err := src.ExportConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := src.ConfigJSON()
+
+	// dst persists, so the imported settings must also survive a restart.
+	dst, rec := newTestMonitor(t)
+	dst.LoadConfig()
+	if err := dst.ImportConfig(path); err != nil {
+		t.Fatalf("ImportConfig: %v", err)
+	}
+	if got, _ := dst.ConfigJSON(); got != want {
+		t.Errorf("imported config:\ngot  %s\nwant %s", got, want)
+	}
+	updates, _ := rec.snapshotUpdates()
+	if len(updates) == 0 || !strings.Contains(updates[len(updates)-1].Status, "Settings imported from") {
+		t.Error("importing did not refresh the UI with the file named")
+	}
+	reloaded := NewMonitor(&recordingSink{})
+	reloaded.LoadConfig()
+	if got, _ := reloaded.ConfigJSON(); got != want {
+		t.Errorf("reloaded config:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestImportConfigRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"frame_seconds": 15, "small_filter_mode": 4}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name, path, want string
+	}{
+		{"invalid value", invalid, "small_filter_mode"},
+		{"missing file", filepath.Join(dir, "missing.json"), "no such file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, rec := newTestMonitor(t)
+			before, _ := m.ConfigJSON()
+			err := m.ImportConfig(tc.path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+			if after, _ := m.ConfigJSON(); after != before {
+				t.Errorf("rejected config was applied:\n%s", after)
+			}
+			if _, errs := rec.snapshotUpdates(); len(errs) != 1 || !strings.HasPrefix(errs[0], "Settings import failed: ") {
+				t.Errorf("errors = %q, want one import failure", errs)
+			}
+		})
+	}
+}
+
+func TestReloadConfigKeepsRun(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetProcessSource(&busySource{})
+	if err := m.Start(0.2); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	waitForFrames(t, m, 2, 5*time.Second)
+	m.mu.Lock()
+	runID, frameIndex, frames := m.runID, m.frameIndex, len(m.history)
+	m.mu.Unlock()
+
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := `{"hide_small": false, "frame_seconds": 30, "group_mode": 1, "summary_min_total": 5}`
+	if err := os.WriteFile(path, []byte(edited), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := m.ReloadConfig()
+	if err != nil || !changed {
+		t.Fatalf("ReloadConfig() = %v, %v; want a change", changed, err)
+	}
+	if m.HideSmall() || m.GroupMode() != GroupAppHelpers || m.SummaryMinTotal() != 5 || m.FrameSeconds() != 30 {
+		t.Errorf("settings not reloaded: hide small %v, group %d, summary min %v, frame %v",
+			m.HideSmall(), m.GroupMode(), m.SummaryMinTotal(), m.FrameSeconds())
+	}
+	m.mu.Lock()
+	running := m.running && m.runID == runID && m.frameIndex >= frameIndex && len(m.history) >= frames
+	m.mu.Unlock()
+	if !running {
+		t.Error("reloading the settings reset the run")
+	}
+
+	if changed, err := m.ReloadConfig(); err != nil || changed {
+		t.Errorf("second ReloadConfig() = %v, %v; want no change", changed, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"hide_small": true`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReloadConfig(); err == nil || m.HideSmall() {
+		t.Errorf("malformed file: err = %v, hide small %v; want an error and no change", err, m.HideSmall())
+	}
+}
+
+func TestClampFrameSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		in, want float64
+		clamped  bool
+	}{
+		{0.0001, minFrameSeconds, true},
+		{0.1, 0.1, false},
+		{15, 15, false},
+		{86400, 86400, false},
+		{1e9, maxFrameSeconds, true},
+	} {
+		if got, clamped := clampFrameSeconds(tc.in); got != tc.want || clamped != tc.clamped {
+			t.Errorf("clampFrameSeconds(%g) = %g, %v; want %g, %v", tc.in, got, clamped, tc.want, tc.clamped)
+		}
+	}
+}
+
+func TestValidateFrameSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		in, want float64
+		message  string
+	}{
+		{-5, 0, "Frame length must be greater than zero seconds."},
+		{0, 0, "Frame length must be greater than zero seconds."},
+		{math.NaN(), 0, "Frame length must be greater than zero seconds."},
+		{0.0001, minFrameSeconds, "Frame length 0.0001s is outside 0.1s–24h; using 0.1s."},
+		{1e9, maxFrameSeconds, "Frame length 1e+09s is outside 0.1s–24h; using 86400s."},
+		{15, 15, ""},
+	} {
+		if got, message := ValidateFrameSeconds(tc.in); got != tc.want || message != tc.message {
+			t.Errorf("ValidateFrameSeconds(%g) = %g, %q; want %g, %q", tc.in, got, message, tc.want, tc.message)
+		}
+	}
+}
+
+func TestLoadConfigClampsFrameSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		saved, want float64
+		note        bool
+	}{
+		{0.0001, minFrameSeconds, true},
+		{30, 30, false},
+		{1e9, maxFrameSeconds, true},
+	} {
+		m, _ := newTestMonitor(t)
+		path, err := configPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		data := []byte(`{"frame_seconds": ` + strconv.FormatFloat(tc.saved, 'g', -1, 64) + `}`)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		m.LoadConfig()
+		if got := m.FrameSeconds(); got != tc.want {
+			t.Errorf("saved %g: frame seconds = %g, want %g", tc.saved, got, tc.want)
+		}
+		m.mu.Lock()
+		status := m.status
+		m.mu.Unlock()
+		if note := strings.Contains(status, "is outside"); note != tc.note {
+			t.Errorf("saved %g: status = %q, want clamp note %v", tc.saved, status, tc.note)
+		}
+	}
+}
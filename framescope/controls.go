@@ -0,0 +1,839 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errInvalidFrameLength is returned by Start for a frame length ≤ 0.
+var errInvalidFrameLength = errors.New("frame length must be greater than zero seconds")
+
+// Positive frame lengths are clamped to [minFrameSeconds, maxFrameSeconds]:
+// shorter frames end before a snapshot can complete, and longer ones never
+// visibly end.
+const (
+	minFrameSeconds float64 = 0.1
+	maxFrameSeconds float64 = 24 * 60 * 60
+)
+
+// clampFrameSeconds limits a positive frame length to the supported range and
+// reports whether it had to be changed.
+func clampFrameSeconds(seconds float64) (float64, bool) {
+	clamped := min(max(seconds, minFrameSeconds), maxFrameSeconds)
+	return clamped, clamped != seconds
+}
+
+// frameClampNote tells the user that a requested frame length was clamped.
+func frameClampNote(requested, used float64) string {
+	return fmt.Sprintf("Frame length %gs is outside %gs–%gh; using %gs.",
+		requested, minFrameSeconds, maxFrameSeconds/3600, used)
+}
+
+// ValidateFrameSeconds checks a frame length the way Start and the settings
+// file treat it, so the UI can give feedback as it is typed. It returns the
+// length a run would actually use, and a message for the user: "" if seconds
+// is used as is, a note naming the length used instead if it is outside the
+// supported range (see clampFrameSeconds), or why it is rejected if it is not
+// greater than zero, in which case normalized is 0.
+func ValidateFrameSeconds(seconds float64) (normalized float64, message string) {
+	if !(seconds > 0) {
+		return 0, "Frame length must be greater than zero seconds."
+	}
+	if clamped, changed := clampFrameSeconds(seconds); changed {
+		return clamped, frameClampNote(seconds, clamped)
+	}
+	return seconds, ""
+}
+
+// Start cancels any in-progress monitoring run, resets all frame state, and
+// launches a new sampling goroutine. frameSeconds is the desired frame length;
+// it is checked by ValidateFrameSeconds: values ≤ 0 are rejected with its
+// message and errInvalidFrameLength, and others are clamped with its note in
+// the status line.
+func (m *Monitor) Start(frameSeconds float64) error {
+	frameSeconds, note := ValidateFrameSeconds(frameSeconds)
+	if frameSeconds == 0 {
+		m.postError(0, note)
+		return errInvalidFrameLength
+	}
+	status := ""
+	if note != "" {
+		status = " " + note
+	}
+
+	m.controlMu.Lock()
+	defer m.controlMu.Unlock()
+
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.runID++
+	runID := m.runID
+	m.cancel = cancel
+	stopRequest, done := make(chan struct{}, 1), make(chan struct{})
+	m.stopRequest, m.runDone = stopRequest, done
+	wake := make(chan struct{}, 1)
+	m.pauseWake, m.paused, m.windowPauseLifted = wake, false, false
+	m.stopReplayLocked()
+	m.running = true
+	m.frameSeconds = frameSeconds
+	lengths := []float64{frameSeconds}
+	delay := time.Duration(m.startDelaySeconds * float64(time.Second))
+	warmup := m.frameWarmup
+	m.frameView = newFrameView(frameSeconds)
+	m.parked = frameView{}
+	if second := m.secondFrameSeconds; second > 0 && second != frameSeconds {
+		lengths = append(lengths, second)
+		m.parked = newFrameView(second)
+	}
+	m.skipHint = ""
+	m.skipHintShown = false
+	m.chartSnapshotErr = ""
+	m.chartSnapshotFailed = false
+	m.recordErr = ""
+	m.budget.fired = false
+	m.status = fmt.Sprintf("Running. Frame 1 of %.1fs started.", frameSeconds) + status
+	m.mu.Unlock()
+	m.saveConfig()
+
+	go func() {
+		defer close(done)
+		m.run(ctx, runID, lengths, delay, warmup, stopRequest, wake)
+	}()
+	m.pushUI(runID)
+	return nil
+}
+
+// Stop ends the active monitoring run, discarding the frame in progress, and
+// updates state so the UI shows the last completed frame. It is
+// Shutdown(false) with the error shown in the status bar only. Stopping a
+// run is announced only with CompletionAlertAlways (see SetCompletionAlert).
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	running := m.running
+	m.mu.Unlock()
+	m.Shutdown(false)
+	if running {
+		m.alertCompletion(0, true, "Monitoring stopped.")
+	}
+}
+
+// shutdownTimeout bounds how long Shutdown waits for the sampling goroutine,
+// which may be in the middle of a snapshot, first to finish by itself and
+// then to notice the cancellation.
+const shutdownTimeout = 5 * time.Second
+
+// Shutdown ends the active monitoring run like Stop, but only returns once
+// the sampling goroutine has, so that nothing it does is lost. With finalize
+// set, the frame in progress is completed first, unless it has only just
+// begun, and recorded like any other. Afterwards the recorder (see
+// SetRecorder) is flushed; a flush error is returned and shown in the status
+// bar. Stream clients of the HTTP API are disconnected once they have the
+// final update. Shutdown is safe to call when monitoring is already stopped,
+// which only flushes the recorder again.
+func (m *Monitor) Shutdown(finalize bool) error {
+	m.controlMu.Lock()
+	defer m.controlMu.Unlock()
+
+	m.mu.Lock()
+	stopRequest, done := m.stopRequest, m.runDone
+	m.stopRequest, m.runDone = nil, nil
+	m.mu.Unlock()
+
+	// To complete the frame, let the run end by itself, and cancel it only if
+	// it does not in time, e.g. while it is waiting to retry a snapshot.
+	if finalize && stopRequest != nil {
+		stopRequest <- struct{}{}
+		waitForRun(done)
+	}
+
+	m.mu.Lock()
+	cancel := m.cancel
+	m.runID++
+	m.cancel = nil
+	m.running = false
+	m.pauseWake, m.paused = nil, false
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		waitForRun(done)
+	}
+	m.chartWrites.Wait()
+
+	var err error
+	if m.recorder != nil {
+		err = m.recorder.Flush()
+	}
+
+	m.mu.Lock()
+	m.status = "Monitoring stopped."
+	if err != nil {
+		m.status = fmt.Sprintf("Monitoring stopped. Saving the recording failed: %v", err)
+	}
+	if len(m.history) > 0 && m.autoFollowLatestComplete {
+		m.viewingCurrent = false
+		m.selectedHistoryIdx = len(m.history) - 1
+	}
+	m.mu.Unlock()
+
+	m.pushUI(0)
+	if cancel != nil {
+		m.streams.closeAll()
+	}
+	return err
+}
+
+// waitForRun waits until done is closed or shutdownTimeout has passed.
+func waitForRun(done <-chan struct{}) {
+	timeout := time.NewTimer(shutdownTimeout)
+	defer timeout.Stop()
+	select {
+	case <-done:
+	case <-timeout.C:
+	}
+}
+
+// SetHideSmall toggles filtering of rows below 1 CPU-second. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetHideSmall(enabled bool) {
+	m.mu.Lock()
+	m.hideSmall = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetHidePaths toggles showing only the executable basename. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetHidePaths(enabled bool) {
+	m.mu.Lock()
+	m.hidePaths = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowSparklines toggles the trend sparkline column in the current-frame
+// table. The new setting is persisted to disk immediately.
+func (m *Monitor) SetShowSparklines(enabled bool) {
+	m.mu.Lock()
+	m.showSparklines = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowShare toggles the share-of-frame-total column in the current-frame
+// table. The new setting is persisted to disk immediately.
+func (m *Monitor) SetShowShare(enabled bool) {
+	m.mu.Lock()
+	m.showShare = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowPercent toggles the %CPU column of the current-frame table, which
+// shows each row's CPU-seconds as a percentage of one core over the frame
+// alongside the CPU-seconds themselves. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetShowPercent(enabled bool) {
+	m.mu.Lock()
+	m.showPercent = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowUserSys toggles the user and sys columns of the current-frame
+// table, which split each row's CPU-seconds into time spent in userland and
+// in the kernel; the combined CPU-seconds stay. The new setting is persisted
+// to disk immediately.
+func (m *Monitor) SetShowUserSys(enabled bool) {
+	m.mu.Lock()
+	m.showUserSys = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetMarkTinyValues toggles how both tables show a process's CPU-seconds
+// when they are positive but round to 0.0 at one decimal place: as "<0.1", so
+// that a process that did a little work does not look idle, or as "0.0". An
+// exact zero is always "0.0". The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetMarkTinyValues(enabled bool) {
+	m.mu.Lock()
+	m.markTiny = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowStatus toggles the column marking zombie (Z) and stopped (T)
+// processes in the current-frame table. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetShowStatus(enabled bool) {
+	m.mu.Lock()
+	m.showStatus = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetIncludeKernelTask toggles whether kernel_task is counted. It is excluded
+// by default because its CPU time, much of it thermal management, can dwarf
+// every user process. Unlike the pattern lists it applies to the snapshots
+// themselves, so all tables and the summary follow it from the next frame.
+// The new setting is persisted to disk immediately.
+func (m *Monitor) SetIncludeKernelTask(enabled bool) {
+	m.mu.Lock()
+	m.includeKernelTask = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetShowOnlyMine toggles whether only processes owned by the user running
+// FrameScope are counted. Like SetIncludeKernelTask it applies to the
+// snapshots, from the next frame, and combines with every other filter. The
+// new setting is persisted to disk immediately.
+func (m *Monitor) SetShowOnlyMine(enabled bool) {
+	m.mu.Lock()
+	m.showOnlyMine = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetAverageMode switches the summary's average denominator. Unknown modes
+// are ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetAverageMode(mode AverageMode) {
+	if mode != AverageAllFrames && mode != AverageAppearedFrames {
+		return
+	}
+	m.mu.Lock()
+	m.averageMode = mode
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetMergeReusedPIDs toggles whether the summary merges processes that held
+// the same PID and ran the same command into one row, noting how many there
+// were. By default each process gets its own row, since the OS may reuse a
+// PID; in a build loop that keeps restarting the same tool, merging shows the
+// activity as one entry. The summary is re-rendered at once. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetMergeReusedPIDs(enabled bool) {
+	m.mu.Lock()
+	m.mergeReusedPIDs = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetTiebreaker selects how rows with equal CPU are ordered. The summary is
+// re-sorted at once; the current frame follows from its next update, and
+// completed frames keep the order they were recorded in. Unknown tiebreakers
+// are ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetTiebreaker(t Tiebreaker) {
+	if !validTiebreaker(t) {
+		return
+	}
+	m.mu.Lock()
+	m.tiebreaker = t
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// validTiebreaker reports whether t is one of the Tiebreaker constants.
+func validTiebreaker(t Tiebreaker) bool {
+	return t == TiebreakPID || t == TiebreakCommand || t == TiebreakCreateTime
+}
+
+// SetSummarySort orders the summary table by field, largest or last first
+// when descending, independently of the current-frame table. Rows equal in the
+// field keep the tiebreaker's order in both directions. The summary is
+// re-sorted at once. Unknown fields are ignored. The new setting is persisted
+// to disk immediately.
+func (m *Monitor) SetSummarySort(field SummarySortField, descending bool) {
+	if !validSummarySortField(field) {
+		return
+	}
+	m.mu.Lock()
+	m.summarySortField = field
+	m.summarySortDescending = descending
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// validSummarySortField reports whether f is one of the SummarySortField
+// constants.
+func validSummarySortField(f SummarySortField) bool {
+	return f >= SummarySortTotal && f <= SummarySortPID
+}
+
+// SetGroupMode switches how app helper processes are shown in the
+// current-frame table. Unknown modes are ignored. The new setting is persisted
+// to disk immediately.
+func (m *Monitor) SetGroupMode(mode GroupMode) {
+	if mode != GroupNone && mode != GroupAppHelpers && mode != GroupAppHelpersExpanded {
+		return
+	}
+	m.mu.Lock()
+	m.groupMode = mode
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetChangedOnly toggles the changed-only view of the current-frame table,
+// which lists only processes whose CPU moved versus the previous completed
+// frame (see SetChangedMinDelta), started, or exited, largest change first.
+// The new setting is persisted to disk immediately.
+func (m *Monitor) SetChangedOnly(enabled bool) {
+	m.mu.Lock()
+	m.changedOnly = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetFreezeTopN toggles the freeze top-N mode of the live table: it shows
+// only the frozenRowCount heaviest processes of the previous completed frame,
+// in that order, and keeps their positions while their values update, until
+// the frame ends and the set is picked again. The first frame of a run, and a
+// frame the mode is turned on in, pin the heaviest processes so far instead.
+// The new setting is persisted to disk immediately.
+func (m *Monitor) SetFreezeTopN(enabled bool) {
+	m.mu.Lock()
+	m.freezeTopN = enabled
+	m.frozenPIDs = nil
+	if enabled {
+		m.frozenPIDs = topPIDs(m.liveRows, m.tableOptionsLocked(), frozenRowCount)
+	}
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetChangedMinDelta sets how many CPU-seconds a process's usage must move
+// between frames to be listed in the changed-only view. Negative or non-finite
+// values are ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetChangedMinDelta(delta float64) {
+	if delta < 0 || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.changedMinDelta = delta
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// AddPattern adds pattern to list (see PatternList). Surrounding space is
+// trimmed; empty patterns, duplicates (ignoring case), and unknown lists are
+// ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) AddPattern(list PatternList, pattern string) {
+	if !list.valid() {
+		return
+	}
+	m.mu.Lock()
+	m.patterns[list] = normalizePatterns(append(append([]string(nil), m.patterns[list]...), pattern))
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// RemovePattern removes pattern, compared ignoring case and surrounding space,
+// from list. The new setting is persisted to disk immediately.
+func (m *Monitor) RemovePattern(list PatternList, pattern string) {
+	if !list.valid() {
+		return
+	}
+	pattern = strings.TrimSpace(pattern)
+	m.mu.Lock()
+	var kept []string
+	for _, p := range m.patterns[list] {
+		if !strings.EqualFold(p, pattern) {
+			kept = append(kept, p)
+		}
+	}
+	m.patterns[list] = kept
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetActivityThreshold sets how many CPU-seconds per frame a process needs to
+// count as active. Processes below it are treated as idle noise by
+// activity-based features, such as the changed-only view's started and exited
+// processes, but are still displayed according to Hide <1s. Negative or
+// non-finite values are ignored. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetActivityThreshold(threshold float64) {
+	if threshold < 0 || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.activityThreshold = threshold
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// CopyRowField returns field of the current-frame table row for pid, for the
+// caller to put on the clipboard, and confirms the copy in the status line.
+// The PID is looked up among the rows displayed for the current view, so a
+// PID from a stale table is refused rather than naming another process; for
+// a group row it is the application's main process. Failures are reported in
+// the status line and returned.
+func (m *Monitor) CopyRowField(pid int, field RowField) (string, error) {
+	m.mu.Lock()
+	rows := m.currentRowsLocked()
+	opts := m.tableOptionsLocked()
+	m.mu.Unlock()
+
+	text, err := rowField(rows, opts, pid, field)
+	m.mu.Lock()
+	switch {
+	case err != nil:
+		m.status = fmt.Sprintf("Copy failed: %v.", err)
+	case field == FieldPID:
+		m.status = fmt.Sprintf("Copied PID %d.", pid)
+	default:
+		m.status = fmt.Sprintf("Copied the command of PID %d.", pid)
+	}
+	m.mu.Unlock()
+	m.pushUI(0)
+	return text, err
+}
+
+// rowField extracts field for pid from rows as displayed with opts. Gone
+// processes in the changed-only view are looked up in opts.Previous.
+func rowField(rows []ResultRow, opts TableOptions, pid int, field RowField) (string, error) {
+	if !displayedPIDs(rows, opts)[pid] {
+		return "", fmt.Errorf("PID %d is not in the current view", pid)
+	}
+	switch field {
+	case FieldPID:
+		return strconv.Itoa(pid), nil
+	case FieldCommand:
+		for _, source := range [][]ResultRow{rows, opts.Previous} {
+			for _, row := range source {
+				if row.PID == pid && row.Command != "" {
+					return row.Command, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("the command of PID %d is unknown", pid)
+	}
+	return "", fmt.Errorf("unknown field %d", field)
+}
+
+// SetSummaryMinTotal sets the minimum total CPU-seconds a process needs across
+// the history to be listed in the summary; 0 lists every process. Negative or
+// non-finite values are ignored. The current-frame hideSmall filter is
+// unaffected. The new setting is persisted to disk immediately.
+func (m *Monitor) SetSummaryMinTotal(total float64) {
+	if total < 0 || math.IsNaN(total) || math.IsInf(total, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.summaryMinTotal = total
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SetSummaryMinFrames sets the minimum number of frames a process must have
+// appeared in to be listed in the summary, hiding short-lived processes in a
+// long session; 1 lists every process. A row must also reach the minimum
+// total (see SetSummaryMinTotal). Values below 1 are ignored. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetSummaryMinFrames(frames int) {
+	if frames < 1 {
+		return
+	}
+	m.mu.Lock()
+	m.summaryMinFrames = frames
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SelectFrame switches the view to the history popup item at index. It maps
+// to either a completed frame in history or the live in-progress frame (when
+// monitoring is running and the last item is selected), and clears any range
+// set by SetHistoryRange. A playing replay continues from the frame selected
+// (see ReplayPlay). Out-of-range indices are ignored.
+func (m *Monitor) SelectFrame(index int) {
+	m.mu.Lock()
+	if !m.selectFrameLocked(index) {
+		m.mu.Unlock()
+		return
+	}
+	m.summaryRange = frameRange{}
+	m.reanchorReplayLocked()
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// SetHistoryRange limits the summary to the completed frames between the
+// history popup items at from and to, inclusive and in either order, and
+// shows the frame at to like SelectFrame. The in-progress item stands for
+// the newest completed frame. Indices that do not resolve to at least one
+// completed frame are ignored.
+func (m *Monitor) SetHistoryRange(from, to int) {
+	m.mu.Lock()
+	r, ok := resolveHistoryRange(m.history, from, to)
+	if !ok || !m.selectFrameLocked(to) {
+		m.mu.Unlock()
+		return
+	}
+	m.summaryRange = r
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// selectFrameLocked switches the view to the history popup item at index and
+// reports whether index was valid. Must be called with m.mu held.
+func (m *Monitor) selectFrameLocked(index int) bool {
+	completedCount := len(m.history)
+	currentIndex := -1
+	if m.running {
+		currentIndex = completedCount
+	}
+
+	switch {
+	case index == currentIndex:
+		m.viewingCurrent = true
+		m.selectedHistoryIdx = -1
+		m.autoFollowLatestComplete = false
+	case index >= 0 && index < completedCount:
+		m.viewingCurrent = false
+		m.selectedHistoryIdx = index
+		m.autoFollowLatestComplete = index == completedCount-1
+	default:
+		return false
+	}
+	return true
+}
+
+// HideSmall reports whether rows below 1 CPU-second are filtered.
+func (m *Monitor) HideSmall() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hideSmall
+}
+
+// HidePaths reports whether only executable basenames are shown.
+func (m *Monitor) HidePaths() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hidePaths
+}
+
+// ShowSparklines reports whether the trend sparkline column is enabled.
+func (m *Monitor) ShowSparklines() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showSparklines
+}
+
+// ShowStatus reports whether the zombie/stopped marker column is enabled.
+func (m *Monitor) ShowStatus() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showStatus
+}
+
+// IncludeKernelTask reports whether kernel_task is counted.
+func (m *Monitor) IncludeKernelTask() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.includeKernelTask
+}
+
+// FreezeTopN reports whether the freeze top-N mode is enabled.
+func (m *Monitor) FreezeTopN() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.freezeTopN
+}
+
+// ShowOnlyMine reports whether only the current user's processes are counted.
+func (m *Monitor) ShowOnlyMine() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showOnlyMine
+}
+
+// ShowShare reports whether the share-of-frame-total column is enabled.
+func (m *Monitor) ShowShare() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showShare
+}
+
+// ShowPercent reports whether the %CPU column is enabled.
+func (m *Monitor) ShowPercent() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showPercent
+}
+
+// ShowUserSys reports whether the user and sys columns are enabled.
+func (m *Monitor) ShowUserSys() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showUserSys
+}
+
+// MarkTinyValues reports whether tiny CPU-seconds are shown as "<0.1".
+func (m *Monitor) MarkTinyValues() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.markTiny
+}
+
+// AverageMode returns the active summary average denominator.
+func (m *Monitor) AverageMode() AverageMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.averageMode
+}
+
+// MergeReusedPIDs reports whether the summary merges reused PIDs of the same
+// command.
+func (m *Monitor) MergeReusedPIDs() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mergeReusedPIDs
+}
+
+// Tiebreaker returns the active ordering of equal-CPU rows.
+func (m *Monitor) Tiebreaker() Tiebreaker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tiebreaker
+}
+
+// SummarySort returns the field the summary table is ordered by and whether
+// the order is descending.
+func (m *Monitor) SummarySort() (SummarySortField, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.summarySortField, m.summarySortDescending
+}
+
+// GroupMode returns the active app helper grouping.
+func (m *Monitor) GroupMode() GroupMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.groupMode
+}
+
+// ChangedOnly reports whether the changed-only view is enabled.
+func (m *Monitor) ChangedOnly() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.changedOnly
+}
+
+// ChangedMinDelta returns the changed-only view's threshold in CPU-seconds.
+func (m *Monitor) ChangedMinDelta() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.changedMinDelta
+}
+
+// Patterns returns a copy of list, in the order the patterns were added.
+func (m *Monitor) Patterns(list PatternList) []string {
+	if !list.valid() {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.patterns[list]...)
+}
+
+// ActivityThreshold returns the activity threshold in CPU-seconds per frame.
+func (m *Monitor) ActivityThreshold() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.activityThreshold
+}
+
+// SummaryMinTotal returns the summary's minimum total CPU-seconds.
+func (m *Monitor) SummaryMinTotal() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.summaryMinTotal
+}
+
+// SummaryMinFrames returns the number of frames a process must have appeared
+// in to be listed in the summary.
+func (m *Monitor) SummaryMinFrames() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.summaryMinFrames
+}
+
+// Running reports whether a monitoring run is active.
+func (m *Monitor) Running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// FrameCount returns how many completed frames the history holds, for the
+// frame length on display, and whether a frame is in progress, which it is
+// while a run is active. Together they give the entries of the history
+// popup, with the frame in progress last.
+func (m *Monitor) FrameCount() (completed int, inProgress bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.history), m.running
+}
+
+// Status returns the status line as last set, without rendering any tables.
+func (m *Monitor) Status() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// FrameSeconds returns the configured frame length in seconds.
+func (m *Monitor) FrameSeconds() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.frameSeconds
+}
+
+// Frames returns a copy of the completed frames recorded by the current or
+// most recent run, oldest first.
+func (m *Monitor) Frames() []FrameRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	frames := make([]FrameRecord, len(m.history))
+	for i, frame := range m.history {
+		frames[i] = frame
+		frames[i].Rows = cloneRows(frame.Rows)
+	}
+	return frames
+}
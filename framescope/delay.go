@@ -0,0 +1,66 @@
+package framescope
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// startCountdownStep is how often the start delay countdown reads the clock.
+const startCountdownStep = 100 * time.Millisecond
+
+// waitStartDelay holds off the baseline snapshot of a run for delay, so that
+// whatever the user does right after pressing Start does not land in the
+// first frame. Meanwhile the status line counts down the whole seconds left,
+// "Starting in 3…", "Starting in 2…", and so on. Time is read from m.now, so
+// tests drive the countdown with a fake clock. It returns false if the run
+// is stopped or cancelled before the delay is over.
+func (m *Monitor) waitStartDelay(ctx context.Context, runID int64, delay time.Duration, stopRequest <-chan struct{}) bool {
+	deadline := m.now().Add(delay)
+	ticker := time.NewTicker(startCountdownStep)
+	defer ticker.Stop()
+
+	shown := 0
+	for {
+		remaining := deadline.Sub(m.now())
+		if remaining <= 0 {
+			return true
+		}
+		if seconds := int(math.Ceil(remaining.Seconds())); seconds != shown {
+			shown = seconds
+			m.mu.Lock()
+			m.status = fmt.Sprintf("Starting in %d…", seconds)
+			m.mu.Unlock()
+			m.pushUI(runID)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-stopRequest:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetStartDelay sets how long, in seconds, Start waits before taking the
+// baseline snapshot of the first frame; 0 starts at once. The delay can be
+// cut short by Stop. Negative or non-finite values are ignored. The new
+// setting is persisted to disk immediately and applies from the next Start.
+func (m *Monitor) SetStartDelay(seconds float64) {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.startDelaySeconds = seconds
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// StartDelay returns the start delay in seconds.
+func (m *Monitor) StartDelay() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.startDelaySeconds
+}
@@ -0,0 +1,69 @@
+package framescope
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartDelayHoldsBaseline(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetStartDelay(3)
+	defer m.Stop()
+
+	// waitForStatus waits until the latest UI update shows status.
+	waitForStatus := func(status string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			updates, _ := rec.snapshotUpdates()
+			if len(updates) > 0 && updates[len(updates)-1].Status == status {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("status never became %q", status)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	m.Start(5)
+	waitForStatus("Starting in 3…")
+	clock.Advance(1500 * time.Millisecond)
+	waitForStatus("Starting in 2…")
+	if n := source.calls.Load(); n != 0 {
+		t.Fatalf("%d snapshots taken during the delay, want none", n)
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("no baseline after the delay")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	m.mu.Lock()
+	frameStart := m.frameStart
+	m.mu.Unlock()
+	if want := time.Unix(1003, 0); !frameStart.Equal(want) {
+		t.Errorf("frame start = %v, want %v, when the delay ended", frameStart, want)
+	}
+}
+
+func TestStopDuringStartDelay(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.clock = (&fakeClock{now: time.Unix(1000, 0)}).Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetStartDelay(60)
+
+	m.Start(5)
+	m.Stop()
+	if m.Running() || source.calls.Load() != 0 {
+		t.Errorf("running %v after %d snapshots, want stopped before any", m.Running(), source.calls.Load())
+	}
+}
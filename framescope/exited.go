@@ -0,0 +1,49 @@
+package framescope
+
+// exitedCPU estimates the CPU-seconds consumed during a frame by the
+// processes in last, the previous tick's snapshot, that are gone from current,
+// which ComputeResults cannot report because they are absent from the frame's
+// final snapshot. A process that exited counts the CPU time it had used when
+// last sampled, less what it had used by baseline if it was already running
+// then.
+//
+// It is an estimate from the run loop's ticks, 500 ms apart: CPU used between
+// a process's last sample and its exit is missed, as are processes that
+// started and exited between two ticks, so it errs low. A process absent from
+// baseline whose create time precedes frameStart, in ms since the Unix epoch,
+// was running but unreadable when the baseline was taken; as its CPU before
+// the frame is unknown it is not counted.
+func exitedCPU(baseline, last, current map[int]ProcessSample, frameStart int64) float64 {
+	total := 0.0
+	for pid, seen := range last {
+		if after, ok := current[pid]; ok && !reusedPID(seen, after) {
+			continue
+		}
+		before, ok := baseline[pid]
+		switch {
+		case ok && !reusedPID(before, seen):
+			total += max(seen.CPUSeconds-before.CPUSeconds, 0)
+		case seen.CreateTime != 0 && seen.CreateTime < frameStart:
+		default:
+			total += seen.CPUSeconds
+		}
+	}
+	return total
+}
+
+// processChurn counts the processes that started and exited between last and
+// current, two consecutive ticks' snapshots. A PID reused by a new process
+// counts as one of each.
+func processChurn(last, current map[int]ProcessSample) (started, exited int) {
+	for pid, after := range current {
+		if before, ok := last[pid]; !ok || reusedPID(before, after) {
+			started++
+		}
+	}
+	for pid, before := range last {
+		if after, ok := current[pid]; !ok || reusedPID(before, after) {
+			exited++
+		}
+	}
+	return started, exited
+}
@@ -0,0 +1,146 @@
+package framescope
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedSource returns its snapshots in turn, repeating the last.
+type scriptedSource struct {
+	snapshots []map[int]ProcessSample
+}
+
+func (s *scriptedSource) Snapshot() (map[int]ProcessSample, error) {
+	next := s.snapshots[0]
+	if len(s.snapshots) > 1 {
+		s.snapshots = s.snapshots[1:]
+	}
+	return next, nil
+}
+
+func TestExitedProcessesAccounted(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.cores = 1
+	m.running = true
+	m.frameView = newFrameView(10)
+	start := clock.Now()
+	m.frameStart = start
+	startMs := start.UnixMilli()
+
+	editor := ProcessSample{CPUSeconds: 10, Command: "/bin/editor", CreateTime: startMs - 60000}
+	at := func(cpu float64, s ProcessSample) ProcessSample {
+		s.CPUSeconds = cpu
+		return s
+	}
+	cc := ProcessSample{Command: "/usr/bin/cc", CreateTime: startMs + 1000}
+	build := ProcessSample{Command: "/usr/bin/make", CreateTime: startMs - 5000}
+	hidden := ProcessSample{Command: "/bin/unreadable", CreateTime: startMs - 5000}
+	source := &scriptedSource{snapshots: []map[int]ProcessSample{
+		{1: editor, 3: at(4, build)},
+		// A compiler starts mid-frame, and a process unreadable at the
+		// baseline shows up.
+		{1: at(11, editor), 2: at(1.5, cc), 3: at(5, build), 4: at(50, hidden)},
+		{1: at(12, editor), 2: at(3, cc), 3: at(6.5, build), 4: at(51, hidden)},
+		// The compiler, the make already running at the baseline, and the
+		// unreadable process all exit before the frame ends.
+		{1: at(13, editor)},
+	}}
+
+	baseline, _ := source.Snapshot()
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: baseline, start: start, last: baseline}
+	var frame FrameRecord
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		if i == 2 {
+			clock.Advance(7 * time.Second)
+		}
+		current, _ := source.Snapshot()
+		frame, _ = m.advanceTrack(track, current, clock.Now(), false, TiebreakPID)
+	}
+
+	if len(frame.Rows) != 1 || frame.Rows[0].PID != 1 {
+		t.Fatalf("rows = %+v, want only the editor", frame.Rows)
+	}
+	// 3 CPU-s of the compiler's whole life, and the 2.5 the make used since
+	// the baseline; the unreadable process's CPU before the frame is unknown.
+	if math.Abs(frame.ExitedCPU-5.5) > 1e-9 {
+		t.Errorf("ExitedCPU = %v, want 5.5", frame.ExitedCPU)
+	}
+	if track.exited != 0 {
+		t.Errorf("next frame starts with %v exited CPU-s, want 0", track.exited)
+	}
+
+	stats := computeSessionStats([]FrameRecord{frame, {Index: 2, Duration: time.Second, ExitedCPU: 1}}, 1, noiseFilter{})
+	if math.Abs(stats.ExitedCPU-6.5) > 1e-9 {
+		t.Errorf("session ExitedCPU = %v, want 6.5", stats.ExitedCPU)
+	}
+	if got := renderSessionStats(stats, true); !strings.HasSuffix(got, " · ~6.5 CPU-s in exited processes") {
+		t.Errorf("rendered = %q, want the exited estimate last", got)
+	}
+}
+
+func TestExitedCPUPIDReused(t *testing.T) {
+	baseline := map[int]ProcessSample{5: {CPUSeconds: 2, CreateTime: 100}}
+	last := map[int]ProcessSample{5: {CPUSeconds: 3, CreateTime: 100}}
+	// PID 5 exited, and an unrelated process took its PID.
+	current := map[int]ProcessSample{5: {CPUSeconds: 0.1, CreateTime: 900}}
+	if got := exitedCPU(baseline, last, current, 50); got != 1 {
+		t.Errorf("exitedCPU = %v, want 1 for the process that exited", got)
+	}
+	if got := exitedCPU(baseline, current, current, 50); got != 0 {
+		t.Errorf("exitedCPU = %v, want 0 with no process gone", got)
+	}
+}
+
+func TestFrameRecordsProcessChurn(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.running = true
+	m.frameView = newFrameView(10)
+	m.frameStart = clock.Now()
+
+	proc := func(created int64) ProcessSample { return ProcessSample{Command: "/bin/p", CreateTime: created} }
+	source := &scriptedSource{snapshots: []map[int]ProcessSample{
+		{1: proc(1), 3: proc(3)},
+		// Two processes start.
+		{1: proc(1), 2: proc(2), 3: proc(3), 4: proc(4)},
+		// PID 3 exits and is reused at once.
+		{1: proc(1), 2: proc(2), 3: proc(30), 4: proc(4)},
+		// Three exit and one starts.
+		{1: proc(1), 6: proc(6)},
+	}}
+	baseline, _ := source.Snapshot()
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: baseline, start: clock.Now(), last: baseline}
+	var frame FrameRecord
+	for i := 0; i < 3; i++ {
+		clock.Advance(4 * time.Second)
+		current, _ := source.Snapshot()
+		frame, _ = m.advanceTrack(track, current, clock.Now(), false, TiebreakPID)
+	}
+	if frame.SampledCount != 2 || frame.NewProcesses != 4 || frame.ExitedProcesses != 4 {
+		t.Fatalf("sampled %d, new %d, exited %d; want 2, 4, 4", frame.SampledCount, frame.NewProcesses, frame.ExitedProcesses)
+	}
+	if track.started != 0 || track.ended != 0 {
+		t.Errorf("next frame starts with churn %d/%d, want 0/0", track.started, track.ended)
+	}
+
+	data, err := exportNDJSON([]FrameRecord{frame})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(data, `"sampled_processes":2,"new_processes":4,"exited_processes":4`) {
+		t.Errorf("export = %s, want the process counts", data)
+	}
+	imported, err := parseNDJSONFrame([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.SampledCount != 2 || imported.NewProcesses != 4 || imported.ExitedProcesses != 4 {
+		t.Errorf("imported %+v, want the process counts restored", imported)
+	}
+}
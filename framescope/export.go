@@ -0,0 +1,616 @@
+package framescope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportDocument is the top-level JSON history export.
+type exportDocument struct {
+	Frames []exportFrame `json:"frames"`
+}
+
+// exportFrame is one frame of the JSON history export. Timestamps are
+// RFC 3339 (ISO 8601); ended_at is omitted for the in-progress frame, as are
+// the process counts (see FrameRecord.SampledCount).
+type exportFrame struct {
+	Index            int             `json:"index"`
+	StartedAt        time.Time       `json:"started_at,omitzero"`
+	EndedAt          time.Time       `json:"ended_at,omitzero"`
+	DurationSeconds  float64         `json:"duration_seconds"`
+	InProgress       bool            `json:"in_progress,omitempty"`
+	Utilization      float64         `json:"utilization,omitempty"`
+	SampledProcesses int             `json:"sampled_processes,omitempty"`
+	NewProcesses     int             `json:"new_processes,omitempty"`
+	ExitedProcesses  int             `json:"exited_processes,omitempty"`
+	Outlier          bool            `json:"outlier,omitempty"`
+	Rows             json.RawMessage `json:"rows"`
+}
+
+// ExportHistory writes every completed frame, followed by the in-progress one
+// while a run is active, to path. The format follows the file extension:
+// ".json", ".ndjson" (one frame object per line, see ImportNDJSON), or
+// ".csv". Rows are unfiltered but, as everywhere, capped at the 500 heaviest
+// per frame. Failures are reported via postError and returned; on success the
+// status line names the file.
+func (m *Monitor) ExportHistory(path string) error {
+	m.mu.Lock()
+	frames := append([]FrameRecord(nil), m.history...)
+	if live, ok := m.liveFrameLocked(); ok {
+		frames = append(frames, live)
+	}
+	m.mu.Unlock()
+
+	if err := writeFrames(path, frames); err != nil {
+		m.postError(0, fmt.Sprintf("History export failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("History exported to %s.", path)
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
+
+// ExportFrame writes the frame the current-frame table shows, completed or
+// in progress, to path in the same formats as ExportHistory, so the file can
+// be read back like a one-frame history. Rows are unfiltered. It is an error
+// to export before any frame has rows or a start time. Failures are reported
+// via postError and returned; on success the status line names the file.
+func (m *Monitor) ExportFrame(path string) error {
+	m.mu.Lock()
+	frame, ok := m.displayedFrameLocked()
+	m.mu.Unlock()
+
+	err := errors.New("no frame to export")
+	if ok {
+		err = writeFrames(path, []FrameRecord{frame})
+	}
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Frame export failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Frame %d exported to %s.", frame.Index, path)
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
+
+// writeFrames writes frames to path in the format its extension selects:
+// ".json", ".ndjson", or ".csv".
+func writeFrames(path string, frames []FrameRecord) error {
+	var data string
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		data, err = exportJSON(frames)
+	case ".ndjson":
+		data, err = exportNDJSON(frames)
+	case ".csv":
+		data = exportCSV(frames)
+	default:
+		err = fmt.Errorf("unsupported export format %q (use .json, .ndjson, or .csv)", ext)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// newExportFrame converts frame for the JSON and NDJSON exports. A frame with
+// a zero EndedAt is marked in progress.
+func newExportFrame(frame FrameRecord) exportFrame {
+	return exportFrame{
+		Index:            frame.Index,
+		StartedAt:        frame.StartedAt,
+		EndedAt:          frame.EndedAt,
+		DurationSeconds:  frame.Duration.Seconds(),
+		InProgress:       frame.EndedAt.IsZero(),
+		Utilization:      frame.Utilization,
+		SampledProcesses: frame.SampledCount,
+		NewProcesses:     frame.NewProcesses,
+		ExitedProcesses:  frame.ExitedProcesses,
+		Outlier:          frame.Outlier,
+		Rows:             json.RawMessage(RenderTable(frame.Rows, TableOptions{}, JSONFormatter{})),
+	}
+}
+
+// exportJSON encodes frames as an exportDocument.
+func exportJSON(frames []FrameRecord) (string, error) {
+	doc := exportDocument{Frames: make([]exportFrame, len(frames))}
+	for i, frame := range frames {
+		doc.Frames[i] = newExportFrame(frame)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// exportNDJSON encodes frames as newline-delimited JSON: one exportFrame per
+// line, so a recording can be appended to and read back line by line.
+func exportNDJSON(frames []FrameRecord) (string, error) {
+	var b strings.Builder
+	for _, frame := range frames {
+		data, err := json.Marshal(newExportFrame(frame))
+		if err != nil {
+			return "", err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// frameDetail is one frame with every attribute of its processes, as returned
+// by FrameJSON. Its frame fields match exportFrame.
+type frameDetail struct {
+	Index            int              `json:"index"`
+	StartedAt        time.Time        `json:"started_at,omitzero"`
+	EndedAt          time.Time        `json:"ended_at,omitzero"`
+	DurationSeconds  float64          `json:"duration_seconds"`
+	InProgress       bool             `json:"in_progress,omitempty"`
+	SampledProcesses int              `json:"sampled_processes,omitempty"`
+	NewProcesses     int              `json:"new_processes,omitempty"`
+	ExitedProcesses  int              `json:"exited_processes,omitempty"`
+	Rows             []frameDetailRow `json:"rows"`
+}
+
+// frameDetailRow is one process of a frameDetail. Percent is framePercent
+// (100 = one core busy for the whole frame) and SharePercent is frameShare.
+// Flags lists "zombie", "stopped", and "exec" (the process exec'd into
+// ExecCommand during the frame) as they apply, and "noise" for a process left
+// out of the frame total (see NoiseList), whose SharePercent is 0. Commands is
+// set only for a process seen with more than one command up to this frame (see
+// commandHistories).
+type frameDetailRow struct {
+	PID          int      `json:"pid"`
+	PPID         int      `json:"ppid,omitempty"`
+	User         string   `json:"user,omitempty"`
+	CPUSeconds   float64  `json:"cpu_seconds"`
+	Percent      float64  `json:"percent"`
+	SharePercent float64  `json:"share_percent"`
+	Command      string   `json:"command"`
+	ExecCommand  string   `json:"exec_command,omitempty"`
+	Exe          string   `json:"exe,omitempty"`
+	Flags        []string `json:"flags,omitempty"`
+	Commands     []string `json:"commands,omitempty"`
+}
+
+// commandHistoryLimit is how many commands commandHistories keeps per process.
+const commandHistoryLimit = 5
+
+// commandHistories returns the commands each process in frames was seen with,
+// keyed by PID and start time, oldest first. A command is added each time it
+// differs from the previous one (see latestCommand), so a process that exec'd
+// and came back lists both changes, and only the last commandHistoryLimit are
+// kept. frames must be in order.
+func commandHistories(frames []FrameRecord) map[commandKey][]string {
+	histories := make(map[commandKey][]string)
+	for _, frame := range frames {
+		for _, row := range frame.Rows {
+			key := commandKey{row.PID, row.CreateTime}
+			for _, command := range []string{row.Command, row.ExecCommand} {
+				if command == "" || command == unknownCommand {
+					continue
+				}
+				seen := histories[key]
+				if n := len(seen); n > 0 && seen[n-1] == command {
+					continue
+				}
+				if len(seen) == commandHistoryLimit {
+					seen = seen[1:]
+				}
+				histories[key] = append(seen, command)
+			}
+		}
+	}
+	return histories
+}
+
+// FrameJSON returns the frame whose Index is index, or the in-progress frame
+// if index is its number, as a JSON frameDetail with every process of the
+// frame, unfiltered and uncapped. A frame that does not exist or has been
+// evicted from the history yields "{}". Each process's command history runs
+// up to and including the frame.
+func (m *Monitor) FrameJSON(index int) string {
+	m.mu.Lock()
+	frame, ok := m.frameByIndexLocked(index)
+	var histories map[commandKey][]string
+	if ok {
+		var frames []FrameRecord
+		for _, earlier := range m.history {
+			if earlier.Index < frame.Index {
+				frames = append(frames, earlier)
+			}
+		}
+		histories = commandHistories(append(frames, frame))
+	}
+	noise := m.noiseLocked()
+	m.mu.Unlock()
+	if !ok {
+		return "{}"
+	}
+
+	frameTotal := noise.countedCPU(frame.Rows)
+	detail := frameDetail{
+		Index:            frame.Index,
+		StartedAt:        frame.StartedAt,
+		EndedAt:          frame.EndedAt,
+		DurationSeconds:  frame.Duration.Seconds(),
+		InProgress:       frame.EndedAt.IsZero(),
+		SampledProcesses: frame.SampledCount,
+		NewProcesses:     frame.NewProcesses,
+		ExitedProcesses:  frame.ExitedProcesses,
+		Rows:             make([]frameDetailRow, len(frame.Rows)),
+	}
+	for i, row := range frame.Rows {
+		detail.Rows[i] = frameDetailRow{
+			PID:          row.PID,
+			PPID:         row.PPID,
+			User:         row.User,
+			CPUSeconds:   row.Diff,
+			Percent:      framePercent(row.Diff, frame.Duration),
+			SharePercent: frameShare(row.Diff, frameTotal),
+			Command:      row.Command,
+			ExecCommand:  row.ExecCommand,
+			Exe:          row.Exe,
+			Flags:        rowFlags(row),
+		}
+		if noise.matches(row) {
+			detail.Rows[i].SharePercent = 0
+			detail.Rows[i].Flags = append(detail.Rows[i].Flags, "noise")
+		}
+		if commands := histories[commandKey{row.PID, row.CreateTime}]; len(commands) > 1 {
+			detail.Rows[i].Commands = commands
+		}
+	}
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// summaryDetail is the summary with every computed metric, as returned by
+// SummaryJSON. FirstFrame and LastFrame are the span of completed frames it
+// aggregates, and Approximate is set when some of them kept only their
+// busiest rows (see SetCollectTopN).
+type summaryDetail struct {
+	AverageMode string             `json:"average_mode"`
+	FirstFrame  int                `json:"first_frame,omitempty"`
+	LastFrame   int                `json:"last_frame,omitempty"`
+	Frames      int                `json:"frames"`
+	Approximate bool               `json:"approximate,omitempty"`
+	Rows        []summaryDetailRow `json:"rows"`
+}
+
+// summaryDetailRow is one row of a summaryDetail, with the aggregateRow
+// metrics in CPU-seconds or %CPU of one core. Instances is set only for a row
+// merging reused PIDs, Alias only for a process with one (see SetAlias), and
+// SinceViewedSeconds only once the summary has been viewed (see
+// MarkSummaryViewed).
+type summaryDetailRow struct {
+	PID                int       `json:"pid"`
+	Command            string    `json:"command"`
+	Alias              string    `json:"alias,omitempty"`
+	StartedAt          time.Time `json:"started_at,omitzero"`
+	Instances          int       `json:"instances,omitempty"`
+	TotalSeconds       float64   `json:"total_seconds"`
+	AverageSeconds     float64   `json:"average_seconds"`
+	AveragePercent     float64   `json:"average_percent"`
+	PeakPercent        float64   `json:"peak_percent"`
+	PeakFrame          int       `json:"peak_frame"`
+	StdDevSeconds      float64   `json:"stddev_seconds"`
+	MinSeconds         float64   `json:"min_seconds"`
+	MaxSeconds         float64   `json:"max_seconds"`
+	Appearances        int       `json:"appearances"`
+	Spiky              bool      `json:"spiky,omitempty"`
+	SinceViewedSeconds *float64  `json:"since_viewed_seconds,omitempty"`
+}
+
+// SummaryJSON returns the summary as a JSON summaryDetail: the same rows as
+// the summary table, with its frame range, minimum total and appearance
+// filters, average mode, reused-PID merging, and sort order, but uncapped and
+// with every metric as a number rather than display text. Commands are full
+// command lines whatever Basename only says. With no completed frames it
+// has no rows.
+func (m *Monitor) SummaryJSON() string {
+	m.mu.Lock()
+	summarized := framesInRange(append([]FrameRecord(nil), m.history...), m.summaryRange)
+	opts := m.summaryOptionsLocked()
+	m.mu.Unlock()
+
+	detail := summaryDetail{
+		AverageMode: "all_frames",
+		Frames:      len(summarized),
+		Approximate: collectionNote(summarized) != "",
+		Rows:        []summaryDetailRow{},
+	}
+	if opts.AverageMode == AverageAppearedFrames {
+		detail.AverageMode = "appeared_frames"
+	}
+	if n := len(summarized); n > 0 {
+		detail.FirstFrame, detail.LastFrame = summarized[0].Index, summarized[n-1].Index
+	}
+
+	rows := summaryRows(summarized, opts.MinTotal, opts.MinFrames, opts.AverageMode, opts.MergeReused)
+	for _, i := range topIndices(len(rows), len(rows), bySummarySort(rows, opts.Sort, !opts.Ascending, opts.Tiebreak)) {
+		row := rows[i]
+		out := summaryDetailRow{
+			PID:            row.PID,
+			Command:        row.Command,
+			Alias:          opts.aliases.label(ResultRow{PID: row.PID, Command: row.Command}),
+			TotalSeconds:   row.Total,
+			AverageSeconds: row.Average,
+			AveragePercent: row.AveragePercent,
+			PeakPercent:    row.PeakPercent,
+			PeakFrame:      row.PeakFrame,
+			StdDevSeconds:  row.StdDev,
+			MinSeconds:     row.Min,
+			MaxSeconds:     row.Max,
+			Appearances:    row.Frames,
+			Spiky:          spiky(row, opts.SpikeRatio),
+		}
+		if row.CreateTime != 0 {
+			out.StartedAt = time.UnixMilli(row.CreateTime).UTC()
+		}
+		if row.Instances > 1 {
+			out.Instances = row.Instances
+		}
+		if opts.viewed != nil {
+			growth := row.Total - opts.viewed[row.key]
+			out.SinceViewedSeconds = &growth
+		}
+		detail.Rows = append(detail.Rows, out)
+	}
+
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// topProcess is the busiest row of the current-frame table, as returned by
+// TopProcessJSON.
+type topProcess struct {
+	PID        int     `json:"pid"`
+	Command    string  `json:"command"`
+	CPUSeconds float64 `json:"cpu"`
+	Percent    float64 `json:"percent"`
+}
+
+// TopProcessJSON returns the row of the current view that used the most CPU
+// as a JSON topProcess, for a widget that shows only the busiest process:
+// its CPU-seconds and %CPU of one core over the frame so far, or over a
+// completed frame's measured length. The row is picked among those the table
+// shows, with its filters, hide-small threshold, and grouping applied, but
+// without rendering the table; against a reference frame it is still the
+// busiest by its own CPU. Commands are full command lines, and a group's is
+// its application's. With no row to show it returns "{}".
+func (m *Monitor) TopProcessJSON() string {
+	m.mu.Lock()
+	rows := m.currentRowsLocked()
+	opts := m.tableOptionsLocked()
+	frame, _ := m.displayedFrameLocked()
+	m.mu.Unlock()
+
+	opts.Reference = nil
+	var top groupedRow
+	found := false
+	for _, row := range displayedRows(rows, opts) {
+		if !found || row.Diff > top.Diff {
+			top, found = row, true
+		}
+	}
+	if !found {
+		return "{}"
+	}
+	data, err := json.Marshal(topProcess{
+		PID:        top.PID,
+		Command:    top.Command,
+		CPUSeconds: top.Diff,
+		Percent:    framePercent(top.Diff, frame.Duration),
+	})
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// historyJSONLimit caps the size of HistoryJSON's output. A full history of
+// maxHistory frames of maxRows rows would be tens of megabytes, more than a
+// caller wants in one string; files from ExportHistory have no limit.
+const historyJSONLimit = 8 << 20
+
+// HistoryJSON returns every completed frame, followed by the in-progress one
+// while a run is active, as a JSON array of the frame objects ExportHistory
+// writes, without the round trip through a file. If the frames exceed
+// historyJSONLimit bytes, the oldest are left out; the first frame's index
+// shows where the array starts. With no frames it returns "[]".
+func (m *Monitor) HistoryJSON() string {
+	m.mu.Lock()
+	frames := append([]FrameRecord(nil), m.history...)
+	if live, ok := m.liveFrameLocked(); ok {
+		frames = append(frames, live)
+	}
+	m.mu.Unlock()
+
+	return historyJSON(frames, historyJSONLimit)
+}
+
+// historyJSON encodes the newest of frames that fit in limit bytes as a JSON
+// array of exportFrames.
+func historyJSON(frames []FrameRecord, limit int) string {
+	encoded := make([][]byte, 0, len(frames))
+	size := len("[]")
+	for i := len(frames) - 1; i >= 0; i-- {
+		data, err := json.Marshal(newExportFrame(frames[i]))
+		if err != nil {
+			break
+		}
+		next := size + len(data)
+		if len(encoded) > 0 {
+			next++ // the comma before it
+		}
+		if next > limit {
+			break
+		}
+		encoded = append(encoded, data)
+		size = next
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteByte('[')
+	for i := len(encoded) - 1; i >= 0; i-- {
+		b.Write(encoded[i])
+		if i > 0 {
+			b.WriteByte(',')
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// rowFlags returns the frameDetailRow flags that apply to row.
+func rowFlags(row ResultRow) []string {
+	var flags []string
+	switch statusMarker(row.Status) {
+	case "Z":
+		flags = append(flags, "zombie")
+	case "T":
+		flags = append(flags, "stopped")
+	}
+	if row.ExecCommand != "" {
+		flags = append(flags, "exec")
+	}
+	return flags
+}
+
+// exportCSV writes one CSV record per process per frame: the frame number and
+// its RFC 3339 start and end times (end empty while in progress), followed by
+// the CSVFormatter columns.
+func exportCSV(frames []FrameRecord) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(append([]string{"frame", "started_at", "ended_at"}, csvHeader...))
+	for _, frame := range frames {
+		lead := []string{strconv.Itoa(frame.Index), formatTimestamp(frame.StartedAt), formatTimestamp(frame.EndedAt)}
+		for _, r := range tableRecords(frame.Rows, TableOptions{}) {
+			w.Write(append(append([]string(nil), lead...), csvFields(r)...))
+		}
+	}
+	w.Flush()
+	return b.String()
+}
+
+// formatTimestamp formats t as RFC 3339 with millisecond precision, or "" for
+// the zero time.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// timelinePoint is one frame of a single process's timeline. CPU is 0 when
+// the process was absent from the frame.
+type timelinePoint struct {
+	Frame   FrameRecord
+	CPU     float64
+	Present bool
+}
+
+// pidTimeline returns pid's CPU in every frame of history, in order, and the
+// command it was first recorded with ("" if it never appeared).
+func pidTimeline(history []FrameRecord, pid int) ([]timelinePoint, string) {
+	points := make([]timelinePoint, len(history))
+	command := ""
+	for i, frame := range history {
+		points[i].Frame = frame
+		for _, row := range frame.Rows {
+			if row.PID != pid {
+				continue
+			}
+			points[i].CPU = row.Diff
+			points[i].Present = true
+			if command == "" {
+				command = row.Command
+			}
+			break
+		}
+	}
+	return points, command
+}
+
+// ExportPidTimeline writes pid's CPU in every completed frame to path as CSV
+// with the columns frame_index, started_at, ended_at, cpu_seconds, and
+// percent (of one core over the frame's duration). The first line is a
+// "# pid N: command" comment. Frames the process was absent from are written
+// with 0 CPU if includeAbsent is set and skipped otherwise. It is an error
+// for pid not to appear in any completed frame. Failures are reported via
+// postError and returned; on success the status line names the file.
+func (m *Monitor) ExportPidTimeline(pid int, path string, includeAbsent bool) error {
+	m.mu.Lock()
+	history := append([]FrameRecord(nil), m.history...)
+	m.mu.Unlock()
+
+	var err error
+	points, command := pidTimeline(history, pid)
+	if command == "" {
+		err = fmt.Errorf("PID %d does not appear in any completed frame", pid)
+	} else {
+		err = os.WriteFile(path, []byte(exportTimelineCSV(pid, command, points, includeAbsent)), 0644)
+	}
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Timeline export failed: %v", err))
+		return err
+	}
+
+	m.mu.Lock()
+	m.status = fmt.Sprintf("Timeline of PID %d exported to %s.", pid, path)
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
+
+// timelineHeader names the columns written by exportTimelineCSV.
+var timelineHeader = []string{"frame_index", "started_at", "ended_at", "cpu_seconds", "percent"}
+
+// exportTimelineCSV renders points as the CSV document described at
+// ExportPidTimeline. Line breaks in command are replaced so the comment stays
+// on one line.
+func exportTimelineCSV(pid int, command string, points []timelinePoint, includeAbsent bool) string {
+	var b strings.Builder
+	command = strings.NewReplacer("\r", " ", "\n", " ").Replace(command)
+	fmt.Fprintf(&b, "# pid %d: %s\n", pid, command)
+	w := csv.NewWriter(&b)
+	w.Write(timelineHeader)
+	for _, p := range points {
+		if !p.Present && !includeAbsent {
+			continue
+		}
+		w.Write([]string{
+			strconv.Itoa(p.Frame.Index),
+			formatTimestamp(p.Frame.StartedAt),
+			formatTimestamp(p.Frame.EndedAt),
+			strconv.FormatFloat(p.CPU, 'f', -1, 64),
+			strconv.FormatFloat(framePercent(p.CPU, p.Frame.Duration), 'f', 1, 64),
+		})
+	}
+	w.Flush()
+	return b.String()
+}
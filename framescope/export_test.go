@@ -0,0 +1,488 @@
+package framescope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// newExportMonitor returns a running monitor with one completed frame and a
+// live frame in progress, driven by a fake clock.
+func newExportMonitor(t *testing.T) (*Monitor, time.Time) {
+	t.Helper()
+	m, _ := newTestMonitor(t)
+	start := time.Date(2026, 3, 4, 5, 6, 7, 250*int(time.Millisecond), time.FixedZone("CET", 3600))
+	clock := &fakeClock{now: start.Add(20 * time.Second)}
+	m.clock = clock.Now
+	m.running = true
+	m.frameIndex = 2
+	m.frameStart = start.Add(15 * time.Second)
+	m.liveRows = []ResultRow{{PID: 3, Diff: 0.5, Command: "/bin/live"}}
+	m.history = []FrameRecord{{
+		Index:     1,
+		Rows:      []ResultRow{{PID: 1, Diff: 4, Command: "/bin/a, b"}},
+		Duration:  15 * time.Second,
+		StartedAt: start,
+		EndedAt:   start.Add(15 * time.Second),
+	}}
+	return m, start
+}
+
+func TestExportHistoryJSONRoundTripsTimestamps(t *testing.T) {
+	m, start := newExportMonitor(t)
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := m.ExportHistory(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc exportDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	if len(doc.Frames) != 2 {
+		t.Fatalf("got %d frames, want completed + live", len(doc.Frames))
+	}
+
+	done, live := doc.Frames[0], doc.Frames[1]
+	if !done.StartedAt.Equal(start) || !done.EndedAt.Equal(start.Add(15*time.Second)) || done.InProgress {
+		t.Errorf("completed frame = %+v", done)
+	}
+	if !live.StartedAt.Equal(start.Add(15*time.Second)) || !live.EndedAt.IsZero() || !live.InProgress {
+		t.Errorf("live frame = %+v", live)
+	}
+	if live.DurationSeconds != 5 {
+		t.Errorf("live duration = %v, want 5 (from the clock)", live.DurationSeconds)
+	}
+
+	var rows []jsonRow
+	if err := json.Unmarshal(done.Rows, &rows); err != nil || len(rows) != 1 || rows[0].Command != "/bin/a, b" {
+		t.Errorf("rows = %s (%v)", done.Rows, err)
+	}
+}
+
+func TestHistoryJSON(t *testing.T) {
+	m, start := newExportMonitor(t)
+
+	var frames []exportFrame
+	if err := json.Unmarshal([]byte(m.HistoryJSON()), &frames); err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Index != 1 || frames[1].Index != 2 {
+		t.Fatalf("got frames %+v, want 1 and the live 2", frames)
+	}
+	if !frames[0].StartedAt.Equal(start) || frames[0].InProgress || !frames[1].InProgress {
+		t.Errorf("frames = %+v", frames)
+	}
+	var rows []jsonRow
+	if err := json.Unmarshal(frames[1].Rows, &rows); err != nil || len(rows) != 1 || rows[0].Command != "/bin/live" {
+		t.Errorf("live rows = %s (%v)", frames[1].Rows, err)
+	}
+
+	// The array follows the history as it changes.
+	m.mu.Lock()
+	m.running = false
+	m.history = append(m.history, FrameRecord{Index: 2, Duration: 15 * time.Second, StartedAt: start.Add(15 * time.Second), EndedAt: start.Add(30 * time.Second)})
+	m.mu.Unlock()
+	frames = nil
+	if err := json.Unmarshal([]byte(m.HistoryJSON()), &frames); err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[1].Index != 2 || frames[1].InProgress {
+		t.Errorf("after the run = %+v, want frames 1 and 2 completed", frames)
+	}
+
+	m.mu.Lock()
+	m.history = nil
+	m.mu.Unlock()
+	if got := m.HistoryJSON(); got != "[]" {
+		t.Errorf("empty history = %s, want []", got)
+	}
+}
+
+func TestHistoryJSONKeepsNewestWithinLimit(t *testing.T) {
+	var history []FrameRecord
+	for i := 1; i <= 5; i++ {
+		history = append(history, FrameRecord{Index: i, Rows: []ResultRow{{PID: i, Diff: 1, Command: "/bin/worker"}}})
+	}
+	full := historyJSON(history, historyJSONLimit)
+	one := historyJSON(history[4:], historyJSONLimit)
+
+	// Room for two frames and a bit keeps the newest two.
+	var frames []exportFrame
+	if err := json.Unmarshal([]byte(historyJSON(history, 2*len(one))), &frames); err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Index != 4 || frames[1].Index != 5 {
+		t.Errorf("capped frames = %+v, want 4 and 5", frames)
+	}
+	if got := historyJSON(history, len(full)); got != full {
+		t.Errorf("limit of exactly the full size dropped frames: %s", got)
+	}
+	if got := historyJSON(history, 4); got != "[]" {
+		t.Errorf("limit below one frame = %s, want []", got)
+	}
+}
+
+func TestExportHistoryCSV(t *testing.T) {
+	m, _ := newExportMonitor(t)
+	path := filepath.Join(t.TempDir(), "history.csv")
+	if err := m.ExportHistory(path); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want header + 2: %q", len(records), records)
+	}
+	if got := records[1][:4]; got[0] != "1" || got[1] != "2026-03-04T05:06:07.250+01:00" ||
+		got[2] != "2026-03-04T05:06:22.250+01:00" || got[3] != "1" {
+		t.Errorf("completed record = %q", records[1])
+	}
+	if got := records[2]; got[0] != "2" || got[2] != "" || got[len(got)-1] != "/bin/live" {
+		t.Errorf("live record = %q", records[2])
+	}
+}
+
+func TestExportHistoryRejectsUnknownExtension(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	if err := m.ExportHistory(filepath.Join(t.TempDir(), "history.txt")); err == nil {
+		t.Fatal("export to .txt succeeded")
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 1 {
+		t.Errorf("errors = %v, want one", errs)
+	}
+}
+
+func TestExportFrameWritesDisplayedFrame(t *testing.T) {
+	m, _ := newExportMonitor(t)
+	dir := t.TempDir()
+
+	// The live frame is on display until a completed one is selected.
+	m.viewingCurrent = true
+	live := filepath.Join(dir, "live.ndjson")
+	if err := m.ExportFrame(live); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 ||
+		!strings.Contains(lines[0], `"index":2`) || !strings.Contains(lines[0], `"in_progress":true`) {
+		t.Errorf("live export = %s", data)
+	}
+
+	m.SelectFrame(0)
+	done := filepath.Join(dir, "frame.json")
+	if err := m.ExportFrame(done); err != nil {
+		t.Fatal(err)
+	}
+	frames, err := readSession(done)
+	if err != nil || len(frames) != 1 || frames[0].Index != 1 || frames[0].Rows[0].Command != "/bin/a, b" {
+		t.Errorf("completed export = %+v (%v)", frames, err)
+	}
+	m.mu.Lock()
+	status := m.status
+	m.mu.Unlock()
+	if !strings.Contains(status, "Frame 1 exported") {
+		t.Errorf("status = %q", status)
+	}
+}
+
+func TestExportFrameWithoutFrame(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	if err := m.ExportFrame(filepath.Join(t.TempDir(), "frame.csv")); err == nil {
+		t.Fatal("export without a frame succeeded")
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 1 {
+		t.Errorf("errors = %v, want one", errs)
+	}
+}
+
+// TestScriptedActionsNeedNoUI drives start, export, and stop the way the
+// AppleScript commands do, with no UI attached, from another goroutine than
+// the one that created the monitor.
+func TestScriptedActionsNeedNoUI(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	m := NewMonitor(DiscardSink{})
+	m.SetProcessSource(&busySource{})
+
+	errs := make(chan error, 1)
+	go func() {
+		if err := m.Start(0); !errors.Is(err, errInvalidFrameLength) {
+			errs <- fmt.Errorf("Start(0) = %v", err)
+			return
+		}
+		if err := m.Start(60); err != nil {
+			errs <- err
+			return
+		}
+		path := filepath.Join(dir, "frame.csv")
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+			if err := m.ExportFrame(path); err == nil {
+				break
+			} else if time.Now().After(deadline) {
+				errs <- err
+				return
+			}
+		}
+		m.Stop()
+		errs <- nil
+	}()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if m.Running() {
+		t.Error("still running after Stop")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "frame.csv")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExportPidTimelineRoundTripsSparseTimeline(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	start := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	for i, rows := range [][]ResultRow{
+		{{PID: 7, Diff: 5, Command: "/bin/worker --batch"}},
+		{{PID: 8, Diff: 1, Command: "/bin/other"}},
+		{{PID: 8, Diff: 2, Command: "/bin/other"}, {PID: 7, Diff: 2.5, Command: "/bin/worker --batch"}},
+	} {
+		m.history = append(m.history, FrameRecord{
+			Index:     i + 1,
+			Rows:      rows,
+			Duration:  10 * time.Second,
+			StartedAt: start.Add(time.Duration(i) * 10 * time.Second),
+			EndedAt:   start.Add(time.Duration(i+1) * 10 * time.Second),
+		})
+	}
+
+	read := func(includeAbsent bool) [][]string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "timeline.csv")
+		if err := m.ExportPidTimeline(7, path, includeAbsent); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), "# pid 7: /bin/worker --batch\n") {
+			t.Errorf("export does not start with the command comment:\n%s", data)
+		}
+		r := csv.NewReader(strings.NewReader(string(data)))
+		r.Comment = '#'
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+
+	want := [][]string{
+		{"frame_index", "started_at", "ended_at", "cpu_seconds", "percent"},
+		{"1", "2026-03-04T05:06:07.000Z", "2026-03-04T05:06:17.000Z", "5", "50.0"},
+		{"2", "2026-03-04T05:06:17.000Z", "2026-03-04T05:06:27.000Z", "0", "0.0"},
+		{"3", "2026-03-04T05:06:27.000Z", "2026-03-04T05:06:37.000Z", "2.5", "25.0"},
+	}
+	if got := read(true); !reflect.DeepEqual(got, want) {
+		t.Errorf("with absent frames:\n got %q\nwant %q", got, want)
+	}
+	if got, want := read(false), [][]string{want[0], want[1], want[3]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("present frames only:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestExportPidTimelineUnknownPID(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 4, Command: "/bin/a"}}, Duration: time.Second}}
+	m.running = true
+	m.liveRows = []ResultRow{{PID: 3, Diff: 0.5, Command: "/bin/live"}}
+	path := filepath.Join(t.TempDir(), "timeline.csv")
+	// PID 3 is only in the live frame, which the timeline does not cover.
+	if err := m.ExportPidTimeline(3, path, true); err == nil {
+		t.Fatal("export of a PID without completed frames succeeded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file was written for an unknown PID (%v)", err)
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 1 {
+		t.Errorf("errors = %v, want one", errs)
+	}
+}
+
+func TestFrameJSON(t *testing.T) {
+	m, start := newExportMonitor(t)
+	m.history[0].Rows = []ResultRow{
+		{PID: 1, Diff: 3, Command: "/bin/a", Status: process.Zombie},
+		{PID: 2, Diff: 1, Command: "/bin/sh", ExecCommand: "/bin/b", User: "root"},
+	}
+
+	var done frameDetail
+	if err := json.Unmarshal([]byte(m.FrameJSON(1)), &done); err != nil {
+		t.Fatal(err)
+	}
+	if done.Index != 1 || done.InProgress || !done.StartedAt.Equal(start) || len(done.Rows) != 2 {
+		t.Fatalf("frame 1 = %+v", done)
+	}
+	want := frameDetailRow{PID: 1, CPUSeconds: 3, Percent: 20, SharePercent: 75, Command: "/bin/a", Flags: []string{"zombie"}}
+	if !reflect.DeepEqual(done.Rows[0], want) {
+		t.Errorf("row 0 = %+v, want %+v", done.Rows[0], want)
+	}
+	if row := done.Rows[1]; row.User != "root" || row.ExecCommand != "/bin/b" || !reflect.DeepEqual(row.Flags, []string{"exec"}) {
+		t.Errorf("row 1 = %+v", row)
+	}
+
+	if got, want := done.Rows[1].Commands, []string{"/bin/sh", "/bin/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("row 1 commands = %q, want %q", got, want)
+	}
+	if done.Rows[0].Commands != nil {
+		t.Errorf("row 0 commands = %q, want none for a single command", done.Rows[0].Commands)
+	}
+
+	var live frameDetail
+	if err := json.Unmarshal([]byte(m.FrameJSON(2)), &live); err != nil {
+		t.Fatal(err)
+	}
+	if live.Index != 2 || !live.InProgress || live.DurationSeconds != 5 || len(live.Rows) != 1 || live.Rows[0].PID != 3 {
+		t.Errorf("live frame = %+v", live)
+	}
+
+	// Frame 1 falls out of the history once newer frames push it out.
+	m.history = []FrameRecord{{Index: 2, Duration: time.Second}}
+	m.frameIndex = 3
+	for _, index := range []int{1, 0, 4} {
+		if got := m.FrameJSON(index); got != "{}" {
+			t.Errorf("FrameJSON(%d) = %s, want {}", index, got)
+		}
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.history = []FrameRecord{
+		{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 1, Diff: 2, Command: "/bin/build", CreateTime: 1700000000000}, {PID: 2, Diff: 0.5, Command: "/bin/idle"}}},
+		{Index: 2, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 1, Diff: 6, Command: "/bin/build", CreateTime: 1700000000000}}},
+		{Index: 3, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 1, Diff: 4, Command: "/bin/build", CreateTime: 1700000000000}, {PID: 3, Diff: 1, Command: "/bin/test"}}},
+	}
+	m.SetAlias("build", "Builder")
+
+	var got summaryDetail
+	if err := json.Unmarshal([]byte(m.SummaryJSON()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.AverageMode != "all_frames" || got.Frames != 3 || got.FirstFrame != 1 || got.LastFrame != 3 || got.Approximate {
+		t.Errorf("summary = %+v", got)
+	}
+	// The default minimum total of 1 CPU-second leaves out /bin/idle.
+	if len(got.Rows) != 2 || got.Rows[0].PID != 1 || got.Rows[1].PID != 3 {
+		t.Fatalf("rows = %+v, want PIDs 1 and 3", got.Rows)
+	}
+	build := got.Rows[0]
+	want := summaryDetailRow{
+		PID:            1,
+		Command:        "/bin/build",
+		Alias:          "Builder",
+		StartedAt:      time.UnixMilli(1700000000000).UTC(),
+		TotalSeconds:   12,
+		AverageSeconds: 4,
+		AveragePercent: 40,
+		PeakPercent:    60,
+		PeakFrame:      2,
+		StdDevSeconds:  math.Sqrt(8.0 / 3),
+		MinSeconds:     2,
+		MaxSeconds:     6,
+		Appearances:    3,
+	}
+	if math.Abs(build.StdDevSeconds-want.StdDevSeconds) < 1e-9 {
+		build.StdDevSeconds = want.StdDevSeconds
+	}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("build row = %+v\nwant %+v", build, want)
+	}
+
+	// The summary's range, filters, average mode, and order apply.
+	m.SetHistoryRange(1, 2)
+	m.SetSummaryMinTotal(0)
+	m.SetAverageMode(AverageAppearedFrames)
+	m.SetSummarySort(SummarySortPID, false)
+	got = summaryDetail{}
+	if err := json.Unmarshal([]byte(m.SummaryJSON()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.AverageMode != "appeared_frames" || got.Frames != 2 || got.FirstFrame != 2 || len(got.Rows) != 2 {
+		t.Fatalf("ranged summary = %+v", got)
+	}
+	if got.Rows[0].PID != 1 || got.Rows[0].AverageSeconds != 5 || got.Rows[1].PID != 3 || got.Rows[1].AverageSeconds != 1 {
+		t.Errorf("ranged rows = %+v", got.Rows)
+	}
+
+	empty := NewMonitor(DiscardSink{})
+	if got := empty.SummaryJSON(); got != `{"average_mode":"all_frames","frames":0,"rows":[]}` {
+		t.Errorf("empty summary = %s", got)
+	}
+}
+
+func TestTopProcessJSON(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	if got := m.TopProcessJSON(); got != "{}" {
+		t.Errorf("TopProcessJSON() = %s with no frames, want {}", got)
+	}
+
+	m.history = []FrameRecord{{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{
+		{PID: 1, Diff: 8, Command: "/usr/bin/make -j8"},
+		{PID: 2, Diff: 5, Command: "/usr/bin/cc main.c"},
+		{PID: 3, Diff: 0.5, Command: "/usr/bin/ld"},
+	}}}
+	m.selectedHistoryIdx = 0
+	top := func() topProcess {
+		t.Helper()
+		var got topProcess
+		if err := json.Unmarshal([]byte(m.TopProcessJSON()), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got, want := top(), (topProcess{PID: 1, Command: "/usr/bin/make -j8", CPUSeconds: 8, Percent: 80}); got != want {
+		t.Errorf("top = %+v, want %+v", got, want)
+	}
+	// Excluding make leaves cc on top, as in the table.
+	m.AddPattern(ExcludeList, "make")
+	if got := top(); got.PID != 2 || got.CPUSeconds != 5 || got.Percent != 50 {
+		t.Errorf("top with make excluded = %+v, want cc", got)
+	}
+	// Only ld is left, and Hide <1s hides it.
+	m.AddPattern(IncludeList, "ld")
+	if got := m.TopProcessJSON(); got != "{}" {
+		t.Errorf("TopProcessJSON() = %s with every row filtered out, want {}", got)
+	}
+	m.SetHideSmall(false)
+	if got := top(); got.PID != 3 {
+		t.Errorf("top with small rows shown = %+v, want ld", got)
+	}
+}
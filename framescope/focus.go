@@ -0,0 +1,124 @@
+package framescope
+
+import "fmt"
+
+// focusTree is the process subtree the monitor is focused on (see
+// SetFocusPID). members is replaced, never modified in place.
+type focusTree struct {
+	root int
+
+	// createTime is the root's start time, read from the first snapshot
+	// after focus was set, so that another process given its PID after it
+	// exits is not taken for it; 0 until then or if unknown.
+	createTime int64
+
+	// members are the PIDs of the subtree in the last snapshot, with their
+	// start times.
+	members map[int]int64
+}
+
+// focusSubtree returns the PIDs in samples that descend from root, root
+// included, with their start times. The set is recomputed from the PPIDs of
+// every snapshot, since children come and go, but a process that was a
+// member in previous, the last snapshot's set, stays one, with its own
+// descendants, while it runs: a child whose parent exits is reparented to
+// launchd and would otherwise drop out of the subtree.
+func focusSubtree(samples map[int]ProcessSample, root int, previous map[int]int64) map[int]int64 {
+	children := make(map[int][]int)
+	for pid, sample := range samples {
+		if pid != sample.PPID {
+			children[sample.PPID] = append(children[sample.PPID], pid)
+		}
+	}
+
+	members := make(map[int]int64)
+	queue := []int{root}
+	for pid, createTime := range previous {
+		if sample, ok := samples[pid]; ok && !reusedPID(ProcessSample{CreateTime: createTime}, sample) {
+			queue = append(queue, pid)
+		}
+	}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		sample, ok := samples[pid]
+		if _, seen := members[pid]; seen || !ok {
+			continue
+		}
+		members[pid] = sample.CreateTime
+		queue = append(queue, children[pid]...)
+	}
+	return members
+}
+
+// applyFocus drops every sample outside the focused subtree from samples, if
+// focus is set. If the root has exited, or its PID has been reused, focus
+// ends instead and every sample is kept; focusNote says so in the status bar
+// until the frame in progress completes.
+func (m *Monitor) applyFocus(samples map[int]ProcessSample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	focus := m.focus
+	if focus == nil {
+		return
+	}
+	root, ok := samples[focus.root]
+	if !ok || reusedPID(ProcessSample{CreateTime: focus.createTime}, root) {
+		m.focus = nil
+		m.focusNote = fmt.Sprintf("focused PID %d is gone; showing all processes", focus.root)
+		return
+	}
+
+	members := focusSubtree(samples, focus.root, focus.members)
+	for pid := range samples {
+		if _, ok := members[pid]; !ok {
+			delete(samples, pid)
+		}
+	}
+	m.focus = &focusTree{root: focus.root, createTime: root.CreateTime, members: members}
+}
+
+// SetFocusPID restricts monitoring to the process pid and its descendants,
+// such as a build tool and everything it spawns: from the next snapshot on,
+// every other process is left out of the snapshots, so the tables, the
+// summary of the frames that follow, and the exports reflect only the
+// subtree. Frames completed before keep every process. When the process
+// exits, focus ends and every process is monitored again, with a note in the
+// status bar. A pid that cannot name a process (see checkPID) is refused
+// with a note in the status bar. Focus lasts for this session only, since
+// the PID may name another process after a restart.
+func (m *Monitor) SetFocusPID(pid int) {
+	if err := checkPID(pid); err != nil {
+		m.mu.Lock()
+		m.status = fmt.Sprintf("Focus failed: %v.", err)
+		m.mu.Unlock()
+		m.pushUI(0)
+		return
+	}
+	m.mu.Lock()
+	m.focus = &focusTree{root: pid}
+	m.focusNote = ""
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// ClearFocusPID ends the focus set with SetFocusPID, so every process is
+// monitored again from the next snapshot on.
+func (m *Monitor) ClearFocusPID() {
+	m.mu.Lock()
+	m.focus = nil
+	m.focusNote = ""
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// FocusPID returns the PID set with SetFocusPID, and false if monitoring is
+// not focused, including after the focused process has exited.
+func (m *Monitor) FocusPID() (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.focus == nil {
+		return 0, false
+	}
+	return m.focus.root, true
+}
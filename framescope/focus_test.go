@@ -0,0 +1,115 @@
+package framescope
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// focusTreeSamples is a synthetic process tree:
+//
+//	1 launchd
+//	├── 10 make
+//	│   ├── 11 cc
+//	│   │   └── 12 ld
+//	│   └── 13 sh
+//	└── 20 editor
+//	    └── 21 helper
+func focusTreeSamples() map[int]ProcessSample {
+	return map[int]ProcessSample{
+		1:  {PPID: 0, CreateTime: 1, Command: "/sbin/launchd"},
+		10: {PPID: 1, CreateTime: 10, Command: "/usr/bin/make"},
+		11: {PPID: 10, CreateTime: 11, Command: "/usr/bin/cc"},
+		12: {PPID: 11, CreateTime: 12, Command: "/usr/bin/ld"},
+		13: {PPID: 10, CreateTime: 13, Command: "/bin/sh"},
+		20: {PPID: 1, CreateTime: 20, Command: "/bin/editor"},
+		21: {PPID: 20, CreateTime: 21, Command: "/bin/helper"},
+	}
+}
+
+func samplePIDs(samples map[int]ProcessSample) []int {
+	pids := make([]int, 0, len(samples))
+	for pid := range samples {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+func TestFocusKeepsSubtree(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetFocusPID(10)
+
+	samples := focusTreeSamples()
+	m.applyFocus(samples)
+	if got := samplePIDs(samples); !equalInts(got, []int{10, 11, 12, 13}) {
+		t.Errorf("focused snapshot = %v, want make and its descendants", got)
+	}
+
+	// cc exits, orphaning ld to launchd, and make spawns another child:
+	// both stay in the subtree, and the editor stays out.
+	samples = focusTreeSamples()
+	delete(samples, 11)
+	samples[12] = ProcessSample{PPID: 1, CreateTime: 12, Command: "/usr/bin/ld"}
+	samples[14] = ProcessSample{PPID: 10, CreateTime: 14, Command: "/usr/bin/as"}
+	m.applyFocus(samples)
+	if got := samplePIDs(samples); !equalInts(got, []int{10, 12, 13, 14}) {
+		t.Errorf("next snapshot = %v, want the orphan and the new child kept", got)
+	}
+	if pid, ok := m.FocusPID(); !ok || pid != 10 {
+		t.Errorf("FocusPID() = %d, %v; want 10", pid, ok)
+	}
+
+	m.mu.Lock()
+	status := m.buildStatusLocked(10, m.now(), m.now(), nil)
+	m.mu.Unlock()
+	if !strings.Contains(status, "focused on PID 10 and 3 descendants") {
+		t.Errorf("status = %q, want the focus noted", status)
+	}
+
+	m.ClearFocusPID()
+	samples = focusTreeSamples()
+	m.applyFocus(samples)
+	if len(samples) != 7 {
+		t.Errorf("snapshot after ClearFocusPID has %d processes, want all 7", len(samples))
+	}
+}
+
+func TestFocusEndsWhenRootExits(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetFocusPID(20)
+	samples := focusTreeSamples()
+	m.applyFocus(samples)
+	if got := samplePIDs(samples); !equalInts(got, []int{20, 21}) {
+		t.Fatalf("focused snapshot = %v, want the editor and its helper", got)
+	}
+
+	// The editor exits and an unrelated process takes its PID.
+	samples = focusTreeSamples()
+	samples[20] = ProcessSample{PPID: 1, CreateTime: 99, Command: "/bin/other"}
+	m.applyFocus(samples)
+	if len(samples) != 7 {
+		t.Errorf("snapshot after the root exited has %d processes, want all 7", len(samples))
+	}
+	if _, ok := m.FocusPID(); ok {
+		t.Error("focus did not end when its root exited")
+	}
+	m.mu.Lock()
+	note := m.focusNote
+	m.mu.Unlock()
+	if note != "focused PID 20 is gone; showing all processes" {
+		t.Errorf("status note = %q", note)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
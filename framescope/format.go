@@ -0,0 +1,151 @@
+package framescope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// TableRecord is one current-frame row after filtering, ready to be written
+// in any output format.
+type TableRecord struct {
+	PID        int
+	CPU        float64 // CPU-seconds consumed during the frame
+	HasPercent bool    // Percent is meaningful (TableOptions.ShowPercent was set)
+	Percent    float64 // CPU as %CPU of one core over the frame (see framePercent)
+	HasUserSys bool    // User and System are meaningful (TableOptions.ShowUserSys was set)
+	User       float64 // CPU-seconds spent in userland
+	System     float64 // CPU-seconds spent in the kernel
+	HasShare   bool    // Share is meaningful (TableOptions.ShowShare was set)
+	Share      float64 // percentage of the frame's total CPU
+	Trend      string  // sparkline, or empty
+	Command    string  // command line, already reduced to a basename if requested
+	Depth      int     // 1 for a process listed under its app's group row, else 0
+	Status     string  // "Z" (zombie), "T" (stopped), or empty (see statusMarker)
+	Exec       string  // command exec'd into during the frame (ResultRow.ExecCommand), or empty
+	HasRate    bool    // Rate is meaningful (TableOptions.Rates was set)
+	Rate       float64 // %CPU of one core over the last tick, live frame only
+	Delta      string  // change versus the last completed frame (see formatDelta), live frame only
+}
+
+// RowFormatter turns rendered current-frame rows into one output payload.
+// Formatters only encode; filtering and the row cap live in RenderTable.
+type RowFormatter interface {
+	FormatRows(records []TableRecord) string
+}
+
+// TabFormatter writes the tab-separated payload consumed by the Cocoa table
+// view, one line per row. By default the columns are FrameColumnNames:
+//
+//	PID \t CPU-seconds \t HH:MM:SS \t share \t trend \t status \t command \t
+//	rate \t %CPU \t user \t sys \t delta
+//
+// Columns, if it names any of FrameColumnNames, lists the columns to write
+// instead, in order; other names are skipped. rate, e.g. "87.5%", is empty
+// unless rates were requested, %CPU unless TableOptions.ShowPercent was set,
+// user and sys unless TableOptions.ShowUserSys was set, and delta unless
+// TableOptions.LivePrevious was; they come last by default so that payloads
+// without them keep their layout. Tabs and newlines in commands are replaced by
+// spaces via sanitizeCommand. Records below a group row (Depth 1) have their
+// command indented with "↳". A process that exec'd during the frame shows both
+// commands, "old ⇢ new".
+type TabFormatter struct {
+	Columns []string
+
+	// MarkTiny writes positive CPU-seconds that round to 0.0 as "<0.1" (see
+	// SetMarkTinyValues).
+	MarkTiny bool
+}
+
+func (f TabFormatter) FormatRows(records []TableRecord) string {
+	columns := columnsOrDefault(selectColumns(f.Columns, FrameColumnNames), FrameColumnNames)
+	var b strings.Builder
+	for _, r := range records {
+		writeColumns(&b, columns, func(name string) string { return frameColumn(r, name, f.MarkTiny) })
+	}
+	return b.String()
+}
+
+// CSVFormatter writes RFC 4180 CSV with a header row (csvHeader). Numbers are
+// written at full precision; share_percent is empty when shares were not
+// requested.
+type CSVFormatter struct{}
+
+// csvHeader names the columns written by csvFields.
+var csvHeader = []string{"pid", "cpu_seconds", "duration", "share_percent", "trend", "status", "exec_command", "command"}
+
+func (CSVFormatter) FormatRows(records []TableRecord) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(csvHeader)
+	for _, r := range records {
+		w.Write(csvFields(r))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// csvFields returns the CSV columns for one record, in csvHeader order.
+func csvFields(r TableRecord) []string {
+	share := ""
+	if r.HasShare {
+		share = strconv.FormatFloat(r.Share, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(r.PID),
+		strconv.FormatFloat(r.CPU, 'f', -1, 64),
+		FormatDuration(r.CPU),
+		share,
+		r.Trend,
+		r.Status,
+		r.Exec,
+		r.Command,
+	}
+}
+
+// jsonRow is the JSON encoding of a TableRecord.
+type jsonRow struct {
+	PID          int      `json:"pid"`
+	CPUSeconds   float64  `json:"cpu_seconds"`
+	Duration     string   `json:"duration"`
+	SharePercent *float64 `json:"share_percent,omitempty"`
+	UserSeconds  *float64 `json:"user_seconds,omitempty"`
+	SysSeconds   *float64 `json:"system_seconds,omitempty"`
+	Trend        string   `json:"trend,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	ExecCommand  string   `json:"exec_command,omitempty"`
+	Command      string   `json:"command"`
+}
+
+// JSONFormatter writes a JSON array of row objects (see jsonRow). An empty
+// input produces "[]".
+type JSONFormatter struct{}
+
+func (JSONFormatter) FormatRows(records []TableRecord) string {
+	rows := make([]jsonRow, len(records))
+	for i, r := range records {
+		rows[i] = jsonRow{
+			PID:         r.PID,
+			CPUSeconds:  r.CPU,
+			Duration:    FormatDuration(r.CPU),
+			Trend:       r.Trend,
+			Status:      r.Status,
+			ExecCommand: r.Exec,
+			Command:     r.Command,
+		}
+		if r.HasShare {
+			share := r.Share
+			rows[i].SharePercent = &share
+		}
+		if r.HasUserSys {
+			user, system := r.User, r.System
+			rows[i].UserSeconds, rows[i].SysSeconds = &user, &system
+		}
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
@@ -0,0 +1,104 @@
+package framescope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// formatterRows is the shared input for the formatter tests: one row hidden by
+// the small-process filter and one command containing a comma and a tab.
+var formatterRows = []ResultRow{
+	{PID: 7, Diff: 3, Command: "/usr/bin/make -j8, all\tquiet"},
+	{PID: 8, Diff: 1, Command: "/bin/sh"},
+	{PID: 9, Diff: 0, Command: "/bin/idle"},
+}
+
+var formatterOpts = TableOptions{HideSmall: true, ShowShare: true, Sparks: map[int]string{7: "▁▇"}}
+
+func TestTabFormatter(t *testing.T) {
+	got := splitPayload(RenderTable(formatterRows, formatterOpts, TabFormatter{}))
+	want := [][]string{
+		{"7", "3.0", "00:00:03", "75.0%", "▁▇", "", "/usr/bin/make -j8, all quiet", "", "", "", "", ""},
+		{"8", "1.0", "00:00:01", "25.0%", "", "", "/bin/sh", "", "", "", "", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// With rates, every row gets one, zero for a PID missing from the map.
+	opts := formatterOpts
+	opts.Rates = map[int]float64{7: 87.5}
+	got = splitPayload(RenderTable(formatterRows, opts, TabFormatter{}))
+	if len(got) != 2 || got[0][7] != "87.5%" || got[1][7] != "0.0%" {
+		t.Errorf("rate column = %q, want 87.5%% and 0.0%%", got)
+	}
+}
+
+func TestCSVFormatter(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(RenderTable(formatterRows, formatterOpts, CSVFormatter{}))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"pid", "cpu_seconds", "duration", "share_percent", "trend", "status", "exec_command", "command"},
+		{"7", "3", "00:00:03", "75", "▁▇", "", "", "/usr/bin/make -j8, all\tquiet"},
+		{"8", "1", "00:00:01", "25", "", "", "", "/bin/sh"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %q", len(records), len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	var rows []jsonRow
+	payload := RenderTable(formatterRows, formatterOpts, JSONFormatter{})
+	if err := json.Unmarshal([]byte(payload), &rows); err != nil {
+		t.Fatalf("invalid JSON %q: %v", payload, err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	if r := rows[0]; r.PID != 7 || r.CPUSeconds != 3 || r.Trend != "▁▇" ||
+		r.Command != "/usr/bin/make -j8, all\tquiet" || r.SharePercent == nil {
+		t.Errorf("row 0 = %+v", r)
+	}
+
+	// Without shares the field is omitted, and no rows encode as an array.
+	plain := RenderTable(formatterRows[:1], TableOptions{}, JSONFormatter{})
+	if strings.Contains(plain, "share_percent") {
+		t.Errorf("share_percent present without ShowShare: %s", plain)
+	}
+	if empty := RenderTable(nil, TableOptions{}, JSONFormatter{}); empty != "[]" {
+		t.Errorf("empty payload = %q, want []", empty)
+	}
+}
+
+func TestRenderTableCapsRowsForEveryFormatter(t *testing.T) {
+	rows := make([]ResultRow, 600)
+	for i := range rows {
+		rows[i] = ResultRow{PID: i + 1, Diff: 1, Command: "/bin/x"}
+	}
+	if n := len(splitPayload(RenderTable(rows, TableOptions{}, TabFormatter{}))); n != 500 {
+		t.Errorf("tab rows = %d, want 500", n)
+	}
+	if n := strings.Count(RenderTable(rows, TableOptions{}, CSVFormatter{}), "\n"); n != 501 {
+		t.Errorf("csv lines = %d, want 501 (header + 500)", n)
+	}
+	var decoded []jsonRow
+	json.Unmarshal([]byte(RenderTable(rows, TableOptions{}, JSONFormatter{})), &decoded)
+	if len(decoded) != 500 {
+		t.Errorf("json rows = %d, want 500", len(decoded))
+	}
+}
@@ -0,0 +1,42 @@
+package framescope
+
+// frozenRowCount is how many processes the freeze top-N mode pins for a
+// frame (see Monitor.SetFreezeTopN).
+const frozenRowCount = 20
+
+// topPIDs returns, in table order, the PIDs of the first n processes the
+// current-frame table would show for rows with opts, ungrouped and without
+// the hideSmall filter. It returns nil if there are none, so that the caller
+// picks again on the next update.
+func topPIDs(rows []ResultRow, opts TableOptions, n int) []int {
+	opts.Reference = nil
+	opts.ChangedOnly = false
+	opts.Pinned = nil
+	opts.HideSmall = false
+	opts.Group = GroupNone
+	var pids []int
+	for _, row := range displayedRows(rows, opts) {
+		if len(pids) == n {
+			break
+		}
+		pids = append(pids, row.PID)
+	}
+	return pids
+}
+
+// pinnedRows returns the rows of the PIDs in order, in that order, dropping
+// every other row. Pinned processes missing from rows, which have exited, are
+// left out.
+func pinnedRows(rows []ResultRow, order []int) []ResultRow {
+	byPID := make(map[int]ResultRow, len(rows))
+	for _, row := range rows {
+		byPID[row.PID] = row
+	}
+	pinned := make([]ResultRow, 0, len(order))
+	for _, pid := range order {
+		if row, ok := byPID[pid]; ok {
+			pinned = append(pinned, row)
+		}
+	}
+	return pinned
+}
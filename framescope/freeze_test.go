@@ -0,0 +1,119 @@
+package framescope
+
+import (
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPinnedTableKeepsOrder(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 3, Diff: 9, Command: "/bin/c"},
+		{PID: 1, Diff: 4, Command: "/bin/a"},
+		{PID: 2, Diff: 0.2, Command: "/bin/b"},
+		{PID: 4, Diff: 0.1, Command: "/bin/d"},
+	}
+	// PID 5 has exited; PID 4 was not pinned. HideSmall must not drop PID 2.
+	opts := TableOptions{HideSmall: true, Group: GroupAppHelpers, Pinned: []int{1, 2, 5, 3}}
+	got := splitPayload(RenderTable(rows, opts, TabFormatter{}))
+	var pids []string
+	for _, row := range got {
+		pids = append(pids, row[0])
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(pids, want) {
+		t.Errorf("PIDs = %v, want %v", pids, want)
+	}
+
+	opts.ChangedOnly = true
+	if rows := splitPayload(RenderTable(rows, opts, TabFormatter{})); len(rows) != 4 {
+		t.Errorf("changed-only table = %q, want it to ignore the pinning", rows)
+	}
+}
+
+func TestTopPIDsIgnoresHideSmallAndGrouping(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 1, Diff: 3, Command: "/bin/a"},
+		{PID: 2, Diff: 0.5, Command: "/bin/b"},
+		{PID: 3, Diff: 0.2, Command: "/bin/excluded"},
+		{PID: 4, Diff: 0.1, Command: "/bin/d"},
+	}
+	opts := TableOptions{HideSmall: true, Group: GroupAppHelpers, Exclude: []string{"excluded"}}
+	if got := topPIDs(rows, opts, 2); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("topPIDs = %v, want [1 2]", got)
+	}
+	if got := topPIDs(nil, opts, 2); got != nil {
+		t.Errorf("topPIDs of no rows = %v, want nil", got)
+	}
+}
+
+// overtakingSource reports two processes: PID 1 uses one CPU-second per
+// snapshot, and PID 2 is idle for two snapshots and then far busier.
+type overtakingSource struct {
+	calls atomic.Int32
+}
+
+func (s *overtakingSource) Snapshot() (map[int]ProcessSample, error) {
+	n := float64(s.calls.Add(1))
+	busy := 0.0
+	if n > 2 {
+		busy = 10 * n
+	}
+	return map[int]ProcessSample{
+		1: {CPUSeconds: n, Command: "/bin/steady"},
+		2: {CPUSeconds: busy, Command: "/bin/burst"},
+	}, nil
+}
+
+func TestFreezeTopNKeepsPositionsWithinFrame(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &overtakingSource{}
+	m.SetProcessSource(source)
+	m.SetHideSmall(false)
+	m.SetFreezeTopN(true)
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 4 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("too few snapshots")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// PID 2 overtakes PID 1 during the frame but stays in second place.
+	updates, _ := rec.snapshotUpdates()
+	var overtaken bool
+	for _, u := range updates {
+		rows := splitPayload(u.Table)
+		if len(rows) == 0 {
+			continue
+		}
+		if len(rows) != 2 || rows[0][0] != "1" || rows[1][0] != "2" {
+			t.Fatalf("table = %q, want PID 1 above PID 2", rows)
+		}
+		first, _ := strconv.ParseFloat(rows[0][1], 64)
+		second, _ := strconv.ParseFloat(rows[1][1], 64)
+		if second > first {
+			overtaken = true
+		}
+	}
+	if !overtaken {
+		t.Error("PID 2 never overtook PID 1; the test does not exercise the pinning")
+	}
+
+	// At the frame boundary the set is picked again from the completed frame.
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 1, 5*time.Second)
+	m.Stop()
+	m.mu.Lock()
+	frozen := m.frozenPIDs
+	m.mu.Unlock()
+	if !reflect.DeepEqual(frozen, []int{2, 1}) {
+		t.Errorf("frozen PIDs after the frame = %v, want [2 1]", frozen)
+	}
+}
@@ -0,0 +1,171 @@
+package framescope
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// groupedRow is a row of the current-frame table after GroupMode has been
+// applied. Depth is 1 for an app's individual processes listed under its
+// group row, and 0 otherwise.
+type groupedRow struct {
+	ResultRow
+	Depth int
+
+	members []ResultRow // for a group row, the processes it sums
+}
+
+// appGroup collects the processes of one application bundle.
+type appGroup struct {
+	main    *ResultRow
+	members []ResultRow // main process and helpers
+	helpers int
+	total   float64
+
+	// user and system split total (see ResultRow.UserDiff).
+	user, system float64
+}
+
+// isAppHelper reports whether exe is a helper of an application bundle: an
+// executable in a bundle nested inside the app (Chrome's and Electron's
+// "… Helper (Renderer).app" pattern), or one named "… Helper" inside it.
+func isAppHelper(exe string) bool {
+	outer, inner := appBundles(exe)
+	if outer == "" {
+		return false
+	}
+	return inner != outer || strings.Contains(filepath.Base(exe), " Helper")
+}
+
+// groupRows applies mode to rows, which must be sorted by CPU descending.
+//
+// With GroupAppHelpers every application bundle that has at least one helper
+// process in rows is collapsed into one row: its CPU is the sum of the app's
+// main process and all helpers, its PID is the main process's (or, if the
+// main process is not in rows, the busiest helper's), and its command is the
+// app name with the helper count. The main process is the nearest ancestor of
+// a helper, following PPIDs, that runs from the same bundle and is not a
+// helper itself; any non-helper process of the bundle is used as a fallback.
+// Processes outside bundles, and apps without helpers, are left as they are.
+// GroupAppHelpersExpanded additionally lists each member, busiest first, at
+// Depth 1 below its group row. The result is sorted by CPU descending.
+func groupRows(rows []ResultRow, mode GroupMode) []groupedRow {
+	if mode != GroupAppHelpers && mode != GroupAppHelpersExpanded {
+		out := make([]groupedRow, len(rows))
+		for i, row := range rows {
+			out[i] = groupedRow{ResultRow: row}
+		}
+		return out
+	}
+
+	byPID := make(map[int]ResultRow, len(rows))
+	groups := make(map[string]*appGroup)
+	for _, row := range rows {
+		byPID[row.PID] = row
+		if isAppHelper(row.Exe) {
+			bundle, _ := appBundles(row.Exe)
+			if groups[bundle] == nil {
+				groups[bundle] = &appGroup{}
+			}
+			groups[bundle].helpers++
+		}
+	}
+
+	// Find each group's main process: first by walking up from its helpers,
+	// then by any non-helper process from the same bundle.
+	for _, row := range rows {
+		if !isAppHelper(row.Exe) {
+			continue
+		}
+		bundle, _ := appBundles(row.Exe)
+		group := groups[bundle]
+		if group.main != nil {
+			continue
+		}
+		seen := map[int]bool{row.PID: true}
+		for pid := row.PPID; pid > 0 && !seen[pid]; {
+			seen[pid] = true
+			parent, ok := byPID[pid]
+			if !ok {
+				break
+			}
+			if outer, _ := appBundles(parent.Exe); outer == bundle && !isAppHelper(parent.Exe) {
+				group.main = &parent
+				break
+			}
+			pid = parent.PPID
+		}
+	}
+	for _, row := range rows {
+		outer, _ := appBundles(row.Exe)
+		if group := groups[outer]; group != nil && group.main == nil && !isAppHelper(row.Exe) {
+			main := row
+			group.main = &main
+		}
+	}
+
+	var out []groupedRow
+	for _, row := range rows {
+		outer, _ := appBundles(row.Exe)
+		group := groups[outer]
+		if group == nil || (!isAppHelper(row.Exe) && row.PID != group.main.PID) {
+			out = append(out, groupedRow{ResultRow: row})
+			continue
+		}
+		group.members = append(group.members, row)
+		group.total += row.Diff
+		group.user += row.UserDiff
+		group.system += row.SystemDiff
+	}
+
+	bundles := make([]string, 0, len(groups))
+	for bundle := range groups {
+		bundles = append(bundles, bundle)
+	}
+	sort.Strings(bundles)
+	for _, bundle := range bundles {
+		group := groups[bundle]
+		// rows is sorted, so members[0] is the busiest process.
+		head := group.members[0]
+		if group.main != nil {
+			head = *group.main
+		}
+		noun := "helpers"
+		if group.helpers == 1 {
+			noun = "helper"
+		}
+		out = append(out, groupedRow{
+			ResultRow: ResultRow{
+				PID:        head.PID,
+				Diff:       group.total,
+				Command:    fmt.Sprintf("%s (%d %s)", strings.TrimSuffix(filepath.Base(bundle), ".app"), group.helpers, noun),
+				PPID:       head.PPID,
+				Exe:        head.Exe,
+				UserDiff:   group.user,
+				SystemDiff: group.system,
+			},
+			members: group.members,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Diff == out[j].Diff {
+			return out[i].PID < out[j].PID
+		}
+		return out[i].Diff > out[j].Diff
+	})
+	if mode != GroupAppHelpersExpanded {
+		return out
+	}
+
+	expanded := make([]groupedRow, 0, len(rows)+len(groups))
+	for _, row := range out {
+		expanded = append(expanded, row)
+		for _, member := range row.members {
+			expanded = append(expanded, groupedRow{ResultRow: member, Depth: 1})
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,109 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+)
+
+// chromeRows is a synthetic Chrome-like process tree: the browser, two
+// helpers it spawned, a crashpad handler inside the app bundle, and an
+// unrelated process. It is sorted by CPU like ComputeResults output.
+func chromeRows() []ResultRow {
+	const app = "/Applications/Google Chrome.app/Contents"
+	const helpers = app + "/Frameworks/Google Chrome Framework.framework/Versions/120.0/Helpers"
+	return []ResultRow{
+		{PID: 200, Diff: 4, Command: "/usr/bin/python3 train.py", PPID: 1, Exe: "/usr/bin/python3"},
+		{PID: 101, Diff: 3, Command: "Google Chrome Helper (Renderer) --type=renderer", PPID: 100,
+			Exe: helpers + "/Google Chrome Helper (Renderer).app/Contents/MacOS/Google Chrome Helper (Renderer)"},
+		{PID: 100, Diff: 2, Command: "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", PPID: 1,
+			Exe: app + "/MacOS/Google Chrome"},
+		{PID: 102, Diff: 1.5, Command: "Google Chrome Helper (GPU) --type=gpu-process", PPID: 100,
+			Exe: helpers + "/Google Chrome Helper (GPU).app/Contents/MacOS/Google Chrome Helper (GPU)"},
+		{PID: 103, Diff: 0.5, Command: "chrome_crashpad_handler", PPID: 100,
+			Exe: helpers + "/chrome_crashpad_handler"},
+	}
+}
+
+func TestIsAppHelper(t *testing.T) {
+	tests := map[string]bool{
+		"/Applications/Slack.app/Contents/Frameworks/Slack Helper (Renderer).app/Contents/MacOS/Slack Helper (Renderer)": true,
+		"/Applications/Visual Studio Code.app/Contents/Frameworks/Code Helper.app/Contents/MacOS/Code Helper":            true,
+		"/Applications/Foo.app/Contents/MacOS/Foo Helper":                                                                true,
+		"/Applications/Slack.app/Contents/MacOS/Slack":                                                                   false,
+		"/usr/libexec/helperd": false,
+		"":                     false,
+	}
+	for exe, want := range tests {
+		if got := isAppHelper(exe); got != want {
+			t.Errorf("isAppHelper(%q) = %v, want %v", exe, got, want)
+		}
+	}
+}
+
+func TestGroupRowsCollapsesChromeHelpers(t *testing.T) {
+	got := groupRows(chromeRows(), GroupAppHelpers)
+	if len(got) != 3 {
+		t.Fatalf("got %d rows, want chrome group, python, chrome_crashpad_handler: %+v", len(got), got)
+	}
+
+	// The crashpad handler lives in the bundle but is neither nested in a
+	// helper bundle nor named "Helper", so it stays on its own row; the
+	// renderer and GPU helpers roll up into the browser's PID.
+	group := got[0]
+	if group.PID != 100 || group.Diff != 6.5 || group.Command != "Google Chrome (2 helpers)" || group.Depth != 0 {
+		t.Errorf("group row = %+v", group)
+	}
+	if got[1].PID != 200 || got[2].PID != 103 {
+		t.Errorf("remaining rows = %+v, %+v", got[1], got[2])
+	}
+}
+
+func TestGroupRowsExpandedListsMembers(t *testing.T) {
+	got := groupRows(chromeRows(), GroupAppHelpersExpanded)
+	var pids []int
+	var depths []int
+	for _, row := range got {
+		pids = append(pids, row.PID)
+		depths = append(depths, row.Depth)
+	}
+	wantPIDs := []int{100, 101, 100, 102, 200, 103}
+	wantDepths := []int{0, 1, 1, 1, 0, 0}
+	for i := range wantPIDs {
+		if i >= len(got) || pids[i] != wantPIDs[i] || depths[i] != wantDepths[i] {
+			t.Fatalf("pids = %v depths = %v, want %v %v", pids, depths, wantPIDs, wantDepths)
+		}
+	}
+}
+
+func TestGroupRowsWithoutMainProcess(t *testing.T) {
+	// Neither the browser nor any other non-helper of the bundle is in the
+	// frame, so the group takes the busiest helper's PID.
+	var rows []ResultRow
+	for _, row := range chromeRows() {
+		if row.PID != 100 && row.PID != 103 {
+			rows = append(rows, row)
+		}
+	}
+	got := groupRows(rows, GroupAppHelpers)
+	if got[0].PID != 101 || got[0].Diff != 4.5 || got[0].Command != "Google Chrome (2 helpers)" {
+		t.Errorf("group row = %+v", got[0])
+	}
+}
+
+func TestGroupNoneLeavesRows(t *testing.T) {
+	rows := chromeRows()
+	got := groupRows(rows, GroupNone)
+	if len(got) != len(rows) {
+		t.Fatalf("got %d rows, want %d", len(got), len(rows))
+	}
+}
+
+func TestRenderTableGroupedKeepsLabelAndIndents(t *testing.T) {
+	got := splitPayload(RenderTable(chromeRows(), TableOptions{Group: GroupAppHelpersExpanded, HidePaths: true}, TabFormatter{}))
+	if got[0][6] != "Google Chrome (2 helpers)" {
+		t.Errorf("group command = %q, want the unshortened app label", got[0][6])
+	}
+	if !strings.HasPrefix(got[1][6], "  ↳ ") {
+		t.Errorf("member command = %q, want an indented entry", got[1][6])
+	}
+}
@@ -0,0 +1,125 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"monitor_cpu/framescopepb"
+)
+
+// frameService implements the FrameService of proto/frames.proto on a
+// Monitor. Watchers subscribe to m.watchers, which pushUI feeds with the
+// frames completed since its last refresh, so a slow watcher is dropped
+// rather than holding up the monitor.
+type frameService struct {
+	framescopepb.UnimplementedFrameServiceServer
+
+	m *Monitor
+
+	// stopping is closed when the server shuts down, ending every Watch.
+	stopping <-chan struct{}
+}
+
+// Watch streams every frame of the displayed frame length that completes
+// after the call, until the client cancels, the server stops, or the watcher
+// falls more than streamClientBuffer frames behind, which ends the stream
+// with codes.ResourceExhausted.
+func (s *frameService) Watch(_ *framescopepb.WatchRequest, stream framescopepb.FrameService_WatchServer) error {
+	ch := s.m.watchers.subscribe()
+	defer s.m.watchers.unsubscribe(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-s.stopping:
+			return nil
+		case frame, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell too far behind")
+			}
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ServeGRPC runs the FrameService on addr until ctx is cancelled, then ends
+// every Watch and shuts the server down. It returns nil after a clean
+// shutdown.
+func (m *Monitor) ServeGRPC(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return m.serveGRPCListener(ctx, lis)
+}
+
+// serveGRPCListener is ServeGRPC on an open listener, which it closes.
+func (m *Monitor) serveGRPCListener(ctx context.Context, lis net.Listener) error {
+	srv := grpc.NewServer()
+	stopping := make(chan struct{})
+	framescopepb.RegisterFrameServiceServer(srv, &frameService{m: m, stopping: stopping})
+
+	go func() {
+		<-ctx.Done()
+		close(stopping)
+		srv.GracefulStop()
+	}()
+
+	if err := srv.Serve(lis); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// unwatchedFramesLocked returns the completed frames that ended after the
+// last one handed to gRPC watchers, oldest first, and marks them handed over.
+// m.mu must be held.
+func (m *Monitor) unwatchedFramesLocked() []FrameRecord {
+	i := len(m.history)
+	for i > 0 && m.history[i-1].EndedAt.After(m.watchedThrough) {
+		i--
+	}
+	if i == len(m.history) {
+		return nil
+	}
+	m.watchedThrough = m.history[len(m.history)-1].EndedAt
+	return append([]FrameRecord(nil), m.history[i:]...)
+}
+
+// publishFrames sends frames to gRPC watchers. It does nothing when no
+// watcher is connected.
+func (m *Monitor) publishFrames(frames []FrameRecord) {
+	if len(frames) == 0 || !m.watchers.active() {
+		return
+	}
+	for _, frame := range frames {
+		m.watchers.publish(watchFrame(frame))
+	}
+}
+
+// watchFrame converts a completed frame to its FrameService message.
+func watchFrame(frame FrameRecord) *framescopepb.Frame {
+	rows := make([]*framescopepb.Row, len(frame.Rows))
+	for i, row := range frame.Rows {
+		rows[i] = &framescopepb.Row{
+			Pid:        int64(row.PID),
+			CpuSeconds: row.Diff,
+			Command:    row.Command,
+		}
+	}
+	return &framescopepb.Frame{
+		Index:     int64(frame.Index),
+		StartedAt: timestamppb.New(frame.StartedAt),
+		EndedAt:   timestamppb.New(frame.EndedAt),
+		Rows:      rows,
+	}
+}
@@ -0,0 +1,144 @@
+package framescope
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"monitor_cpu/framescopepb"
+)
+
+// waitForWatchers polls until m has n gRPC watchers subscribed.
+func waitForWatchers(t *testing.T, m *Monitor, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.watchers.mu.Lock()
+		subscribed := len(m.watchers.clients)
+		m.watchers.mu.Unlock()
+		if subscribed == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d watchers subscribed, want %d", subscribed, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// completeFrame appends a completed frame to m's history, as the sampling
+// loop does, and refreshes the UI.
+func completeFrame(m *Monitor, frame FrameRecord) {
+	m.mu.Lock()
+	m.history = append(m.history, frame)
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+func TestFrameServiceWatch(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	start := time.Unix(1000, 0)
+
+	// A frame completed before anyone watches is not sent.
+	completeFrame(m, FrameRecord{Index: 1, StartedAt: start, EndedAt: start.Add(10 * time.Second)})
+
+	lis := bufconn.Listen(1 << 20)
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- m.serveGRPCListener(ctx, lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := framescopepb.NewFrameServiceClient(conn)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	first, err := client.Watch(firstCtx, &framescopepb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.Watch(context.Background(), &framescopepb.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForWatchers(t, m, 2)
+
+	completeFrame(m, FrameRecord{
+		Index:     2,
+		StartedAt: start.Add(10 * time.Second),
+		EndedAt:   start.Add(20 * time.Second),
+		Rows:      []ResultRow{{PID: 10, Diff: 4.5, Command: "/bin/busy"}, {PID: 11, Diff: 0.5, Command: "/bin/idle"}},
+	})
+	for name, stream := range map[string]framescopepb.FrameService_WatchClient{"first": first, "second": second} {
+		frame, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("%s watcher: %v", name, err)
+		}
+		if frame.Index != 2 || !frame.StartedAt.AsTime().Equal(start.Add(10*time.Second)) || !frame.EndedAt.AsTime().Equal(start.Add(20*time.Second)) {
+			t.Errorf("%s watcher got frame %d (%v–%v), want frame 2 (%v–%v)", name,
+				frame.Index, frame.StartedAt.AsTime(), frame.EndedAt.AsTime(), start.Add(10*time.Second), start.Add(20*time.Second))
+		}
+		if len(frame.Rows) != 2 || frame.Rows[0].Pid != 10 || frame.Rows[0].CpuSeconds != 4.5 || frame.Rows[0].Command != "/bin/busy" {
+			t.Errorf("%s watcher got rows %v, want busy (4.5 CPU-s) then idle", name, frame.Rows)
+		}
+	}
+
+	// Cancelling one watcher leaves the other streaming.
+	cancelFirst()
+	if _, err := first.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("cancelled watcher got %v, want Canceled", err)
+	}
+	waitForWatchers(t, m, 1)
+	completeFrame(m, FrameRecord{Index: 3, StartedAt: start.Add(20 * time.Second), EndedAt: start.Add(30 * time.Second)})
+	if frame, err := second.Recv(); err != nil || frame.Index != 3 {
+		t.Fatalf("remaining watcher got %v, %v, want frame 3", frame, err)
+	}
+
+	// Stopping the server ends the remaining stream cleanly.
+	stop()
+	if _, err := second.Recv(); err != io.EOF {
+		t.Errorf("after shutdown Recv = %v, want io.EOF", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serveGRPCListener = %v, want nil", err)
+	}
+}
+
+func TestFrameServiceDropsSlowWatcher(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	ch := m.watchers.subscribe()
+
+	// A watcher that never reads must not block pushUI.
+	start := time.Unix(1000, 0)
+	for i := 1; i <= streamClientBuffer+1; i++ {
+		end := start.Add(time.Duration(i) * 10 * time.Second)
+		completeFrame(m, FrameRecord{Index: i, StartedAt: end.Add(-10 * time.Second), EndedAt: end})
+	}
+
+	for i := 1; i <= streamClientBuffer; i++ {
+		if frame := <-ch; frame.Index != int64(i) {
+			t.Fatalf("queued frame %d, want %d", frame.Index, i)
+		}
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("slow watcher was not dropped")
+	}
+	if m.watchers.active() {
+		t.Error("slow watcher still subscribed")
+	}
+}
@@ -0,0 +1,101 @@
+package framescope
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// iconCache memoises resolveIconPath by executable path. Resolution walks the
+// filesystem, so each executable is resolved at most once per session,
+// including executables without an icon.
+type iconCache struct {
+	mu      sync.Mutex
+	paths   map[string]string
+	resolve func(exe string) string // resolveIconPath; replaced in tests
+}
+
+func newIconCache() *iconCache {
+	return &iconCache{paths: make(map[string]string), resolve: resolveIconPath}
+}
+
+// lookup returns the icon path for exe, resolving and caching it on first use.
+func (c *iconCache) lookup(exe string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if path, ok := c.paths[exe]; ok {
+		return path
+	}
+	path := c.resolve(exe)
+	c.paths[exe] = path
+	return path
+}
+
+// resolveIconPath finds the icon of the application bundle enclosing exe: it
+// walks up to the outermost ".app" directory (so helpers nested inside an
+// app's Frameworks resolve to the app itself) and picks an .icns file from its
+// Contents/Resources, preferring AppIcon.icns, then one named after the
+// bundle, then the first alphabetically. Returns "" when exe is not inside a
+// bundle or the bundle has no icon.
+func resolveIconPath(exe string) string {
+	bundle, _ := appBundles(exe)
+	if bundle == "" {
+		return ""
+	}
+
+	resources := filepath.Join(bundle, "Contents", "Resources")
+	icons, _ := filepath.Glob(filepath.Join(resources, "*.icns"))
+	if len(icons) == 0 {
+		return ""
+	}
+	name := strings.TrimSuffix(filepath.Base(bundle), ".app")
+	for _, preferred := range []string{"AppIcon.icns", name + ".icns"} {
+		path := filepath.Join(resources, preferred)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	sort.Strings(icons)
+	return icons[0]
+}
+
+// appBundles returns the outermost and innermost ".app" directories that
+// contain exe, or empty strings if it is not inside an application bundle.
+// They differ for helpers nested inside an app (e.g. in Contents/Frameworks).
+func appBundles(exe string) (outer, inner string) {
+	if exe == "" {
+		return "", ""
+	}
+	for dir := filepath.Dir(exe); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if strings.HasSuffix(dir, ".app") {
+			outer = dir
+			if inner == "" {
+				inner = dir
+			}
+		}
+	}
+	return outer, inner
+}
+
+// IconPath returns the path of the .icns icon of the application running as
+// pid, or "" if the process is gone, its executable cannot be read, or it is
+// not part of an application bundle, or if pid cannot name a process (see
+// checkPID). Results are cached by executable path.
+func (m *Monitor) IconPath(pid int) string {
+	if checkPID(pid) != nil {
+		return ""
+	}
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return ""
+	}
+	exe, err := proc.Exe()
+	if err != nil {
+		return ""
+	}
+	return m.icons.lookup(exe)
+}
@@ -0,0 +1,71 @@
+package framescope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates path and any missing parent directories.
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveIconPath(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "Applications", "Editor.app")
+	writeFile(t, filepath.Join(app, "Contents", "MacOS", "Editor"))
+	writeFile(t, filepath.Join(app, "Contents", "Resources", "Document.icns"))
+	writeFile(t, filepath.Join(app, "Contents", "Resources", "Editor.icns"))
+	helper := filepath.Join(app, "Contents", "Frameworks", "Editor Helper.app", "Contents", "MacOS", "Editor Helper")
+	writeFile(t, helper)
+	writeFile(t, filepath.Join(root, "Bare.app", "Contents", "MacOS", "bare"))
+
+	tests := []struct {
+		name string
+		exe  string
+		want string
+	}{
+		{"bundle named icon", filepath.Join(app, "Contents", "MacOS", "Editor"), filepath.Join(app, "Contents", "Resources", "Editor.icns")},
+		{"nested helper uses outer app", helper, filepath.Join(app, "Contents", "Resources", "Editor.icns")},
+		{"bundle without icons", filepath.Join(root, "Bare.app", "Contents", "MacOS", "bare"), ""},
+		{"not in a bundle", "/usr/bin/true", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveIconPath(tt.exe); got != tt.want {
+			t.Errorf("%s: resolveIconPath(%q) = %q, want %q", tt.name, tt.exe, got, tt.want)
+		}
+	}
+}
+
+func TestIconCacheResolvesEachExecutableOnce(t *testing.T) {
+	calls := map[string]int{}
+	cache := newIconCache()
+	cache.resolve = func(exe string) string {
+		calls[exe]++
+		if exe == "/Applications/A.app/Contents/MacOS/A" {
+			return "/Applications/A.app/Contents/Resources/AppIcon.icns"
+		}
+		return ""
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := cache.lookup("/Applications/A.app/Contents/MacOS/A"); got != "/Applications/A.app/Contents/Resources/AppIcon.icns" {
+			t.Fatalf("lookup = %q", got)
+		}
+		// Misses are cached too, so a daemon is not re-walked every refresh.
+		if got := cache.lookup("/usr/sbin/daemon"); got != "" {
+			t.Fatalf("lookup of a bare executable = %q, want empty", got)
+		}
+	}
+	if calls["/Applications/A.app/Contents/MacOS/A"] != 1 || calls["/usr/sbin/daemon"] != 1 {
+		t.Errorf("resolve calls = %v, want one per executable", calls)
+	}
+}
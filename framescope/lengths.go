@@ -0,0 +1,102 @@
+package framescope
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// errUnknownFrameLength is returned by SelectFrameLength for a frame length
+// the monitor is not collecting.
+var errUnknownFrameLength = errors.New("frame length is not being collected")
+
+// viewLocked returns the frameView that collects frames of length seconds,
+// displayed or parked, or nil if there is none. m.mu must be held.
+func (m *Monitor) viewLocked(length float64) *frameView {
+	switch {
+	case length <= 0:
+		return nil
+	case m.frameView.length == length:
+		return &m.frameView
+	case m.parked.length == length:
+		return &m.parked
+	}
+	return nil
+}
+
+// SetSecondFrameLength sets a second frame length, in seconds, collected
+// alongside the main one from the next Start: every snapshot then feeds both,
+// so a fast and a slow view of the same session cost no extra sampling. Each
+// length has its own frames, history, and summary; SelectFrameLength picks
+// the one on display. seconds <= 0 removes the second length, and other
+// values are clamped like Start's. Invalid values are ignored. The new
+// setting is persisted to disk immediately.
+func (m *Monitor) SetSecondFrameLength(seconds float64) {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds > 0 {
+		seconds, _ = clampFrameSeconds(seconds)
+	}
+	m.mu.Lock()
+	m.secondFrameSeconds = seconds
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// SecondFrameLength returns the configured second frame length in seconds,
+// or 0 if none is set.
+func (m *Monitor) SecondFrameLength() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.secondFrameSeconds
+}
+
+// FrameLengths returns the frame lengths, in seconds, of the current or last
+// run: the main length first, then the second one if it was collected.
+func (m *Monitor) FrameLengths() []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var lengths []float64
+	for _, v := range []*frameView{&m.frameView, &m.parked} {
+		if v.length > 0 {
+			lengths = append(lengths, v.length)
+		}
+	}
+	if len(lengths) == 2 && lengths[1] == m.frameSeconds {
+		lengths[0], lengths[1] = lengths[1], lengths[0]
+	}
+	return lengths
+}
+
+// SelectFrameLength shows the frames of length seconds, one of FrameLengths,
+// in every table. Each length keeps its own frame selection and summary
+// range, so switching back returns to where the user was. Selecting a length
+// that is not being collected leaves the display unchanged, says so in the
+// status line, and returns an error; the next Start shows the main length
+// again.
+func (m *Monitor) SelectFrameLength(seconds float64) error {
+	m.mu.Lock()
+	switch m.viewLocked(seconds) {
+	case &m.frameView:
+		m.mu.Unlock()
+		return nil
+	case &m.parked:
+		m.frameView, m.parked = m.parked, m.frameView
+		m.status = fmt.Sprintf("Showing %gs frames; frame %d in progress.", seconds, m.frameIndex)
+		if !m.running {
+			m.status = fmt.Sprintf("Showing %gs frames.", seconds)
+		}
+	default:
+		m.status = fmt.Sprintf("No %gs frames are being collected. Set a second frame length and press Start.", seconds)
+		m.mu.Unlock()
+		m.pushUI(0)
+		return errUnknownFrameLength
+	}
+	m.mu.Unlock()
+	m.pushUI(0)
+	return nil
+}
@@ -0,0 +1,122 @@
+package framescope
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTwoFrameLengthsShareOneSnapshotStream(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	start := time.Unix(1000, 0)
+	clock := &fakeClock{now: start}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetSecondFrameLength(20)
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("run did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for frames := 1; frames <= 4; frames++ {
+		clock.Advance(5 * time.Second)
+		waitForFrames(t, m, frames, 5*time.Second)
+	}
+	// The 20s frame ends on the same snapshot as the fourth 5s frame.
+	for {
+		m.mu.Lock()
+		long := len(m.parked.history)
+		m.mu.Unlock()
+		if long == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("the 20s frame did not complete")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	m.Stop()
+
+	short := m.Frames()
+	if len(short) != 4 {
+		t.Fatalf("5s frames = %d, want 4", len(short))
+	}
+	var shortCPU float64
+	for i, frame := range short {
+		if frame.Index != i+1 || frame.Duration != 5*time.Second {
+			t.Errorf("5s frame %d = index %d, %v", i, frame.Index, frame.Duration)
+		}
+		shortCPU += frame.Rows[0].Diff
+	}
+
+	if got := m.FrameLengths(); len(got) != 2 || got[0] != 5 || got[1] != 20 {
+		t.Fatalf("FrameLengths() = %v, want [5 20]", got)
+	}
+	if err := m.SelectFrameLength(20); err != nil {
+		t.Fatal(err)
+	}
+	long := m.Frames()
+	if len(long) != 1 || long[0].Duration != 20*time.Second || !long[0].StartedAt.Equal(start) {
+		t.Fatalf("20s frames = %+v, want one from the run's start", long)
+	}
+	// Both lengths diff the same snapshots, so the long frame accounts for
+	// exactly the CPU of the four short ones.
+	if got := long[0].Rows[0].Diff; got != shortCPU {
+		t.Errorf("20s frame CPU = %v, 5s frames = %v", got, shortCPU)
+	}
+	if got := m.FrameLengths(); len(got) != 2 || got[0] != 5 {
+		t.Errorf("FrameLengths() after switching = %v, want the main length first", got)
+	}
+
+	if err := m.SelectFrameLength(5); err != nil || len(m.Frames()) != 4 {
+		t.Errorf("switching back: %v, %d frames", err, len(m.Frames()))
+	}
+}
+
+func TestSelectFrameLengthRejectsUnknownLength(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.frameView = newFrameView(15)
+	m.history = []FrameRecord{{Index: 1}}
+
+	if err := m.SelectFrameLength(60); !errors.Is(err, errUnknownFrameLength) {
+		t.Errorf("SelectFrameLength(60) = %v, want errUnknownFrameLength", err)
+	}
+	if len(m.history) != 1 || m.frameView.length != 15 {
+		t.Errorf("view changed to length %v with %d frames", m.frameView.length, len(m.history))
+	}
+	if _, errs := rec.snapshotUpdates(); len(errs) != 0 {
+		t.Errorf("errors = %q, want only a status note", errs)
+	}
+}
+
+func TestSetSecondFrameLength(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetSecondFrameLength(2 * maxFrameSeconds)
+	if got := m.SecondFrameLength(); got != maxFrameSeconds {
+		t.Errorf("SecondFrameLength() = %v, want it clamped to %v", got, maxFrameSeconds)
+	}
+
+	m.SetSecondFrameLength(60)
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if got := loaded.SecondFrameLength(); got != 60 {
+		t.Errorf("loaded SecondFrameLength() = %v, want 60", got)
+	}
+
+	// The same length as the main one adds nothing.
+	m.SetProcessSource(&busySource{})
+	m.SetSecondFrameLength(15)
+	m.Start(15)
+	m.Stop()
+	if got := m.FrameLengths(); len(got) != 1 {
+		t.Errorf("FrameLengths() = %v, want only the main length", got)
+	}
+}
@@ -0,0 +1,106 @@
+package framescope
+
+import "fmt"
+
+// liveDelta is a live row's change versus the last completed frame (see
+// TableOptions.LivePrevious).
+type liveDelta struct {
+	known   bool    // percent is meaningful
+	isNew   bool    // the process was absent from the last completed frame
+	percent float64 // signed change of the projected CPU, in percent
+}
+
+// projectedDelta returns the change of cpuSeconds, measured over part of a
+// frame and projected to a full frame by scale, versus previous, the
+// CPU-seconds of the same process in the last completed frame. seen is false
+// for a process absent from that frame, which is new. A process that used no
+// CPU in that frame gives no basis for a percent, and its delta is unknown.
+func projectedDelta(cpuSeconds, scale, previous float64, seen bool) liveDelta {
+	switch {
+	case !seen:
+		return liveDelta{isNew: true}
+	case previous <= 0:
+		return liveDelta{}
+	}
+	return liveDelta{known: true, percent: (cpuSeconds*scale - previous) / previous * 100}
+}
+
+// rowDelta returns the liveDelta of row under opts, comparing the sum over
+// its members for a group row. A group is new only if none of its members
+// appeared in the last completed frame.
+func rowDelta(row groupedRow, opts TableOptions) liveDelta {
+	members := row.members
+	if members == nil {
+		members = []ResultRow{row.ResultRow}
+	}
+	var current, previous float64
+	seen := false
+	for _, member := range members {
+		current += member.Diff
+		if cpu, ok := opts.LivePrevious[member.PID]; ok {
+			previous += cpu
+			seen = true
+		}
+	}
+	return projectedDelta(current, opts.LiveScale, previous, seen)
+}
+
+// previousCPU maps the PIDs of rows, a completed frame's rows, to their
+// CPU-seconds.
+func previousCPU(rows []ResultRow) map[int]float64 {
+	cpu := make(map[int]float64, len(rows))
+	for _, row := range rows {
+		cpu[row.PID] = row.Diff
+	}
+	return cpu
+}
+
+// formatDelta renders a liveDelta for the delta column: "+25%" or "-40%",
+// "new" for a process absent from the last completed frame, or empty.
+func formatDelta(d liveDelta) string {
+	switch {
+	case d.isNew:
+		return "new"
+	case d.known:
+		return fmt.Sprintf("%+.0f%%", d.percent)
+	}
+	return ""
+}
+
+// liveDeltaOptionsLocked sets the live delta fields of opts (see
+// TableOptions.LivePrevious) when the live delta is enabled and the table
+// shows the live frame of a run that has completed a frame. Must be called
+// with m.mu held.
+func (m *Monitor) liveDeltaOptionsLocked(opts *TableOptions) {
+	if !m.showLiveDelta || !m.running || !m.viewingCurrent || len(m.history) == 0 {
+		return
+	}
+	elapsed := m.liveDurationLocked()
+	if elapsed <= 0 {
+		return
+	}
+	opts.LivePrevious = previousCPU(m.history[len(m.history)-1].Rows)
+	opts.LiveScale = m.frameView.length / elapsed.Seconds()
+}
+
+// SetShowLiveDelta toggles the delta column of the current-frame table,
+// which shows, while a frame is in progress, how each process's CPU so far,
+// projected to the full frame, compares with its CPU in the last completed
+// frame, e.g. "+40%" for a process getting busier or "new" for one that was
+// absent from it. It stays empty for completed frames, during the first
+// frame, and against a reference frame. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetShowLiveDelta(enabled bool) {
+	m.mu.Lock()
+	m.showLiveDelta = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// ShowLiveDelta reports whether the delta column is filled in the live view.
+func (m *Monitor) ShowLiveDelta() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showLiveDelta
+}
@@ -0,0 +1,79 @@
+package framescope
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProjectedDelta(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		cpu, scale, previous float64
+		seen                 bool
+		want                 string
+	}{
+		{"busier", 3, 2, 4, true, "+50%"},
+		{"quieter", 1, 2, 4, true, "-50%"},
+		{"unchanged", 2, 2, 4, true, "+0%"},
+		{"new", 1, 2, 0, false, "new"},
+		{"idle before", 2, 2, 0, true, ""},
+	} {
+		if got := formatDelta(projectedDelta(tc.cpu, tc.scale, tc.previous, tc.seen)); got != tc.want {
+			t.Errorf("%s: delta = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if d := projectedDelta(3, 4, 8, true); !d.known || math.Abs(d.percent-50) > 1e-9 {
+		t.Errorf("projectedDelta(3, 4, 8) = %+v, want +50%%", d)
+	}
+}
+
+func TestLiveDeltaColumn(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.hideSmall = false
+	m.running = true
+	m.viewingCurrent = true
+	m.frameView.length = 10
+	m.frameStart = clock.Now().Add(-5 * time.Second) // halfway through
+	m.liveRows = []ResultRow{
+		{PID: 1, Diff: 3, Command: "/bin/busier"},
+		{PID: 2, Diff: 1, Command: "/bin/quieter"},
+		{PID: 3, Diff: 0.5, Command: "/bin/new"},
+	}
+	// deltas returns the delta column by PID.
+	deltas := func() map[string]string {
+		m.mu.Lock()
+		opts := m.tableOptionsLocked()
+		rows := m.currentRowsLocked()
+		m.mu.Unlock()
+		out := make(map[string]string)
+		for _, row := range splitPayload(RenderTable(rows, opts, TabFormatter{Columns: []string{"pid", "delta"}})) {
+			out[row[0]] = row[1]
+		}
+		return out
+	}
+
+	m.SetShowLiveDelta(true)
+	if got := deltas(); got["1"] != "" || got["3"] != "" {
+		t.Errorf("first frame: deltas = %q, want none without a completed frame", got)
+	}
+
+	m.history = []FrameRecord{{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{
+		{PID: 1, Diff: 4, Command: "/bin/busier"},
+		{PID: 2, Diff: 4, Command: "/bin/quieter"},
+	}}}
+	want := map[string]string{"1": "+50%", "2": "-50%", "3": "new"}
+	got := deltas()
+	for pid, delta := range want {
+		if got[pid] != delta {
+			t.Errorf("PID %s: delta = %q, want %q", pid, got[pid], delta)
+		}
+	}
+
+	m.SetShowLiveDelta(false)
+	if got := deltas(); got["1"] != "" {
+		t.Errorf("toggle off: deltas = %q, want none", got)
+	}
+}
@@ -0,0 +1,57 @@
+package framescope
+
+import (
+	"errors"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// machineCPUUnknown is the machine meter value (see UIUpdate.MachineCPU)
+// before a run has two readings to compare, and while stopped.
+const machineCPUUnknown = -1.0
+
+// systemCPUTimes reads the machine's cumulative CPU times, summed over every
+// core, via gopsutil.
+func systemCPUTimes() (cpu.TimesStat, error) {
+	times, err := cpu.Times(false)
+	if err != nil {
+		return cpu.TimesStat{}, err
+	}
+	if len(times) == 0 {
+		return cpu.TimesStat{}, errors.New("no CPU times reported")
+	}
+	return times[0], nil
+}
+
+// machineTimes returns the machine's cumulative CPU times from the monitor's
+// reader, and false if they could not be read.
+func (m *Monitor) machineTimes() (cpu.TimesStat, bool) {
+	read := m.cpuTimes
+	if read == nil {
+		read = systemCPUTimes
+	}
+	times, err := read()
+	return times, err == nil
+}
+
+// machineUtilization returns the percentage of the machine's CPU capacity,
+// all cores together, that was busy between two consecutive readings of
+// cpu.Times(false). Those readings sum every core's time, so the elapsed
+// total is already cores × wall time and the result lies between 0 and 100
+// however many cores there are; unlike a frame's utilization it needs no
+// core count. Guest time is part of user time on Linux and zero elsewhere, so
+// it is left out to avoid counting it twice. It returns false if the total
+// did not advance, e.g. for the same reading twice.
+func machineUtilization(previous, current cpu.TimesStat) (float64, bool) {
+	total := machineTotal(current) - machineTotal(previous)
+	if total <= 0 {
+		return 0, false
+	}
+	busy := total - (current.Idle - previous.Idle) - (current.Iowait - previous.Iowait)
+	return min(max(busy/total*100, 0), 100), true
+}
+
+// machineTotal returns every second a reading accounts for, less guest time.
+func machineTotal(t cpu.TimesStat) float64 {
+	return t.Total() - t.Guest - t.GuestNice
+}
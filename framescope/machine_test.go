@@ -0,0 +1,82 @@
+package framescope
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+func TestMachineUtilization(t *testing.T) {
+	// Two consecutive cpu.Times(false) readings of a 4-core machine 0.5 s
+	// apart: 2 core-seconds elapsed, of which 0.5 were busy (0.3 user, 0.1
+	// system, 0.1 nice) and 1.5 idle or waiting on I/O.
+	previous := cpu.TimesStat{CPU: "cpu-total", User: 100, System: 50, Idle: 800, Nice: 10, Iowait: 5}
+	current := cpu.TimesStat{CPU: "cpu-total", User: 100.3, System: 50.1, Idle: 801.4, Nice: 10.1, Iowait: 5.1}
+
+	got, ok := machineUtilization(previous, current)
+	if !ok || math.Abs(got-25) > 1e-9 {
+		t.Fatalf("utilization = %v, %v, want 25, true", got, ok)
+	}
+
+	// Guest time is already counted as user time.
+	previous.Guest, current.Guest = 20, 20.3
+	current.User += 0.3
+	current.Idle -= 0.3
+	if got, _ := machineUtilization(previous, current); math.Abs(got-40) > 1e-9 {
+		t.Errorf("utilization with guest time = %v, want 40", got)
+	}
+
+	if _, ok := machineUtilization(current, current); ok {
+		t.Error("utilization of the same reading twice is known, want unknown")
+	}
+}
+
+// steppingTimes is a cpu.Times reader for a 2-core machine that is half busy
+// every time it is read.
+type steppingTimes struct {
+	mu    sync.Mutex
+	times cpu.TimesStat
+}
+
+func (s *steppingTimes) read() (cpu.TimesStat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.times.User += 0.5
+	s.times.Idle += 0.5
+	return s.times, nil
+}
+
+func TestMachineCPUInUpdates(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetProcessSource(&fakeSource{})
+	m.cpuTimes = (&steppingTimes{}).read
+
+	if err := m.Start(60); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		updates, _ := rec.snapshotUpdates()
+		if n := len(updates); n > 0 && updates[n-1].MachineCPU >= 0 {
+			if got := updates[n-1].MachineCPU; math.Abs(got-50) > 1e-9 {
+				t.Errorf("machine CPU = %v, want 50", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("no update reported the machine's CPU use")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Stop()
+
+	m.pushUI(0)
+	updates, _ := rec.snapshotUpdates()
+	if got := updates[len(updates)-1].MachineCPU; got != machineCPUUnknown {
+		t.Errorf("machine CPU after Stop = %v, want %v", got, machineCPUUnknown)
+	}
+}
@@ -0,0 +1,691 @@
+// Package framescope measures per-process CPU consumption across fixed-length
+// time windows ("frames"). It samples cumulative CPU time at the start and end
+// of each frame and reports the difference, making it easy to see which
+// processes were most active over a given period.
+//
+// A Monitor runs the sampling loop and keeps the completed-frame history; it
+// delivers rendered payloads to a UISink, so the same engine drives the macOS
+// app, the HTTP API, and headless tools. The rendering helpers (RenderTable,
+// RenderSummaryTable) and ComputeResults can also be used on their own.
+package framescope
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+
+	"monitor_cpu/framescopepb"
+)
+
+// ProcessSample holds a single process's cumulative CPU usage at a point in time,
+// captured during a snapshot. Both User and System CPU seconds are summed into
+// CPUSeconds, and kept apart in UserSeconds and SystemSeconds.
+type ProcessSample struct {
+	CPUSeconds float64 // total user+system CPU seconds consumed so far
+	Command    string  // full command line, or name if cmdline is unavailable; empty if left to the Monitor (SystemSource)
+	CreateTime int64   // process start time in ms since the Unix epoch; 0 if unknown
+	PPID       int     // parent PID; 0 if unknown
+	Exe        string  // absolute executable path; empty if unknown
+	Status     string  // scheduler state as reported by gopsutil (e.g. "zombie"); empty if unknown
+	User       string  // owner's username, or the numeric UID if it has none; empty if unknown
+	UID        int     // owner's effective UID; meaningful only if User is set
+	TimedOut   bool    // attribute reads exceeded the per-process timeout; later fields may be missing
+
+	// UserSeconds and SystemSeconds split CPUSeconds into time spent in
+	// userland and in the kernel on the process's behalf.
+	UserSeconds   float64
+	SystemSeconds float64
+}
+
+// ResultRow is a computed row in the results table, representing the CPU
+// consumed by one process between two snapshots (one frame interval).
+type ResultRow struct {
+	PID        int
+	Diff       float64 // CPU-seconds consumed during the frame
+	Command    string
+	PPID       int    // parent PID at the start of the frame; 0 if unknown
+	CreateTime int64  // process start time in ms since the Unix epoch; 0 if unknown
+	Exe        string // executable path; empty if unknown
+	Status     string // scheduler state at the end of the frame; empty if unknown
+	User       string // owner's username or numeric UID; empty if unknown
+
+	// UserDiff and SystemDiff split Diff into the CPU-seconds spent in
+	// userland and in the kernel during the frame.
+	UserDiff   float64
+	SystemDiff float64
+
+	// ExecCommand is set when the process exec'd into another command during
+	// the frame: Command is the command before, ExecCommand the one after.
+	ExecCommand string
+}
+
+// frameRange is an inclusive span of completed frames by FrameRecord.Index.
+// The zero value means no range.
+type frameRange struct {
+	First, Last int
+}
+
+// FrameRecord stores the completed results for a single frame, identified by
+// its sequential frame number.
+type FrameRecord struct {
+	Index     int           // 1-based frame number assigned when the frame completed
+	Rows      []ResultRow   // results sorted by CPU consumption (descending)
+	Duration  time.Duration // wall-clock time the frame actually covered
+	StartedAt time.Time     // when the frame's baseline snapshot was taken
+	EndedAt   time.Time     // when the frame completed; zero while in progress
+
+	// Utilization is the CPU used by every process during the frame, noise
+	// aside (see NoiseList), as a percentage of all cores over Duration (see
+	// utilization), computed from the full row set when the frame completes.
+	Utilization float64
+
+	// DroppedRows is how many rows beyond the busiest were discarded when
+	// the frame completed (see SetCollectTopN); 0 if every row was kept.
+	DroppedRows int
+
+	// ExitedCPU estimates the CPU-seconds used during the frame by processes
+	// that exited before it completed, which Rows cannot include (see
+	// exitedCPU). It is not part of Utilization.
+	ExitedCPU float64
+
+	// SampledCount is how many processes the frame's final snapshot held.
+	// NewProcesses and ExitedProcesses count those that started and exited
+	// during the frame, between ticks 500 ms apart (see processChurn), so a
+	// process that lived for less than a tick is missed. All three are 0
+	// while the frame is in progress.
+	SampledCount    int
+	NewProcesses    int
+	ExitedProcesses int
+
+	// Outlier is set when the frame's Utilization stood out above the
+	// recent frames' when it completed (see SetAnomalySigma).
+	Outlier bool
+}
+
+// aggregateRow represents a process's totals and per-frame averages across all
+// completed frames, used to populate the summary table.
+type aggregateRow struct {
+	PID            int
+	Total          float64 // sum of CPU-seconds across all frames the process appeared in
+	Average        float64 // Total / number of frames selected by the AverageMode
+	AveragePercent float64 // Total as %CPU of one core over those frames' Duration
+	PeakPercent    float64 // highest single-frame %CPU (of one core), see framePercent
+	PeakFrame      int     // frame Index at which PeakPercent was reached; 0 if none
+	Frames         int     // number of completed frames the process appeared in
+	StdDev         float64 // population standard deviation of per-frame CPU-seconds over the same frames as Average
+	Min, Max       float64 // lowest and highest CPU-seconds in a single frame the process appeared in
+	Command        string
+	CreateTime     int64 // process start time in ms since the Unix epoch; 0 if unknown
+	// Instances counts the distinct processes, by PID and start time, whose
+	// CPU the row sums over all the frames it covers, including ones that
+	// have since exited rather than only those in the final frame: above 1
+	// only when reused PIDs are merged.
+	Instances int
+
+	key aggregateKey // identifies the row across renders (see MarkSummaryViewed)
+}
+
+// AverageMode selects how the summary table's per-frame average is computed.
+// The values are part of the cgo bridge (GoSetAverageMode) and the config file,
+// so they must not be renumbered.
+type AverageMode int
+
+const (
+	// AverageAllFrames divides a process's total by every completed frame,
+	// so processes that only ran briefly have a small average.
+	AverageAllFrames AverageMode = iota
+
+	// AverageAppearedFrames divides a process's total by the number of frames
+	// in which it appeared, giving its typical load while it was running.
+	AverageAppearedFrames
+)
+
+// Tiebreaker orders rows with equal CPU in the current-frame and summary
+// tables, so exports and repeated renders list them reproducibly. The values
+// are part of the cgo bridge (GoSetTiebreaker) and the config file, so they
+// must not be renumbered.
+type Tiebreaker int
+
+const (
+	// TiebreakPID lists equal rows by ascending PID.
+	TiebreakPID Tiebreaker = iota
+
+	// TiebreakCommand lists equal rows alphabetically by command,
+	// ignoring case, then by PID.
+	TiebreakCommand
+
+	// TiebreakCreateTime lists equal rows oldest process first, then by
+	// PID. Processes whose start time is unknown come last.
+	TiebreakCreateTime
+)
+
+// SummarySortField selects the column the summary table is ordered by,
+// independently of the current-frame table. The values are part of the cgo
+// bridge (GoSetSummarySort) and the config file, so they must not be
+// renumbered.
+type SummarySortField int
+
+const (
+	// SummarySortTotal orders rows by total CPU-seconds.
+	SummarySortTotal SummarySortField = iota
+
+	// SummarySortAverage orders rows by average CPU-seconds per frame.
+	SummarySortAverage
+
+	// SummarySortPeak orders rows by peak single-frame %CPU.
+	SummarySortPeak
+
+	// SummarySortStdDev orders rows by the standard deviation of their
+	// per-frame CPU-seconds, so bursty processes can be told from steady
+	// ones.
+	SummarySortStdDev
+
+	// SummarySortAppearances orders rows by the number of frames the process
+	// appeared in.
+	SummarySortAppearances
+
+	// SummarySortCommand orders rows alphabetically by command, ignoring
+	// case.
+	SummarySortCommand
+
+	// SummarySortPID orders rows by PID.
+	SummarySortPID
+)
+
+// GroupMode selects whether the current-frame table rolls application helper
+// processes (browser renderers, Electron helpers, …) up into one row per app.
+// The values are part of the cgo bridge (GoSetGroupMode) and the config file,
+// so they must not be renumbered.
+type GroupMode int
+
+const (
+	// GroupNone lists every process on its own row.
+	GroupNone GroupMode = iota
+
+	// GroupAppHelpers replaces an app's helpers and main process with a
+	// single row labelled with the app name.
+	GroupAppHelpers
+
+	// GroupAppHelpersExpanded is GroupAppHelpers with the app's individual
+	// processes listed, indented, below its group row.
+	GroupAppHelpersExpanded
+)
+
+// CompletionAlert selects when the user is alerted that a monitoring run has
+// ended (see Monitor.SetCompletionAlert). The values are part of the cgo
+// bridge (GoSetCompletionAlert) and the config file, so they must not be
+// renumbered.
+type CompletionAlert int
+
+const (
+	// CompletionAlertOff never alerts.
+	CompletionAlertOff CompletionAlert = iota
+
+	// CompletionAlertFinished alerts when a run ends by itself, such as on
+	// reaching its frame limit, but not when the user stops it.
+	CompletionAlertFinished
+
+	// CompletionAlertAlways is CompletionAlertFinished that also alerts on
+	// a manual Stop.
+	CompletionAlertAlways
+)
+
+// RowField selects what Monitor.CopyRowField extracts from a row.
+type RowField int
+
+const (
+	// FieldPID is the row's process ID.
+	FieldPID RowField = iota
+
+	// FieldCommand is the row's full command line, never basenamed or
+	// truncated.
+	FieldCommand
+)
+
+// frameView is the state of one frame length: its frames, the frame being
+// collected, and the user's view selection among them. A Monitor keeps one
+// per frame length a run collects.
+type frameView struct {
+	length     float64   // frame length in seconds; 0 for an unused view
+	frameIndex int       // 1-based index of the frame currently being collected
+	frameStart time.Time // when the baseline of the frame currently being collected was taken; later than its start by any warm-up
+
+	// history holds completed frames, capped at maxHistory entries and
+	// the historyRetention window (oldest dropped; see trimHistory).
+	history []FrameRecord
+
+	// liveRows holds the latest computed rows for the frame currently in progress.
+	// Nil when no frame is active.
+	liveRows []ResultRow
+
+	// selectedHistoryIdx is the index into history that the user is viewing.
+	// -1 means no specific frame is selected (defers to viewingCurrent).
+	selectedHistoryIdx int
+
+	// viewingCurrent is true when the UI is displaying the live in-progress frame
+	// rather than a completed one from history.
+	viewingCurrent bool
+
+	// reference is a copy of the completed frame the current-frame table is
+	// compared against (see SetReferenceFrame); nil for none.
+	reference *FrameRecord
+
+	// frozenPIDs is the set of rows the live table is pinned to while
+	// freezeTopN is set; nil while no set has been picked.
+	frozenPIDs []int
+
+	// summaryRange limits the summary, statistics, and by-command tables to a
+	// span of completed frames picked in the history popup. The zero value
+	// aggregates the whole history.
+	summaryRange frameRange
+
+	// utilizationStats are the rolling statistics of the recent completed
+	// frames' utilization that outliers are measured against (see
+	// flagOutlierLocked).
+	utilizationStats rollingStats
+
+	// summaryViewed holds the summary's totals when MarkSummaryViewed was
+	// last called, and summarySince those of the call before, which the
+	// since_viewed column is relative to; nil until then. Both are replaced,
+	// never modified, so they may be shared with a render after mu is
+	// released.
+	summaryViewed map[aggregateKey]float64
+	summarySince  map[aggregateKey]float64
+
+	// autoFollowLatestComplete causes the UI to automatically advance to the
+	// newest completed frame whenever a frame finishes, unless the user has
+	// manually navigated away.
+	autoFollowLatestComplete bool
+}
+
+// newFrameView returns the view of a run's first frame of the given length,
+// following the newest frame.
+func newFrameView(length float64) frameView {
+	return frameView{
+		length:                   length,
+		frameIndex:               1,
+		selectedHistoryIdx:       -1,
+		viewingCurrent:           true,
+		autoFollowLatestComplete: true,
+	}
+}
+
+// Monitor owns all mutable state for one monitoring session: the sampling
+// goroutine, the completed-frame history, the user's view selection, and the
+// display preferences. All fields must be accessed with mu held, except where
+// noted. Construct one with NewMonitor.
+type Monitor struct {
+	// controlMu serialises Start and Stop so that concurrent callers (the UI
+	// and the HTTP API) apply their transitions one after another. It is
+	// always acquired before mu.
+	controlMu sync.Mutex
+
+	mu           sync.Mutex
+	running      bool    // true while a monitoring goroutine is active
+	hideSmall    bool    // filter rows below 1 CPU-second in the UI
+	hidePaths    bool    // show only basename of the command, not full path
+	frameSeconds float64 // configured frame length in seconds
+
+	// smallFilterMode selects what hideSmall measures rows against, and
+	// smallFilterPercent is the threshold of SmallFilterPercent (see
+	// SetSmallFilter).
+	smallFilterMode    SmallFilterMode
+	smallFilterPercent float64
+
+	// spikeRatio is the peak-to-average ratio above which the summary marks
+	// a process spiky (see SetSpikeRatio).
+	spikeRatio float64
+
+	// anomalySigma is how many standard deviations above the rolling mean
+	// a frame's utilization must be to flag it as an outlier, and
+	// notifyAnomalies whether outliers are notified (see SetAnomalySigma).
+	anomalySigma    float64
+	notifyAnomalies bool
+
+	// secondFrameSeconds is the configured length of the second, concurrent
+	// frame length (see SetSecondFrameLength); 0 means none.
+	secondFrameSeconds float64
+
+	// startDelaySeconds is how long a run waits before its baseline
+	// snapshot (see SetStartDelay); 0 for none.
+	startDelaySeconds float64
+
+	// frameWarmup is how much of the start of each frame is discarded before
+	// its baseline is taken (see SetFrameWarmup); 0 for none.
+	frameWarmup time.Duration
+
+	// historyRetention is how long completed frames are kept after they
+	// end (see SetHistoryRetention); 0 keeps them by count alone.
+	historyRetention time.Duration
+
+	// showOnlyStartedWithin limits the current-frame table to processes
+	// started at most this long before the frame shown ended (see
+	// SetMaxProcessAge); 0 shows processes of any age.
+	showOnlyStartedWithin time.Duration
+
+	// frameView is the state of the frame length on display, and parked
+	// that of the other one while a run collects two (see
+	// SelectFrameLength); parked.length is 0 otherwise. Embedding keeps the
+	// displayed view's fields directly on the Monitor.
+	frameView
+	parked frameView
+
+	// liveRates holds each process's %CPU over the last tick (see
+	// tickRates), shared by every frame length and shown in the live view
+	// only. It is replaced, never modified in place, each tick; nil before
+	// the second snapshot of a run.
+	liveRates map[int]float64
+
+	// machineCPU is the whole machine's %CPU, all cores together, over the
+	// last tick (see machineUtilization); machineCPUUnknown before the
+	// second reading of a run or when the machine's CPU times cannot be read.
+	machineCPU float64
+
+	// columns and summaryColumns are the current-frame and summary table
+	// layouts (see SetColumns); nil for the default. Like patterns, each
+	// slice is replaced, never modified in place.
+	columns        []string
+	summaryColumns []string
+
+	// stopAfterFrames ends a run once it has completed that many frames of
+	// its first length; 0 runs until stopped. completionAlert selects whether
+	// the end of a run is announced (see SetCompletionAlert).
+	stopAfterFrames int
+	completionAlert CompletionAlert
+
+	// collectTopN limits completed frames to their busiest rows (see
+	// SetCollectTopN); 0 keeps every row.
+	collectTopN int
+
+	// summaryMinTotal hides summary rows whose total CPU-seconds across the
+	// history is below it; 0 shows every row. Unlike hideSmall it never
+	// affects the current-frame table.
+	summaryMinTotal float64
+
+	// summaryMinFrames hides summary rows for processes that appeared in
+	// fewer frames; 1 shows every row. Rows must pass both it and
+	// summaryMinTotal.
+	summaryMinFrames int
+
+	// averageMode selects the denominator for the summary's per-frame average.
+	averageMode AverageMode
+
+	// theme selects the colours of the rendered chart; systemDark is the
+	// system appearance ThemeSystem follows (see SetSystemAppearance).
+	theme      Theme
+	systemDark bool
+
+	// summarySortField and summarySortDescending order the summary table
+	// (see SetSummarySort); the tiebreaker orders rows equal in the field.
+	summarySortField      SummarySortField
+	summarySortDescending bool
+
+	// mergeReusedPIDs merges summary rows for processes that held the same
+	// PID and ran the same command (see aggregateHistory).
+	mergeReusedPIDs bool
+
+	// tiebreaker orders equal-CPU rows in the current-frame and summary
+	// tables (see SetTiebreaker).
+	tiebreaker Tiebreaker
+
+	// showSparklines adds a per-row trend sparkline of recent frames to the
+	// current-frame table.
+	showSparklines bool
+
+	// groupMode rolls app helper processes up in the current-frame table.
+	groupMode GroupMode
+
+	// patterns holds the exclude, include, watch, and noise lists, indexed by
+	// PatternList. Each slice is replaced, never modified in place, so it
+	// may be shared with a render after mu is released.
+	patterns [patternListCount][]string
+
+	// noiseIncludesSelf leaves FrameScope's own process out of the frame
+	// totals along with the NoiseList (see SetNoiseIncludesSelf).
+	noiseIncludesSelf bool
+
+	// aliases holds the user's labels for processes by PID and by command
+	// pattern (see SetAlias).
+	aliases aliasTable
+
+	// showShare adds each row's share of the frame's total CPU to the
+	// current-frame table.
+	showShare bool
+
+	// showPercent adds each row's CPU as %CPU of one core over the frame to
+	// the current-frame table, next to its CPU-seconds.
+	showPercent bool
+
+	// showLiveDelta fills the current-frame table's delta column in the live
+	// view (see SetShowLiveDelta).
+	showLiveDelta bool
+
+	// showUserSys splits each row's CPU-seconds into user and system time
+	// in two extra columns of the current-frame table.
+	showUserSys bool
+
+	// markTiny shows positive CPU-seconds that round to 0.0 as "<0.1" in
+	// both tables (see SetMarkTinyValues).
+	markTiny bool
+
+	// showStatus adds a column to the current-frame table marking zombie and
+	// stopped processes.
+	showStatus bool
+
+	// includeKernelTask keeps kernel_task (see isKernelTask) in the
+	// snapshots; by default it is dropped before any table sees it.
+	includeKernelTask bool
+
+	// menuBarItem enables the menu bar indicator of the busiest process
+	// (see SetMenuBarItem).
+	menuBarItem bool
+
+	// showOnlyMine drops processes not owned by the user running FrameScope
+	// from the snapshots.
+	showOnlyMine bool
+
+	// runID is incremented each time monitoring starts or stops. It is used by
+	// background goroutines to detect whether their results are still relevant.
+	runID  int64
+	cancel context.CancelFunc // cancels the active monitoring context; nil when stopped
+
+	// stopRequest asks the active run to complete the frame in progress and
+	// end, and runDone is closed once the run's goroutine has returned (see
+	// Shutdown). Both are nil when stopped.
+	stopRequest chan struct{}
+	runDone     chan struct{}
+
+	// replay is the playback of the history (see ReplayPlay), at
+	// replaySpeed frames per second (0 means defaultReplaySpeed), starting
+	// over after the last frame if loopReplay is set.
+	replay      replayState
+	replaySpeed float64
+	loopReplay  bool
+
+	// paused holds the active run's sampling until Resume (see Pause).
+	// windowHidden is whether the window can be seen (see SetWindowVisible),
+	// which pauses the run while pauseOnWindowClose is set unless
+	// windowPauseLifted, set by Resume until the window is next shown or
+	// hidden. pauseWake tells the active run any of them may have changed;
+	// it is nil when stopped. resumeMode decides what becomes of the frames
+	// in progress on resuming (see SetResumeMode).
+	paused             bool
+	pauseOnWindowClose bool
+	windowHidden       bool
+	windowPauseLifted  bool
+	pauseWake          chan struct{}
+	resumeMode         ResumeMode
+
+	// changedOnly limits the current-frame table to processes whose CPU
+	// moved by at least changedMinDelta CPU-seconds versus the previous
+	// completed frame, plus processes that started or exited.
+	changedOnly     bool
+	changedMinDelta float64
+
+	// freezeTopN pins the live table to frozenPIDs, the top processes of the
+	// previous completed frame or, lacking one, of the first update, so that
+	// rows keep their positions until the frame ends (see SetFreezeTopN).
+	freezeTopN bool
+
+	// activityThreshold is the CPU-seconds per frame a process needs to count
+	// as active rather than idle noise. It is separate from the Hide <1s
+	// display threshold (hideSmallThreshold).
+	activityThreshold float64
+
+	// budget is the session CPU budget (see SetCPUBudget); its zero value
+	// means none.
+	budget cpuBudget
+
+	// baseline holds the completed frames of a session loaded for comparison
+	// (see LoadBaselineSession); nil means none.
+	baseline []FrameRecord
+
+	// skipHint is the permission hint shown in the status bar during the
+	// frame it was raised in, and skipHintShown keeps it to once per run
+	// (see noteSkipped).
+	skipHint      string
+	skipHintShown bool
+
+	// focus restricts the snapshots to a process subtree (see SetFocusPID);
+	// nil means every process. focusNote says in the status bar, like
+	// skipHint, that focus ended because its root exited.
+	focus     *focusTree
+	focusNote string
+
+	// chartExportEvery and chartExportDir save a time-lapse chart every so
+	// many frames (see SetChartExport). chartWrites counts the charts being
+	// written; chartSnapshotErr is the first failure of the run, shown in the
+	// status bar like skipHint, and chartSnapshotFailed keeps it to once per
+	// run.
+	chartExportEvery    int
+	chartExportDir      string
+	chartWrites         sync.WaitGroup
+	chartSnapshotErr    string
+	chartSnapshotFailed bool
+
+	// recordErr is the latest failure to record a frame (see SetRecorder),
+	// shown in the status bar like skipHint until a frame is recorded again.
+	recordErr string
+
+	status string // human-readable status line shown in the status bar
+
+	// watchedThrough is when the newest frame handed to gRPC watchers ended
+	// (see unwatchedFramesLocked).
+	watchedThrough time.Time
+
+	// ui receives rendered payloads. It is set once before the monitor is used
+	// and never reassigned afterwards, so it may be read without mu.
+	ui UISink
+
+	// streams fans UI refreshes out to WebSocket clients of the HTTP API. It
+	// has its own lock and is never reassigned.
+	streams *streamHub[[]byte]
+
+	// watchers fans completed frames out to gRPC watchers (see frameService).
+	// It has its own lock and is never reassigned.
+	watchers *streamHub[*framescopepb.Frame]
+
+	// icons caches application icon paths by executable (see IconPath). It
+	// has its own lock and is never reassigned.
+	icons *iconCache
+
+	// commands resolves the command lines of displayed rows (see
+	// commandCache). It has its own lock and is never reassigned.
+	commands *commandCache
+
+	// persistConfig is set by LoadConfig; when false, preference changes are
+	// kept in memory only.
+	persistConfig bool
+
+	// source supplies process snapshots (see SetProcessSource). Nil means
+	// SystemSource; like clock, it is never reassigned after the monitor is
+	// in use.
+	source ProcessSource
+
+	// retryLimit is how many snapshots in a row may fail before the run
+	// stops (see SetRetryLimit); 0 means defaultRetryLimit. retryBase is the
+	// first retry delay; 0 means defaultRetryBase, and tests shorten it.
+	// Neither is reassigned after the monitor is in use.
+	retryLimit int
+	retryBase  time.Duration
+
+	// binaryPayload delivers the row tables as binary to sinks that accept
+	// it (see SetBinaryPayload). It is never reassigned after the monitor is
+	// in use.
+	binaryPayload bool
+
+	// recorder receives every completed frame (see SetRecorder); nil means
+	// none. It is never reassigned after the monitor is in use.
+	recorder FrameRecorder
+
+	// clock returns the current time. Nil means time.Now; tests substitute a
+	// fake to control frame durations. Like ui, it is never reassigned after
+	// the monitor is in use.
+	clock func() time.Time
+
+	// cpuTimes reads the machine's cumulative CPU times for the meter. Nil
+	// means systemCPUTimes; like clock, it is never reassigned after the
+	// monitor is in use.
+	cpuTimes func() (cpu.TimesStat, error)
+
+	// snapshotting is set while SnapshotNow takes a reading, so that only one
+	// is taken at a time.
+	snapshotting bool
+
+	// cores is the number of logical cores utilization is measured against.
+	// Zero means runtime.NumCPU; tests set it to get fixed percentages.
+	cores int
+
+	// pid is FrameScope's own PID for SetNoiseIncludesSelf. Zero means
+	// os.Getpid; tests set it to stand for a sampled process.
+	pid int
+}
+
+// defaultSummaryMinTotal is the initial summary threshold, matching the
+// current-frame table's 1 CPU-second hideSmall cutoff.
+const defaultSummaryMinTotal = 1.0
+
+// NewMonitor returns a Monitor initialised with sensible defaults that posts
+// its UI payloads to sink.
+func NewMonitor(sink UISink) *Monitor {
+	return &Monitor{
+		hideSmall:             true,
+		smallFilterPercent:    defaultSmallFilterPercent,
+		spikeRatio:            defaultSpikeRatio,
+		anomalySigma:          defaultAnomalySigma,
+		hidePaths:             false,
+		frameSeconds:          15,
+		summaryMinTotal:       defaultSummaryMinTotal,
+		summaryMinFrames:      1,
+		summarySortDescending: true,
+		changedMinDelta:       defaultChangedMinDelta,
+		activityThreshold:     defaultActivityThreshold,
+		machineCPU:            machineCPUUnknown,
+		frameView:             frameView{selectedHistoryIdx: -1},
+		ui:                    sink,
+		streams:               newStreamHub[[]byte](),
+		watchers:              newStreamHub[*framescopepb.Frame](),
+		icons:                 newIconCache(),
+		commands:              newCommandCache(),
+	}
+}
+
+// now returns the current time from the monitor's clock.
+func (m *Monitor) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock()
+}
+
+// coreCount returns the number of logical cores utilization is measured
+// against.
+func (m *Monitor) coreCount() int {
+	if m.cores <= 0 {
+		return runtime.NumCPU()
+	}
+	return m.cores
+}
@@ -0,0 +1,632 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// run is the core sampling loop. It runs in its own goroutine and is
+// cancelled via ctx when the user stops monitoring or starts a new run.
+//
+// A failed snapshot does not end the run: retrySnapshot backs off and tries
+// again, and once a snapshot succeeds the frames in progress restart from it.
+// Only a run of failures reaching the retry limit stops monitoring.
+//
+// The loop ticks every 500 ms. On each tick it takes a snapshot of all running
+// processes, diffs it against the previous tick's for the live rate column (see
+// tickRates), does the same with the machine's CPU times for the machine meter
+// (see machineUtilization), and, for each of the frame lengths in lengths,
+// diffs the CPU times against that length's baseline and updates the length's
+// frameView: the displayed one also resolves the commands of the rows the table
+// shows and sets the status line. It then pushes a UI refresh. When a length's
+// elapsed time is reached the current snapshot becomes its baseline for the
+// next frame, the completed frame, with the commands of all its rows resolved,
+// is appended to its history, and its cycle resets. One snapshot stream thus
+// feeds every length, whose frames end independently. The CPU budget, the
+// recorder, and the time-lapse charts (see SetChartExport) follow the first
+// length only.
+//
+// A positive delay holds off the baseline snapshot (see waitStartDelay). A
+// positive warmup discards the start of every frame: its baseline is retaken
+// once warmup has passed (see SetFrameWarmup), clamped per length by
+// frameWarmup.
+//
+// Once the first length has completed stopAfterFrames frames, if set, the
+// run ends by itself (see finishRun).
+//
+// A value on stopRequest ends the run after completing the frames in
+// progress as if their time were up (see Shutdown); cancelling ctx ends it at
+// once.
+//
+// While the run is paused (see Pause) it takes no snapshots and waits for a
+// value on wake; once resumed, the frames in progress restart from a fresh
+// snapshot or are extended by the pause, by the resume mode (see
+// SetResumeMode).
+//
+// runID is compared against m.runID on every write to detect stale goroutines
+// from previous runs. Frame timing is read from m.now rather than the ticker so
+// that tests can drive it with a fake clock.
+func (m *Monitor) run(ctx context.Context, runID int64, lengths []float64, delay, warmup time.Duration, stopRequest, wake <-chan struct{}) {
+	if delay > 0 && !m.waitStartDelay(ctx, runID, delay, stopRequest) {
+		return
+	}
+	baseline, err := m.snapshot()
+	if err != nil {
+		var ok bool
+		if baseline, ok = m.retrySnapshot(ctx, runID, err); !ok {
+			return
+		}
+	}
+
+	tracks := make([]frameTrack, len(lengths))
+	for i, length := range lengths {
+		duration := time.Duration(length * float64(time.Second))
+		tracks[i] = frameTrack{length: length, duration: duration, warmup: frameWarmup(warmup, duration)}
+	}
+	// lastTick and lastTickAt are the previous snapshot and when it was
+	// taken, for the per-tick rates, and lastTimes the machine's CPU times
+	// read with it for the machine meter (haveTimes is false if they could
+	// not be read).
+	var lastTick map[int]ProcessSample
+	var lastTickAt time.Time
+	var lastTimes cpu.TimesStat
+	var haveTimes bool
+
+	// restart begins every length's frame in progress afresh from samples.
+	restart := func(samples map[int]ProcessSample) {
+		start := m.now()
+		lastTick, lastTickAt = samples, start
+		lastTimes, haveTimes = m.machineTimes()
+		m.mu.Lock()
+		m.liveRates = nil
+		m.machineCPU = machineCPUUnknown
+		for i := range tracks {
+			tracks[i].baseline, tracks[i].start, tracks[i].warmedAt = samples, start, time.Time{}
+			tracks[i].last, tracks[i].exited = samples, 0
+			tracks[i].started, tracks[i].ended = 0, 0
+			tracks[i].paused = 0
+			if v := m.viewLocked(tracks[i].length); v != nil {
+				v.frameStart = start
+				v.liveRows = nil
+			}
+		}
+		m.mu.Unlock()
+	}
+	restart(baseline)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	// limitReached is set to the frame count once the frame limit is reached.
+	limitReached := 0
+
+	// updateFrame takes a fresh snapshot, advances every length with it, and
+	// pushes a UI refresh, followed by the budget alert and the outlier
+	// notification if either was raised. finish completes every frame in
+	// progress that is not just beginning.
+	updateFrame := func(now time.Time, finish bool) error {
+		current, err := m.snapshot()
+		if err != nil {
+			return err
+		}
+
+		rates := tickRates(lastTick, current, now.Sub(lastTickAt))
+		lastTick, lastTickAt = current, now
+		machineCPU := machineCPUUnknown
+		times, ok := m.machineTimes()
+		if ok && haveTimes {
+			if percent, advanced := machineUtilization(lastTimes, times); advanced {
+				machineCPU = percent
+			}
+		}
+		lastTimes, haveTimes = times, ok
+		m.mu.Lock()
+		tiebreak := m.tiebreaker
+		if rates != nil {
+			m.liveRates = rates
+		}
+		m.machineCPU = machineCPU
+		m.mu.Unlock()
+		var alertTitle, alertBody string
+		var alert bool
+		var outlierTitle, outlierBody string
+		var outlier bool
+		var chart chartSnapshot
+		var chartDue bool
+		for i := range tracks {
+			t := &tracks[i]
+			if finish && (t.warming() || now.Sub(t.measuredFrom()).Seconds() < minFrameSeconds) {
+				// A frame that has only just begun, or whose measurement
+				// has not, holds nothing worth keeping.
+				continue
+			}
+			frame, completed := m.advanceTrack(t, current, now, finish, tiebreak)
+			if !completed || i > 0 {
+				continue
+			}
+			m.mu.Lock()
+			if v := m.viewLocked(t.length); v != nil {
+				alertTitle, alertBody, alert = m.checkBudgetLocked(v.history)
+				chart, chartDue = m.chartSnapshotLocked(frame, v.history)
+			}
+			outlierTitle, outlierBody, outlier = m.outlierAlertLocked(frame)
+			if limit := m.stopAfterFrames; limit > 0 && frame.Index >= limit {
+				limitReached = frame.Index
+			}
+			m.mu.Unlock()
+			if m.recorder != nil {
+				err := m.recorder.RecordFrame(frame)
+				m.mu.Lock()
+				m.recordErr = ""
+				if err != nil {
+					m.recordErr = fmt.Sprintf("recording frame %d failed: %v", frame.Index, err)
+				}
+				m.mu.Unlock()
+			}
+		}
+		if chartDue {
+			m.writeChartSnapshot(runID, chart)
+		}
+		m.pushUI(runID)
+		if alert {
+			m.postNotification(runID, alertTitle, alertBody)
+		}
+		if outlier {
+			m.postNotification(runID, outlierTitle, outlierBody)
+		}
+		return nil
+	}
+
+	// restartFrame recovers from failed snapshots: the frames in progress
+	// start over from the snapshot that finally succeeded, since the gap
+	// would otherwise be attributed to them.
+	restartFrame := func(err error) bool {
+		current, ok := m.retrySnapshot(ctx, runID, err)
+		if !ok {
+			return false
+		}
+		restart(current)
+		m.mu.Lock()
+		m.status = fmt.Sprintf("Running. Snapshots recovered; frame %d restarted.", m.frameIndex)
+		m.mu.Unlock()
+		m.pushUI(runID)
+		return true
+	}
+
+	// resume goes on after a pause of pausedFor by the resume mode: it
+	// restarts the frames in progress from a fresh snapshot, since the time
+	// paused was not measured, or extends them by pausedFor, keeping their
+	// baselines. Either way the per-tick rates start over from the fresh
+	// snapshot, rather than averaging over the pause.
+	resume := func(pausedFor time.Duration) bool {
+		current, err := m.snapshot()
+		if err != nil {
+			return restartFrame(err)
+		}
+		m.mu.Lock()
+		mode := m.resumeMode
+		m.mu.Unlock()
+		if mode != ResumeContinue {
+			restart(current)
+			m.mu.Lock()
+			m.status = fmt.Sprintf("Running. Resumed; frame %d restarted.", m.frameIndex)
+			m.mu.Unlock()
+			m.pushUI(runID)
+			return true
+		}
+		lastTick, lastTickAt = current, m.now()
+		lastTimes, haveTimes = m.machineTimes()
+		for i := range tracks {
+			tracks[i].paused += pausedFor
+		}
+		m.mu.Lock()
+		m.status = fmt.Sprintf("Running. Resumed; frame %d continued, extended by %.1fs.", m.frameIndex, pausedFor.Seconds())
+		m.mu.Unlock()
+		m.pushUI(runID)
+		return true
+	}
+
+	// Take an immediate first snapshot so the UI is not blank for the first tick.
+	if err := updateFrame(tracks[0].start, false); err != nil && !restartFrame(err) {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopRequest:
+			updateFrame(m.now(), true)
+			return
+		case <-ticker.C:
+			if m.isPaused() {
+				pausedAt := m.now()
+				if !m.waitWhilePaused(ctx, runID, stopRequest, wake) || !resume(m.now().Sub(pausedAt)) {
+					return
+				}
+				ticker.Reset(500 * time.Millisecond)
+				continue
+			}
+			if err := updateFrame(m.now(), false); err != nil && !restartFrame(err) {
+				return
+			}
+			if limitReached > 0 {
+				m.finishRun(runID, limitReached)
+				return
+			}
+		}
+	}
+}
+
+// frameTrack is the run loop's accumulator for one frame length: the
+// snapshot its frame in progress is diffed against and when that frame began.
+type frameTrack struct {
+	length   float64 // seconds, as in frameView.length
+	duration time.Duration
+	baseline map[int]ProcessSample
+	start    time.Time
+
+	// warmup is how much of each frame is discarded (see SetFrameWarmup),
+	// and warmedAt when the baseline was retaken at its end; zero while the
+	// frame is warming up or without a warm-up.
+	warmup   time.Duration
+	warmedAt time.Time
+
+	// last is the previous tick's snapshot, and exited the CPU-seconds the
+	// processes that have exited since the baseline used during the frame
+	// (see exitedCPU). started and ended count the processes that have
+	// started and exited since the baseline (see processChurn).
+	last           map[int]ProcessSample
+	exited         float64
+	started, ended int
+
+	// paused is how far the frame in progress was extended by pauses it
+	// continued across (see ResumeContinue).
+	paused time.Duration
+}
+
+// warming reports whether t's frame in progress is still in its warm-up.
+func (t *frameTrack) warming() bool {
+	return t.warmup > 0 && t.warmedAt.IsZero()
+}
+
+// due reports whether t's frame in progress has run for its full length at
+// now, extended by any pauses it continued across.
+func (t *frameTrack) due(now time.Time) bool {
+	return now.Sub(t.start) >= t.duration+t.paused
+}
+
+// measuredFrom returns when t's baseline was taken: the end of the warm-up,
+// if there is one, otherwise the start of the frame.
+func (t *frameTrack) measuredFrom() time.Time {
+	if t.warmedAt.IsZero() {
+		return t.start
+	}
+	return t.warmedAt
+}
+
+// advanceTrack diffs current against t's baseline and updates the frameView
+// of t's length. If the frame is due (see due), or finish is set, it also
+// appends the completed frame to the history, resets t to begin the next one
+// from current, and returns the frame with completed set. Only the displayed
+// length resolves commands for its live rows and sets the status line.
+//
+// Once the warm-up of the frame has passed, current becomes its baseline
+// instead, so the frame measures only the time since; its Duration and
+// StartedAt, and the view's frameStart, follow the measured window.
+//
+// Processes that were in the previous tick's snapshot but are gone from
+// current add their estimated CPU to the frame's ExitedCPU (see exitedCPU),
+// and every tick's churn is added to its NewProcesses and ExitedProcesses.
+func (m *Monitor) advanceTrack(t *frameTrack, current map[int]ProcessSample, now time.Time, finish bool, tiebreak Tiebreaker) (frame FrameRecord, completed bool) {
+	last := t.last
+	if last == nil {
+		last = t.baseline
+	}
+	started, ended := processChurn(last, current)
+	t.exited += exitedCPU(t.baseline, t.last, current, t.measuredFrom().UnixMilli())
+	t.started += started
+	t.ended += ended
+	t.last = current
+	warmedUp := false
+	if t.warming() && now.Sub(t.start) >= t.warmup {
+		t.baseline, t.warmedAt, t.exited = current, now, 0
+		t.started, t.ended = 0, 0
+		warmedUp = true
+	}
+	results := ComputeResults(t.baseline, current, tiebreak)
+	completed = finish || t.due(now)
+
+	m.mu.Lock()
+	displayed := m.frameView.length == t.length
+	var opts TableOptions
+	if displayed && !completed {
+		opts = TableOptions{HideSmall: m.hideSmall, Group: m.groupMode}
+		if opts.SmallPercent = m.smallPercentLocked(); opts.SmallPercent > 0 {
+			opts.Elapsed = now.Sub(t.measuredFrom())
+		}
+		if m.changedOnly {
+			opts.ChangedOnly = true
+			if len(m.history) > 0 {
+				opts.Previous = m.history[len(m.history)-1].Rows
+			}
+			opts.MinChange = m.changedMinDelta
+			opts.MinActivity = m.activityThreshold
+		}
+		opts.Exclude, opts.Include, opts.Watch = m.patterns[ExcludeList], m.patterns[IncludeList], m.patterns[WatchList]
+		if m.showOnlyStartedWithin > 0 {
+			opts.MaxAge, opts.AgeAt = m.showOnlyStartedWithin, now
+		}
+		if m.freezeTopN {
+			if m.frozenPIDs == nil {
+				m.frozenPIDs = topPIDs(results, opts, frozenRowCount)
+			}
+			opts.Pinned = m.frozenPIDs
+		}
+	}
+	m.mu.Unlock()
+
+	switch {
+	case completed:
+		m.commands.fill(results, nil, t.start)
+		m.commands.prune(current)
+	case displayed:
+		visible := displayedPIDs(results, opts)
+		m.commands.fill(results, func(pid int) bool { return visible[pid] }, t.start)
+	default:
+		// Nothing shows these rows yet: use cached commands only.
+		m.commands.fill(results, func(int) bool { return false }, t.start)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v := m.viewLocked(t.length)
+	if v == nil {
+		return FrameRecord{}, false
+	}
+	displayed = v == &m.frameView
+	v.liveRows = cloneRows(results)
+	if warmedUp {
+		v.frameStart = now
+	}
+	if displayed {
+		m.status = m.buildStatusLocked(t.length+t.paused.Seconds(), t.start, now, results)
+		if t.warming() {
+			m.status += fmt.Sprintf(" | warming up, measuring in %.1fs", (t.warmup - now.Sub(t.start)).Seconds())
+		}
+	}
+	if !completed || !m.running {
+		return FrameRecord{}, false
+	}
+
+	// Frames beyond maxHistory or older than the retention are discarded
+	// and selectedHistoryIdx is adjusted so the UI selection remains stable.
+	// Only the busiest rows are kept if collection is limited; the
+	// utilization still covers every process.
+	kept, dropped := keepTopRows(results, m.collectTopN)
+	frame = FrameRecord{
+		Index:       v.frameIndex,
+		Rows:        cloneRows(kept),
+		Duration:    now.Sub(t.measuredFrom()),
+		StartedAt:   t.measuredFrom(),
+		EndedAt:     now,
+		DroppedRows: dropped,
+		ExitedCPU:   t.exited,
+
+		SampledCount:    len(current),
+		NewProcesses:    t.started,
+		ExitedProcesses: t.ended,
+	}
+	frame.Utilization = utilization(m.noiseLocked().countedCPU(results), frame.Duration, m.coreCount())
+	m.flagOutlierLocked(v, &frame)
+	v.history = append(v.history, frame)
+	v.trimHistory(now, m.historyRetention)
+	if v.autoFollowLatestComplete || len(v.history) == 1 {
+		v.viewingCurrent = false
+		v.selectedHistoryIdx = len(v.history) - 1
+		v.autoFollowLatestComplete = true
+	}
+	v.frameIndex++
+	v.frameStart = now
+	v.liveRows = nil
+	v.frozenPIDs = nil
+	if displayed {
+		if m.freezeTopN {
+			m.frozenPIDs = topPIDs(results, m.tableOptionsLocked(), frozenRowCount)
+		}
+		m.skipHint = ""
+		m.chartSnapshotErr = ""
+		m.focusNote = ""
+		m.status = fmt.Sprintf("Running. Frame %d started. Length %.1fs.", v.frameIndex, t.length)
+	}
+	t.baseline, t.start, t.warmedAt, t.exited = current, now, time.Time{}, 0
+	t.started, t.ended, t.paused = 0, 0, 0
+	return frame, true
+}
+
+const (
+	// defaultRetryLimit is how many snapshots in a row may fail before the
+	// run stops, when SetRetryLimit has not been called.
+	defaultRetryLimit = 10
+
+	// defaultRetryBase is the delay before the first retry. It doubles with
+	// every further failure up to maxRetryDelay.
+	defaultRetryBase = time.Second
+	maxRetryDelay    = 30 * time.Second
+)
+
+// retryDelay returns how long to wait after the given number of consecutive
+// failures: base, doubled for each failure after the first, capped at
+// maxRetryDelay.
+func retryDelay(base time.Duration, failures int) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// retrySnapshot is called after a snapshot failed with err. It waits with
+// exponential backoff and tries again until a snapshot succeeds, which it
+// returns, or until the retry limit is reached, when it stops the run with an
+// error message and returns false. While waiting, the status line shows the
+// last error, the delay, and the failure count; the tables keep their rows.
+// It also returns false if ctx is cancelled.
+func (m *Monitor) retrySnapshot(ctx context.Context, runID int64, err error) (map[int]ProcessSample, bool) {
+	limit := m.retryLimit
+	if limit <= 0 {
+		limit = defaultRetryLimit
+	}
+	base := m.retryBase
+	if base <= 0 {
+		base = defaultRetryBase
+	}
+
+	for failures := 1; ; failures++ {
+		if failures >= limit {
+			m.postError(runID, fmt.Sprintf("Snapshot failed %d times in a row; monitoring stopped: %v", failures, err))
+			m.stopFromWorker(runID)
+			return nil, false
+		}
+
+		delay := retryDelay(base, failures)
+		m.mu.Lock()
+		m.status = fmt.Sprintf("Snapshot failed: %v. Retrying in %s (%d failures).", err, delay, failures)
+		m.mu.Unlock()
+		m.pushUI(runID)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false
+		case <-timer.C:
+		}
+
+		var samples map[int]ProcessSample
+		if samples, err = m.snapshot(); err == nil {
+			return samples, true
+		}
+	}
+}
+
+// stopFromWorker marks the monitor as stopped. It is called
+// by the monitor goroutine itself when it encounters a fatal error. It is a
+// no-op if runID no longer matches m.runID (i.e. a newer run has already
+// started).
+func (m *Monitor) stopFromWorker(runID int64) {
+	m.mu.Lock()
+	if m.runID != runID {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.cancel = nil
+	m.mu.Unlock()
+}
+
+// SetProcessSource replaces the SystemSource the monitor samples, e.g. with
+// one using a different per-process timeout. It must be called before the
+// first Start.
+func (m *Monitor) SetProcessSource(source ProcessSource) {
+	m.source = source
+}
+
+// SetRetryLimit sets how many snapshots in a row may fail before monitoring
+// stops; failures below it are retried with exponential backoff. n <= 0
+// restores the default of 10. It must be called before the first Start.
+func (m *Monitor) SetRetryLimit(n int) {
+	m.retryLimit = n
+}
+
+// SetRecorder makes the monitor pass every completed frame to recorder, which
+// is flushed whenever monitoring stops. Closing it is left to the caller,
+// after a final Shutdown. It must be called before the first Start.
+func (m *Monitor) SetRecorder(recorder FrameRecorder) {
+	m.recorder = recorder
+}
+
+// maxHistory caps the number of retained completed frames.
+const maxHistory = 1000
+
+// snapshot reads every running process from the monitor's ProcessSource and
+// drops kernel_task unless includeKernelTask is set, other users' processes
+// if showOnlyMine is set, and the processes outside the focused subtree if
+// focus is set (see applyFocus), so that every table, the summary, and the
+// exports agree. The filters apply on top of any display filter. A
+// *SkippedError is not a failure: the samples are kept and the skips are
+// passed to noteSkipped.
+func (m *Monitor) snapshot() (map[int]ProcessSample, error) {
+	samples, err := m.processSource().Snapshot()
+	var skipped *SkippedError
+	if errors.As(err, &skipped) {
+		m.noteSkipped(skipped)
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	includeKernelTask := m.includeKernelTask
+	showOnlyMine := m.showOnlyMine
+	m.mu.Unlock()
+	uid := os.Getuid()
+	for pid, sample := range samples {
+		if (!includeKernelTask && isKernelTask(pid, sample)) || (showOnlyMine && !ownedBy(sample, uid)) {
+			delete(samples, pid)
+		}
+	}
+	m.applyFocus(samples)
+	return samples, nil
+}
+
+// processSource returns the monitor's ProcessSource, SystemSource if none
+// was set.
+func (m *Monitor) processSource() ProcessSource {
+	if m.source != nil {
+		return m.source
+	}
+	return SystemSource{}
+}
+
+// ownedBy reports whether sample is known to belong to uid. A process whose
+// owner could not be read is not.
+func ownedBy(sample ProcessSample, uid int) bool {
+	return sample.User != "" && sample.UID == uid
+}
+
+// isKernelTask reports whether a sample is macOS's kernel_task, which accounts
+// for kernel threads and, on some systems, thermal throttling as CPU time. It
+// is PID 0, but the executable name is checked too in case a source numbers
+// it differently.
+func isKernelTask(pid int, sample ProcessSample) bool {
+	return pid == 0 || filepath.Base(sample.Exe) == "kernel_task"
+}
+
+// skipHintRatio is the share of listed processes that must be refused for
+// lack of permission before the status bar suggests elevated privileges.
+const skipHintRatio = 0.1
+
+// noteSkipped sets a hint, shown by buildStatusLocked until the current frame
+// completes, the first time in a run that a snapshot is refused access to at
+// least skipHintRatio of the processes it lists. Later snapshots never repeat
+// it, so the status bar does not nag every frame.
+func (m *Monitor) noteSkipped(skipped *SkippedError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.skipHintShown || skipped.Listed == 0 || float64(skipped.Denied) < skipHintRatio*float64(skipped.Listed) {
+		return
+	}
+	m.skipHintShown = true
+	m.skipHint = fmt.Sprintf("%d of %d processes unreadable", skipped.Denied, skipped.Listed)
+	if skipped.Err != nil {
+		m.skipHint += fmt.Sprintf(" (%v)", skipped.Err)
+	}
+	m.skipHint += "; run with sudo to count them"
+}
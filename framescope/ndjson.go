@@ -0,0 +1,174 @@
+package framescope
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// maxNDJSONLine bounds one line of an NDJSON import; a frame of 500 rows with
+// long command lines stays well below it.
+const maxNDJSONLine = 16 << 20
+
+// ImportWarning describes an NDJSON line that ImportNDJSON skipped.
+type ImportWarning struct {
+	Line int // 1-based line number
+	Err  error
+}
+
+func (w ImportWarning) Error() string {
+	return fmt.Sprintf("line %d: %v", w.Line, w.Err)
+}
+
+// ImportNDJSON replaces the history with the completed frames recorded in
+// path, one frame object per line as written by ExportHistory to a ".ndjson"
+// file, and shows the newest of them. The monitor stays stopped, so the
+// imported frames can be browsed, summarised, and exported until the next
+// Start discards them. Lines that cannot be parsed, and in-progress frames,
+// are skipped and returned as warnings rather than failing the import; if
+// more than maxHistory frames remain only the newest are kept. It is an error
+// to import while monitoring is running or to import a file without a usable
+// frame. Failures are reported via postError and returned; on success the
+// status line names the file.
+func (m *Monitor) ImportNDJSON(path string) ([]ImportWarning, error) {
+	frames, warnings, err := readNDJSONFile(path)
+	if err == nil && len(frames) == 0 {
+		err = errors.New("no completed frames found")
+	}
+	if err == nil {
+		m.mu.Lock()
+		if m.running {
+			err = errors.New("stop monitoring before importing")
+		}
+		m.mu.Unlock()
+	}
+	if err != nil {
+		m.postError(0, fmt.Sprintf("Import failed: %v", err))
+		return warnings, err
+	}
+	if len(frames) > maxHistory {
+		frames = frames[len(frames)-maxHistory:]
+	}
+
+	m.mu.Lock()
+	m.stopReplayLocked()
+	m.history = frames
+	m.parked = frameView{}
+	m.summaryRange = frameRange{}
+	m.liveRows = nil
+	m.viewingCurrent = false
+	m.selectedHistoryIdx = len(frames) - 1
+	m.autoFollowLatestComplete = false
+	m.status = fmt.Sprintf("Imported %d frames from %s.", len(frames), path)
+	if len(warnings) > 0 {
+		m.status = fmt.Sprintf("Imported %d frames from %s; skipped %d malformed lines.", len(frames), path, len(warnings))
+	}
+	m.mu.Unlock()
+	m.pushUI(0)
+	return warnings, nil
+}
+
+// readNDJSONFile opens path and parses it with readNDJSON.
+func readNDJSONFile(path string) ([]FrameRecord, []ImportWarning, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return readNDJSON(f)
+}
+
+// readNDJSON parses one exportFrame per line of r into completed frames, in
+// file order. Blank lines are ignored; malformed lines and in-progress frames
+// become warnings. The returned error is for r itself failing.
+func readNDJSON(r io.Reader) ([]FrameRecord, []ImportWarning, error) {
+	var frames []FrameRecord
+	var warnings []ImportWarning
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxNDJSONLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		frame, err := parseNDJSONFrame(scanner.Bytes())
+		if err != nil {
+			warnings = append(warnings, ImportWarning{Line: line, Err: err})
+			continue
+		}
+		if frame.Index == 0 {
+			frame.Index = len(frames) + 1
+		}
+		frames = append(frames, frame)
+	}
+	return frames, warnings, scanner.Err()
+}
+
+// parseNDJSONFrame decodes one exportFrame line into a FrameRecord with
+// importFrame.
+func parseNDJSONFrame(data []byte) (FrameRecord, error) {
+	var in exportFrame
+	if err := json.Unmarshal(data, &in); err != nil {
+		return FrameRecord{}, err
+	}
+	return importFrame(in)
+}
+
+// importFrame converts an exported frame back into a FrameRecord. Rows are
+// restored from the JSONFormatter fields and sorted by CPU, as ComputeResults
+// returns them. The duration falls back to the span between the timestamps.
+// Files written before utilization was recorded get it recomputed from the
+// rows against this machine's cores, which undercounts frames whose rows were
+// capped on export.
+func importFrame(in exportFrame) (FrameRecord, error) {
+	if in.InProgress {
+		return FrameRecord{}, errors.New("frame is still in progress")
+	}
+	var rows []jsonRow
+	if len(in.Rows) > 0 {
+		if err := json.Unmarshal(in.Rows, &rows); err != nil {
+			return FrameRecord{}, fmt.Errorf("rows: %w", err)
+		}
+	}
+
+	frame := FrameRecord{
+		Index:       in.Index,
+		StartedAt:   in.StartedAt,
+		EndedAt:     in.EndedAt,
+		Duration:    time.Duration(in.DurationSeconds * float64(time.Second)),
+		Rows:        make([]ResultRow, len(rows)),
+		Utilization: in.Utilization,
+
+		SampledCount:    in.SampledProcesses,
+		NewProcesses:    in.NewProcesses,
+		ExitedProcesses: in.ExitedProcesses,
+		Outlier:         in.Outlier,
+	}
+	if frame.Duration <= 0 && !in.StartedAt.IsZero() && in.EndedAt.After(in.StartedAt) {
+		frame.Duration = in.EndedAt.Sub(in.StartedAt)
+	}
+	for i, row := range rows {
+		frame.Rows[i] = ResultRow{
+			PID:         row.PID,
+			Diff:        row.CPUSeconds,
+			Command:     row.Command,
+			Status:      row.Status,
+			ExecCommand: row.ExecCommand,
+		}
+	}
+	sort.SliceStable(frame.Rows, func(i, j int) bool {
+		if frame.Rows[i].Diff == frame.Rows[j].Diff {
+			return frame.Rows[i].PID < frame.Rows[j].PID
+		}
+		return frame.Rows[i].Diff > frame.Rows[j].Diff
+	})
+	if in.Utilization == 0 {
+		frame.Utilization = utilization(frameCPU(frame.Rows), frame.Duration, runtime.NumCPU())
+	}
+	return frame, nil
+}
@@ -0,0 +1,84 @@
+package framescope
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ndjsonSample = `{"index":3,"started_at":"2026-03-04T05:06:07Z","ended_at":"2026-03-04T05:06:22Z","duration_seconds":15,"rows":[{"pid":2,"cpu_seconds":1,"duration":"00:00:01","command":"/bin/b"},{"pid":1,"cpu_seconds":4,"duration":"00:00:04","status":"zombie","command":"/bin/a"}]}
+{"index":4, "rows": [
+
+{"index":5,"started_at":"2026-03-04T05:06:22Z","ended_at":"2026-03-04T05:06:37Z","rows":[{"pid":1,"cpu_seconds":2,"duration":"00:00:02","command":"/bin/a"}]}
+{"index":6,"started_at":"2026-03-04T05:06:37Z","duration_seconds":3,"in_progress":true,"rows":[]}
+`
+
+func TestImportNDJSONSkipsBadLines(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	if err := os.WriteFile(path, []byte(ndjsonSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings, err := m.ImportNDJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || warnings[0].Line != 2 || warnings[1].Line != 5 {
+		t.Errorf("warnings = %v, want the truncated line 2 and the in-progress line 5", warnings)
+	}
+
+	frames := m.Frames()
+	if len(frames) != 2 || frames[0].Index != 3 || frames[1].Index != 5 {
+		t.Fatalf("frames = %+v, want frames 3 and 5", frames)
+	}
+	first := frames[0]
+	if first.Duration != 15*time.Second || !first.StartedAt.Equal(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("frame 3 span = %v from %v", first.Duration, first.StartedAt)
+	}
+	if first.Rows[0].PID != 1 || first.Rows[0].Status != "zombie" || first.Rows[1].PID != 2 {
+		t.Errorf("frame 3 rows = %+v, want sorted by CPU with state kept", first.Rows)
+	}
+	if frames[1].Duration != 15*time.Second {
+		t.Errorf("frame 5 duration = %v, want it from the timestamps", frames[1].Duration)
+	}
+
+	// The summary covers the imported frames: PID 1 used 4 + 2 CPU-seconds.
+	updates, _ := rec.snapshotUpdates()
+	last := updates[len(updates)-1]
+	summary := splitPayload(last.Summary)
+	if len(summary) == 0 || summary[0][0] != "1" || summary[0][1] != "6.0" {
+		t.Errorf("summary = %q, want PID 1 with 6.0 CPU-s first", summary)
+	}
+	if !strings.Contains(last.Status, "skipped 2 malformed lines") {
+		t.Errorf("status = %q", last.Status)
+	}
+}
+
+func TestImportNDJSONRoundTripsExport(t *testing.T) {
+	m, _ := newExportMonitor(t)
+	path := filepath.Join(t.TempDir(), "history.ndjson")
+	if err := m.ExportHistory(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := newTestMonitor(t)
+	warnings, err := loaded.ImportNDJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The live frame was exported in progress and is not imported.
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want the in-progress frame", warnings)
+	}
+	frames := loaded.Frames()
+	if len(frames) != 1 || frames[0].Rows[0].Command != "/bin/a, b" || frames[0].Duration != 15*time.Second {
+		t.Errorf("frames = %+v", frames)
+	}
+
+	if _, err := m.ImportNDJSON(path); err == nil {
+		t.Error("imported into a running monitor")
+	}
+}
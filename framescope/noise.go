@@ -0,0 +1,111 @@
+package framescope
+
+import (
+	"os"
+	"strconv"
+)
+
+// noiseFilter picks out the processes left out of the frame totals that
+// shares and utilization are measured against (see NoiseList), so that the
+// percentages describe the activity the user cares about rather than
+// FrameScope's own sampling and unavoidable helpers. The zero value counts
+// every process.
+type noiseFilter struct {
+	// patterns are the NoiseList entries: a decimal entry names a PID, any
+	// other a case-insensitive command pattern as in the other lists (see
+	// matchesAny).
+	patterns []string
+
+	// self is FrameScope's own PID when SetNoiseIncludesSelf is enabled, or
+	// 0.
+	self int
+}
+
+// matches reports whether row is noise, left out of the frame totals.
+func (f noiseFilter) matches(row ResultRow) bool {
+	if f.self != 0 && row.PID == f.self {
+		return true
+	}
+	for _, pattern := range f.patterns {
+		if pid, err := strconv.Atoi(pattern); err == nil {
+			if row.PID == pid {
+				return true
+			}
+			continue
+		}
+		if matchesAny(row, []string{pattern}) {
+			return true
+		}
+	}
+	return false
+}
+
+// countedCPU returns the CPU-seconds consumed by the rows of a frame that are
+// not noise: the frame total that shares and utilization are measured
+// against. Without noise it is frameCPU.
+func (f noiseFilter) countedCPU(rows []ResultRow) float64 {
+	if len(f.patterns) == 0 && f.self == 0 {
+		return frameCPU(rows)
+	}
+	total := 0.0
+	for _, row := range rows {
+		if !f.matches(row) {
+			total += row.Diff
+		}
+	}
+	return total
+}
+
+// countedShare returns the CPU-seconds of a displayed row that count toward
+// the frame total: none for a noise process, and for a group row the sum of
+// its members that are not noise.
+func (f noiseFilter) countedShare(row groupedRow) float64 {
+	if row.members != nil {
+		return f.countedCPU(row.members)
+	}
+	if f.matches(row.ResultRow) {
+		return 0
+	}
+	return row.Diff
+}
+
+// noiseLocked returns the filter for the user's NoiseList and, with
+// SetNoiseIncludesSelf, FrameScope itself. m.mu must be held.
+func (m *Monitor) noiseLocked() noiseFilter {
+	f := noiseFilter{patterns: m.patterns[NoiseList]}
+	if m.noiseIncludesSelf {
+		f.self = m.ownPID()
+	}
+	return f
+}
+
+// ownPID returns the PID of FrameScope itself.
+func (m *Monitor) ownPID() int {
+	if m.pid <= 0 {
+		return os.Getpid()
+	}
+	return m.pid
+}
+
+// SetNoiseIncludesSelf leaves FrameScope's own process out of the frame
+// totals, as if its PID were on the NoiseList, so that the CPU its sampling
+// costs does not dilute the shares of the processes under study. Like the
+// NoiseList it changes only the totals: FrameScope is still listed unless
+// excluded. Utilization is recorded as frames complete, so it applies to the
+// frames completed from then on; shares follow at once. The new setting is
+// persisted to disk immediately.
+func (m *Monitor) SetNoiseIncludesSelf(enabled bool) {
+	m.mu.Lock()
+	m.noiseIncludesSelf = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// NoiseIncludesSelf reports whether FrameScope's own process is left out of
+// the frame totals.
+func (m *Monitor) NoiseIncludesSelf() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.noiseIncludesSelf
+}
@@ -0,0 +1,85 @@
+package framescope
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNoiseLeftOutOfDenominators(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.cores = 2
+	m.pid = 30
+	m.running = true
+	m.frameView = newFrameView(10)
+	m.frameStart = clock.Now()
+	m.AddPattern(NoiseList, "spotlight")
+	m.AddPattern(NoiseList, "40")
+	m.SetNoiseIncludesSelf(true)
+	m.SetShowShare(true)
+	m.hideSmall = false
+
+	// node uses 19 CPU-s of a ten-second frame, Spotlight, FrameScope (PID
+	// 30), and PID 40 another 6 between them, and make 1.
+	sample := func(node, noise, other float64) map[int]ProcessSample {
+		return map[int]ProcessSample{
+			10: {CPUSeconds: node, Command: "/usr/local/bin/node"},
+			20: {CPUSeconds: noise, Command: "/System/Library/Spotlight/mds_stores"},
+			30: {CPUSeconds: noise, Command: "/Applications/FrameScope.app/Contents/MacOS/FrameScope"},
+			40: {CPUSeconds: noise, Command: "/usr/libexec/helper"},
+			50: {CPUSeconds: other, Command: "/usr/bin/make"},
+		}
+	}
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: sample(0, 0, 0), start: clock.Now()}
+	clock.Advance(10 * time.Second)
+	frame, completed := m.advanceTrack(track, sample(19, 2, 1), clock.Now(), false, TiebreakPID)
+	if !completed {
+		t.Fatal("frame did not complete")
+	}
+
+	// 20 of 26 CPU-s count: 100% of one core over ten seconds on two cores.
+	if math.Abs(frame.Utilization-100) > 1e-9 {
+		t.Errorf("utilization = %v, want 100 with the noise left out", frame.Utilization)
+	}
+
+	m.mu.Lock()
+	records := tableRecords(m.currentRowsLocked(), m.tableOptionsLocked())
+	m.mu.Unlock()
+	if len(records) != 5 {
+		t.Fatalf("%d rows shown, want the noise still listed among 5", len(records))
+	}
+	for _, record := range records {
+		noise := record.PID == 20 || record.PID == 30 || record.PID == 40
+		switch {
+		case noise && record.HasShare:
+			t.Errorf("PID %d is noise but has a share of %.1f%%", record.PID, record.Share)
+		case record.PID == 10 && math.Abs(record.Share-95) > 1e-9:
+			t.Errorf("node's share = %v, want 95%% of the counted activity", record.Share)
+		}
+	}
+
+	var detail frameDetail
+	if err := json.Unmarshal([]byte(m.FrameJSON(1)), &detail); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range detail.Rows {
+		if row.PID == 50 && math.Abs(row.SharePercent-5) > 1e-9 {
+			t.Errorf("JSON share of make = %v, want 5", row.SharePercent)
+		}
+		if row.PID == 30 && (row.SharePercent != 0 || len(row.Flags) != 1 || row.Flags[0] != "noise") {
+			t.Errorf("JSON row of FrameScope = %+v, want no share and the noise flag", row)
+		}
+	}
+
+	// Without the self option, FrameScope's 2 CPU-s count again.
+	m.SetNoiseIncludesSelf(false)
+	m.mu.Lock()
+	stats := computeSessionStats(m.history, m.coreCount(), m.noiseLocked())
+	m.mu.Unlock()
+	if math.Abs(stats.Utilization-110) > 1e-9 || stats.TotalCPU != 26 {
+		t.Errorf("session stats = %.1f%% of %v CPU-s, want 110%% of 26", stats.Utilization, stats.TotalCPU)
+	}
+}
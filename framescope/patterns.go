@@ -0,0 +1,97 @@
+package framescope
+
+import "strings"
+
+// PatternList names one of the user's command pattern lists. A pattern matches
+// a process whose command line or executable path contains it, ignoring case.
+type PatternList int
+
+const (
+	// ExcludeList hides matching processes from the current-frame table.
+	ExcludeList PatternList = iota
+
+	// IncludeList, when not empty, hides every process that matches none of
+	// its patterns from the current-frame table.
+	IncludeList
+
+	// WatchList keeps matching processes in the current-frame table even
+	// when they are excluded, not included, or below the Hide <1s
+	// threshold. It holds patterns rather than PIDs so that a watch outlives
+	// the process and survives a restart.
+	WatchList
+
+	// NoiseList leaves matching processes out of the frame totals that
+	// shares and utilization are measured against, without hiding them;
+	// exclude them as well to hide them (see noiseFilter). Unlike the other
+	// lists, a decimal entry names a PID.
+	NoiseList
+
+	patternListCount
+)
+
+// valid reports whether l is one of the defined lists.
+func (l PatternList) valid() bool {
+	return l >= 0 && l < patternListCount
+}
+
+// normalizePatterns returns patterns with surrounding space trimmed and
+// empty and duplicate entries dropped. Duplicates are compared ignoring
+// case, like matching, and the first spelling is kept.
+func normalizePatterns(patterns []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		key := strings.ToLower(pattern)
+		if pattern == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, pattern)
+	}
+	return out
+}
+
+// matchesAny reports whether row's command line or executable path contains
+// any of patterns, ignoring case. The executable is checked too because the
+// live frame resolves command lines only for displayed rows (see
+// commandCache).
+func matchesAny(row ResultRow, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	command := strings.ToLower(row.Command)
+	exe := strings.ToLower(row.Exe)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.Contains(command, pattern) || strings.Contains(exe, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRows applies the exclude, include, and watch lists and the maximum
+// process age in opts to rows. It returns the rows that pass and the PIDs of
+// the watched ones, which displayedRows keeps regardless of HideSmall.
+func filterRows(rows []ResultRow, opts TableOptions) ([]ResultRow, map[int]bool) {
+	if len(opts.Exclude) == 0 && len(opts.Include) == 0 && len(opts.Watch) == 0 && opts.MaxAge == 0 {
+		return rows, nil
+	}
+	out := make([]ResultRow, 0, len(rows))
+	watched := make(map[int]bool)
+	for _, row := range rows {
+		switch {
+		case matchesAny(row, opts.Watch):
+			watched[row.PID] = true
+		case matchesAny(row, opts.Exclude):
+			continue
+		case len(opts.Include) > 0 && !matchesAny(row, opts.Include):
+			continue
+		case opts.MaxAge > 0 && !startedWithin(row, opts.MaxAge, opts.AgeAt):
+			continue
+		}
+		out = append(out, row)
+	}
+	return out, watched
+}
@@ -0,0 +1,102 @@
+package framescope
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePatternsTrimsAndDedupes(t *testing.T) {
+	got := normalizePatterns([]string{" chrome ", "", "Chrome", "python", "  ", "python"})
+	if strings.Join(got, "|") != "chrome|python" {
+		t.Errorf("normalizePatterns = %q, want chrome, python", got)
+	}
+}
+
+func TestFilterRowsAppliesLists(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 1, Diff: 5, Command: "/usr/bin/python3 train.py"},
+		{PID: 2, Diff: 4, Command: "/Applications/Slack.app/Contents/MacOS/Slack"},
+		{PID: 3, Diff: 0.2, Exe: "/usr/sbin/backupd"}, // command not yet resolved
+		{PID: 4, Diff: 3, Command: "/usr/bin/make -j8"},
+	}
+	pids := func(rows []groupedRow) string {
+		var out []string
+		for _, row := range rows {
+			out = append(out, strconv.Itoa(row.PID))
+		}
+		return strings.Join(out, ",")
+	}
+
+	opts := TableOptions{HideSmall: true, Exclude: []string{"slack"}}
+	if got := pids(displayedRows(rows, opts)); got != "1,4" {
+		t.Errorf("excluding slack shows %s, want 1,4", got)
+	}
+
+	opts = TableOptions{HideSmall: true, Include: []string{"PYTHON", "slack"}}
+	if got := pids(displayedRows(rows, opts)); got != "1,2" {
+		t.Errorf("including python and slack shows %s, want 1,2", got)
+	}
+
+	// A watched process is shown although it is excluded, outside the
+	// include list, and below 1 CPU-second; it matches on its executable.
+	opts = TableOptions{HideSmall: true, Include: []string{"python"}, Exclude: []string{"backupd"}, Watch: []string{"backupd"}}
+	if got := pids(displayedRows(rows, opts)); got != "1,3" {
+		t.Errorf("watching backupd shows %s, want 1,3", got)
+	}
+}
+
+func TestPatternListsRoundTrip(t *testing.T) {
+	for _, list := range []PatternList{ExcludeList, IncludeList, WatchList} {
+		m, _ := newTestMonitor(t)
+		m.LoadConfig()
+		m.AddPattern(list, "chrome")
+		m.AddPattern(list, " Chrome ")
+		m.AddPattern(list, "python")
+		m.AddPattern(list, "make")
+		m.RemovePattern(list, "MAKE")
+
+		loaded := NewMonitor(DiscardSink{})
+		loaded.LoadConfig()
+		if got := loaded.Patterns(list); strings.Join(got, "|") != "chrome|python" {
+			t.Errorf("list %d loaded as %q, want chrome, python", list, got)
+		}
+		for other := PatternList(0); other < patternListCount; other++ {
+			if other != list && len(loaded.Patterns(other)) != 0 {
+				t.Errorf("adding to list %d also filled list %d: %q", list, other, loaded.Patterns(other))
+			}
+		}
+	}
+}
+
+func TestPatternListsLoadFromOldAndHandEditedConfigs(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A config written before the lists existed leaves them empty.
+	if err := os.WriteFile(path, []byte(`{"hide_small": false, "frame_seconds": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.LoadConfig()
+	if m.HideSmall() || len(m.Patterns(ExcludeList)) != 0 || len(m.Patterns(WatchList)) != 0 {
+		t.Errorf("old config: hideSmall=%v exclude=%q watch=%q", m.HideSmall(), m.Patterns(ExcludeList), m.Patterns(WatchList))
+	}
+
+	// Duplicates added by hand are dropped on load.
+	if err := os.WriteFile(path, []byte(`{"include": ["ssh", "SSH", " ", "git"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if got := loaded.Patterns(IncludeList); strings.Join(got, "|") != "ssh|git" {
+		t.Errorf("include loaded as %q, want ssh, git", got)
+	}
+}
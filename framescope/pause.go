@@ -0,0 +1,211 @@
+package framescope
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResumeMode selects what becomes of the frames in progress when a paused
+// run resumes (see SetResumeMode). The values are part of the cgo bridge
+// (GoSetResumeMode) and the config file, so they must not be renumbered.
+type ResumeMode int
+
+const (
+	// ResumeRebaseline restarts the frames in progress from a fresh
+	// snapshot, discarding what they had measured before the pause, so that
+	// a frame covers only sampled time: its elapsed time and %CPU leave the
+	// pause out, and so does the CPU used during it.
+	ResumeRebaseline ResumeMode = iota
+
+	// ResumeContinue keeps the frames in progress and their baselines, and
+	// moves their end out by the length of the pause, so that each still
+	// samples for its full length. The pause counts as part of the frame:
+	// the CPU used during it is included, and the frame's elapsed time,
+	// duration, and %CPU cover the whole window, pause and all, which makes
+	// the %CPU an average over wall-clock time.
+	ResumeContinue
+)
+
+// validResumeMode reports whether mode is one of the defined ResumeMode
+// values.
+func validResumeMode(mode ResumeMode) bool {
+	return mode == ResumeRebaseline || mode == ResumeContinue
+}
+
+// pausedLocked reports whether the active run should take no snapshots: the
+// user paused it (see Pause), or the window is hidden with
+// SetPauseOnWindowClose enabled, unless the menu bar item still shows the
+// results or the user resumed since the window was hidden. m.mu must be
+// held.
+func (m *Monitor) pausedLocked() bool {
+	if !m.running {
+		return false
+	}
+	return m.paused || m.windowPauseLocked()
+}
+
+// windowPauseLocked reports whether the hidden window alone pauses the run.
+// m.mu must be held.
+func (m *Monitor) windowPauseLocked() bool {
+	return m.pauseOnWindowClose && m.windowHidden && !m.windowPauseLifted && !m.menuBarItem
+}
+
+// pauseStatusLocked returns the status line of a paused run. m.mu must be
+// held.
+func (m *Monitor) pauseStatusLocked() string {
+	reason := "Paused."
+	if !m.paused {
+		reason = "Paused while the window is hidden."
+	}
+	action := "restarts"
+	if m.resumeMode == ResumeContinue {
+		action = "continues"
+	}
+	return fmt.Sprintf("%s Frame %d %s when monitoring resumes.", reason, m.frameIndex, action)
+}
+
+// wakeRunLocked tells the active run that its pause state may have changed.
+// It never blocks. m.mu must be held.
+func (m *Monitor) wakeRunLocked() {
+	select {
+	case m.pauseWake <- struct{}{}:
+	default:
+	}
+}
+
+// waitWhilePaused blocks the run loop while the run is paused, keeping the
+// status line current. It returns false if the run ended meanwhile, by
+// cancellation or a stop request, in which case the frames in progress are
+// discarded rather than completed, since they stopped being measured when the
+// pause began.
+func (m *Monitor) waitWhilePaused(ctx context.Context, runID int64, stopRequest, wake <-chan struct{}) bool {
+	for {
+		m.mu.Lock()
+		paused := m.pausedLocked()
+		if paused {
+			m.status = m.pauseStatusLocked()
+			m.liveRates = nil
+			m.machineCPU = machineCPUUnknown
+		}
+		m.mu.Unlock()
+		if !paused {
+			return true
+		}
+		m.pushUI(runID)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-stopRequest:
+			return false
+		case <-wake:
+		}
+	}
+}
+
+// isPaused reports whether the active run should take no snapshots (see
+// pausedLocked).
+func (m *Monitor) isPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pausedLocked()
+}
+
+// Pause stops the active run from taking snapshots, within one tick, until
+// Resume, e.g. to save power. When sampling resumes, the frames in progress
+// restart from a fresh snapshot or go on with the pause counted in them, as set
+// by SetResumeMode; completed frames are kept. Stopping a paused run discards
+// its frames in progress. A pause lasts until Resume, Start, or Stop, and is
+// not persisted. It does nothing while monitoring is stopped.
+func (m *Monitor) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return
+	}
+	m.paused = true
+	m.wakeRunLocked()
+}
+
+// Resume lifts a pause, whether from Pause or from a hidden window (see
+// SetPauseOnWindowClose); a window pause applies again the next time the
+// window is hidden.
+func (m *Monitor) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+	if m.windowHidden {
+		m.windowPauseLifted = true
+	}
+	m.wakeRunLocked()
+}
+
+// Paused reports whether the active run is paused, by Pause or by a hidden
+// window.
+func (m *Monitor) Paused() bool {
+	return m.isPaused()
+}
+
+// SetPauseOnWindowClose makes monitoring pause while the window is closed or
+// fully hidden (see SetWindowVisible), and resume when it is shown again, to
+// save power when FrameScope is left open out of sight. It never pauses while
+// the menu bar item is enabled, since that still shows the results, and
+// showing the window does not lift a pause made with Pause. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetPauseOnWindowClose(enabled bool) {
+	m.mu.Lock()
+	m.pauseOnWindowClose = enabled
+	m.wakeRunLocked()
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// PauseOnWindowClose reports whether monitoring pauses while the window is
+// hidden.
+func (m *Monitor) PauseOnWindowClose() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pauseOnWindowClose
+}
+
+// SetWindowVisible tells the monitor whether its window can be seen: false
+// when it is closed, minimized, or entirely covered, true when it is shown
+// again. It only matters with SetPauseOnWindowClose enabled.
+func (m *Monitor) SetWindowVisible(visible bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.windowHidden == !visible {
+		return
+	}
+	m.windowHidden = !visible
+	m.windowPauseLifted = false
+	m.wakeRunLocked()
+}
+
+// SetResumeMode chooses what becomes of the frames in progress when a paused
+// run resumes: ResumeRebaseline, the default, restarts them from a fresh
+// snapshot, and ResumeContinue keeps them and extends them by the pause (see
+// ResumeMode for how each affects elapsed time and %CPU). It applies from the
+// next resume, including that of a run paused now. Unknown modes are
+// ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetResumeMode(mode ResumeMode) {
+	if !validResumeMode(mode) {
+		return
+	}
+	m.mu.Lock()
+	m.resumeMode = mode
+	if m.pausedLocked() {
+		m.status = m.pauseStatusLocked()
+	}
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// ResumeMode returns what becomes of the frames in progress when a paused
+// run resumes.
+func (m *Monitor) ResumeMode() ResumeMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resumeMode
+}
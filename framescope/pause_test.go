@@ -0,0 +1,181 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPauseOnWindowCloseTransitions(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.running = true
+
+	m.SetWindowVisible(false)
+	if m.Paused() {
+		t.Fatal("paused on a hidden window with the option off")
+	}
+	m.SetPauseOnWindowClose(true)
+	if !m.Paused() {
+		t.Fatal("not paused with the window hidden and the option on")
+	}
+	m.SetWindowVisible(true)
+	if m.Paused() {
+		t.Fatal("still paused after the window was shown")
+	}
+
+	// Showing the window does not lift an explicit pause.
+	m.Pause()
+	m.SetWindowVisible(false)
+	m.SetWindowVisible(true)
+	if !m.Paused() {
+		t.Fatal("showing the window lifted an explicit pause")
+	}
+	m.Resume()
+	if m.Paused() {
+		t.Fatal("still paused after Resume")
+	}
+
+	// Resuming while hidden lasts until the window is next hidden.
+	m.SetWindowVisible(false)
+	m.Resume()
+	if m.Paused() {
+		t.Fatal("Resume did not lift the window pause")
+	}
+	m.SetWindowVisible(true)
+	m.SetWindowVisible(false)
+	if !m.Paused() {
+		t.Fatal("not paused when the window was hidden again")
+	}
+
+	// The menu bar item still shows the results, so nothing pauses.
+	m.SetMenuBarItem(true)
+	if m.Paused() {
+		t.Fatal("paused with the menu bar item on")
+	}
+	m.SetMenuBarItem(false)
+	if !m.Paused() {
+		t.Fatal("not paused once the menu bar item was turned off")
+	}
+
+	m.running = false
+	if m.Paused() {
+		t.Fatal("paused while stopped")
+	}
+}
+
+func TestPausedRunTakesNoSnapshots(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetPauseOnWindowClose(true)
+	if err := m.Start(60); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	// waitForStatus polls until the status line contains want.
+	waitForStatus := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			m.mu.Lock()
+			status := m.status
+			m.mu.Unlock()
+			if strings.Contains(status, want) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("status = %q, want it to contain %q", status, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	m.SetWindowVisible(false)
+	waitForStatus("Paused while the window is hidden.")
+	calls := source.calls.Load()
+	time.Sleep(time.Second)
+	if got := source.calls.Load(); got != calls {
+		t.Fatalf("%d snapshots taken while paused", got-calls)
+	}
+
+	m.SetWindowVisible(true)
+	waitForStatus("Resumed; frame 1 restarted.")
+	if source.calls.Load() == calls {
+		t.Error("no snapshot taken after resuming")
+	}
+}
+
+func TestResumeModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode      ResumeMode
+		status    string
+		remaining time.Duration // from resuming to the end of the frame
+		duration  time.Duration // of the frame, paused for 30 s 10 s in
+		started   time.Duration // after the run started
+	}{
+		{ResumeRebaseline, "Resumed; frame 1 restarted.", 60 * time.Second, 60 * time.Second, 40 * time.Second},
+		{ResumeContinue, "Resumed; frame 1 continued, extended by 30.0s.", 50 * time.Second, 90 * time.Second, 0},
+	} {
+		m, _ := newTestMonitor(t)
+		start := time.Unix(1000, 0)
+		clock := &fakeClock{now: start}
+		m.clock = clock.Now
+		m.SetProcessSource(&busySource{})
+		m.SetResumeMode(tc.mode)
+		if err := m.Start(60); err != nil {
+			t.Fatal(err)
+		}
+
+		// waitForStatus polls until the status line contains want.
+		waitForStatus := func(want string) {
+			t.Helper()
+			deadline := time.Now().Add(3 * time.Second)
+			for {
+				m.mu.Lock()
+				status := m.status
+				m.mu.Unlock()
+				if strings.Contains(status, want) {
+					return
+				}
+				if time.Now().After(deadline) {
+					m.Stop()
+					t.Fatalf("mode %d: status = %q, want it to contain %q", tc.mode, status, want)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		}
+
+		// 10 s in, the run pauses for 30 s.
+		waitForStatus("elapsed 0.0s")
+		clock.Advance(10 * time.Second)
+		waitForStatus("elapsed 10.0s")
+		m.Pause()
+		waitForStatus("Paused.")
+		clock.Advance(30 * time.Second)
+		m.Resume()
+		waitForStatus(tc.status)
+
+		// Rebaselined, the frame ends a full length after resuming; continued,
+		// 30 s later than it would have without the pause.
+		clock.Advance(tc.remaining - time.Second)
+		time.Sleep(1200 * time.Millisecond)
+		m.mu.Lock()
+		early := len(m.history)
+		m.mu.Unlock()
+		if early != 0 {
+			m.Stop()
+			t.Fatalf("mode %d: frame completed before its extended end", tc.mode)
+		}
+		clock.Advance(time.Second)
+		waitForFrames(t, m, 1, 3*time.Second)
+		m.Stop()
+
+		m.mu.Lock()
+		frame := m.history[0]
+		m.mu.Unlock()
+		if frame.Duration != tc.duration || !frame.StartedAt.Equal(start.Add(tc.started)) {
+			t.Errorf("mode %d: frame of %v from %v, want %v from %v", tc.mode, frame.Duration, frame.StartedAt, tc.duration, start.Add(tc.started))
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package framescope
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxPID is the largest PID a process can have: pid_t is 32 bits on macOS,
+// as are the PIDs the process sources read.
+const maxPID = math.MaxInt32
+
+// checkPID returns an error if pid cannot name a process, being non-positive
+// or too large for a pid_t. Whatever acts on or reads a live process by PID
+// checks it first, so that a PID that cannot be real, such as one from an
+// imported recording, is refused rather than truncated into the PID of
+// another process.
+func checkPID(pid int) error {
+	if pid <= 0 || pid > maxPID {
+		return fmt.Errorf("PID %d is out of range", pid)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package framescope
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHugePIDsAreRefused(t *testing.T) {
+	for _, tc := range []struct {
+		pid int
+		ok  bool
+	}{
+		{1, true},
+		{maxPID, true},
+		{maxPID + 1, false},
+		{1<<32 + 412, false}, // 412 once truncated to 32 bits
+		{0, false},
+		{-1, false},
+	} {
+		if err := checkPID(tc.pid); (err == nil) != tc.ok {
+			t.Errorf("checkPID(%d) = %v, want ok = %v", tc.pid, err, tc.ok)
+		}
+	}
+
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1, Duration: time.Second, Rows: []ResultRow{
+		{PID: 412, Diff: 3, Command: "/usr/bin/python3"},
+	}}}
+	m.selectedHistoryIdx = 0
+
+	// A PID that truncates to a displayed one must not be acted on as it.
+	huge := 1<<32 + 412
+	m.SetFocusPID(huge)
+	if pid, ok := m.FocusPID(); ok {
+		t.Errorf("focused on PID %d given %d", pid, huge)
+	}
+	updates, _ := rec.snapshotUpdates()
+	if status := updates[len(updates)-1].Status; !strings.Contains(status, "out of range") {
+		t.Errorf("status = %q, want the PID refused", status)
+	}
+	if got, err := m.CopyRowField(huge, FieldCommand); err == nil {
+		t.Errorf("copied %q for PID %d", got, huge)
+	}
+
+	m.SetFocusPID(maxPID)
+	if pid, ok := m.FocusPID(); !ok || pid != maxPID {
+		t.Errorf("FocusPID() = %d, %v; want %d at the 32-bit boundary", pid, ok, maxPID)
+	}
+
+	self := os.Getpid()
+	if resolveCommand(self) == "" {
+		t.Skip("cannot read this process's command line")
+	}
+	if got := resolveCommand(1<<32 + self); got != "" {
+		t.Errorf("resolveCommand read %q for a PID that truncates to this process", got)
+	}
+}
@@ -0,0 +1,43 @@
+package framescope
+
+import (
+	"math"
+	"time"
+)
+
+// startedWithin reports whether row's process started at most maxAge before
+// at. A process whose start time is unknown is treated as old and excluded:
+// start times are unavailable mostly for other users' long-running daemons,
+// which the filter is meant to hide.
+func startedWithin(row ResultRow, maxAge time.Duration, at time.Time) bool {
+	if row.CreateTime == 0 {
+		return false
+	}
+	return at.Sub(time.UnixMilli(row.CreateTime)) <= maxAge
+}
+
+// SetMaxProcessAge limits the current-frame table to processes started at
+// most seconds before the end of the frame shown, or before now for the live
+// frame, e.g. 60 to follow what launched in the last minute while chasing a
+// startup regression; 0 shows processes of any age. Processes whose start
+// time is unknown are left out while it is set. It composes with the pattern
+// lists, and watched processes are kept whatever their age. Negative or
+// non-finite values are ignored. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetMaxProcessAge(seconds float64) {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.showOnlyStartedWithin = time.Duration(seconds * float64(time.Second))
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// MaxProcessAge returns the maximum process age in seconds, 0 for none.
+func (m *Monitor) MaxProcessAge() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.showOnlyStartedWithin.Seconds()
+}
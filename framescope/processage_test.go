@@ -0,0 +1,59 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxProcessAgeFilter(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(10000, 0)}
+	m.clock = clock.Now
+	end := clock.Now()
+	startedAgo := func(age time.Duration) int64 { return end.Add(-age).UnixMilli() }
+	rows := []ResultRow{
+		{PID: 1, Diff: 5, Command: "/usr/bin/launchd-helper", CreateTime: startedAgo(10 * time.Second)},
+		{PID: 2, Diff: 4, Command: "/usr/bin/indexer", CreateTime: startedAgo(45 * time.Second)},
+		{PID: 3, Diff: 3, Command: "/usr/sbin/daemon", CreateTime: startedAgo(2 * time.Hour)},
+		{PID: 4, Diff: 2, Command: "/usr/sbin/rootd"}, // start time unknown
+		{PID: 5, Diff: 1, Command: "/usr/bin/old-worker", CreateTime: startedAgo(61 * time.Second)},
+	}
+	m.history = []FrameRecord{{Index: 1, Duration: 15 * time.Second, EndedAt: end, Rows: rows}}
+	m.viewingCurrent = false
+	m.selectedHistoryIdx = 0
+
+	// shown returns the PIDs of the table's rows.
+	shown := func() string {
+		m.mu.Lock()
+		opts := m.tableOptionsLocked()
+		m.mu.Unlock()
+		var pids []string
+		for _, row := range splitPayload(RenderTable(rows, opts, TabFormatter{})) {
+			pids = append(pids, row[0])
+		}
+		return strings.Join(pids, ",")
+	}
+
+	if got := shown(); got != "1,2,3,4,5" {
+		t.Fatalf("no age limit shows %s, want every process", got)
+	}
+	m.SetMaxProcessAge(60)
+	// Ages are measured at the end of the frame shown, not now.
+	clock.Advance(time.Hour)
+	if got := shown(); got != "1,2" {
+		t.Errorf("60s age limit shows %s, want 1,2", got)
+	}
+
+	m.AddPattern(ExcludeList, "indexer")
+	m.AddPattern(WatchList, "rootd")
+	if got := shown(); got != "1,4" {
+		t.Errorf("with indexer excluded and rootd watched shows %s, want 1,4", got)
+	}
+
+	m.SetMaxProcessAge(-1)
+	m.SetMaxProcessAge(0)
+	if got := shown(); got != "1,3,4,5" {
+		t.Errorf("after turning the age limit off shows %s, want 1,3,4,5", got)
+	}
+}
@@ -0,0 +1,69 @@
+package framescope
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FrameRecorder receives every frame the monitor completes, e.g. to append it
+// to a file as NDJSONRecorder does. RecordFrame may buffer; Flush writes the
+// buffered frames through and is called whenever monitoring stops (see
+// Monitor.Shutdown). Both are called from the sampling goroutine or the
+// stopping one, never concurrently with each other for the same monitor.
+type FrameRecorder interface {
+	RecordFrame(frame FrameRecord) error
+	Flush() error
+}
+
+// NDJSONRecorder appends completed frames to a file in the NDJSON export
+// format, one frame per line, so a session can be recorded while it runs and
+// viewed later with ImportNDJSON.
+type NDJSONRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// NewNDJSONRecorder opens path for appending, creating it if needed.
+func NewNDJSONRecorder(path string) (*NDJSONRecorder, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &NDJSONRecorder{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// RecordFrame buffers frame as one NDJSON line.
+func (r *NDJSONRecorder) RecordFrame(frame FrameRecord) error {
+	data, err := json.Marshal(newExportFrame(frame))
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(data); err != nil {
+		return err
+	}
+	return r.w.WriteByte('\n')
+}
+
+// Flush writes the buffered frames to the file.
+func (r *NDJSONRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Flush()
+}
+
+// Close flushes the buffered frames and closes the file. The recorder must
+// not be used afterwards.
+func (r *NDJSONRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.w.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package framescope
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bufferingRecorder is a FrameRecorder that holds recorded frames until
+// Flush, like a buffered file writer.
+type bufferingRecorder struct {
+	mu        sync.Mutex
+	pending   []int
+	flushed   []int
+	recordErr error
+	flushErr  error
+}
+
+func (r *bufferingRecorder) RecordFrame(frame FrameRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recordErr != nil {
+		return r.recordErr
+	}
+	r.pending = append(r.pending, frame.Index)
+	return nil
+}
+
+func (r *bufferingRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushed = append(r.flushed, r.pending...)
+	r.pending = nil
+	return r.flushErr
+}
+
+func (r *bufferingRecorder) snapshot() (flushed []int, pending int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.flushed...), len(r.pending)
+}
+
+// startRecordedRun starts m with a fake clock and rec, completes one frame,
+// and lets the second frame run for a few seconds of fake time.
+func startRecordedRun(t *testing.T, m *Monitor, rec FrameRecorder) {
+	t.Helper()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	m.SetRecorder(rec)
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("run did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 1, 5*time.Second)
+	clock.Advance(2 * time.Second)
+}
+
+func TestStopFlushesRecordedFrames(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	rec := &bufferingRecorder{}
+	startRecordedRun(t, m, rec)
+
+	if err := m.Shutdown(false); err != nil {
+		t.Fatal(err)
+	}
+	flushed, pending := rec.snapshot()
+	if len(flushed) != 1 || flushed[0] != 1 || pending != 0 {
+		t.Errorf("flushed = %v with %d pending, want frame 1 flushed", flushed, pending)
+	}
+	if len(m.Frames()) != 1 {
+		t.Errorf("history has %d frames, want the in-progress one discarded", len(m.Frames()))
+	}
+
+	// Stopping again is harmless.
+	if err := m.Shutdown(false); err != nil {
+		t.Fatal(err)
+	}
+	if m.Running() {
+		t.Error("monitor running after a second stop")
+	}
+}
+
+func TestShutdownFinalizesFrameInProgress(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	rec := &bufferingRecorder{}
+	startRecordedRun(t, m, rec)
+
+	if err := m.Shutdown(true); err != nil {
+		t.Fatal(err)
+	}
+	flushed, pending := rec.snapshot()
+	if len(flushed) != 2 || flushed[1] != 2 || pending != 0 {
+		t.Fatalf("flushed = %v with %d pending, want frames 1 and 2", flushed, pending)
+	}
+	frames := m.Frames()
+	if len(frames) != 2 || frames[1].Duration != 2*time.Second {
+		t.Errorf("frames = %+v, want a 2s final frame", frames)
+	}
+}
+
+func TestShutdownReportsFlushError(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetRecorder(&bufferingRecorder{flushErr: errors.New("disk full")})
+
+	if err := m.Shutdown(false); err == nil || err.Error() != "disk full" {
+		t.Fatalf("err = %v, want the flush error", err)
+	}
+	updates, _ := rec.snapshotUpdates()
+	if status := updates[len(updates)-1].Status; !strings.Contains(status, "disk full") {
+		t.Errorf("status = %q, want the flush error", status)
+	}
+}
+
+func TestRecordFailureStaysInStatus(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	startRecordedRun(t, m, &bufferingRecorder{recordErr: errors.New("disk full")})
+	if err := m.Shutdown(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failure outlives the status line the next frame sets.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start := time.Now()
+	if status := m.buildStatusLocked(5, start, start, nil); !strings.Contains(status, "| recording frame 1 failed: disk full") {
+		t.Errorf("status = %q, want the recording failure", status)
+	}
+}
+
+func TestNDJSONRecorderRoundTrips(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	rec, err := NewNDJSONRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startRecordedRun(t, m, rec)
+	if err := m.Shutdown(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Fatalf("recorded %d lines, want 2:\n%s", lines, data)
+	}
+	viewer, _ := newTestMonitor(t)
+	if warnings, err := viewer.ImportNDJSON(path); err != nil || len(warnings) != 0 {
+		t.Fatalf("import: %v, warnings %v", err, warnings)
+	}
+	if frames := viewer.Frames(); len(frames) != 2 || frames[0].Rows[0].PID != 7 {
+		t.Errorf("imported frames = %+v", frames)
+	}
+}
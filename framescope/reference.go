@@ -0,0 +1,114 @@
+package framescope
+
+import (
+	"sort"
+	"time"
+)
+
+// scaleReference returns the rows of the reference frame with each Diff
+// pro-rated from the frame's measured Duration to shown, the length of the
+// frame it is compared with, so that the frame in progress or a frame of
+// uneven length is compared at the same rate rather than the same total. The
+// rows are returned unscaled if either duration is unknown.
+func scaleReference(frame FrameRecord, shown time.Duration) []ResultRow {
+	rows := cloneRows(frame.Rows)
+	if frame.Duration <= 0 || shown <= 0 {
+		return rows
+	}
+	factor := shown.Seconds() / frame.Duration.Seconds()
+	for i := range rows {
+		rows[i].Diff *= factor
+	}
+	return rows
+}
+
+// referenceRows returns rows with each Diff replaced by its change versus
+// reference, the scaled rows of the locked reference frame (see
+// scaleReference): positive for a process that used more CPU than in the
+// reference, negative for one that used less. A process absent from the
+// reference, or whose PID was reused since, keeps its full Diff. Processes
+// only in the reference are not listed. The result is sorted by the change,
+// largest increase first, with PID as a tiebreaker.
+func referenceRows(rows, reference []ResultRow) []ResultRow {
+	before := make(map[int]ResultRow, len(reference))
+	for _, row := range reference {
+		before[row.PID] = row
+	}
+	out := make([]ResultRow, len(rows))
+	for i, row := range rows {
+		// As in reusedPID, unknown (zero) start times never differ.
+		ref, ok := before[row.PID]
+		if ok && (ref.CreateTime == 0 || row.CreateTime == 0 || ref.CreateTime == row.CreateTime) {
+			row.Diff -= ref.Diff
+		}
+		out[i] = row
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Diff == out[j].Diff {
+			return out[i].PID < out[j].PID
+		}
+		return out[i].Diff > out[j].Diff
+	})
+	return out
+}
+
+// referenceLocked returns the reference rows for the frame currentRowsLocked
+// resolves to, scaled to its length: the measured duration of a completed
+// frame, or the time elapsed in the frame in progress. Returns nil if no
+// reference frame is locked. Must be called with m.mu held.
+func (m *Monitor) referenceLocked() []ResultRow {
+	if m.reference == nil {
+		return nil
+	}
+	shown := m.now().Sub(m.frameStart)
+	if !m.viewingCurrent {
+		if i := m.selectedHistoryIdx; i >= 0 && i < len(m.history) {
+			shown = m.history[i].Duration
+		} else if n := len(m.history); n > 0 {
+			shown = m.history[n-1].Duration
+		}
+	}
+	rows := scaleReference(*m.reference, shown)
+	if rows == nil {
+		rows = []ResultRow{}
+	}
+	return rows
+}
+
+// SetReferenceFrame locks the completed frame at history popup item index as
+// the reference: until ClearReferenceFrame, the current-frame table shows
+// each process's change in CPU versus that frame instead of its CPU, for the
+// frame in progress and every frame selected later. The reference belongs to
+// the frame length shown and is cleared by Start. An index that is not a
+// completed frame, such as the frame in progress, is ignored.
+func (m *Monitor) SetReferenceFrame(index int) {
+	m.mu.Lock()
+	if index < 0 || index >= len(m.history) {
+		m.mu.Unlock()
+		return
+	}
+	frame := m.history[index]
+	m.reference = &frame
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// ClearReferenceFrame returns the current-frame table to showing each
+// process's CPU.
+func (m *Monitor) ClearReferenceFrame() {
+	m.mu.Lock()
+	m.reference = nil
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// ReferenceFrame returns the Index of the locked reference frame, or 0 if
+// none is locked.
+func (m *Monitor) ReferenceFrame() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reference == nil {
+		return 0
+	}
+	return m.reference.Index
+}
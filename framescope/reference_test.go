@@ -0,0 +1,117 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReferenceRows(t *testing.T) {
+	reference := []ResultRow{
+		{PID: 1, Diff: 2, CreateTime: 100},
+		{PID: 2, Diff: 5, CreateTime: 200},
+		{PID: 3, Diff: 4, CreateTime: 300},
+		{PID: 4, Diff: 1},
+		{PID: 9, Diff: 8, CreateTime: 900}, // exited since
+	}
+	rows := []ResultRow{
+		{PID: 1, Diff: 6, CreateTime: 100}, // +4
+		{PID: 2, Diff: 3, CreateTime: 200}, // -2
+		{PID: 3, Diff: 1, CreateTime: 333}, // reused PID: full value
+		{PID: 4, Diff: 1, CreateTime: 400}, // unknown start in the reference: 0
+		{PID: 5, Diff: 2.5},                // absent from the reference: full value
+	}
+
+	got := referenceRows(rows, reference)
+	want := []ResultRow{
+		{PID: 1, Diff: 4, CreateTime: 100},
+		{PID: 5, Diff: 2.5},
+		{PID: 3, Diff: 1, CreateTime: 333},
+		{PID: 4, Diff: 0, CreateTime: 400},
+		{PID: 2, Diff: -2, CreateTime: 200},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if rows[0].Diff != 6 {
+		t.Errorf("input row modified: %+v", rows[0])
+	}
+}
+
+func TestScaleReference(t *testing.T) {
+	frame := FrameRecord{Duration: 10 * time.Second, Rows: []ResultRow{{PID: 1, Diff: 8}}}
+	if got := scaleReference(frame, 5*time.Second); got[0].Diff != 4 {
+		t.Errorf("half-length frame: Diff = %v, want 4", got[0].Diff)
+	}
+	if got := scaleReference(frame, 0); got[0].Diff != 8 {
+		t.Errorf("unknown length: Diff = %v, want 8 unscaled", got[0].Diff)
+	}
+	if frame.Rows[0].Diff != 8 {
+		t.Errorf("reference frame modified: %+v", frame.Rows[0])
+	}
+}
+
+func TestSetReferenceFrame(t *testing.T) {
+	m, start := newExportMonitor(t)
+	m.history = append(m.history, FrameRecord{
+		Index:    2,
+		Rows:     []ResultRow{{PID: 1, Diff: 7, Command: "/bin/a"}, {PID: 2, Diff: 3, Command: "/bin/b"}},
+		Duration: 15 * time.Second,
+	})
+	m.frameIndex = 3
+	// The live frame is 5s in, a third of the reference's length.
+	m.liveRows = []ResultRow{{PID: 1, Diff: 1, Command: "/bin/a"}, {PID: 3, Diff: 2, Command: "/bin/c"}}
+	m.frameStart = start.Add(15 * time.Second)
+	m.viewingCurrent = true
+	m.hideSmall = false
+
+	m.SetReferenceFrame(2) // the frame in progress is not a reference
+	if got := m.ReferenceFrame(); got != 0 {
+		t.Fatalf("ReferenceFrame() = %d after locking the live frame, want 0", got)
+	}
+	m.SetReferenceFrame(0)
+	if got := m.ReferenceFrame(); got != 1 {
+		t.Fatalf("ReferenceFrame() = %d, want 1", got)
+	}
+
+	table := func() [][]string {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return splitPayload(RenderTable(m.currentRowsLocked(), m.tableOptionsLocked(), TabFormatter{Columns: []string{"pid", "cpu"}}))
+	}
+	// Frame 1 had PID 1 at 4 CPU-seconds over 15s, 1.33 over the 5s so far.
+	if got, want := table(), "3 2.0|1 -0.3"; joinRows(got) != want {
+		t.Errorf("live table = %q, want %q", joinRows(got), want)
+	}
+
+	// A completed frame of the same length is compared in full.
+	m.SelectFrame(1)
+	if got, want := table(), "1 3.0|2 3.0"; joinRows(got) != want {
+		t.Errorf("frame 2 table = %q, want %q", joinRows(got), want)
+	}
+	m.mu.Lock()
+	label := m.currentViewLabelLocked()
+	m.mu.Unlock()
+	if label != "Frame 2 vs Frame 1" {
+		t.Errorf("view label = %q, want Frame 2 vs Frame 1", label)
+	}
+
+	m.ClearReferenceFrame()
+	if got, want := table(), "1 7.0|2 3.0"; joinRows(got) != want {
+		t.Errorf("table after clearing = %q, want %q", joinRows(got), want)
+	}
+}
+
+// joinRows renders payload rows as "a b|c d" for compact comparison.
+func joinRows(rows [][]string) string {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, " ")
+	}
+	return strings.Join(lines, "|")
+}
@@ -0,0 +1,866 @@
+package framescope
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// buildStatusLocked composes the status-bar string shown while monitoring is
+// active. It reports the current frame number, configured length, elapsed and
+// remaining time within the frame, the number of visible rows and their
+// combined CPU-seconds, how many zombie and stopped processes the frame holds
+// and how many processes exec'd into another command (each only when there are
+// any), the one-time permission hint (see noteSkipped), the latest failure to
+// record a frame (see SetRecorder), and which frame the user is viewing.
+// Visibility uses displayedRows with the table's options, measured over the
+// time elapsed in the frame, so the totals always match the live table: the
+// change filter, the pattern lists, maximum age, grouping, the Hide <1s
+// filter, and the row cap all apply. A group row counts each of its members as
+// a process. Must be called with m.mu held.
+func (m *Monitor) buildStatusLocked(frameSeconds float64, frameStart, now time.Time, rows []ResultRow) string {
+	frameIndex := m.frameIndex
+	viewLabel := m.currentViewLabelLocked()
+
+	elapsed := now.Sub(frameStart).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	remaining := frameSeconds - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	opts := m.tableOptionsLocked()
+	opts.Elapsed = time.Duration(elapsed * float64(time.Second))
+	if opts.MaxAge > 0 {
+		opts.AgeAt = now
+	}
+	visibleTotal, visible := 0.0, 0
+	for _, row := range displayedRows(rows, opts) {
+		switch {
+		case row.Depth > 0:
+			// A member listed below its group row, which already sums it.
+		case row.members != nil:
+			visibleTotal += row.Diff
+			visible += len(row.members)
+		default:
+			visibleTotal += row.Diff
+			visible++
+		}
+	}
+	noun := "processes"
+	if visible == 1 {
+		noun = "process"
+	}
+
+	flagged := ""
+	if zombies, stopped := statusCounts(rows); zombies+stopped > 0 {
+		flagged = fmt.Sprintf(" | %d zombie, %d stopped", zombies, stopped)
+	}
+	execs := 0
+	for _, row := range rows {
+		if row.ExecCommand != "" {
+			execs++
+		}
+	}
+	if execs > 0 {
+		flagged += fmt.Sprintf(" | %d exec'd mid-frame", execs)
+	}
+	if m.skipHint != "" {
+		flagged += " | " + m.skipHint
+	}
+	if m.chartSnapshotErr != "" {
+		flagged += " | " + m.chartSnapshotErr
+	}
+	if m.recordErr != "" {
+		flagged += " | " + m.recordErr
+	}
+	if m.focus != nil {
+		flagged += fmt.Sprintf(" | focused on PID %d and %d descendants", m.focus.root, max(len(m.focus.members)-1, 0))
+	}
+	if m.focusNote != "" {
+		flagged += " | " + m.focusNote
+	}
+
+	return fmt.Sprintf(
+		"Running. Frame %d | length %.1fs | elapsed %.1fs | remaining %.1fs | visible total %.1f CPU-s across %d %s%s | viewing %s",
+		frameIndex,
+		frameSeconds,
+		elapsed,
+		remaining,
+		visibleTotal,
+		visible,
+		noun,
+		flagged,
+		viewLabel,
+	)
+}
+
+// TableOptions controls what RenderTable includes in the current-frame
+// payload. The zero value renders every row with full command lines and
+// leaves the optional columns empty.
+type TableOptions struct {
+	HideSmall    bool            // drop rows below 1 CPU-second, or SmallPercent
+	HidePaths    bool            // show only the executable basename
+	ShowShare    bool            // fill the share-of-frame column
+	ShowPercent  bool            // fill the %CPU column, over Elapsed
+	ShowUserSys  bool            // fill the user and sys columns
+	Elapsed      time.Duration   // length of the frame shown; see framePercent
+	SmallPercent float64         // with HideSmall, drop rows below this %CPU over Elapsed instead; 0 for CPU-seconds
+	ShowStatus   bool            // fill the zombie/stopped marker column
+	Sparks       map[int]string  // trend sparklines by PID (see sparklines); nil for none
+	Rates        map[int]float64 // %CPU over the last tick by PID (see tickRates); nil for no rate column
+	Group        GroupMode       // roll app helpers up (see groupRows)
+
+	// LivePrevious, if non-nil, maps PIDs to their CPU-seconds in the last
+	// completed frame, and fills the delta column with each row's change
+	// versus it once its CPU is projected to a full frame by multiplying by
+	// LiveScale, the frame length over the time elapsed (see rowDelta). It
+	// is set for the live frame only and ignored with Reference.
+	LivePrevious map[int]float64
+	LiveScale    float64
+
+	// ChangedOnly keeps only the rows that moved by at least MinChange
+	// CPU-seconds versus Previous, the prior completed frame's rows, and the
+	// processes that started or exited with at least MinActivity
+	// CPU-seconds (see changedRows), ordered by the size of the change.
+	// HideSmall and Group are ignored in this mode.
+	ChangedOnly bool
+	Previous    []ResultRow
+	MinChange   float64
+	MinActivity float64
+
+	// Reference, if non-nil, shows each row's change in CPU-seconds versus
+	// these rows of a locked reference frame instead of its CPU (see
+	// referenceRows), ordered by the change. ChangedOnly and Pinned are
+	// ignored, HideSmall applies to the size of the change, and the share
+	// column is left empty.
+	Reference []ResultRow
+
+	// Pinned, if non-nil, fixes which processes are shown and in what order
+	// (see pinnedRows), whatever their CPU. HideSmall and Group are ignored,
+	// and so is Pinned itself in ChangedOnly mode.
+	Pinned []int
+
+	// Exclude, Include, and Watch are the user's pattern lists (see
+	// PatternList), applied before grouping.
+	Exclude []string
+	Include []string
+	Watch   []string
+
+	// MaxAge, if non-zero, keeps only the processes started at most MaxAge
+	// before AgeAt, the end of the frame shown (see startedWithin), on top of
+	// the pattern lists; watched processes are kept whatever their age.
+	MaxAge time.Duration
+	AgeAt  time.Time
+
+	// aliases labels rows in place of their commands (see SetAlias); group
+	// rows keep the app name.
+	aliases aliasTable
+
+	// noise leaves processes out of the frame total the share column is
+	// measured against (see NoiseList); their own share is left empty.
+	noise noiseFilter
+}
+
+// RenderTable is the shared driver for every current-frame output: it computes
+// changes versus a reference frame (opts.Reference) or keeps changed rows
+// (opts.ChangedOnly) or pinned rows (opts.Pinned), applies the pattern lists
+// (opts.Exclude, opts.Include, opts.Watch), groups app helpers (opts.Group),
+// filters rows (opts.HideSmall), caps them at maxRows to keep consumers
+// responsive, computes the optional columns, and hands the resulting
+// TableRecords to format. The Cocoa table uses TabFormatter; exports and the
+// HTTP API use CSVFormatter or JSONFormatter.
+//
+// The share column is each row's percentage of the CPU-seconds consumed by all
+// rows in the frame (see frameShare), computed only when opts.ShowShare is set.
+// Because the denominator includes rows hidden by the filter, the shares of the
+// shown rows sum to at most 100%. Noise processes (see NoiseList) are left out
+// of the denominator, whether shown or not, and have no share. The %CPU column,
+// filled when opts.ShowPercent is set, is the row's CPU-seconds as a percentage
+// of one core over opts.Elapsed (see framePercent), so that absolute work and
+// intensity are shown side by side. The user and sys columns, filled when
+// opts.ShowUserSys is set, split the CPU-seconds into userland and kernel time
+// (ResultRow.UserDiff and SystemDiff), telling a compute-bound process from a
+// syscall-heavy one. All are left empty in reference mode. The trend column is
+// the row's entry in opts.Sparks, or empty; group rows have no trend. The
+// status column holds statusMarker of the row's state when opts.ShowStatus is
+// set. The rate column is the row's entry in opts.Rates, summed over the
+// members of a group row, and is filled only when opts.Rates is non-nil. Group
+// labels are never shortened by opts.HidePaths.
+func RenderTable(rows []ResultRow, opts TableOptions, format RowFormatter) string {
+	return format.FormatRows(tableRecords(rows, opts))
+}
+
+// displayedRows applies RenderTable's change filter or pinning, pattern
+// lists, grouping, filtering, and row cap to rows.
+func displayedRows(rows []ResultRow, opts TableOptions) []groupedRow {
+	switch {
+	case opts.Reference != nil:
+		rows = referenceRows(rows, opts.Reference)
+	case opts.ChangedOnly:
+		rows = changedRows(rows, opts.Previous, opts.MinChange, opts.MinActivity)
+		opts.HideSmall = false
+		opts.Group = GroupNone
+	case opts.Pinned != nil:
+		rows = pinnedRows(rows, opts.Pinned)
+		opts.HideSmall = false
+		opts.Group = GroupNone
+	}
+	rows, watched := filterRows(rows, opts)
+	grouped := groupRows(rows, opts.Group)
+	shown := make([]groupedRow, 0, min(len(grouped), maxRows))
+	for _, row := range grouped {
+		if len(shown) == maxRows {
+			break
+		}
+		if opts.HideSmall && belowSmall(math.Abs(row.Diff), opts.SmallPercent, opts.Elapsed) && !watched[row.PID] {
+			continue
+		}
+		shown = append(shown, row)
+	}
+	return shown
+}
+
+// displayedPIDs returns the PIDs of the processes whose commands RenderTable
+// may show for rows with opts: every displayed row and the members of every
+// displayed group.
+func displayedPIDs(rows []ResultRow, opts TableOptions) map[int]bool {
+	pids := make(map[int]bool)
+	for _, row := range displayedRows(rows, opts) {
+		pids[row.PID] = true
+		for _, member := range row.members {
+			pids[member.PID] = true
+		}
+	}
+	return pids
+}
+
+// tableRecords applies RenderTable's filtering, row cap, and optional columns
+// to rows.
+func tableRecords(rows []ResultRow, opts TableOptions) []TableRecord {
+	frameTotal := opts.noise.countedCPU(rows)
+	shown := displayedRows(rows, opts)
+
+	records := make([]TableRecord, 0, len(shown))
+	for _, row := range shown {
+		isGroup := row.members != nil
+		command := row.Command
+		if opts.HidePaths && !isGroup {
+			command = baseCommand(command)
+		}
+		if label := opts.aliases.label(row.ResultRow); label != "" && !isGroup {
+			command = aliasCommand(label, command)
+		}
+		trend := opts.Sparks[row.PID]
+		if isGroup {
+			trend = ""
+		}
+		status := ""
+		if opts.ShowStatus {
+			status = statusMarker(row.Status)
+		}
+		exec := row.ExecCommand
+		if opts.HidePaths && exec != "" {
+			exec = baseCommand(exec)
+		}
+		rate := opts.Rates[row.PID]
+		if isGroup {
+			rate = 0
+			for _, member := range row.members {
+				rate += opts.Rates[member.PID]
+			}
+		}
+		var delta liveDelta
+		if opts.LivePrevious != nil && opts.Reference == nil {
+			delta = rowDelta(row, opts)
+		}
+		records = append(records, TableRecord{
+			PID:        row.PID,
+			CPU:        row.Diff,
+			HasPercent: opts.ShowPercent && opts.Reference == nil,
+			Percent:    framePercent(row.Diff, opts.Elapsed),
+			HasUserSys: opts.ShowUserSys && opts.Reference == nil,
+			User:       row.UserDiff,
+			System:     row.SystemDiff,
+			HasShare:   opts.ShowShare && opts.Reference == nil && (isGroup || !opts.noise.matches(row.ResultRow)),
+			Share:      frameShare(opts.noise.countedShare(row), frameTotal),
+			Trend:      trend,
+			Command:    command,
+			Depth:      row.Depth,
+			Status:     status,
+			Exec:       exec,
+			HasRate:    opts.Rates != nil,
+			Rate:       rate,
+			Delta:      formatDelta(delta),
+		})
+	}
+	return records
+}
+
+// statusMarker returns the ps(1) letter for the process states worth flagging
+// while debugging: "Z" for a zombie, "T" for a stopped process, "" otherwise.
+func statusMarker(status string) string {
+	switch status {
+	case process.Zombie:
+		return "Z"
+	case process.Stop:
+		return "T"
+	}
+	return ""
+}
+
+// statusCounts counts the zombie and stopped processes in rows.
+func statusCounts(rows []ResultRow) (zombies, stopped int) {
+	for _, row := range rows {
+		switch statusMarker(row.Status) {
+		case "Z":
+			zombies++
+		case "T":
+			stopped++
+		}
+	}
+	return zombies, stopped
+}
+
+// frameShare returns cpuSeconds as a percentage of frameTotal, the CPU-seconds
+// consumed by every process in the frame. Unlike framePercent this is
+// attribution ("40% of all CPU activity"), not core utilisation. Returns 0 for
+// an empty frame.
+func frameShare(cpuSeconds, frameTotal float64) float64 {
+	if frameTotal <= 0 {
+		return 0
+	}
+	return cpuSeconds / frameTotal * 100
+}
+
+// sparklineWindow is the number of most recent completed frames shown in each
+// row's trend sparkline.
+const sparklineWindow = 16
+
+// sparkGlyphs are the sparkline levels from lowest to highest.
+var sparkGlyphs = []rune("▁▂▃▄▅▆▇")
+
+// sparklines returns a trend sparkline for every PID that appears in the last
+// window frames of history. Each PID's series runs from its first appearance
+// in the window to the newest frame (frames it is absent from count as 0), is
+// normalised to its own maximum, and is left-padded with spaces to window
+// characters so the newest frame always lines up on the right.
+func sparklines(history []FrameRecord, window int) map[int]string {
+	if len(history) > window {
+		history = history[len(history)-window:]
+	}
+
+	series := make(map[int][]float64)
+	for f, frame := range history {
+		for _, row := range frame.Rows {
+			values, ok := series[row.PID]
+			if !ok {
+				values = make([]float64, len(history)-f)
+				series[row.PID] = values
+			}
+			values[len(values)-(len(history)-f)] = row.Diff
+		}
+	}
+
+	out := make(map[int]string, len(series))
+	for pid, values := range series {
+		out[pid] = sparkline(values, window)
+	}
+	return out
+}
+
+// sparkline renders values as a string of sparkGlyphs scaled to the series'
+// own maximum, left-padded with spaces to width characters. An all-zero
+// series renders as the lowest glyph throughout.
+func sparkline(values []float64, width int) string {
+	peak := 0.0
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+	}
+
+	var b strings.Builder
+	for i := len(values); i < width; i++ {
+		b.WriteByte(' ')
+	}
+	top := float64(len(sparkGlyphs) - 1)
+	for _, v := range values {
+		level := 0
+		if peak > 0 && v > 0 {
+			level = int(v/peak*top + 0.5)
+		}
+		b.WriteRune(sparkGlyphs[level])
+	}
+	return b.String()
+}
+
+// SummaryOptions controls what RenderSummaryTable includes in the summary
+// payload. The zero value keeps every process, averages over all completed
+// frames, orders rows by total descending with equal totals by PID, and
+// writes every column.
+type SummaryOptions struct {
+	MinTotal    float64          // omit processes below this many CPU-seconds in total
+	MinFrames   int              // omit processes seen in fewer frames
+	HidePaths   bool             // show only the executable basename
+	AverageMode AverageMode      // which frames the averages are taken over
+	Sort        SummarySortField // the field rows are ordered by
+	Ascending   bool             // order by Sort smallest first
+	Tiebreak    Tiebreaker       // order rows equal in Sort
+	MergeReused bool             // one row per PID reused for the same command
+	Columns     []string         // the SummaryColumnNames to write; nil for all
+	SpikeRatio  float64          // peak-to-average ratio marked spiky (see spiky); 0 for the default
+	MarkTiny    bool             // mark CPU-seconds too small to show (see formatSeconds)
+
+	// viewed holds the totals when the summary was last viewed, which fill
+	// the since_viewed column (see sinceViewed); nil leaves it empty.
+	viewed map[aggregateKey]float64
+
+	// aliases labels rows in place of their commands (see SetAlias).
+	aliases aliasTable
+}
+
+// RenderSummaryTable aggregates CPU usage across all completed frames and
+// returns a tab-separated payload for the summary table view. By default
+// each line contains the SummaryColumnNames:
+//
+//	PID \t total-s \t avg-s \t total-HH:MM:SS \t avg-HH:MM:SS \t peak-%CPU \t
+//	command \t avg-%CPU \t since-viewed \t stddev-s \t frames \t spiky \t
+//	processes
+//
+// opts.Columns, if it names any of SummaryColumnNames, lists the columns to
+// write instead, in order; other names are skipped.
+//
+// With AverageAllFrames, averages are computed over the total number of
+// completed frames; with AverageAppearedFrames, over the number of frames in
+// which the process appeared. The average %CPU column is the total as a
+// percentage of one core over the measured Duration of the same frames, so it
+// reads like the current-frame %CPU column. The peak column is the highest
+// %CPU the process reached in any single frame, rendered as "184.3% @5" where
+// 5 is the frame number. Because it is derived from each frame's own measured
+// Duration it can differ from the frame with the most CPU-seconds when frame
+// lengths are uneven.
+//
+// Processes whose total across the whole history is below opts.MinTotal
+// CPU-seconds, or that appeared in fewer than opts.MinFrames frames, are
+// omitted. The thresholds apply to the whole history, not to any single
+// frame, and are independent of the current-frame table's HideSmall filter.
+// With opts.MergeReused, a PID reused for the same command is one row (see
+// aggregateHistory) whose command notes how many processes it covers, e.g.
+// "make (3 processes)". Rows with an alias show it in place of their command.
+// Rows are ordered by opts.Sort (see bySummarySort) and capped at maxRows,
+// picked with topIndices rather than by sorting every process, so a sort by
+// command lists the first commands alphabetically rather than the busiest
+// processes. Returns an empty string if no frames have completed yet.
+func RenderSummaryTable(history []FrameRecord, opts SummaryOptions) string {
+	if len(history) == 0 {
+		return ""
+	}
+	columns := columnsOrDefault(selectColumns(opts.Columns, SummaryColumnNames), SummaryColumnNames)
+	spikeRatio := opts.SpikeRatio
+	if spikeRatio == 0 {
+		spikeRatio = defaultSpikeRatio
+	}
+
+	kept := summaryRows(history, opts.MinTotal, opts.MinFrames, opts.AverageMode, opts.MergeReused)
+	since := sinceViewed(kept, opts.viewed)
+
+	var b strings.Builder
+	for _, i := range topIndices(len(kept), maxRows, bySummarySort(kept, opts.Sort, !opts.Ascending, opts.Tiebreak)) {
+		row := kept[i]
+		command := sanitizeCommand(row.Command, opts.HidePaths)
+		if label := opts.aliases.label(ResultRow{PID: row.PID, Command: row.Command}); label != "" {
+			command = sanitizeCommand(aliasCommand(label, command), false)
+		}
+		if row.Instances > 1 {
+			command = fmt.Sprintf("%s (%d processes)", command, row.Instances)
+		}
+		grew := ""
+		if since != nil {
+			grew = since[i]
+		}
+		spike := ""
+		if spiky(row, spikeRatio) {
+			spike = spikyMarker
+		}
+		writeColumns(&b, columns, func(name string) string { return summaryColumn(row, command, grew, spike, name, opts.MarkTiny) })
+	}
+
+	return b.String()
+}
+
+// summaryRows returns the summary's rows for history, in no particular order:
+// the aggregateHistory rows with a total of at least minTotal CPU-seconds
+// that appeared in at least minFrames frames. It is the selection shared by
+// the summary table and SummaryJSON.
+func summaryRows(history []FrameRecord, minTotal float64, minFrames int, avgMode AverageMode, mergeReused bool) []aggregateRow {
+	var kept []aggregateRow
+	for _, row := range aggregateHistory(history, avgMode, mergeReused) {
+		if row.Total >= minTotal && row.Frames >= minFrames {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// aggregateKey identifies one summary row: a PID and the start time of the
+// process that held it, so that a PID the OS reused for another process gets a
+// row of its own. When reused PIDs are merged, the start time is left zero and
+// the command's basename is set instead.
+type aggregateKey struct {
+	PID        int
+	CreateTime int64
+	Command    string
+}
+
+// aggregateHistory totals each process's CPU across history and returns one
+// unfiltered aggregateRow per process, in no particular order (see byTotal).
+// Averages and standard deviations use the frames selected by avgMode. It is
+// the shared aggregation behind the summary table and sessionStats.
+//
+// A process is a PID and its start time, so a reused PID appears once per
+// process that held it. With mergeReused, processes that held the same PID and
+// ran the same command (see baseCommand) are one row instead, as in a build
+// loop that keeps restarting "make": the row's Instances counts them, and its
+// CreateTime is the first one's.
+//
+// A row's Command is the latest one the process was seen with (see
+// latestCommand), so a process that exec'd or whose command was first read as
+// unknownCommand is listed under what it ran last.
+func aggregateHistory(history []FrameRecord, avgMode AverageMode, mergeReused bool) []aggregateRow {
+	aggregates := make(map[aggregateKey]*aggregateRow)
+	instances := make(map[aggregateKey]map[int64]struct{})
+	appeared := make(map[aggregateKey]time.Duration)
+	squares := make(map[aggregateKey]float64)
+	var elapsed time.Duration
+	for _, frame := range history {
+		elapsed += frame.Duration
+		for _, row := range frame.Rows {
+			key := aggregateKey{PID: row.PID, CreateTime: row.CreateTime}
+			if mergeReused {
+				key = aggregateKey{PID: row.PID, Command: baseCommand(row.Command)}
+			}
+			entry := aggregates[key]
+			if entry == nil {
+				entry = &aggregateRow{PID: row.PID, Command: row.Command, CreateTime: row.CreateTime, key: key}
+				aggregates[key] = entry
+				instances[key] = make(map[int64]struct{})
+			}
+			if command := latestCommand(row); command != "" {
+				entry.Command = command
+			}
+			instances[key][row.CreateTime] = struct{}{}
+			entry.Total += row.Diff
+			squares[key] += row.Diff * row.Diff
+			if entry.Frames == 0 || row.Diff < entry.Min {
+				entry.Min = row.Diff
+			}
+			entry.Max = max(entry.Max, row.Diff)
+			entry.Frames++
+			appeared[key] += frame.Duration
+			if pct := framePercent(row.Diff, frame.Duration); entry.PeakFrame == 0 || pct > entry.PeakPercent {
+				entry.PeakPercent = pct
+				entry.PeakFrame = frame.Index
+			}
+		}
+	}
+
+	rows := make([]aggregateRow, 0, len(aggregates))
+	for key, entry := range aggregates {
+		entry.Instances = len(instances[key])
+		denominator, averaged := len(history), elapsed
+		if avgMode == AverageAppearedFrames {
+			denominator, averaged = entry.Frames, appeared[key]
+		}
+		entry.Average = entry.Total / float64(denominator)
+		// Frames the process was absent from count as zero, so only the
+		// sum of squares is needed; rounding can leave it just below zero.
+		entry.StdDev = math.Sqrt(max(squares[key]/float64(denominator)-entry.Average*entry.Average, 0))
+		entry.AveragePercent = framePercent(entry.Total, averaged)
+		rows = append(rows, *entry)
+	}
+	return rows
+}
+
+// byTotal returns the summary order of rows, for sort.Slice or topIndices:
+// total descending, with tiebreak ordering equal totals.
+func byTotal(rows []aggregateRow, tiebreak Tiebreaker) func(i, j int) bool {
+	return bySummarySort(rows, SummarySortTotal, true, tiebreak)
+}
+
+// bySummarySort returns an order of rows by field, for sort.Slice or
+// topIndices. Rows equal in field are ordered by tiebreak in either
+// direction, so reversing the sort does not reshuffle them.
+func bySummarySort(rows []aggregateRow, field SummarySortField, descending bool, tiebreak Tiebreaker) func(i, j int) bool {
+	return func(i, j int) bool {
+		if c := compareSummaryField(rows[i], rows[j], field); c != 0 {
+			return (c > 0) == descending
+		}
+		return tiebreak.less(rows[i].tieKey(), rows[j].tieKey())
+	}
+}
+
+// compareSummaryField returns -1, 0, or 1 as a is less than, equal to, or
+// greater than b in field. Unknown fields compare like SummarySortTotal.
+func compareSummaryField(a, b aggregateRow, field SummarySortField) int {
+	switch field {
+	case SummarySortAverage:
+		return compareFloat(a.Average, b.Average)
+	case SummarySortPeak:
+		return compareFloat(a.PeakPercent, b.PeakPercent)
+	case SummarySortStdDev:
+		return compareFloat(a.StdDev, b.StdDev)
+	case SummarySortAppearances:
+		return compareFloat(float64(a.Frames), float64(b.Frames))
+	case SummarySortCommand:
+		return strings.Compare(strings.ToLower(a.Command), strings.ToLower(b.Command))
+	case SummarySortPID:
+		return compareFloat(float64(a.PID), float64(b.PID))
+	}
+	return compareFloat(a.Total, b.Total)
+}
+
+// compareFloat returns -1, 0, or 1 as a is less than, equal to, or greater
+// than b.
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// commandRow is one line of the by-command table: CPU summed across every
+// PID that ran the same executable.
+type commandRow struct {
+	Command string // executable basename (see baseCommand)
+	Total   float64
+	PIDs    int // distinct PIDs seen for the command across the history
+}
+
+// renderCommandTable aggregates CPU usage across all completed frames by
+// executable basename and returns a tab-separated payload for the by-command
+// table. Each line contains:
+//
+//	total-s \t total-HH:MM:SS \t PIDs \t command
+//
+// PIDs counts every distinct PID that ran the command in any frame, so a
+// command whose workers are restarted is credited with all of them. This is
+// deliberately not the number of PIDs in the final frame: the count must
+// explain the total beside it, which includes CPU from processes that have
+// since exited, and a command absent from the last frame would otherwise show
+// zero. Unlike the per-PID summary no rows are filtered. Rows are sorted by total
+// descending, then command, and capped at maxRows. Returns an empty string if no
+// frames have completed yet.
+func renderCommandTable(history []FrameRecord) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	totals := make(map[string]float64)
+	pids := make(map[string]map[int]struct{})
+	for _, frame := range history {
+		for _, row := range frame.Rows {
+			key := baseCommand(row.Command)
+			totals[key] += row.Diff
+			if pids[key] == nil {
+				pids[key] = make(map[int]struct{})
+			}
+			pids[key][row.PID] = struct{}{}
+		}
+	}
+
+	rows := make([]commandRow, 0, len(totals))
+	for command, total := range totals {
+		rows = append(rows, commandRow{Command: command, Total: total, PIDs: len(pids[command])})
+	}
+	top := topIndices(len(rows), maxRows, func(i, j int) bool {
+		if rows[i].Total == rows[j].Total {
+			return rows[i].Command < rows[j].Command
+		}
+		return rows[i].Total > rows[j].Total
+	})
+
+	var b strings.Builder
+	for _, i := range top {
+		row := rows[i]
+		fmt.Fprintf(&b, "%.1f\t%s\t%d\t%s\n",
+			row.Total, FormatDuration(row.Total), row.PIDs, sanitizeCommand(row.Command, false))
+	}
+	return b.String()
+}
+
+// userRow is one line of the by-user table: CPU summed across every process
+// owned by the same user.
+type userRow struct {
+	User  string // username, numeric UID, or unknownUser
+	Total float64
+	PIDs  int // distinct PIDs seen for the user across the history
+}
+
+// renderUserTable aggregates CPU usage across all completed frames by process
+// owner and returns a tab-separated payload for the by-user table. Each line
+// contains:
+//
+//	total-s \t total-HH:MM:SS \t PIDs \t user
+//
+// Processes whose owner could not be read are counted under "unknown"; owners
+// without a username appear as their numeric UID. As in renderCommandTable,
+// PIDs counts every distinct PID across the history, no rows are filtered,
+// and rows are sorted by total descending, then user, and capped at maxRows.
+// Returns an empty string if no frames have completed yet.
+func renderUserTable(history []FrameRecord) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	totals := make(map[string]float64)
+	pids := make(map[string]map[int]struct{})
+	for _, frame := range history {
+		for _, row := range frame.Rows {
+			key := row.User
+			if key == "" {
+				key = unknownUser
+			}
+			totals[key] += row.Diff
+			if pids[key] == nil {
+				pids[key] = make(map[int]struct{})
+			}
+			pids[key][row.PID] = struct{}{}
+		}
+	}
+
+	rows := make([]userRow, 0, len(totals))
+	for name, total := range totals {
+		rows = append(rows, userRow{User: name, Total: total, PIDs: len(pids[name])})
+	}
+	top := topIndices(len(rows), maxRows, func(i, j int) bool {
+		if rows[i].Total == rows[j].Total {
+			return rows[i].User < rows[j].User
+		}
+		return rows[i].Total > rows[j].Total
+	})
+
+	var b strings.Builder
+	for _, i := range top {
+		row := rows[i]
+		fmt.Fprintf(&b, "%.1f\t%s\t%d\t%s\n",
+			row.Total, FormatDuration(row.Total), row.PIDs, row.User)
+	}
+	return b.String()
+}
+
+// summaryTitle returns the summary pane header, naming the active average
+// denominator so the Avg columns are never ambiguous.
+func summaryTitle(avgMode AverageMode) string {
+	if avgMode == AverageAppearedFrames {
+		return "Summary — Totals & Averages (per frame appeared)"
+	}
+	return "Summary — Totals & Averages (per completed frame)"
+}
+
+// rangeTitle returns summaryTitle extended with the active frame range and
+// how many recorded frames it covers, e.g. "… · Frames 3–7 (5 frames)".
+func rangeTitle(avgMode AverageMode, r frameRange, frames int) string {
+	title := summaryTitle(avgMode)
+	if r == (frameRange{}) {
+		return title
+	}
+	noun := "frames"
+	if frames == 1 {
+		noun = "frame"
+	}
+	return fmt.Sprintf("%s · Frames %d–%d (%d %s)", title, r.First, r.Last, frames, noun)
+}
+
+// sanitizeCommand prepares a raw command string for display. If hidePaths is
+// true only the basename of the executable is kept (arguments are dropped).
+// Tabs and newlines are replaced with spaces to preserve the integrity of the
+// tab-separated table format.
+func sanitizeCommand(command string, hidePaths bool) string {
+	if hidePaths {
+		command = baseCommand(command)
+	}
+	command = strings.ReplaceAll(command, "\t", " ")
+	command = strings.ReplaceAll(command, "\n", " ")
+	return command
+}
+
+// latestCommand returns the command row ended its frame with: ExecCommand if
+// the process exec'd during the frame, otherwise Command. It returns "" if
+// that command is empty or unknownCommand.
+func latestCommand(row ResultRow) string {
+	command := row.Command
+	if row.ExecCommand != "" {
+		command = row.ExecCommand
+	}
+	if command == unknownCommand {
+		return ""
+	}
+	return command
+}
+
+// baseCommand extracts the basename of the first whitespace-delimited token in
+// command (i.e. the executable path), discarding any arguments. Returns the
+// original string unchanged if it contains no fields.
+func baseCommand(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return command
+	}
+	parts := strings.Split(fields[0], "/")
+	return parts[len(parts)-1]
+}
+
+// framePercent converts CPU-seconds consumed during a frame into a
+// percentage of one core over the frame's measured elapsed time, so 200 means
+// two cores were saturated for the whole frame. Returns 0 when elapsed is not
+// positive (e.g. a frame recorded without a duration).
+func framePercent(cpuSeconds float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return cpuSeconds / elapsed.Seconds() * 100
+}
+
+// formatPeak renders a peak %CPU and the frame it occurred in for the summary
+// table, e.g. "184.3% @5". Returns an empty string when frame is 0.
+func formatPeak(percent float64, frame int) string {
+	if frame == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%% @%d", percent, frame)
+}
+
+// formatSeconds formats CPU-seconds to one decimal place, as both tables
+// show them. With markTiny, a positive value too small to show at that
+// precision is written "<0.1" rather than "0.0", which would read as no work
+// at all; an exact zero stays "0.0".
+func formatSeconds(seconds float64, markTiny bool) string {
+	text := fmt.Sprintf("%.1f", seconds)
+	if markTiny && seconds > 0 && text == "0.0" {
+		return "<0.1"
+	}
+	return text
+}
+
+// FormatDuration formats a duration expressed as fractional seconds into the
+// human-readable HH:MM:SS string used in both table views. A decrease of at
+// least a second versus a reference frame is prefixed with "-".
+func FormatDuration(seconds float64) string {
+	if seconds <= -1 {
+		return "-" + FormatDuration(-seconds)
+	}
+	total := int(seconds)
+	hours := total / 3600
+	minutes := (total % 3600) / 60
+	secs := total % 60
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, secs)
+}
@@ -0,0 +1,652 @@
+package framescope
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// splitPayload parses a tab-separated table payload into rows of fields.
+func splitPayload(payload string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(payload, "\n") {
+		if line == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+	return rows
+}
+
+func TestRenderSummaryPeakPercentUsesFrameDuration(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Duration: 15 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 3, Command: "/bin/worker"}}},
+		{Index: 2, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 3, Command: "/bin/worker"}}},
+	}
+
+	rows := splitPayload(RenderSummaryTable(history, SummaryOptions{}))
+	if len(rows) != 1 {
+		t.Fatalf("got %d summary rows, want 1", len(rows))
+	}
+	if got, want := rows[0][5], "30.0% @2"; got != want {
+		t.Errorf("peak column = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSummaryShowsLatestCommand(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 1, Command: unknownCommand, CreateTime: 100}}},
+		{Index: 2, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 1, Command: "/bin/sh", ExecCommand: "/bin/worker", CreateTime: 100}}},
+		{Index: 3, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 1, Command: "/bin/server", CreateTime: 100}}},
+		{Index: 4, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 42, Diff: 1, Command: unknownCommand, CreateTime: 100}}},
+	}
+
+	rows := splitPayload(RenderSummaryTable(history, SummaryOptions{Columns: []string{"pid", "total", "command"}}))
+	if len(rows) != 1 {
+		t.Fatalf("got %d summary rows, want 1: %q", len(rows), rows)
+	}
+	if got, want := strings.Join(rows[0], "|"), "42|4.0|/bin/server"; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+	if got := splitPayload(RenderSummaryTable(history[:2], SummaryOptions{Columns: []string{"command"}})); got[0][0] != "/bin/worker" {
+		t.Errorf("command after the exec = %q, want worker", got[0][0])
+	}
+}
+
+func TestRenderSummaryTableTiebreakers(t *testing.T) {
+	initial, current := tiedSamples()
+	history := []FrameRecord{{Index: 1, Duration: 10 * time.Second, Rows: ComputeResults(initial, current, TiebreakPID)}}
+
+	// The summary follows the same rule as the current frame, whatever order
+	// the frame was recorded in.
+	for _, tiebreak := range []Tiebreaker{TiebreakPID, TiebreakCommand, TiebreakCreateTime} {
+		var frame, summary []string
+		for _, row := range ComputeResults(initial, current, tiebreak) {
+			frame = append(frame, fmt.Sprint(row.PID))
+		}
+		for _, row := range splitPayload(RenderSummaryTable(history, SummaryOptions{Tiebreak: tiebreak})) {
+			summary = append(summary, row[0])
+		}
+		if strings.Join(summary, ",") != strings.Join(frame, ",") {
+			t.Errorf("tiebreaker %d: summary PIDs = %v, current frame %v", tiebreak, summary, frame)
+		}
+	}
+}
+
+func TestSetTiebreakerPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetTiebreaker(TiebreakCreateTime)
+	m.SetTiebreaker(Tiebreaker(9))
+	if got := m.Tiebreaker(); got != TiebreakCreateTime {
+		t.Fatalf("Tiebreaker() = %d, want the invalid value ignored", got)
+	}
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if got := reloaded.Tiebreaker(); got != TiebreakCreateTime {
+		t.Errorf("reloaded Tiebreaker() = %d, want %d", got, TiebreakCreateTime)
+	}
+}
+
+// summarySortHistory is four processes over four 10-second frames that each
+// summary sort field orders differently. PIDs 10 and 40 tie on total and, with
+// AverageAppearedFrames, PIDs 10 and 20 tie on standard deviation.
+func summarySortHistory() []FrameRecord {
+	frame := func(index int, rows ...ResultRow) FrameRecord {
+		return FrameRecord{Index: index, Duration: 10 * time.Second, Rows: rows}
+	}
+	beta := func(diff float64) ResultRow { return ResultRow{PID: 10, Diff: diff, Command: "beta"} }
+	alpha := func(diff float64) ResultRow { return ResultRow{PID: 20, Diff: diff, Command: "Alpha"} }
+	zeta := func(diff float64) ResultRow { return ResultRow{PID: 30, Diff: diff, Command: "zeta"} }
+	gamma := func(diff float64) ResultRow { return ResultRow{PID: 40, Diff: diff, Command: "gamma"} }
+	return []FrameRecord{
+		frame(1, beta(2), alpha(9), zeta(3), gamma(1)),
+		frame(2, beta(2), zeta(4), gamma(1)),
+		frame(3, beta(2), gamma(6)),
+		frame(4, beta(2)),
+	}
+}
+
+func TestRenderSummaryTableSortFields(t *testing.T) {
+	history := summarySortHistory()
+	tests := []struct {
+		field      SummarySortField
+		descending bool
+		want       string
+	}{
+		{SummarySortTotal, true, "20|10|40|30"},
+		{SummarySortTotal, false, "30|10|40|20"}, // the tie keeps its order
+		{SummarySortAverage, true, "20|30|40|10"},
+		{SummarySortPeak, true, "20|40|30|10"},
+		{SummarySortStdDev, true, "40|30|10|20"},
+		{SummarySortStdDev, false, "10|20|30|40"},
+		{SummarySortAppearances, true, "10|40|30|20"},
+		{SummarySortCommand, false, "20|10|40|30"},
+		{SummarySortCommand, true, "30|40|10|20"},
+		{SummarySortPID, true, "40|30|20|10"},
+		{SummarySortPID, false, "10|20|30|40"},
+	}
+	for _, tt := range tests {
+		opts := SummaryOptions{AverageMode: AverageAppearedFrames, Sort: tt.field, Ascending: !tt.descending, Columns: []string{"pid"}}
+		rows := splitPayload(RenderSummaryTable(history, opts))
+		if got := joinRows(rows); got != tt.want {
+			t.Errorf("field %d descending %v: PIDs = %q, want %q", tt.field, tt.descending, got, tt.want)
+		}
+	}
+}
+
+func TestRenderSummaryStdDevColumns(t *testing.T) {
+	history := summarySortHistory()
+	columns := []string{"pid", "stddev", "appearances"}
+
+	// Over all frames the absent ones count as zero: 9, 0, 0, 0 for PID 20.
+	rows := splitPayload(RenderSummaryTable(history, SummaryOptions{Columns: columns}))
+	if got, want := joinRows(rows), "20 3.9 1|10 0.0 4|40 2.3 3|30 1.8 2"; got != want {
+		t.Errorf("all frames: rows = %q, want %q", got, want)
+	}
+	rows = splitPayload(RenderSummaryTable(history, SummaryOptions{AverageMode: AverageAppearedFrames, Columns: columns}))
+	if got, want := joinRows(rows), "20 0.0 1|10 0.0 4|40 2.4 3|30 0.5 2"; got != want {
+		t.Errorf("appeared frames: rows = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSummaryTableMinFrames(t *testing.T) {
+	// PIDs 10, 20, 30, and 40 appear in 4, 1, 2, and 3 frames, with totals
+	// of 8, 9, 7, and 8 CPU-seconds.
+	history := summarySortHistory()
+	tests := []struct {
+		minTotal  float64
+		minFrames int
+		want      string
+	}{
+		{0, 1, "20|10|40|30"},
+		{0, 2, "10|40|30"},
+		{0, 3, "10|40"},
+		{0, 5, ""},
+		{7.5, 2, "10|40"}, // both filters must pass
+		{8.5, 1, "20"},
+	}
+	for _, tt := range tests {
+		opts := SummaryOptions{MinTotal: tt.minTotal, MinFrames: tt.minFrames, Columns: []string{"pid"}}
+		rows := splitPayload(RenderSummaryTable(history, opts))
+		if got := joinRows(rows); got != tt.want {
+			t.Errorf("min total %v, min frames %d: PIDs = %q, want %q", tt.minTotal, tt.minFrames, got, tt.want)
+		}
+	}
+}
+
+func TestSetSummaryMinFramesPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	if got := m.SummaryMinFrames(); got != 1 {
+		t.Fatalf("default SummaryMinFrames() = %d, want 1", got)
+	}
+	m.LoadConfig()
+	m.SetSummaryMinFrames(3)
+	m.SetSummaryMinFrames(0)
+	if got := m.SummaryMinFrames(); got != 3 {
+		t.Fatalf("SummaryMinFrames() = %d, want 0 ignored", got)
+	}
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if got := reloaded.SummaryMinFrames(); got != 3 {
+		t.Errorf("reloaded SummaryMinFrames() = %d, want 3", got)
+	}
+}
+
+func TestSetSummarySortPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	if field, descending := m.SummarySort(); field != SummarySortTotal || !descending {
+		t.Fatalf("default SummarySort() = %d, %v, want total descending", field, descending)
+	}
+	m.LoadConfig()
+	m.SetSummarySort(SummarySortStdDev, false)
+	m.SetSummarySort(SummarySortField(42), true)
+	if field, descending := m.SummarySort(); field != SummarySortStdDev || descending {
+		t.Fatalf("SummarySort() = %d, %v, want the invalid field ignored", field, descending)
+	}
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if field, descending := reloaded.SummarySort(); field != SummarySortStdDev || descending {
+		t.Errorf("reloaded SummarySort() = %d, %v, want stddev ascending", field, descending)
+	}
+}
+
+func TestFramePercent(t *testing.T) {
+	if got := framePercent(3, 0); got != 0 {
+		t.Errorf("framePercent with zero elapsed = %v, want 0", got)
+	}
+	if got := framePercent(30, 15*time.Second); got != 200 {
+		t.Errorf("framePercent(30, 15s) = %v, want 200", got)
+	}
+}
+
+func TestRenderSummaryAverageModes(t *testing.T) {
+	// PID 7 runs for 4 CPU-seconds in one of four frames.
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{{PID: 7, Diff: 4, Command: "sparse"}, {PID: 8, Diff: 1, Command: "steady"}}},
+		{Index: 2, Rows: []ResultRow{{PID: 8, Diff: 1, Command: "steady"}}},
+		{Index: 3, Rows: []ResultRow{{PID: 8, Diff: 1, Command: "steady"}}},
+		{Index: 4, Rows: []ResultRow{{PID: 8, Diff: 1, Command: "steady"}}},
+	}
+
+	tests := []struct {
+		mode      AverageMode
+		sparseAvg string
+		steadyAvg string
+	}{
+		{AverageAllFrames, "1.0", "1.0"},
+		{AverageAppearedFrames, "4.0", "1.0"},
+	}
+	for _, tt := range tests {
+		rows := splitPayload(RenderSummaryTable(history, SummaryOptions{AverageMode: tt.mode}))
+		if len(rows) != 2 {
+			t.Fatalf("mode %d: got %d rows, want 2", tt.mode, len(rows))
+		}
+		avgs := map[string]string{rows[0][0]: rows[0][2], rows[1][0]: rows[1][2]}
+		if avgs["7"] != tt.sparseAvg {
+			t.Errorf("mode %d: sparse avg = %s, want %s", tt.mode, avgs["7"], tt.sparseAvg)
+		}
+		if avgs["8"] != tt.steadyAvg {
+			t.Errorf("mode %d: steady avg = %s, want %s", tt.mode, avgs["8"], tt.steadyAvg)
+		}
+	}
+}
+
+func TestSparklineGlyphs(t *testing.T) {
+	tests := []struct {
+		values []float64
+		width  int
+		want   string
+	}{
+		{[]float64{0, 1, 2, 3, 4, 5, 6}, 7, "▁▂▃▄▅▆▇"},
+		{[]float64{6, 3, 0}, 3, "▇▄▁"},
+		{[]float64{0, 0}, 2, "▁▁"},
+		{[]float64{2, 4}, 5, "   ▄▇"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values, tt.width); got != tt.want {
+			t.Errorf("sparkline(%v, %d) = %q, want %q", tt.values, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestSparklinesWindowAndPadding(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 9}}},
+		{Index: 2, Rows: []ResultRow{{PID: 1, Diff: 1}, {PID: 2, Diff: 2}}},
+		{Index: 3, Rows: []ResultRow{{PID: 1, Diff: 2}}},
+	}
+
+	sparks := sparklines(history, 2)
+	// Frame 1 falls outside the window, so PID 1 is normalised to max 2.
+	if got, want := sparks[1], "▄▇"; got != want {
+		t.Errorf("PID 1 sparkline = %q, want %q", got, want)
+	}
+	// PID 2 is absent from the newest frame, which counts as zero.
+	if got, want := sparks[2], "▇▁"; got != want {
+		t.Errorf("PID 2 sparkline = %q, want %q", got, want)
+	}
+
+	sparks = sparklines(history[2:], 4)
+	if got, want := sparks[1], "   ▇"; got != want {
+		t.Errorf("short history sparkline = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTableTrendColumn(t *testing.T) {
+	rows := []ResultRow{{PID: 1, Diff: 2, Command: "a"}, {PID: 2, Diff: 1, Command: "b"}}
+
+	got := splitPayload(RenderTable(rows, TableOptions{Sparks: map[int]string{1: "▁▇"}}, TabFormatter{}))
+	if got[0][4] != "▁▇" || got[1][4] != "" {
+		t.Errorf("trend column = [%q %q], want [%q %q]", got[0][4], got[1][4], "▁▇", "")
+	}
+	if got[0][6] != "a" {
+		t.Errorf("command column = %q, want %q", got[0][6], "a")
+	}
+}
+
+func TestRenderTableStatusMarker(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 1, Diff: 2, Command: "a", Status: "running"},
+		{PID: 2, Diff: 0, Command: "b", Status: "zombie"},
+		{PID: 3, Diff: 0, Command: "c", Status: "stop"},
+		{PID: 4, Diff: 0, Command: "d"},
+	}
+
+	got := splitPayload(RenderTable(rows, TableOptions{ShowStatus: true}, TabFormatter{}))
+	var markers []string
+	for _, row := range got {
+		markers = append(markers, row[5])
+	}
+	if want := []string{"", "Z", "T", ""}; strings.Join(markers, ",") != strings.Join(want, ",") {
+		t.Errorf("status column = %q, want %q", markers, want)
+	}
+	if got[1][6] != "b" {
+		t.Errorf("command column = %q, want %q", got[1][6], "b")
+	}
+
+	for _, row := range splitPayload(RenderTable(rows, TableOptions{}, TabFormatter{})) {
+		if row[5] != "" {
+			t.Errorf("PID %s has marker %q with the column off", row[0], row[5])
+		}
+	}
+}
+
+func TestBuildStatusCountsZombiesAndStopped(t *testing.T) {
+	m := NewMonitor(DiscardSink{})
+	rows := []ResultRow{
+		{PID: 1, Diff: 2, Status: "running"},
+		{PID: 2, Status: "zombie"},
+		{PID: 3, Status: "zombie"},
+		{PID: 4, Status: "stop"},
+	}
+
+	start := time.Now()
+	if status := m.buildStatusLocked(15, start, start, rows); !strings.Contains(status, "| 2 zombie, 1 stopped |") {
+		t.Errorf("status = %q, want the zombie and stopped counts", status)
+	}
+	if status := m.buildStatusLocked(15, start, start, rows[:1]); strings.Contains(status, "zombie") {
+		t.Errorf("status = %q, want no counts without flagged processes", status)
+	}
+}
+
+func TestBuildStatusVisibleTotalMatchesFilter(t *testing.T) {
+	m := NewMonitor(DiscardSink{})
+	m.hideSmall = true
+	m.frameIndex = 3
+	rows := []ResultRow{{PID: 1, Diff: 2.5}, {PID: 2, Diff: 1}, {PID: 3, Diff: 0.9}}
+
+	var want float64
+	for _, row := range splitPayload(RenderTable(rows, TableOptions{HideSmall: true}, TabFormatter{})) {
+		for _, r := range rows {
+			if row[0] == fmt.Sprint(r.PID) {
+				want += r.Diff
+			}
+		}
+	}
+
+	start := time.Now()
+	status := m.buildStatusLocked(15, start, start.Add(5*time.Second), rows)
+	if expect := fmt.Sprintf("visible total %.1f CPU-s across 2 processes", want); !strings.Contains(status, expect) {
+		t.Errorf("status = %q, want it to contain %q", status, expect)
+	}
+}
+
+func TestBuildStatusVisibleTotalFollowsTableOptions(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.hideSmall = false
+	m.viewingCurrent = true
+	rows := []ResultRow{{PID: 1, Diff: 4, Command: "/usr/bin/make"}, {PID: 2, Diff: 3, Command: "/usr/bin/backupd"}}
+	start := time.Now()
+
+	// An excluded process is neither shown nor counted.
+	m.AddPattern(ExcludeList, "backupd")
+	if status := m.buildStatusLocked(15, start, start, rows); !strings.Contains(status, "visible total 4.0 CPU-s across 1 process |") {
+		t.Errorf("status = %q, want only make counted", status)
+	}
+
+	// An expanded group counts its members once each, not its row as well.
+	m.SetGroupMode(GroupAppHelpersExpanded)
+	status := m.buildStatusLocked(15, start, start, chromeRows())
+	if !strings.Contains(status, "visible total 11.0 CPU-s across 5 processes") {
+		t.Errorf("status = %q, want every process counted once", status)
+	}
+}
+
+func TestSmallPercentFilterConsistentAcrossFrameLengths(t *testing.T) {
+	// rowsAt returns processes at 1%, 3%, and 50% of a core over a frame of
+	// length frame.
+	rowsAt := func(frame time.Duration) []ResultRow {
+		var rows []ResultRow
+		for pid, percent := range []float64{50, 3, 1} {
+			rows = append(rows, ResultRow{PID: pid + 1, Diff: percent / 100 * frame.Seconds()})
+		}
+		return rows
+	}
+	// shown returns the PIDs the table lists and the status bar's visible
+	// count for a frame of length frame.
+	shown := func(m *Monitor, frame time.Duration) ([]string, string) {
+		rows := rowsAt(frame)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		opts := TableOptions{HideSmall: true, SmallPercent: m.smallPercentLocked(), Elapsed: frame}
+		var pids []string
+		for _, row := range splitPayload(RenderTable(rows, opts, TabFormatter{})) {
+			pids = append(pids, row[0])
+		}
+		start := time.Now()
+		status := m.buildStatusLocked(frame.Seconds(), start, start.Add(frame), rows)
+		return pids, status[strings.Index(status, "across"):]
+	}
+
+	m, _ := newTestMonitor(t)
+	shortPIDs, _ := shown(m, 5*time.Second)
+	longPIDs, _ := shown(m, time.Minute)
+	if reflect.DeepEqual(shortPIDs, longPIDs) {
+		t.Fatalf("CPU-seconds filter showed %q for both frame lengths, want them to differ", shortPIDs)
+	}
+
+	m.SetSmallFilter(SmallFilterPercent, 2)
+	for _, frame := range []time.Duration{5 * time.Second, time.Minute} {
+		pids, visible := shown(m, frame)
+		if want := []string{"1", "2"}; !reflect.DeepEqual(pids, want) {
+			t.Errorf("%v frame: table shows PIDs %q, want %q", frame, pids, want)
+		}
+		if !strings.HasPrefix(visible, "across 2 processes") {
+			t.Errorf("%v frame: status %q, want 2 visible processes", frame, visible)
+		}
+	}
+}
+
+func TestRenderTableShareColumn(t *testing.T) {
+	rows := []ResultRow{{PID: 1, Diff: 3}, {PID: 2, Diff: 1.5}, {PID: 3, Diff: 0.5}}
+
+	got := splitPayload(RenderTable(rows, TableOptions{ShowShare: true, HideSmall: true}, TabFormatter{}))
+	if len(got) != 2 {
+		t.Fatalf("got %d rows, want 2", len(got))
+	}
+	if got[0][3] != "60.0%" || got[1][3] != "30.0%" {
+		t.Errorf("shares = [%s %s], want [60.0%% 30.0%%]", got[0][3], got[1][3])
+	}
+
+	var sum float64
+	for _, row := range got {
+		var share float64
+		fmt.Sscanf(row[3], "%f%%", &share)
+		sum += share
+	}
+	if sum > 100 {
+		t.Errorf("shown shares sum to %.1f%%, want ≤ 100%%", sum)
+	}
+
+	if off := splitPayload(RenderTable(rows, TableOptions{}, TabFormatter{})); off[0][3] != "" {
+		t.Errorf("share column with ShowShare off = %q, want empty", off[0][3])
+	}
+}
+
+func TestFrameShareEmptyFrame(t *testing.T) {
+	if got := frameShare(0, 0); got != 0 {
+		t.Errorf("frameShare(0, 0) = %v, want 0", got)
+	}
+	got := splitPayload(RenderTable([]ResultRow{{PID: 1, Diff: 0}}, TableOptions{ShowShare: true}, TabFormatter{}))
+	if got[0][3] != "0.0%" {
+		t.Errorf("zero-total share = %q, want 0.0%%", got[0][3])
+	}
+}
+
+func TestRenderCommandTableSumsAcrossPIDs(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{
+			{PID: 10, Diff: 2, Command: "/usr/bin/worker --id 1"},
+			{PID: 11, Diff: 3, Command: "/opt/worker --id 2"},
+			{PID: 20, Diff: 1, Command: "/bin/editor"},
+		}},
+		{Index: 2, Rows: []ResultRow{
+			{PID: 10, Diff: 1, Command: "/usr/bin/worker --id 1"},
+			{PID: 20, Diff: 0.5, Command: "/bin/editor"},
+			{PID: 30, Diff: 0.2, Command: "/bin/idle"},
+		}},
+	}
+
+	got := splitPayload(renderCommandTable(history))
+	want := [][]string{
+		{"6.0", "00:00:06", "2", "worker"},
+		{"1.5", "00:00:01", "1", "editor"},
+		{"0.2", "00:00:00", "1", "idle"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRenderCommandTableEmptyHistory(t *testing.T) {
+	if got := renderCommandTable(nil); got != "" {
+		t.Errorf("empty history payload = %q, want empty", got)
+	}
+}
+
+func TestRenderUserTableAggregatesByOwner(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{
+			{PID: 10, Diff: 4, User: "alice"},
+			{PID: 11, Diff: 1, User: "bob"},
+			{PID: 12, Diff: 0.5, User: ""},
+		}},
+		{Index: 2, Rows: []ResultRow{
+			{PID: 11, Diff: 2, User: "bob"},
+			{PID: 13, Diff: 2, User: "bob"},
+			{PID: 10, Diff: 1, User: "alice"},
+			{PID: 14, Diff: 0.5, User: "501"},
+		}},
+	}
+
+	got := splitPayload(renderUserTable(history))
+	// Equal totals fall back to name order; the unresolved owner is bucketed
+	// as "unknown" and a UID without a name is listed as is.
+	want := [][]string{
+		{"5.0", "00:00:05", "1", "alice"},
+		{"5.0", "00:00:05", "2", "bob"},
+		{"0.5", "00:00:00", "1", "501"},
+		{"0.5", "00:00:00", "1", "unknown"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := renderUserTable(nil); got != "" {
+		t.Errorf("empty history payload = %q, want empty", got)
+	}
+}
+
+func TestRenderCommandTableCountsChurningPIDs(t *testing.T) {
+	// A pool of short-lived workers: no frame has more than two at once, but
+	// five distinct PIDs contributed, and the final frame has only one.
+	history := []FrameRecord{
+		{Index: 1, Rows: []ResultRow{{PID: 101, Diff: 1, Command: "/bin/job"}, {PID: 102, Diff: 1, Command: "/bin/job"}}},
+		{Index: 2, Rows: []ResultRow{{PID: 102, Diff: 1, Command: "/bin/job"}, {PID: 103, Diff: 1, Command: "/bin/job"}}},
+		{Index: 3, Rows: []ResultRow{{PID: 104, Diff: 1, Command: "/bin/job"}, {PID: 105, Diff: 1, Command: "/bin/job"}}},
+		{Index: 4, Rows: []ResultRow{{PID: 105, Diff: 1, Command: "/bin/job"}, {PID: 200, Diff: 9, Command: "/bin/steady"}}},
+	}
+
+	got := splitPayload(renderCommandTable(history))
+	if len(got) != 2 {
+		t.Fatalf("got %q, want two commands", got)
+	}
+	if got[0][3] != "steady" || got[0][2] != "1" {
+		t.Errorf("steady row = %q, want one PID", got[0])
+	}
+	if got[1][3] != "job" || got[1][2] != "5" || got[1][0] != "7.0" {
+		t.Errorf("job row = %q, want 7.0 CPU-s from 5 PIDs", got[1])
+	}
+}
+
+func TestRenderSummaryTableCountsReusedPIDProcesses(t *testing.T) {
+	// make is restarted under the same PID in every frame, and one of its
+	// processes skips a frame; the editor runs throughout.
+	history := []FrameRecord{
+		{Index: 1, Duration: time.Second, Rows: []ResultRow{{PID: 50, CreateTime: 1, Diff: 2, Command: "/usr/bin/make"}, {PID: 60, CreateTime: 9, Diff: 1, Command: "/bin/editor"}}},
+		{Index: 2, Duration: time.Second, Rows: []ResultRow{{PID: 50, CreateTime: 2, Diff: 2, Command: "/usr/bin/make"}, {PID: 60, CreateTime: 9, Diff: 1, Command: "/bin/editor"}}},
+		{Index: 3, Duration: time.Second, Rows: []ResultRow{{PID: 60, CreateTime: 9, Diff: 1, Command: "/bin/editor"}}},
+		{Index: 4, Duration: time.Second, Rows: []ResultRow{{PID: 50, CreateTime: 3, Diff: 2, Command: "/usr/bin/make"}, {PID: 60, CreateTime: 9, Diff: 1, Command: "/bin/editor"}}},
+	}
+	columns := []string{"pid", "total", "processes"}
+
+	// Merged, the make row counts every process that held the PID, not
+	// only the one in the final frame.
+	got := splitPayload(RenderSummaryTable(history, SummaryOptions{MergeReused: true, Columns: columns}))
+	want := [][]string{{"50", "6.0", "3"}, {"60", "4.0", "1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged summary = %q, want %q", got, want)
+	}
+
+	// Unmerged, each process has a row of its own.
+	got = splitPayload(RenderSummaryTable(history, SummaryOptions{Columns: columns}))
+	for _, row := range got {
+		if row[2] != "1" {
+			t.Errorf("unmerged row %q counts %s processes, want 1", row, row[2])
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("unmerged summary has %d rows, want 4", len(got))
+	}
+}
+
+func TestRenderSummaryReusedPIDs(t *testing.T) {
+	// PID 500 runs make, exits, and is reused for another make; PID 501 is
+	// reused for a different command.
+	history := []FrameRecord{
+		{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{
+			{PID: 500, Diff: 2, Command: "/usr/bin/make all", CreateTime: 1000},
+			{PID: 501, Diff: 1, Command: "/bin/sh", CreateTime: 1000},
+		}},
+		{Index: 2, Duration: 10 * time.Second, Rows: []ResultRow{
+			{PID: 500, Diff: 3, Command: "/usr/bin/make install", CreateTime: 12000},
+			{PID: 501, Diff: 1, Command: "/usr/bin/cc", CreateTime: 12000},
+		}},
+	}
+
+	rows := splitPayload(RenderSummaryTable(history, SummaryOptions{HidePaths: true}))
+	var got []string
+	for _, row := range rows {
+		got = append(got, row[0]+" "+row[1]+" "+row[6])
+	}
+	if want := "500 3.0 make,500 2.0 make,501 1.0 sh,501 1.0 cc"; strings.Join(got, ",") != want {
+		t.Errorf("separate rows = %q, want %q", strings.Join(got, ","), want)
+	}
+
+	rows = splitPayload(RenderSummaryTable(history, SummaryOptions{HidePaths: true, MergeReused: true}))
+	got = got[:0]
+	for _, row := range rows {
+		got = append(got, row[0]+" "+row[1]+" "+row[6])
+	}
+	if want := "500 5.0 make (2 processes),501 1.0 sh,501 1.0 cc"; strings.Join(got, ",") != want {
+		t.Errorf("merged rows = %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestSetMergeReusedPIDsPersists(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetMergeReusedPIDs(true)
+
+	reloaded := NewMonitor(DiscardSink{})
+	reloaded.LoadConfig()
+	if !reloaded.MergeReusedPIDs() {
+		t.Error("reloaded MergeReusedPIDs() = false, want true")
+	}
+}
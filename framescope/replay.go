@@ -0,0 +1,243 @@
+package framescope
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// defaultReplaySpeed is the playback rate, in frames per second, until
+	// SetReplaySpeed is called.
+	defaultReplaySpeed = 1.0
+
+	// maxReplaySpeed caps the playback rate, so that every frame is shown for
+	// at least one replayTick.
+	maxReplaySpeed = 20.0
+
+	// replayTick is how often a playing replay checks whether to advance.
+	replayTick = 50 * time.Millisecond
+)
+
+// replayState is the playback of the loaded history (see ReplayPlay). The
+// frame shown is derived from the time since startedAt rather than counted
+// per tick, so late ticks do not slow playback down.
+type replayState struct {
+	// startIdx is the history index shown at startedAt, re-anchored when the
+	// speed changes or the user selects a frame.
+	startIdx  int
+	startedAt time.Time
+
+	// stop ends the playback goroutine; nil while not playing.
+	stop chan struct{}
+}
+
+// playing reports whether playback is in progress.
+func (r *replayState) playing() bool {
+	return r.stop != nil
+}
+
+// replayIndex returns the history index a replay anchored at start shows
+// after elapsed at speed frames per second, over n frames, and whether
+// playback has reached the end: with loop it wraps around to the first frame
+// instead, and never ends.
+func replayIndex(start, n int, elapsed time.Duration, speed float64, loop bool) (index int, ended bool) {
+	index = start + int(elapsed.Seconds()*speed)
+	switch {
+	case index < n:
+		return index, false
+	case loop:
+		return index % n, false
+	default:
+		return n - 1, true
+	}
+}
+
+// ReplayPlay plays back the loaded history, such as an imported recording,
+// by advancing the selected frame at the rate set by SetReplaySpeed, whatever
+// the frames' own lengths, through the same selection as SelectFrame. It
+// starts from the frame on display, or from the first frame if the last is
+// on display, and at the last frame stops or, with SetReplayLoop, starts over.
+// Selecting a frame while playing continues from it. It is an error to play
+// back while monitoring is running or without completed frames; the status
+// line says so. Playing an already playing replay does nothing.
+func (m *Monitor) ReplayPlay() error {
+	m.mu.Lock()
+	var err error
+	switch {
+	case m.running:
+		err = errors.New("stop monitoring before replaying")
+	case len(m.history) == 0:
+		err = errors.New("no frames to replay")
+	}
+	if err != nil {
+		m.status = "Replay: " + err.Error() + "."
+		m.mu.Unlock()
+		m.pushUI(0)
+		return err
+	}
+	if m.replay.playing() {
+		m.mu.Unlock()
+		return nil
+	}
+	start := m.selectedHistoryIdx
+	if m.viewingCurrent || start < 0 || start >= len(m.history)-1 {
+		start = 0
+	}
+	stop := make(chan struct{})
+	m.replay = replayState{startIdx: start, startedAt: m.now(), stop: stop}
+	m.showReplayFrameLocked(start)
+	m.mu.Unlock()
+	m.pushUI(0)
+
+	go m.replayLoop(stop)
+	return nil
+}
+
+// ReplayPause stops playback, keeping the frame on display. It does nothing
+// while not playing.
+func (m *Monitor) ReplayPause() {
+	m.mu.Lock()
+	if !m.replay.playing() {
+		m.mu.Unlock()
+		return
+	}
+	m.stopReplayLocked()
+	m.status = "Replay paused."
+	m.mu.Unlock()
+	m.pushUI(0)
+}
+
+// ReplayPlaying reports whether the history is being played back.
+func (m *Monitor) ReplayPlaying() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.replay.playing()
+}
+
+// stopReplayLocked ends playback, if any. Must be called with m.mu held.
+func (m *Monitor) stopReplayLocked() {
+	if m.replay.stop != nil {
+		close(m.replay.stop)
+		m.replay.stop = nil
+	}
+}
+
+// reanchorReplayLocked makes a playing replay continue from the frame on
+// display, from now. Must be called with m.mu held.
+func (m *Monitor) reanchorReplayLocked() {
+	if m.replay.playing() && !m.viewingCurrent && m.selectedHistoryIdx >= 0 {
+		m.replay.startIdx, m.replay.startedAt = m.selectedHistoryIdx, m.now()
+	}
+}
+
+// replayLoop advances playback every replayTick until stop is closed or
+// playback ends.
+func (m *Monitor) replayLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(replayTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !m.advanceReplay() {
+				return
+			}
+		}
+	}
+}
+
+// advanceReplay shows the frame playback has reached (see replayIndex),
+// pushing a UI refresh if it changed, and reports whether playback goes on.
+func (m *Monitor) advanceReplay() bool {
+	m.mu.Lock()
+	if !m.replay.playing() || len(m.history) == 0 {
+		m.mu.Unlock()
+		return false
+	}
+	index, ended := replayIndex(m.replay.startIdx, len(m.history), m.now().Sub(m.replay.startedAt), m.replaySpeedLocked(), m.loopReplay)
+	changed := m.viewingCurrent || index != m.selectedHistoryIdx
+	if changed {
+		m.showReplayFrameLocked(index)
+	}
+	if ended {
+		m.stopReplayLocked()
+		m.status = "Replay finished."
+		changed = true
+	}
+	m.mu.Unlock()
+	if changed {
+		m.pushUI(0)
+	}
+	return !ended
+}
+
+// showReplayFrameLocked selects the completed frame at index, as SelectFrame
+// would, and names it in the status line. Must be called with m.mu held.
+func (m *Monitor) showReplayFrameLocked(index int) {
+	m.viewingCurrent = false
+	m.selectedHistoryIdx = index
+	m.autoFollowLatestComplete = false
+	m.summaryRange = frameRange{}
+	m.status = replayStatus(m.history[index].Index, len(m.history), m.replaySpeedLocked())
+}
+
+// replayStatus returns the status line while frame is played back.
+func replayStatus(frame, frames int, speed float64) string {
+	unit := "frames/s"
+	if speed == 1 {
+		unit = "frame/s"
+	}
+	return fmt.Sprintf("Replaying frame %d of %d at %g %s.", frame, frames, speed, unit)
+}
+
+// replaySpeedLocked returns the playback rate in frames per second. Must be
+// called with m.mu held.
+func (m *Monitor) replaySpeedLocked() float64 {
+	if m.replaySpeed <= 0 {
+		return defaultReplaySpeed
+	}
+	return m.replaySpeed
+}
+
+// SetReplaySpeed sets the playback rate of ReplayPlay in frames per second,
+// e.g. 1 to show each frame for a second, or 0.5 for two. Rates above 20 are
+// capped; non-positive or non-finite ones are ignored. Changing the rate
+// while playing continues from the frame on display. The new setting is
+// persisted to disk immediately.
+func (m *Monitor) SetReplaySpeed(framesPerSecond float64) {
+	if !(framesPerSecond > 0) || math.IsInf(framesPerSecond, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.replaySpeed = min(framesPerSecond, maxReplaySpeed)
+	m.reanchorReplayLocked()
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// ReplaySpeed returns the playback rate in frames per second.
+func (m *Monitor) ReplaySpeed() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.replaySpeedLocked()
+}
+
+// SetReplayLoop makes playback start over from the first frame after the
+// last, instead of stopping there. The new setting is persisted to disk
+// immediately.
+func (m *Monitor) SetReplayLoop(enabled bool) {
+	m.mu.Lock()
+	m.loopReplay = enabled
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// ReplayLoop reports whether playback starts over after the last frame.
+func (m *Monitor) ReplayLoop() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loopReplay
+}
@@ -0,0 +1,107 @@
+package framescope
+
+import (
+	"testing"
+	"time"
+)
+
+// newReplayMonitor returns a stopped monitor holding n completed frames,
+// numbered from 1, with the last on display, driven by the returned clock.
+func newReplayMonitor(t *testing.T, n int) (*Monitor, *fakeClock) {
+	t.Helper()
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	for i := 1; i <= n; i++ {
+		m.history = append(m.history, FrameRecord{Index: i, Duration: time.Minute})
+	}
+	m.selectedHistoryIdx = n - 1
+	t.Cleanup(m.ReplayPause)
+	return m, clock
+}
+
+// shownFrame returns the Index of the frame on display after playback has
+// caught up with the clock.
+func shownFrame(m *Monitor) int {
+	m.advanceReplay()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.history[m.selectedHistoryIdx].Index
+}
+
+func TestReplayAdvancesAtSpeed(t *testing.T) {
+	m, clock := newReplayMonitor(t, 4)
+	m.SetReplaySpeed(2)
+	if err := m.ReplayPlay(); err != nil {
+		t.Fatal(err)
+	}
+	// With the last frame on display, playback starts over from the first.
+	if got := shownFrame(m); got != 1 {
+		t.Fatalf("frame %d shown at the start, want 1", got)
+	}
+
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{400 * time.Millisecond, 1},
+		{100 * time.Millisecond, 2}, // half a second per frame, whatever the frames' length
+		{500 * time.Millisecond, 3},
+		{500 * time.Millisecond, 4},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		if got := shownFrame(m); got != step.want {
+			t.Fatalf("frame %d shown, want %d", got, step.want)
+		}
+	}
+
+	// Without a loop, playback stops at the last frame.
+	clock.Advance(5 * time.Second)
+	if got := shownFrame(m); got != 4 || m.ReplayPlaying() {
+		t.Errorf("at the end: frame %d shown, playing = %v; want 4, stopped", got, m.ReplayPlaying())
+	}
+}
+
+func TestReplayLoopsAndFollowsSelection(t *testing.T) {
+	m, clock := newReplayMonitor(t, 3)
+	m.SetReplayLoop(true)
+	m.SelectFrame(1)
+	if err := m.ReplayPlay(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(2 * time.Second)
+	if got := shownFrame(m); got != 1 {
+		t.Fatalf("frame %d shown after wrapping around, want 1", got)
+	}
+
+	// Picking a frame continues from it, and a new speed from the frame shown.
+	m.SelectFrame(2)
+	clock.Advance(500 * time.Millisecond)
+	if got := shownFrame(m); got != 3 {
+		t.Fatalf("frame %d shown after selecting frame 3, want it kept", got)
+	}
+	m.SetReplaySpeed(4)
+	clock.Advance(250 * time.Millisecond)
+	if got := shownFrame(m); got != 1 {
+		t.Fatalf("frame %d shown at 4 frames/s, want 1", got)
+	}
+
+	m.ReplayPause()
+	clock.Advance(10 * time.Second)
+	if got := shownFrame(m); got != 1 {
+		t.Errorf("frame %d shown while paused, want 1", got)
+	}
+}
+
+func TestReplayNeedsStoppedHistory(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	if err := m.ReplayPlay(); err == nil {
+		t.Error("ReplayPlay accepted an empty history")
+	}
+	m.history = []FrameRecord{{Index: 1}}
+	m.running = true
+	if err := m.ReplayPlay(); err == nil {
+		t.Error("ReplayPlay accepted a running monitor")
+	}
+}
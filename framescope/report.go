@@ -0,0 +1,205 @@
+package framescope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ReportOptions configures RunReport.
+type ReportOptions struct {
+	Duration  time.Duration // length of the sampled frame
+	Top       int           // rows to print; <= 0 prints every row
+	HidePaths bool          // show only the executable basename
+	MinCPU    float64       // omit rows below this many CPU-seconds
+
+	// clock and wait replace time.Now and the timer in tests; wait returns
+	// early if ctx is done.
+	clock func() time.Time
+	wait  func(ctx context.Context, d time.Duration)
+}
+
+// TopReport is the result of RunReport: the heaviest processes of one frame.
+type TopReport struct {
+	Rows      []ResultRow   // sorted by CPU-seconds descending, filtered and capped
+	Elapsed   time.Duration // length of the frame actually sampled
+	HidePaths bool
+}
+
+// String renders the report as an aligned table for the terminal, one row per
+// process with its PID, CPU-seconds, and %CPU over the frame.
+func (r TopReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tCPU-s\t%CPU\tCOMMAND")
+	for _, row := range r.Rows {
+		fmt.Fprintf(w, "%d\t%.2f\t%.1f\t%s\n",
+			row.PID, row.Diff, framePercent(row.Diff, r.Elapsed), sanitizeCommand(row.Command, r.HidePaths))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// RunReport measures a single frame of opts.Duration without a Monitor: it
+// snapshots source, waits, snapshots again, and returns the opts.Top processes
+// that used the most CPU in between, skipping kernel_task and rows below
+// opts.MinCPU. If ctx is cancelled while waiting, the shorter frame is
+// reported. A *SkippedError from source is not a failure; any other snapshot
+// error is returned.
+func RunReport(ctx context.Context, source ProcessSource, opts ReportOptions) (TopReport, error) {
+	clock, wait := opts.clock, opts.wait
+	if clock == nil {
+		clock = time.Now
+	}
+	if wait == nil {
+		wait = sleepContext
+	}
+
+	start := clock()
+	initial, err := reportSnapshot(source)
+	if err != nil {
+		return TopReport{}, err
+	}
+	wait(ctx, opts.Duration)
+	current, err := reportSnapshot(source)
+	if err != nil {
+		return TopReport{}, err
+	}
+
+	report := TopReport{Elapsed: clock().Sub(start), HidePaths: opts.HidePaths}
+	for _, row := range ComputeResults(initial, current, TiebreakPID) {
+		if row.Diff < opts.MinCPU {
+			continue
+		}
+		if opts.Top > 0 && len(report.Rows) == opts.Top {
+			break
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report, nil
+}
+
+// reportSnapshot takes one snapshot for RunReport, ignoring skipped processes
+// and dropping kernel_task as the Monitor does by default.
+func reportSnapshot(source ProcessSource) (map[int]ProcessSample, error) {
+	samples, err := source.Snapshot()
+	if err != nil && !errors.As(err, new(*SkippedError)) {
+		return nil, err
+	}
+	for pid, sample := range samples {
+		if isKernelTask(pid, sample) {
+			delete(samples, pid)
+		}
+	}
+	return samples, nil
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// snapshotNowTop is how many processes SnapshotNow returns, and
+// maxSnapshotNow the longest reading it takes.
+const (
+	snapshotNowTop = 20
+	maxSnapshotNow = time.Minute
+)
+
+// snapshotReading is the JSON object SnapshotNow returns. Error is set instead
+// of Rows if no reading was taken.
+type snapshotReading struct {
+	DurationSeconds float64       `json:"duration_seconds"`
+	Rows            []snapshotRow `json:"rows"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// snapshotRow is one process of a snapshotReading. Percent is framePercent
+// over the reading's duration.
+type snapshotRow struct {
+	PID        int     `json:"pid"`
+	CPUSeconds float64 `json:"cpu_seconds"`
+	Percent    float64 `json:"percent"`
+	Command    string  `json:"command"`
+}
+
+// SnapshotNow takes a one-off reading of seconds, capped at maxSnapshotNow,
+// and returns the snapshotNowTop processes that used the most CPU as a JSON
+// snapshotReading. It blocks for the whole reading. It samples the monitor's
+// process source as RunReport does but leaves monitoring alone: the frames,
+// history, and status of a run in progress are untouched, so it can be used
+// whether or not one is. Only one reading is taken at a time; a call made
+// during another, or with a negative or non-finite length, returns a reading
+// with only Error set. A completed reading is announced as SetCompletionAlert
+// selects.
+func (m *Monitor) SnapshotNow(seconds float64) string {
+	return m.snapshotNow(seconds, sleepContext)
+}
+
+// snapshotNow is SnapshotNow with the wait between the two snapshots
+// replaced, for tests.
+func (m *Monitor) snapshotNow(seconds float64, wait func(ctx context.Context, d time.Duration)) string {
+	reading := m.takeReading(seconds, wait)
+	if reading.Error == "" {
+		m.alertCompletion(0, false, fmt.Sprintf("The %.0f-second reading is ready.", reading.DurationSeconds))
+	}
+	data, err := json.Marshal(reading)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// takeReading measures the snapshotReading for snapshotNow.
+func (m *Monitor) takeReading(seconds float64, wait func(ctx context.Context, d time.Duration)) snapshotReading {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return snapshotReading{Error: fmt.Sprintf("invalid reading length %v", seconds)}
+	}
+	m.mu.Lock()
+	busy := m.snapshotting
+	m.snapshotting = true
+	m.mu.Unlock()
+	if busy {
+		return snapshotReading{Error: "a reading is already in progress"}
+	}
+	defer func() {
+		m.mu.Lock()
+		m.snapshotting = false
+		m.mu.Unlock()
+	}()
+
+	length := min(time.Duration(seconds*float64(time.Second)), maxSnapshotNow)
+	report, err := RunReport(context.Background(), m.processSource(), ReportOptions{
+		Duration: length,
+		Top:      snapshotNowTop,
+		clock:    m.now,
+		wait:     wait,
+	})
+	if err != nil {
+		return snapshotReading{Error: err.Error()}
+	}
+	m.commands.fill(report.Rows, nil, time.Time{})
+	reading := snapshotReading{
+		DurationSeconds: report.Elapsed.Seconds(),
+		Rows:            make([]snapshotRow, len(report.Rows)),
+	}
+	for i, row := range report.Rows {
+		reading.Rows[i] = snapshotRow{
+			PID:        row.PID,
+			CPUSeconds: row.Diff,
+			Percent:    framePercent(row.Diff, report.Elapsed),
+			Command:    row.Command,
+		}
+	}
+	return reading
+}
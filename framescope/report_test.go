@@ -0,0 +1,143 @@
+package framescope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportSource returns before on its first snapshot and after on every later one.
+type reportSource struct {
+	calls         int
+	before, after map[int]ProcessSample
+}
+
+func (s *reportSource) Snapshot() (map[int]ProcessSample, error) {
+	s.calls++
+	if s.calls == 1 {
+		return s.before, nil
+	}
+	return s.after, nil
+}
+
+func TestRunReportPrintsTopN(t *testing.T) {
+	sample := func(cpu float64, command string) ProcessSample {
+		return ProcessSample{CPUSeconds: cpu, Command: command, CreateTime: 1}
+	}
+	source := &reportSource{
+		before: map[int]ProcessSample{
+			0:  {CPUSeconds: 0, Exe: "kernel_task"},
+			10: sample(1, "/usr/bin/encoder --fast"),
+			11: sample(5, "/bin/idle"),
+			12: sample(2, "/Applications/Browser.app/browser"),
+			13: sample(0, "/bin/indexer"),
+		},
+		after: map[int]ProcessSample{
+			0:  {CPUSeconds: 50, Exe: "kernel_task"},
+			10: sample(9, "/usr/bin/encoder --fast"),
+			11: sample(5.5, "/bin/idle"),
+			12: sample(6, "/Applications/Browser.app/browser"),
+			13: sample(3, "/bin/indexer"),
+		},
+	}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	var waited time.Duration
+	opts := ReportOptions{
+		Duration:  10 * time.Second,
+		Top:       2,
+		HidePaths: true,
+		MinCPU:    1,
+		clock:     clock.Now,
+		wait: func(_ context.Context, d time.Duration) {
+			waited = d
+			clock.Advance(d)
+		},
+	}
+
+	report, err := RunReport(context.Background(), source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waited != 10*time.Second || source.calls != 2 {
+		t.Errorf("waited %v between %d snapshots, want 10s between 2", waited, source.calls)
+	}
+	want := "PID  CPU-s  %CPU  COMMAND\n" +
+		"10   8.00   80.0  encoder\n" +
+		"12   4.00   40.0  browser\n"
+	if got := report.String(); got != want {
+		t.Errorf("report =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without a cap, the threshold still drops the idle process.
+	opts.Top = 0
+	source.calls = 0
+	report, err = RunReport(context.Background(), source, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rows) != 3 || report.Rows[2].PID != 13 {
+		t.Errorf("rows = %+v, want encoder, browser, indexer", report.Rows)
+	}
+}
+
+func TestRunReportSnapshotError(t *testing.T) {
+	source := &fakeSource{err: errors.New("sysctl failed")}
+	_, err := RunReport(context.Background(), source, ReportOptions{Duration: time.Second})
+	if err == nil || !strings.Contains(err.Error(), "sysctl failed") {
+		t.Errorf("err = %v, want the snapshot error", err)
+	}
+}
+
+func TestSnapshotNow(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &reportSource{
+		before: map[int]ProcessSample{
+			10: {CPUSeconds: 1, Command: "/bin/encoder", CreateTime: 1},
+			11: {CPUSeconds: 4, Command: "/bin/idle", CreateTime: 1},
+		},
+		after: map[int]ProcessSample{
+			10: {CPUSeconds: 3, Command: "/bin/encoder", CreateTime: 1},
+			11: {CPUSeconds: 4.5, Command: "/bin/idle", CreateTime: 1},
+		},
+	}
+	m.SetProcessSource(source)
+	var waited time.Duration
+	wait := func(_ context.Context, d time.Duration) {
+		waited = d
+		clock.Advance(d)
+	}
+
+	var reading snapshotReading
+	if err := json.Unmarshal([]byte(m.snapshotNow(4, wait)), &reading); err != nil {
+		t.Fatal(err)
+	}
+	want := []snapshotRow{
+		{PID: 10, CPUSeconds: 2, Percent: 50, Command: "/bin/encoder"},
+		{PID: 11, CPUSeconds: 0.5, Percent: 12.5, Command: "/bin/idle"},
+	}
+	if waited != 4*time.Second || reading.DurationSeconds != 4 || !reflect.DeepEqual(reading.Rows, want) {
+		t.Errorf("reading over %v = %+v, want %+v over 4s", waited, reading, want)
+	}
+
+	// Monitoring is left alone.
+	if updates, _ := rec.snapshotUpdates(); len(updates) != 0 || m.Running() || len(m.history) != 0 {
+		t.Errorf("monitor changed: %d UI updates, running %v, %d frames", len(updates), m.Running(), len(m.history))
+	}
+
+	// A length that cannot be waited for, or a second reading while one is
+	// being taken, is refused.
+	if got := m.snapshotNow(math.NaN(), wait); !strings.Contains(got, `"error":"invalid reading length`) {
+		t.Errorf("NaN length: %s", got)
+	}
+	m.snapshotting = true
+	if got := m.snapshotNow(1, wait); !strings.Contains(got, `"error":"a reading is already in progress"`) {
+		t.Errorf("concurrent reading: %s", got)
+	}
+}
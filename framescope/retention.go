@@ -0,0 +1,53 @@
+package framescope
+
+import (
+	"math"
+	"time"
+)
+
+// trimHistory drops the oldest completed frames while there are more than
+// maxHistory, and, when retention is positive, while the oldest ended more
+// than retention before now. Frames without an EndedAt never expire. The
+// selection is adjusted so the UI stays on the same frame, or on the oldest
+// remaining one if the selected frame was dropped.
+func (v *frameView) trimHistory(now time.Time, retention time.Duration) {
+	drop := max(len(v.history)-maxHistory, 0)
+	if retention > 0 {
+		cutoff := now.Add(-retention)
+		for drop < len(v.history) {
+			ended := v.history[drop].EndedAt
+			if ended.IsZero() || !ended.Before(cutoff) {
+				break
+			}
+			drop++
+		}
+	}
+	if drop == 0 {
+		return
+	}
+	v.history = v.history[drop:]
+	if v.selectedHistoryIdx > 0 {
+		v.selectedHistoryIdx = max(v.selectedHistoryIdx-drop, 0)
+	}
+}
+
+// SetHistoryRetention limits the history to the frames that ended within the
+// last seconds, on top of the maxHistory cap; 0 keeps frames by count alone.
+// Older frames are dropped as each frame completes. Negative or non-finite
+// values are ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetHistoryRetention(seconds float64) {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.historyRetention = time.Duration(seconds * float64(time.Second))
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// HistoryRetention returns the history retention in seconds, 0 for none.
+func (m *Monitor) HistoryRetention() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.historyRetention.Seconds()
+}
@@ -0,0 +1,85 @@
+package framescope
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrimHistory(t *testing.T) {
+	start := time.Unix(1000, 0)
+	ended := func(minutes ...int) []FrameRecord {
+		frames := make([]FrameRecord, len(minutes))
+		for i, m := range minutes {
+			frames[i] = FrameRecord{Index: i + 1}
+			if m >= 0 {
+				frames[i].EndedAt = start.Add(time.Duration(m) * time.Minute)
+			}
+		}
+		return frames
+	}
+	now := start.Add(60 * time.Minute)
+
+	for _, tc := range []struct {
+		name               string
+		history            []FrameRecord
+		retention          time.Duration
+		selected           int
+		wantFirst, wantLen int
+		wantSelected       int
+	}{
+		{"count only", ended(0, 10, 59), 0, 2, 1, 3, 2},
+		{"expired dropped", ended(0, 10, 40, 59), 30 * time.Minute, 3, 3, 2, 1},
+		{"ended exactly at the cutoff kept", ended(29, 30, 59), 30 * time.Minute, -1, 2, 2, -1},
+		{"selected frame dropped", ended(0, 10, 59), 30 * time.Minute, 1, 3, 1, 0},
+		{"no end time never expires", ended(-1, 0, 59), 30 * time.Minute, 2, 1, 3, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := frameView{history: tc.history, selectedHistoryIdx: tc.selected}
+			v.trimHistory(now, tc.retention)
+			if len(v.history) != tc.wantLen || v.history[0].Index != tc.wantFirst {
+				t.Errorf("kept %d frames from %d, want %d from %d", len(v.history), v.history[0].Index, tc.wantLen, tc.wantFirst)
+			}
+			if v.selectedHistoryIdx != tc.wantSelected {
+				t.Errorf("selectedHistoryIdx = %d, want %d", v.selectedHistoryIdx, tc.wantSelected)
+			}
+		})
+	}
+
+	// The count cap still applies with a retention that keeps everything.
+	v := frameView{history: make([]FrameRecord, maxHistory+1), selectedHistoryIdx: maxHistory}
+	v.trimHistory(now, time.Hour)
+	if len(v.history) != maxHistory || v.selectedHistoryIdx != maxHistory-1 {
+		t.Errorf("kept %d frames, selected %d, want %d and %d", len(v.history), v.selectedHistoryIdx, maxHistory, maxHistory-1)
+	}
+}
+
+func TestHistoryRetentionEvictsByTime(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.SetHistoryRetention(25)
+	m.SetHistoryRetention(-1)
+	if got := m.HistoryRetention(); got != 25 {
+		t.Fatalf("HistoryRetention() = %v, want the negative value ignored", got)
+	}
+	m.running = true
+	m.frameView = newFrameView(10)
+	track := &frameTrack{length: 10, duration: 10 * time.Second, baseline: map[int]ProcessSample{}, start: clock.Now()}
+
+	// Complete four 10s frames; the first ends 30s before the last, outside
+	// the 25s window, and the second 20s before, inside it.
+	for range 4 {
+		clock.Advance(10 * time.Second)
+		if _, completed := m.advanceTrack(track, map[int]ProcessSample{}, clock.Now(), false, TiebreakPID); !completed {
+			t.Fatal("frame did not complete")
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.history) != 3 || m.history[0].Index != 2 {
+		t.Fatalf("history = %+v, want frames 2 to 4", m.history)
+	}
+	if m.selectedHistoryIdx != 2 {
+		t.Errorf("selectedHistoryIdx = %d, want the newest frame", m.selectedHistoryIdx)
+	}
+}
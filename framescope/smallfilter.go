@@ -0,0 +1,85 @@
+package framescope
+
+import (
+	"math"
+	"time"
+)
+
+// SmallFilterMode selects what the current-frame table's small-process filter
+// (see SetHideSmall) measures rows against. The values are part of the cgo
+// bridge (GoSetSmallFilter) and the config file, so they must not be
+// renumbered.
+type SmallFilterMode int
+
+const (
+	// SmallFilterSeconds hides rows below hideSmallThreshold CPU-seconds,
+	// whatever the frame length.
+	SmallFilterSeconds SmallFilterMode = iota
+
+	// SmallFilterPercent hides rows below a %CPU of one core over the frame
+	// (see framePercent), so that a process of the same intensity is shown
+	// or hidden alike in 5-second and 60-second frames.
+	SmallFilterPercent
+)
+
+// defaultSmallFilterPercent is the SmallFilterPercent threshold until
+// SetSmallFilter chooses another.
+const defaultSmallFilterPercent = 2.0
+
+// validSmallFilterMode reports whether mode is one of the defined
+// SmallFilterMode values.
+func validSmallFilterMode(mode SmallFilterMode) bool {
+	return mode == SmallFilterSeconds || mode == SmallFilterPercent
+}
+
+// validSmallFilterPercent reports whether percent is a usable
+// SmallFilterPercent threshold.
+func validSmallFilterPercent(percent float64) bool {
+	return percent > 0 && !math.IsInf(percent, 0)
+}
+
+// belowSmall reports whether cpuSeconds in a frame of length elapsed falls
+// under the small-process filter: below hideSmallThreshold CPU-seconds, or,
+// with a non-zero percent, below percent %CPU of one core over elapsed.
+func belowSmall(cpuSeconds, percent float64, elapsed time.Duration) bool {
+	if percent > 0 {
+		return framePercent(cpuSeconds, elapsed) < percent
+	}
+	return cpuSeconds < hideSmallThreshold
+}
+
+// smallPercentLocked returns the %CPU threshold of the small-process filter,
+// or 0 when it measures CPU-seconds. m.mu must be held.
+func (m *Monitor) smallPercentLocked() float64 {
+	if m.smallFilterMode != SmallFilterPercent {
+		return 0
+	}
+	return m.smallFilterPercent
+}
+
+// SetSmallFilter chooses what the small-process filter of the current-frame
+// table and the status bar's visible count hides: rows below 1 CPU-second, or
+// rows below percent %CPU of one core over the frame shown. The percent is
+// kept for SmallFilterSeconds too, to be used when percent filtering is
+// chosen again. Unknown modes, and non-positive or non-finite percents, are
+// ignored. The filter applies only while SetHideSmall is on. The new setting
+// is persisted to disk immediately.
+func (m *Monitor) SetSmallFilter(mode SmallFilterMode, percent float64) {
+	if !validSmallFilterMode(mode) || !validSmallFilterPercent(percent) {
+		return
+	}
+	m.mu.Lock()
+	m.smallFilterMode = mode
+	m.smallFilterPercent = percent
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SmallFilter returns the small-process filter's mode and its %CPU
+// threshold.
+func (m *Monitor) SmallFilter() (SmallFilterMode, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.smallFilterMode, m.smallFilterPercent
+}
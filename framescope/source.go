@@ -0,0 +1,299 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessSource takes snapshots of the running processes. A Monitor samples
+// the system through SystemSource unless a different source is substituted,
+// e.g. a fake in tests or the benchmark harness.
+type ProcessSource interface {
+	// Snapshot returns the cumulative CPU usage of every running process,
+	// keyed by PID.
+	Snapshot() (map[int]ProcessSample, error)
+}
+
+const (
+	// defaultSnapshotWorkers is how many processes SystemSource reads at
+	// once when Workers is not set.
+	defaultSnapshotWorkers = 8
+
+	// defaultMaxInFlight caps concurrent process reads when
+	// SystemSource.MaxInFlight is not set. Each read may hold a file
+	// descriptor, so this stays well below the usual 256 open-file limit.
+	defaultMaxInFlight = 16
+
+	// defaultProcessTimeout bounds the attribute reads of one process when
+	// SystemSource.Timeout is not set.
+	defaultProcessTimeout = 200 * time.Millisecond
+)
+
+// SystemSource is the ProcessSource that reads the operating system's process
+// table via gopsutil. The zero value uses defaultSnapshotWorkers,
+// defaultMaxInFlight, and defaultProcessTimeout.
+type SystemSource struct {
+	// Workers is the number of processes the snapshot works on at once.
+	Workers int
+
+	// MaxInFlight caps the process reads that are running at any moment.
+	// It differs from Workers because a worker moves on when a read times
+	// out while the read itself keeps its process handle open until the
+	// blocked call returns; without the cap, wedged reads could pile up
+	// until the open-file limit is reached. Raising it speeds up snapshots
+	// only while Workers is larger, at the cost of more descriptors in use.
+	MaxInFlight int
+
+	// Timeout bounds how long one process's attribute reads may take. A
+	// process that does not answer in time, e.g. because reading its
+	// command line blocks, is recorded with the attributes read so far and
+	// TimedOut set, so a single wedged process cannot stall the snapshot.
+	Timeout time.Duration
+}
+
+// SkippedError is returned by SystemSource.Snapshot, together with the
+// samples it did read, when some listed processes could not be read, most
+// often because they belong to another user. The samples are still complete
+// for every other process, so callers that only want them can treat it as
+// informational; use errors.As to tell it apart from a failed snapshot.
+type SkippedError struct {
+	Listed  int   // processes listed by the operating system
+	Skipped int   // listed processes whose CPU times could not be read
+	Denied  int   // skipped processes refused for lack of permission
+	Err     error // the first read error; nil if every skip was a timeout
+}
+
+func (e *SkippedError) Error() string {
+	msg := fmt.Sprintf("skipped %d of %d processes (%d permission denied)", e.Skipped, e.Listed, e.Denied)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *SkippedError) Unwrap() error { return e.Err }
+
+// Snapshot reads the current CPU times for every running process and returns
+// them keyed by PID. Processes that cannot be queried (e.g. due to
+// insufficient permissions) are silently skipped. Commands are left empty for
+// the Monitor to resolve for the rows it displays (see commandCache). The
+// create time is recorded when available for PID-reuse detection and command
+// caching, the scheduler state to flag zombie and stopped processes, and the
+// owner for the by-user table. If any listed process could not be read the
+// error is a *SkippedError describing them, returned alongside the samples.
+func (s SystemSource) Snapshot() (map[int]ProcessSample, error) {
+	ctx := context.Background()
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		if tooManyOpenFiles(err) {
+			return nil, fmt.Errorf("%w; raise the open-file limit (ulimit -n) or lower SystemSource.MaxInFlight", err)
+		}
+		return nil, err
+	}
+	samples, skipped := s.collect(ctx, pids, readProcess)
+	if skipped != nil {
+		return samples, skipped
+	}
+	return samples, nil
+}
+
+// permissionDenied reports whether err is a refusal to read another user's
+// process.
+func permissionDenied(err error) bool {
+	if errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EPERM) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "permission denied") || strings.Contains(msg, "operation not permitted")
+}
+
+// tooManyOpenFiles reports whether err is the process running out of file
+// descriptors.
+func tooManyOpenFiles(err error) bool {
+	return errors.Is(err, syscall.EMFILE) || strings.Contains(err.Error(), "too many open files")
+}
+
+// readProcess reads one process's attributes into p, cheapest and most
+// important first, so a timeout loses as little as possible.
+func readProcess(ctx context.Context, pid int32, p *partialSample) {
+	proc := &process.Process{Pid: pid}
+
+	times, err := proc.TimesWithContext(ctx)
+	if err != nil {
+		p.fail(err)
+		return
+	}
+	p.setCPU(times.User, times.System)
+
+	// A missing create time only disables PID-reuse detection for this
+	// process, so the sample is kept with CreateTime 0.
+	if createTime, err := proc.CreateTimeWithContext(ctx); err == nil {
+		p.set(func(s *ProcessSample) { s.CreateTime = createTime })
+	}
+
+	// The parent and executable are only used to group app helpers; either
+	// may be unavailable for other users' processes.
+	ppid, _ := proc.PpidWithContext(ctx)
+	exe, _ := proc.ExeWithContext(ctx)
+	p.set(func(s *ProcessSample) { s.PPID, s.Exe = int(ppid), exe })
+
+	// A status read error leaves the state blank rather than dropping the
+	// process.
+	if states, err := proc.StatusWithContext(ctx); err == nil && len(states) > 0 {
+		p.set(func(s *ProcessSample) { s.Status = states[0] })
+	}
+
+	// The effective UID, as ps reports under USER; gopsutil lists the real
+	// UID first.
+	if uids, err := proc.UidsWithContext(ctx); err == nil && len(uids) > 0 {
+		uid := uids[0]
+		if len(uids) > 1 {
+			uid = uids[1]
+		}
+		user := usernames.name(uid)
+		p.set(func(s *ProcessSample) { s.User, s.UID = user, int(uid) })
+	}
+}
+
+// partialSample is a ProcessSample under construction. It is shared between
+// the goroutine reading the process and collectSamples, which takes whatever
+// has been read when the process times out.
+type partialSample struct {
+	mu     sync.Mutex
+	sample ProcessSample
+	hasCPU bool  // without CPU times the process is dropped
+	err    error // why the CPU times could not be read
+}
+
+func (p *partialSample) fail(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+func (p *partialSample) setCPU(user, system float64) {
+	p.mu.Lock()
+	p.sample.CPUSeconds = user + system
+	p.sample.UserSeconds, p.sample.SystemSeconds = user, system
+	p.hasCPU = true
+	p.mu.Unlock()
+}
+
+func (p *partialSample) set(update func(s *ProcessSample)) {
+	p.mu.Lock()
+	update(&p.sample)
+	p.mu.Unlock()
+}
+
+func (p *partialSample) result() (ProcessSample, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sample, p.hasCPU, p.err
+}
+
+// collect runs read for every PID on s.Workers goroutines, at most
+// s.MaxInFlight reads at a time, and gathers the results. Each read gets
+// s.Timeout, which includes waiting for a free slot: once it expires its
+// context is cancelled and the sample is taken as it stands, with TimedOut
+// set. The read may keep running, and keep its slot, until the blocked call
+// returns, but never delays the snapshot further. Negative PIDs, and processes
+// whose CPU times were not read, are omitted; the latter are counted in the
+// returned *SkippedError, which is nil if there are none.
+func (s SystemSource) collect(ctx context.Context, pids []int32,
+	read func(ctx context.Context, pid int32, p *partialSample)) (map[int]ProcessSample, *SkippedError) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = defaultSnapshotWorkers
+	}
+	maxInFlight := s.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultProcessTimeout
+	}
+	slots := make(chan struct{}, maxInFlight)
+
+	var mu sync.Mutex
+	results := make(map[int]ProcessSample, len(pids))
+	skipped := &SkippedError{}
+	queue := make(chan int32)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pid := range queue {
+				sample, ok, err := readWithTimeout(ctx, pid, timeout, slots, read)
+				mu.Lock()
+				if ok {
+					results[int(pid)] = sample
+				} else {
+					skipped.Skipped++
+					if err != nil && permissionDenied(err) {
+						skipped.Denied++
+					}
+					if skipped.Err == nil {
+						skipped.Err = err
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, pid := range pids {
+		if pid >= 0 {
+			skipped.Listed++
+			queue <- pid
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if skipped.Skipped == 0 {
+		return results, nil
+	}
+	return results, skipped
+}
+
+// readWithTimeout runs read for pid in one of slots and waits at most timeout
+// for both. The slot is freed when read returns. It also returns the error
+// that kept read from getting the CPU times, if any.
+func readWithTimeout(ctx context.Context, pid int32, timeout time.Duration, slots chan struct{},
+	read func(ctx context.Context, pid int32, p *partialSample)) (ProcessSample, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return ProcessSample{}, false, nil
+	}
+
+	p := &partialSample{}
+	done := make(chan struct{})
+	go func() {
+		defer func() { <-slots }()
+		defer close(done)
+		read(ctx, pid, p)
+	}()
+
+	timedOut := false
+	select {
+	case <-done:
+	case <-ctx.Done():
+		timedOut = true
+	}
+
+	sample, ok, err := p.result()
+	sample.TimedOut = timedOut
+	return sample, ok, err
+}
@@ -0,0 +1,373 @@
+package framescope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCollectSamplesBoundsWedgedProcess(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// PID 7 hangs after its CPU times and PID 9 before them; every other
+	// process answers immediately.
+	read := func(ctx context.Context, pid int32, p *partialSample) {
+		if pid == 9 {
+			<-release
+			return
+		}
+		p.setCPU(float64(pid), 0)
+		if pid == 7 {
+			<-release
+			return
+		}
+		p.set(func(s *ProcessSample) { s.Command = "/bin/fast" })
+	}
+
+	pids := make([]int32, 0, 20)
+	for pid := int32(1); pid <= 20; pid++ {
+		pids = append(pids, pid)
+	}
+
+	const timeout = 50 * time.Millisecond
+	start := time.Now()
+	samples, _ := SystemSource{Workers: 4, Timeout: timeout}.collect(context.Background(), pids, read)
+	if elapsed := time.Since(start); elapsed > 10*timeout {
+		t.Errorf("snapshot took %v with a %v per-process timeout", elapsed, timeout)
+	}
+
+	if len(samples) != 19 {
+		t.Errorf("got %d samples, want every process but PID 9", len(samples))
+	}
+	if _, ok := samples[9]; ok {
+		t.Error("PID 9 recorded without CPU times")
+	}
+	wedged := samples[7]
+	if !wedged.TimedOut || wedged.CPUSeconds != 7 || wedged.Command != "" {
+		t.Errorf("wedged sample = %+v, want CPU kept, TimedOut, no command", wedged)
+	}
+	if fast := samples[8]; fast.TimedOut || fast.Command != "/bin/fast" {
+		t.Errorf("fast sample = %+v", fast)
+	}
+}
+
+func TestCollectSamplesBoundsInFlightReads(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	read := func(ctx context.Context, pid int32, p *partialSample) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		p.setCPU(1, 0)
+	}
+
+	pids := make([]int32, 0, 40)
+	for pid := int32(1); pid <= 40; pid++ {
+		pids = append(pids, pid)
+	}
+	samples, _ := SystemSource{Workers: 8, MaxInFlight: 3, Timeout: time.Second}.collect(context.Background(), pids, read)
+	if len(samples) != len(pids) {
+		t.Errorf("got %d samples, want %d", len(samples), len(pids))
+	}
+	if got := peak.Load(); got > 3 || got == 0 {
+		t.Errorf("peak in-flight reads = %d, want between 1 and 3", got)
+	}
+}
+
+func TestCollectSamplesWedgedReadsKeepTheirSlot(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var started atomic.Int32
+	read := func(ctx context.Context, pid int32, p *partialSample) {
+		started.Add(1)
+		p.setCPU(1, 0)
+		<-release
+	}
+
+	// Every read wedges, so after the first two time out their slots stay
+	// taken and the remaining processes cannot start a read at all.
+	pids := []int32{1, 2, 3, 4, 5, 6}
+	samples, _ := SystemSource{Workers: 6, MaxInFlight: 2, Timeout: 20 * time.Millisecond}.collect(context.Background(), pids, read)
+	if got := started.Load(); got != 2 {
+		t.Errorf("%d reads started, want 2", got)
+	}
+	if len(samples) != 2 {
+		t.Errorf("got %d samples, want the 2 timed-out ones", len(samples))
+	}
+}
+
+func TestTooManyOpenFiles(t *testing.T) {
+	if !tooManyOpenFiles(fmt.Errorf("listing pids: %w", syscall.EMFILE)) {
+		t.Error("wrapped EMFILE not recognised")
+	}
+	if !tooManyOpenFiles(errors.New("open /proc: too many open files")) {
+		t.Error("message not recognised")
+	}
+	if tooManyOpenFiles(errors.New("permission denied")) {
+		t.Error("unrelated error recognised")
+	}
+}
+
+func TestCollectSamplesReportsSkips(t *testing.T) {
+	// Even PIDs belong to another user; PID 5 exits before it is read.
+	read := func(ctx context.Context, pid int32, p *partialSample) {
+		switch {
+		case pid%2 == 0:
+			p.fail(fmt.Errorf("reading pid %d: %w", pid, syscall.EPERM))
+		case pid == 5:
+			p.fail(errors.New("process does not exist"))
+		default:
+			p.setCPU(1, 0)
+		}
+	}
+
+	samples, skipped := SystemSource{}.collect(context.Background(), []int32{-1, 1, 2, 3, 4, 5, 6}, read)
+	if len(samples) != 2 {
+		t.Errorf("got %d samples, want PIDs 1 and 3", len(samples))
+	}
+	if skipped == nil || skipped.Listed != 6 || skipped.Skipped != 4 || skipped.Denied != 3 {
+		t.Fatalf("skipped = %+v, want 4 of 6 skipped, 3 denied", skipped)
+	}
+	if skipped.Err == nil || !strings.Contains(skipped.Error(), skipped.Err.Error()) {
+		t.Errorf("skipped error %q does not carry the read error", skipped)
+	}
+
+	if _, skipped := (SystemSource{}).collect(context.Background(), []int32{1, 3}, read); skipped != nil {
+		t.Errorf("skipped = %+v with every process read, want nil", skipped)
+	}
+}
+
+// skippingSource is a ProcessSource that is refused half of the processes on
+// every snapshot, like an unprivileged user on a shared machine.
+type skippingSource struct{}
+
+func (skippingSource) Snapshot() (map[int]ProcessSample, error) {
+	return map[int]ProcessSample{1: {CPUSeconds: 1, Command: "/bin/mine"}},
+		&SkippedError{Listed: 2, Skipped: 1, Denied: 1, Err: syscall.EPERM}
+}
+
+func TestPermissionHintShownOncePerRun(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.SetProcessSource(skippingSource{})
+
+	const hint = "1 of 2 processes unreadable"
+	countHints := func() (shown int, last string) {
+		updates, _ := rec.snapshotUpdates()
+		for _, u := range updates {
+			if strings.Contains(u.Status, hint) {
+				shown++
+			}
+		}
+		return shown, updates[len(updates)-1].Status
+	}
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if shown, _ := countHints(); shown > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("permission hint never shown")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Every later snapshot is refused just as often, but once the first
+	// frame completes the hint is gone for the rest of the run.
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 1, 5*time.Second)
+	before, _ := countHints()
+	clock.Advance(5 * time.Second)
+	waitForFrames(t, m, 2, 5*time.Second)
+	m.Stop()
+
+	after, last := countHints()
+	if after != before {
+		t.Errorf("hint shown in %d more updates after the first frame", after-before)
+	}
+	if strings.Contains(last, hint) {
+		t.Errorf("final status %q still carries the hint", last)
+	}
+}
+
+// kernelSource reports kernel_task next to one user process.
+type kernelSource struct{}
+
+func (kernelSource) Snapshot() (map[int]ProcessSample, error) {
+	return map[int]ProcessSample{
+		0:   {CPUSeconds: 50, Command: "kernel_task"},
+		412: {CPUSeconds: 3, Command: "/usr/bin/make", Exe: "/usr/bin/make"},
+	}, nil
+}
+
+func TestKernelTaskToggle(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetProcessSource(kernelSource{})
+
+	samples, err := m.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := samples[0]; ok || len(samples) != 1 {
+		t.Errorf("default snapshot = %+v, want kernel_task dropped", samples)
+	}
+
+	m.SetIncludeKernelTask(true)
+	samples, err = m.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := samples[0]; !ok || len(samples) != 2 {
+		t.Errorf("snapshot with kernel_task included = %+v, want both processes", samples)
+	}
+
+	if !isKernelTask(88, ProcessSample{Exe: "/kernel/kernel_task"}) || isKernelTask(412, ProcessSample{Exe: "/usr/bin/make"}) {
+		t.Error("isKernelTask does not go by the executable name")
+	}
+}
+
+// sharedSource reports processes of the current user, another user, and an
+// owner that could not be read.
+type sharedSource struct{ uid int }
+
+func (s sharedSource) Snapshot() (map[int]ProcessSample, error) {
+	return map[int]ProcessSample{
+		10: {CPUSeconds: 1, User: "me", UID: s.uid},
+		11: {CPUSeconds: 2, User: "me", UID: s.uid},
+		20: {CPUSeconds: 3, User: "other", UID: s.uid + 1},
+		30: {CPUSeconds: 4},
+	}, nil
+}
+
+func TestShowOnlyMineKeepsCurrentUID(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetProcessSource(sharedSource{uid: os.Getuid()})
+
+	if samples, _ := m.snapshot(); len(samples) != 4 {
+		t.Errorf("default snapshot has %d processes, want all 4", len(samples))
+	}
+
+	m.SetShowOnlyMine(true)
+	samples, err := m.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, mine1 := samples[10]
+	_, mine2 := samples[11]
+	if len(samples) != 2 || !mine1 || !mine2 {
+		t.Errorf("snapshot = %+v, want only PIDs 10 and 11", samples)
+	}
+}
+
+// flakySource fails its first failures snapshots and succeeds afterwards.
+// failures < 0 makes every snapshot fail.
+type flakySource struct {
+	failures int
+	calls    atomic.Int32
+}
+
+func (s *flakySource) Snapshot() (map[int]ProcessSample, error) {
+	n := int(s.calls.Add(1))
+	if s.failures < 0 || n <= s.failures {
+		return nil, fmt.Errorf("listing processes: attempt %d refused", n)
+	}
+	return map[int]ProcessSample{1: {CPUSeconds: float64(n), Command: "/bin/worker"}}, nil
+}
+
+func TestRetryDelayDoublesUpToCap(t *testing.T) {
+	for _, tc := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, maxRetryDelay},
+		{40, maxRetryDelay},
+	} {
+		if got := retryDelay(time.Second, tc.failures); got != tc.want {
+			t.Errorf("retryDelay(1s, %d) = %s, want %s", tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestFailedSnapshotsRetryThenResume(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	source := &flakySource{failures: 3}
+	m.SetProcessSource(source)
+	m.retryBase = time.Millisecond
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 5 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("source never called again after the failures")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	running := m.Running()
+	m.Stop()
+
+	if !running {
+		t.Fatal("monitor stopped after recoverable failures")
+	}
+	updates, errs := rec.snapshotUpdates()
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	var retries []string
+	for _, u := range updates {
+		if strings.Contains(u.Status, "Retrying in") {
+			retries = append(retries, u.Status)
+		}
+	}
+	if len(retries) != 3 {
+		t.Fatalf("got %d retry statuses, want 3: %q", len(retries), retries)
+	}
+	if !strings.Contains(retries[2], "Retrying in 4ms (3 failures)") {
+		t.Errorf("last retry status = %q", retries[2])
+	}
+	if last := updates[len(updates)-1].Status; strings.Contains(last, "Retrying") {
+		t.Errorf("final status %q still reports retrying", last)
+	}
+}
+
+func TestFailedSnapshotsStopAtRetryLimit(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetProcessSource(&flakySource{failures: -1})
+	m.SetRetryLimit(3)
+	m.retryBase = time.Millisecond
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Running() {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("monitor still running after reaching the retry limit")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_, errs := rec.snapshotUpdates()
+	if len(errs) != 1 || !strings.Contains(errs[0], "failed 3 times in a row; monitoring stopped") {
+		t.Errorf("errors = %q, want one stop message", errs)
+	}
+}
@@ -0,0 +1,49 @@
+package framescope
+
+import "math"
+
+// defaultSpikeRatio is the peak-to-average ratio above which a summary row is
+// marked spiky, until SetSpikeRatio chooses another.
+const defaultSpikeRatio = 3.0
+
+// spikyMarker is the summary's spiky column for a spiky row.
+const spikyMarker = "spiky"
+
+// spiky reports whether row's process only ever spikes: its peak single-frame
+// %CPU is more than ratio times its average %CPU over the averaged frames.
+// A process that works steadily has a ratio near 1, whereas one that is idle
+// but for the odd burst has a ratio far above it, so the two are told apart
+// even when their totals match.
+func spiky(row aggregateRow, ratio float64) bool {
+	return row.AveragePercent > 0 && row.PeakPercent > ratio*row.AveragePercent
+}
+
+// validSpikeRatio reports whether ratio is a usable spike ratio: above 1, or
+// every process that is not perfectly steady would count as spiky.
+func validSpikeRatio(ratio float64) bool {
+	return ratio > 1 && !math.IsInf(ratio, 0)
+}
+
+// SetSpikeRatio sets how many times its average %CPU a process's peak
+// single-frame %CPU must exceed for the summary's spiky column to mark it,
+// e.g. 3 to mark processes whose busiest frame was over three times their
+// average. Ratios of 1 or less, and non-finite ones, are ignored. The new
+// setting is persisted to disk immediately.
+func (m *Monitor) SetSpikeRatio(ratio float64) {
+	if !validSpikeRatio(ratio) {
+		return
+	}
+	m.mu.Lock()
+	m.spikeRatio = ratio
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// SpikeRatio returns the peak-to-average ratio above which a summary row is
+// marked spiky.
+func (m *Monitor) SpikeRatio() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.spikeRatio
+}
@@ -0,0 +1,42 @@
+package framescope
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpikyFlagsOnlyBurstyProcess(t *testing.T) {
+	// PID 1 uses 2 CPU-seconds in every frame; PID 2 is idle but for one
+	// 6-second burst, so both total close to each other.
+	var history []FrameRecord
+	for i := 1; i <= 6; i++ {
+		rows := []ResultRow{{PID: 1, Diff: 2, Command: "/usr/bin/steady"}}
+		if i == 4 {
+			rows = append(rows, ResultRow{PID: 2, Diff: 6, Command: "/usr/bin/bursty"})
+		}
+		history = append(history, FrameRecord{Index: i, Duration: 10 * time.Second, Rows: rows})
+	}
+	marked := func(ratio float64) map[string]string {
+		out := make(map[string]string)
+		opts := SummaryOptions{Sort: SummarySortPID, Ascending: true, Columns: []string{"pid", "spiky"}, SpikeRatio: ratio}
+		for _, row := range splitPayload(RenderSummaryTable(history, opts)) {
+			out[row[0]] = row[1]
+		}
+		return out
+	}
+
+	if got := marked(defaultSpikeRatio); got["1"] != "" || got["2"] != spikyMarker {
+		t.Errorf("spiky column = %q, want only PID 2 marked", got)
+	}
+	// PID 2 peaks at 60% against an average of 10%: a ratio of 6.
+	if got := marked(8); got["2"] != "" {
+		t.Errorf("spiky column with ratio 8 = %q, want nothing marked", got)
+	}
+
+	m, _ := newTestMonitor(t)
+	m.SetSpikeRatio(8)
+	m.SetSpikeRatio(1)
+	if got := m.SpikeRatio(); got != 8 {
+		t.Errorf("SpikeRatio() = %v after setting 8 then 1, want 8", got)
+	}
+}
@@ -0,0 +1,299 @@
+package framescope
+
+import (
+	"fmt"
+	"time"
+)
+
+// currentViewLabelLocked returns a short human-readable label describing which
+// frame the UI is currently showing, followed by " vs Frame N" while a
+// reference frame is locked. Must be called with m.mu held.
+func (m *Monitor) currentViewLabelLocked() string {
+	label := m.frameLabelLocked()
+	if m.reference != nil {
+		label += fmt.Sprintf(" vs Frame %d", m.reference.Index)
+	}
+	return label
+}
+
+// frameLabelLocked names the frame currentRowsLocked resolves to. Must be
+// called with m.mu held.
+func (m *Monitor) frameLabelLocked() string {
+	if m.viewingCurrent {
+		if m.running {
+			return fmt.Sprintf("Current Frame %d", m.frameIndex)
+		}
+		return "Current Frame"
+	}
+	if m.selectedHistoryIdx >= 0 && m.selectedHistoryIdx < len(m.history) {
+		return fmt.Sprintf("Frame %d", m.history[m.selectedHistoryIdx].Index)
+	}
+	return "Latest Frame"
+}
+
+// currentRowsLocked returns a copy of the rows that should be displayed in the
+// main table. It resolves the view priority:
+//  1. Live in-progress frame, if viewingCurrent is set.
+//  2. The explicitly selected history entry.
+//  3. The most recently completed frame as a fallback.
+//
+// Must be called with m.mu held.
+func (m *Monitor) currentRowsLocked() []ResultRow {
+	if m.viewingCurrent {
+		return cloneRows(m.liveRows)
+	}
+	if m.selectedHistoryIdx >= 0 && m.selectedHistoryIdx < len(m.history) {
+		return cloneRows(m.history[m.selectedHistoryIdx].Rows)
+	}
+	if len(m.history) > 0 {
+		return cloneRows(m.history[len(m.history)-1].Rows)
+	}
+	return cloneRows(m.liveRows)
+}
+
+// tableOptionsLocked returns the TableOptions for the current-frame table as
+// the user has configured it, for the view currentRowsLocked resolves to.
+// Must be called with m.mu held.
+func (m *Monitor) tableOptionsLocked() TableOptions {
+	opts := TableOptions{
+		HideSmall:    m.hideSmall,
+		SmallPercent: m.smallPercentLocked(),
+		HidePaths:    m.hidePaths,
+		ShowShare:    m.showShare,
+		ShowPercent:  m.showPercent,
+		ShowUserSys:  m.showUserSys,
+		ShowStatus:   m.showStatus,
+		Group:        m.groupMode,
+		Exclude:      m.patterns[ExcludeList],
+		Include:      m.patterns[IncludeList],
+		Watch:        m.patterns[WatchList],
+		aliases:      m.aliases,
+		noise:        m.noiseLocked(),
+	}
+	if m.changedOnly {
+		opts.ChangedOnly = true
+		opts.Previous = m.previousRowsLocked()
+		opts.MinChange = m.changedMinDelta
+		opts.MinActivity = m.activityThreshold
+	}
+	if m.freezeTopN && m.viewingCurrent {
+		opts.Pinned = m.frozenPIDs
+	}
+	if m.running && m.viewingCurrent {
+		opts.Rates = m.liveRates
+	}
+	if m.showPercent || opts.SmallPercent > 0 {
+		if frame, ok := m.displayedFrameLocked(); ok {
+			opts.Elapsed = frame.Duration
+		}
+	}
+	if m.showOnlyStartedWithin > 0 {
+		opts.MaxAge, opts.AgeAt = m.showOnlyStartedWithin, m.now()
+		if frame, ok := m.displayedFrameLocked(); ok && !frame.EndedAt.IsZero() {
+			opts.AgeAt = frame.EndedAt
+		}
+	}
+	m.liveDeltaOptionsLocked(&opts)
+	opts.Reference = m.referenceLocked()
+	return opts
+}
+
+// summaryOptionsLocked returns the SummaryOptions for the summary table as the
+// user has configured it. Must be called with m.mu held.
+func (m *Monitor) summaryOptionsLocked() SummaryOptions {
+	return SummaryOptions{
+		MinTotal:    m.summaryMinTotal,
+		MinFrames:   m.summaryMinFrames,
+		HidePaths:   m.hidePaths,
+		AverageMode: m.averageMode,
+		Sort:        m.summarySortField,
+		Ascending:   !m.summarySortDescending,
+		Tiebreak:    m.tiebreaker,
+		MergeReused: m.mergeReusedPIDs,
+		Columns:     m.summaryColumns,
+		SpikeRatio:  m.spikeRatio,
+		MarkTiny:    m.markTiny,
+		viewed:      m.summarySince,
+		aliases:     m.aliases,
+	}
+}
+
+// previousRowsLocked returns the rows of the completed frame preceding the one
+// currentRowsLocked resolves to, or nil if there is none. The live frame is
+// preceded by the newest completed frame. Must be called with m.mu held.
+func (m *Monitor) previousRowsLocked() []ResultRow {
+	shown := len(m.history)
+	if !m.viewingCurrent {
+		if m.selectedHistoryIdx >= 0 && m.selectedHistoryIdx < len(m.history) {
+			shown = m.selectedHistoryIdx
+		} else if len(m.history) > 0 {
+			shown = len(m.history) - 1
+		}
+	}
+	if shown == 0 {
+		return nil
+	}
+	return m.history[shown-1].Rows
+}
+
+// busyFrameUtilization is the utilization, in percent of all cores, from
+// which the history popup highlights a completed frame as busy.
+const busyFrameUtilization = 50.0
+
+// historyPayloadLocked builds the newline-separated list of frame labels sent
+// to the Cocoa history popup, and returns the index of the currently selected
+// item (-1 if none). Each completed frame is labelled with its measured
+// duration and utilization (see historyLabel), followed by "\tbusy" if the
+// utilization reached busyFrameUtilization and "\toutlier" if the frame was
+// flagged as an outlier (see SetAnomalySigma); the in-progress frame shows both
+// so far and is never marked. There is exactly one item per completed frame, in
+// history order, followed by the in-progress frame, because the Cocoa side maps
+// popup indices back through SelectFrame. Must be called with m.mu held.
+func (m *Monitor) historyPayloadLocked() (string, int) {
+	items := make([]string, 0, len(m.history)+1)
+	selected := -1
+	cores := m.coreCount()
+
+	for i, frame := range m.history {
+		item := historyLabel(frame)
+		if frame.Utilization >= busyFrameUtilization {
+			item += "\tbusy"
+		}
+		if frame.Outlier {
+			item += "\t" + outlierMarker
+		}
+		items = append(items, item)
+		if !m.viewingCurrent && m.selectedHistoryIdx == i {
+			selected = i
+		}
+	}
+
+	if m.running {
+		elapsed := m.liveDurationLocked()
+		items = append(items, fmt.Sprintf("Current Frame %d (in progress) · %.1fs — %.0f%% busy so far",
+			m.frameIndex, elapsed.Seconds(), utilization(m.noiseLocked().countedCPU(m.liveRows), elapsed, cores)))
+		if m.viewingCurrent {
+			selected = len(items) - 1
+		}
+	}
+
+	return joinLines(items), selected
+}
+
+// resolveHistoryRange maps two history popup indices, in either order, to
+// the frameRange of completed frames between them. The in-progress item, one
+// past the last completed frame, is clamped to that frame. ok is false unless
+// both indices are non-negative and at least one completed frame is covered.
+func resolveHistoryRange(history []FrameRecord, from, to int) (r frameRange, ok bool) {
+	if from > to {
+		from, to = to, from
+	}
+	if from < 0 || to > len(history) || from >= len(history) {
+		return frameRange{}, false
+	}
+	to = min(to, len(history)-1)
+	return frameRange{First: history[from].Index, Last: history[to].Index}, true
+}
+
+// framesInRange returns the frames of history inside r, or all of history for
+// the zero frameRange.
+func framesInRange(history []FrameRecord, r frameRange) []FrameRecord {
+	if r == (frameRange{}) {
+		return history
+	}
+	var frames []FrameRecord
+	for _, frame := range history {
+		if frame.Index >= r.First && frame.Index <= r.Last {
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// historyLabel returns the history popup label for a completed frame: its
+// measured duration and the share of all cores its processes kept busy, e.g.
+// "Frame 3 · 15.2s — 62% busy".
+func historyLabel(frame FrameRecord) string {
+	return fmt.Sprintf("Frame %d · %.1fs — %.0f%% busy", frame.Index, frame.Duration.Seconds(), frame.Utilization)
+}
+
+// liveDurationLocked returns how long the in-progress frame has been running,
+// or zero when no frame is active. Must be called with m.mu held.
+func (m *Monitor) liveDurationLocked() time.Duration {
+	if !m.running || m.frameStart.IsZero() {
+		return 0
+	}
+	elapsed := m.now().Sub(m.frameStart)
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// liveFrameLocked returns the in-progress frame as a FrameRecord with a zero
+// EndedAt, and false when no run is active. StartedAt is set as soon as the
+// frame's baseline snapshot has been taken. Must be called with m.mu held.
+func (m *Monitor) liveFrameLocked() (FrameRecord, bool) {
+	if !m.running {
+		return FrameRecord{}, false
+	}
+	return FrameRecord{
+		Index:     m.frameIndex,
+		Rows:      cloneRows(m.liveRows),
+		Duration:  m.liveDurationLocked(),
+		StartedAt: m.frameStart,
+	}, true
+}
+
+// displayedFrameLocked returns the frame whose rows currentRowsLocked
+// resolves to, in progress or completed, and false when there is none yet.
+// Must be called with m.mu held.
+func (m *Monitor) displayedFrameLocked() (FrameRecord, bool) {
+	switch {
+	case m.viewingCurrent:
+	case m.selectedHistoryIdx >= 0 && m.selectedHistoryIdx < len(m.history):
+		return m.history[m.selectedHistoryIdx], true
+	case len(m.history) > 0:
+		return m.history[len(m.history)-1], true
+	}
+	live, ok := m.liveFrameLocked()
+	return live, ok && (len(live.Rows) > 0 || !live.StartedAt.IsZero())
+}
+
+// frameByIndexLocked returns the completed frame whose Index is index, or the
+// in-progress frame if index is its number. Must be called with m.mu held.
+func (m *Monitor) frameByIndexLocked(index int) (FrameRecord, bool) {
+	if live, ok := m.liveFrameLocked(); ok && live.Index == index {
+		return live, true
+	}
+	for _, frame := range m.history {
+		if frame.Index == index {
+			return frame, true
+		}
+	}
+	return FrameRecord{}, false
+}
+
+// cloneRows returns a shallow copy of rows so callers can safely release
+// m.mu before using the slice.
+func cloneRows(rows []ResultRow) []ResultRow {
+	if len(rows) == 0 {
+		return nil
+	}
+	out := make([]ResultRow, len(rows))
+	copy(out, rows)
+	return out
+}
+
+// joinLines joins a slice of strings with newline separators. Returns an empty
+// string for an empty slice.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	result := lines[0]
+	for _, line := range lines[1:] {
+		result += "\n" + line
+	}
+	return result
+}
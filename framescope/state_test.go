@@ -0,0 +1,99 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryLabel(t *testing.T) {
+	frame := FrameRecord{Index: 3, Duration: 15200 * time.Millisecond, Utilization: 62.0}
+	if got, want := historyLabel(frame), "Frame 3 · 15.2s — 62% busy"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+	if got, want := historyLabel(FrameRecord{Index: 1}), "Frame 1 · 0.0s — 0% busy"; got != want {
+		t.Errorf("label without duration = %q, want %q", got, want)
+	}
+}
+
+func TestHistoryPayloadKeepsOneItemPerFrame(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1, Duration: time.Second}, {Index: 2, Duration: time.Second, Utilization: 75}}
+	m.running = true
+	m.frameIndex = 3
+	m.viewingCurrent = true
+
+	payload, selected := m.historyPayloadLocked()
+	items := strings.Split(payload, "\n")
+	if len(items) != 3 || selected != 2 {
+		t.Fatalf("items = %q selected = %d, want 3 items with the live one selected", items, selected)
+	}
+	if !strings.HasPrefix(items[1], "Frame 2 · ") || !strings.HasPrefix(items[2], "Current Frame 3 (in progress) · ") ||
+		!strings.HasSuffix(items[2], "busy so far") {
+		t.Errorf("items = %q", items)
+	}
+	// Only the busy completed frame is flagged for highlighting.
+	if strings.Contains(items[0], "\t") || !strings.HasSuffix(items[1], "% busy\tbusy") || strings.Contains(items[2], "\t") {
+		t.Errorf("busy flags = %q, want only frame 2 flagged", items)
+	}
+}
+
+func TestResolveHistoryRange(t *testing.T) {
+	// Frame 1 has been dropped from the history, so popup index 0 is frame 2.
+	history := []FrameRecord{{Index: 2}, {Index: 3}, {Index: 4}, {Index: 5}}
+	tests := []struct {
+		from, to int
+		want     frameRange
+		ok       bool
+	}{
+		{1, 3, frameRange{3, 5}, true},
+		{3, 1, frameRange{3, 5}, true}, // reversed selection
+		{2, 2, frameRange{4, 4}, true},
+		{1, 4, frameRange{3, 5}, true}, // in-progress item clamps to the newest frame
+		{4, 4, frameRange{}, false},    // only the in-progress frame
+		{-1, 2, frameRange{}, false},
+		{0, 5, frameRange{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveHistoryRange(history, tt.from, tt.to)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("resolveHistoryRange(%d, %d) = %v, %v; want %v, %v", tt.from, tt.to, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := resolveHistoryRange(nil, 0, 0); ok {
+		t.Error("range resolved without completed frames")
+	}
+}
+
+func TestHistoryRangeLimitsSummary(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{
+		{Index: 1, Rows: []ResultRow{{PID: 1, Diff: 10, Command: "/bin/early"}}},
+		{Index: 2, Rows: []ResultRow{{PID: 2, Diff: 5, Command: "/bin/mid"}}},
+		{Index: 3, Rows: []ResultRow{{PID: 3, Diff: 3, Command: "/bin/late"}}},
+	}
+
+	m.SetHistoryRange(2, 1)
+	updates, _ := rec.snapshotUpdates()
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1", len(updates))
+	}
+	got := updates[0]
+	if strings.Contains(got.Summary, "/bin/early") || !strings.Contains(got.Summary, "/bin/mid") ||
+		!strings.Contains(got.Summary, "/bin/late") {
+		t.Errorf("summary = %q, want frames 2–3 only", got.Summary)
+	}
+	if !strings.HasSuffix(got.SummaryTitle, " · Frames 2–3 (2 frames)") {
+		t.Errorf("title = %q", got.SummaryTitle)
+	}
+	if got.SelectedIndex != 1 {
+		t.Errorf("selected = %d, want the shift-clicked item", got.SelectedIndex)
+	}
+
+	m.SelectFrame(0)
+	updates, _ = rec.snapshotUpdates()
+	last := updates[len(updates)-1]
+	if !strings.Contains(last.Summary, "/bin/early") || strings.Contains(last.SummaryTitle, "Frames") {
+		t.Errorf("a normal selection kept the range: %q / %q", last.SummaryTitle, last.Summary)
+	}
+}
@@ -0,0 +1,111 @@
+package framescope
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sessionStats is a one-glance summary of every completed frame in a session.
+type sessionStats struct {
+	Frames      int
+	TotalCPU    float64       // CPU-seconds consumed by all processes
+	Elapsed     time.Duration // sum of the frames' measured durations
+	Utilization float64       // TotalCPU, noise aside, as a percentage of all cores over Elapsed
+	FrameMin    float64       // lowest FrameRecord.Utilization of any frame
+	FrameMax    float64       // highest FrameRecord.Utilization of any frame
+	Busiest     aggregateRow  // process with the highest total; zero PID if none
+	PeakFrame   int           // Index of the frame with the highest total CPU; 0 if none
+	PeakCPU     float64       // CPU-seconds consumed in PeakFrame
+	ExitedCPU   float64       // estimated CPU-seconds of processes that exited mid-frame, not in TotalCPU
+}
+
+// computeSessionStats summarises history for a machine with the given number
+// of logical cores, leaving noise out of the utilization as the frames do.
+// Utilization is 0 when no time has elapsed or cores is not positive.
+func computeSessionStats(history []FrameRecord, cores int, noise noiseFilter) sessionStats {
+	stats := sessionStats{Frames: len(history)}
+	counted := 0.0
+	for i, frame := range history {
+		if i == 0 || frame.Utilization < stats.FrameMin {
+			stats.FrameMin = frame.Utilization
+		}
+		if frame.Utilization > stats.FrameMax {
+			stats.FrameMax = frame.Utilization
+		}
+		total := frameCPU(frame.Rows)
+		stats.TotalCPU += total
+		counted += noise.countedCPU(frame.Rows)
+		stats.Elapsed += frame.Duration
+		stats.ExitedCPU += frame.ExitedCPU
+		if stats.PeakFrame == 0 || total > stats.PeakCPU {
+			stats.PeakFrame = frame.Index
+			stats.PeakCPU = total
+		}
+	}
+	stats.Utilization = utilization(counted, stats.Elapsed, cores)
+	rows := aggregateHistory(history, AverageAllFrames, false)
+	if top := topIndices(len(rows), 1, byTotal(rows, TiebreakPID)); len(top) > 0 {
+		stats.Busiest = rows[top[0]]
+	}
+	return stats
+}
+
+// renderSessionStats formats stats as the single line shown above the summary
+// table, e.g., wrapped here:
+//
+//	12 frames · 184.2 CPU-s · 23.4% avg utilization (min 3.1%, max 61.0%) ·
+//	busiest: chrome (PID 42, 80.1 CPU-s) · heaviest frame: 7 (30.2 CPU-s) ·
+//	~12.5 CPU-s in exited processes
+//
+// The minimum and maximum are those of single frames, so a wide spread says
+// the average hides uneven load; they are left out for a single frame. The
+// estimate for processes that exited mid-frame, which no table shows, is left
+// out when there is none.
+// Returns an empty string when no frames have completed.
+func renderSessionStats(stats sessionStats, hidePaths bool) string {
+	if stats.Frames == 0 {
+		return ""
+	}
+	noun := "frames"
+	if stats.Frames == 1 {
+		noun = "frame"
+	}
+	usage := fmt.Sprintf("%.1f%% avg utilization", stats.Utilization)
+	if stats.Frames > 1 {
+		usage += fmt.Sprintf(" (min %.1f%%, max %.1f%%)", stats.FrameMin, stats.FrameMax)
+	}
+	parts := []string{
+		fmt.Sprintf("%d %s", stats.Frames, noun),
+		fmt.Sprintf("%.1f CPU-s", stats.TotalCPU),
+		usage,
+	}
+	if stats.Busiest.PID != 0 {
+		parts = append(parts, fmt.Sprintf("busiest: %s (PID %d, %.1f CPU-s)",
+			sanitizeCommand(stats.Busiest.Command, hidePaths), stats.Busiest.PID, stats.Busiest.Total))
+	}
+	parts = append(parts, fmt.Sprintf("heaviest frame: %d (%.1f CPU-s)", stats.PeakFrame, stats.PeakCPU))
+	if stats.ExitedCPU > 0 {
+		parts = append(parts, fmt.Sprintf("~%.1f CPU-s in exited processes", stats.ExitedCPU))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// utilization converts CPU-seconds consumed over elapsed into a percentage of
+// the capacity of all cores, so 100 means every core was saturated. Returns 0
+// when elapsed or cores is not positive.
+func utilization(cpuSeconds float64, elapsed time.Duration, cores int) float64 {
+	if cores <= 0 {
+		return 0
+	}
+	return framePercent(cpuSeconds, elapsed) / float64(cores)
+}
+
+// frameCPU returns the CPU-seconds consumed by all rows of a frame.
+func frameCPU(rows []ResultRow) float64 {
+	total := 0.0
+	for _, row := range rows {
+		total += row.Diff
+	}
+	return total
+}
@@ -0,0 +1,93 @@
+package framescope
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeSessionStats(t *testing.T) {
+	history := []FrameRecord{
+		{Index: 1, Duration: 10 * time.Second, Utilization: 20, Rows: []ResultRow{
+			{PID: 1, Diff: 6, Command: "/bin/build"},
+			{PID: 2, Diff: 2, Command: "/bin/editor"},
+		}},
+		{Index: 2, Duration: 10 * time.Second, Utilization: 30, Rows: []ResultRow{
+			{PID: 2, Diff: 9, Command: "/bin/editor"},
+			{PID: 3, Diff: 3, Command: "/bin/indexer"},
+		}},
+		{Index: 3, Duration: 20 * time.Second, Utilization: 2.5, Rows: []ResultRow{
+			{PID: 1, Diff: 2, Command: "/bin/build"},
+		}},
+	}
+
+	stats := computeSessionStats(history, 4, noiseFilter{})
+	if stats.Frames != 3 || stats.TotalCPU != 22 || stats.Elapsed != 40*time.Second {
+		t.Errorf("stats = %+v, want 3 frames, 22 CPU-s over 40s", stats)
+	}
+	// 22 CPU-s over 40 s is 55% of one core, or 13.75% of four.
+	if math.Abs(stats.Utilization-13.75) > 1e-9 {
+		t.Errorf("utilization = %v, want 13.75", stats.Utilization)
+	}
+	if stats.Busiest.PID != 2 || stats.Busiest.Total != 11 {
+		t.Errorf("busiest = %+v, want PID 2 with 11 CPU-s", stats.Busiest)
+	}
+	if stats.PeakFrame != 2 || stats.PeakCPU != 12 {
+		t.Errorf("peak frame = %d (%v CPU-s), want 2 (12)", stats.PeakFrame, stats.PeakCPU)
+	}
+
+	if stats.FrameMin != 2.5 || stats.FrameMax != 30 {
+		t.Errorf("frame utilization range = %v–%v, want 2.5–30", stats.FrameMin, stats.FrameMax)
+	}
+
+	want := "3 frames · 22.0 CPU-s · 13.8% avg utilization (min 2.5%, max 30.0%) · busiest: editor (PID 2, 11.0 CPU-s) · heaviest frame: 2 (12.0 CPU-s)"
+	if got := renderSessionStats(stats, true); got != want {
+		t.Errorf("rendered = %q, want %q", got, want)
+	}
+}
+
+func TestSessionStatsNoFrames(t *testing.T) {
+	stats := computeSessionStats(nil, 8, noiseFilter{})
+	if stats.Frames != 0 || stats.Utilization != 0 || stats.PeakFrame != 0 || stats.Busiest.PID != 0 {
+		t.Errorf("stats = %+v, want zero", stats)
+	}
+	if got := renderSessionStats(stats, false); got != "" {
+		t.Errorf("rendered = %q, want empty", got)
+	}
+}
+
+func TestCompletedFrameStoresUtilization(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.cores = 4
+	m.running = true
+	m.frameView = newFrameView(10)
+	start := time.Unix(1000, 0)
+	track := &frameTrack{
+		length:   10,
+		duration: 10 * time.Second,
+		baseline: map[int]ProcessSample{
+			1: {CPUSeconds: 100, Command: "/bin/build"},
+			2: {CPUSeconds: 5, Command: "/bin/editor"},
+			3: {CPUSeconds: 1, Command: "/bin/idle"},
+		},
+		start: start,
+	}
+	current := map[int]ProcessSample{
+		1: {CPUSeconds: 112, Command: "/bin/build"},
+		2: {CPUSeconds: 8.5, Command: "/bin/editor"},
+		3: {CPUSeconds: 1.5, Command: "/bin/idle"},
+	}
+
+	frame, completed := m.advanceTrack(track, current, start.Add(10*time.Second), false, TiebreakPID)
+	if !completed {
+		t.Fatal("frame did not complete")
+	}
+	// 16 CPU-s over 10 s is 160% of one core, or 40% of four. Rows below
+	// the table's threshold count too.
+	if math.Abs(frame.Utilization-40) > 1e-9 {
+		t.Errorf("utilization = %v, want 40", frame.Utilization)
+	}
+	if got := m.history[0].Utilization; got != frame.Utilization {
+		t.Errorf("stored utilization = %v, want %v", got, frame.Utilization)
+	}
+}
@@ -0,0 +1,61 @@
+package framescope
+
+import (
+	"fmt"
+	"time"
+)
+
+// statusItemMaxName is the longest command name, in runes, shown by the menu
+// bar indicator before it is shortened with an ellipsis.
+const statusItemMaxName = 20
+
+// statusItemText returns the menu bar indicator for a frame: the executable
+// basename of the row that used the most CPU and its %CPU of one core over
+// elapsed, e.g. "Xcode 78%". rows must be sorted by CPU descending, as
+// ComputeResults returns them; only the first is read. Returns an empty string
+// if there is nothing to show.
+func statusItemText(rows []ResultRow, elapsed time.Duration) string {
+	if len(rows) == 0 || rows[0].Diff <= 0 || elapsed <= 0 {
+		return ""
+	}
+	name := []rune(baseCommand(sanitizeCommand(rows[0].Command, false)))
+	if len(name) > statusItemMaxName {
+		name = append(name[:statusItemMaxName-1], '…')
+	}
+	return fmt.Sprintf("%s %.0f%%", string(name), framePercent(rows[0].Diff, elapsed))
+}
+
+// statusItemTextLocked returns the indicator for the latest completed frame,
+// or for the frame in progress until one has completed, whatever frame the
+// window shows. It is empty unless the menu bar item is enabled and a run is
+// active. m.mu must be held.
+func (m *Monitor) statusItemTextLocked() string {
+	if !m.menuBarItem || !m.running {
+		return ""
+	}
+	if n := len(m.history); n > 0 {
+		return statusItemText(m.history[n-1].Rows, m.history[n-1].Duration)
+	}
+	return statusItemText(m.liveRows, m.now().Sub(m.frameStart))
+}
+
+// SetMenuBarItem toggles the menu bar indicator of the process using the most
+// CPU, delivered to UISinks that are StatusItemUpdaters with every UI update.
+// It is cleared when monitoring stops. While it is enabled, a hidden window
+// does not pause monitoring (see SetPauseOnWindowClose). The new setting is
+// persisted to disk immediately.
+func (m *Monitor) SetMenuBarItem(enabled bool) {
+	m.mu.Lock()
+	m.menuBarItem = enabled
+	m.wakeRunLocked()
+	m.mu.Unlock()
+	m.saveConfig()
+	m.pushUI(0)
+}
+
+// MenuBarItem reports whether the menu bar indicator is enabled.
+func (m *Monitor) MenuBarItem() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.menuBarItem
+}
@@ -0,0 +1,75 @@
+package framescope
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatusItemText(t *testing.T) {
+	rows := []ResultRow{
+		{PID: 7, Diff: 7.84, Command: "/Applications/Xcode.app/Contents/MacOS/Xcode -psn"},
+		{PID: 8, Diff: 2, Command: "/usr/libexec/mds_stores"},
+	}
+	tests := []struct {
+		name    string
+		rows    []ResultRow
+		elapsed time.Duration
+		want    string
+	}{
+		{"top row only", rows, 10 * time.Second, "Xcode 78%"},
+		{"over one core", rows, 2 * time.Second, "Xcode 392%"},
+		{"long name", []ResultRow{{Diff: 1, Command: "/opt/bin/a-very-long-helper-process-name"}}, time.Second, "a-very-long-helper-… 100%"},
+		{"idle", []ResultRow{{Diff: 0, Command: "/bin/sleep"}}, time.Second, ""},
+		{"no rows", nil, time.Second, ""},
+		{"no time", rows, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := statusItemText(tt.rows, tt.elapsed); got != tt.want {
+			t.Errorf("%s: statusItemText = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// statusItemSink records the menu bar indicator texts it is sent.
+type statusItemSink struct {
+	DiscardSink
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *statusItemSink) UpdateStatusItem(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.texts = append(s.texts, text)
+}
+
+func (s *statusItemSink) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.texts) == 0 {
+		return "<none>"
+	}
+	return s.texts[len(s.texts)-1]
+}
+
+func TestMenuBarItemFollowsLatestFrame(t *testing.T) {
+	sink := &statusItemSink{}
+	m := NewMonitor(sink)
+	m.history = []FrameRecord{{Index: 1, Duration: 10 * time.Second, Rows: []ResultRow{{PID: 7, Diff: 5, Command: "/bin/encoder"}}}}
+	m.running = true
+
+	m.pushUI(0)
+	if got := sink.last(); got != "" {
+		t.Errorf("indicator while disabled = %q, want empty", got)
+	}
+	m.SetMenuBarItem(true)
+	if got := sink.last(); got != "encoder 50%" {
+		t.Errorf("indicator = %q, want the latest frame's top row", got)
+	}
+	m.running = false
+	m.pushUI(0)
+	if got := sink.last(); got != "" {
+		t.Errorf("indicator after stopping = %q, want it cleared", got)
+	}
+}
@@ -0,0 +1,270 @@
+package framescope
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// streamMessage is the JSON document pushed to WebSocket clients on every UI
+// refresh. Rows are the ones the current-frame table shows (filtered, capped
+// at 500) with unmodified command lines, encoded by JSONFormatter.
+type streamMessage struct {
+	Status     string          `json:"status"`
+	FrameIndex int             `json:"frame_index"`
+	Running    bool            `json:"running"`
+	Rows       json.RawMessage `json:"rows"`
+}
+
+// streamClientBuffer is how many messages a WebSocket client or gRPC watcher
+// may fall behind before it is dropped.
+const streamClientBuffer = 8
+
+// streamHub fans messages out to any number of subscribers without ever
+// blocking the publisher: a subscriber whose buffer is full is dropped and its
+// channel closed. It carries encoded JSON to WebSocket clients and frames to
+// gRPC watchers (see frameService).
+type streamHub[T any] struct {
+	mu      sync.Mutex
+	clients map[chan T]struct{}
+}
+
+func newStreamHub[T any]() *streamHub[T] {
+	return &streamHub[T]{clients: make(map[chan T]struct{})}
+}
+
+// subscribe registers a new client and returns its message channel. The
+// channel is closed when the client is dropped or unsubscribed.
+func (h *streamHub[T]) subscribe() chan T {
+	ch := make(chan T, streamClientBuffer)
+	h.mu.Lock()
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes ch and closes it. It is a no-op if ch was already
+// removed.
+func (h *streamHub[T]) unsubscribe(ch chan T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// active reports whether any client is subscribed, so callers can skip
+// encoding messages nobody will read.
+func (h *streamHub[T]) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients) > 0
+}
+
+// publish delivers msg to every subscriber, dropping those that are too far
+// behind to accept it.
+func (h *streamHub[T]) publish(msg T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- msg:
+		default:
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// closeAll drops every subscriber, whose connections then close once the
+// messages already queued are sent. It is used when monitoring stops and when
+// the API server shuts down, since hijacked WebSocket connections are not
+// tracked by http.Server.
+func (h *streamHub[T]) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// publishStream encodes the rows and status of a UI refresh, rendered with the
+// current-frame table's opts so that clients get exactly the rows it shows,
+// and sends them to WebSocket clients. It does nothing when no client is
+// connected.
+func (m *Monitor) publishStream(status string, frameIndex int, running bool, rows []ResultRow, opts TableOptions) {
+	if !m.streams.active() {
+		return
+	}
+
+	msg := streamMessage{
+		Status:     status,
+		FrameIndex: frameIndex,
+		Running:    running,
+		Rows:       json.RawMessage(RenderTable(rows, opts, JSONFormatter{})),
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	m.streams.publish(data)
+}
+
+// WebSocket opcodes used by the stream endpoint (RFC 6455 §5.2).
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+)
+
+// wsAcceptGUID is the fixed GUID appended to the client key when computing
+// Sec-WebSocket-Accept (RFC 6455 §4.2.2).
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// handleStream upgrades the request to a WebSocket and pushes a
+// streamMessage on every UI refresh until the client disconnects, falls too
+// far behind, or the server shuts down. Messages from the client are read
+// only to detect a close; their content is ignored.
+func (m *Monitor) handleStream(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	ch := m.streams.subscribe()
+	defer m.streams.unsubscribe(ch)
+
+	// The reader ends the subscription when the client closes or the
+	// connection fails; that closes ch and ends the write loop below.
+	go func() {
+		defer m.streams.unsubscribe(ch)
+		for {
+			op, _, err := readWSFrame(rw.Reader)
+			if err != nil || op == wsOpClose {
+				return
+			}
+		}
+	}()
+
+	for msg := range ch {
+		if err := writeWSFrame(rw.Writer, wsOpText, msg); err != nil {
+			return
+		}
+	}
+	writeWSFrame(rw.Writer, wsOpClose, nil)
+}
+
+// headerContains reports whether any comma-separated token of header name
+// equals token, case-insensitively.
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeWSFrame writes a single unmasked, unfragmented frame (the form servers
+// send) and flushes it.
+func writeWSFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// maxWSFrame bounds the payload size accepted from a peer; the stream
+// endpoint never expects large client messages.
+const maxWSFrame = 1 << 20
+
+// readWSFrame reads one frame, unmasking it if the peer masked it (clients
+// must; servers must not), and returns its opcode and payload.
+func readWSFrame(r *bufio.Reader) (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWSFrame {
+		return 0, nil, errors.New("websocket frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
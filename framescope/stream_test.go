@@ -0,0 +1,169 @@
+package framescope
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialStream opens a WebSocket connection to the /ws endpoint of srv and
+// returns the connection with a reader positioned after the handshake.
+func dialStream(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := "GET /ws HTTP/1.1\r\n" +
+		"Host: test\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", resp.StatusCode)
+	}
+	// Example key and accept value from RFC 6455 §1.3.
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Fatalf("Sec-WebSocket-Accept = %q, want %q", got, want)
+	}
+	return conn, r
+}
+
+// waitForClients polls until m has a subscribed stream client.
+func waitForClients(t *testing.T, m *Monitor) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.streams.active() {
+		if time.Now().After(deadline) {
+			t.Fatal("stream client never subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamDeliversUIRefresh(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.status = "Running. test"
+	m.frameIndex = 4
+	m.viewingCurrent = true
+	m.liveRows = []ResultRow{{PID: 10, Diff: 2, Command: "/bin/busy"}, {PID: 11, Diff: 0.2, Command: "/bin/idle"}, {PID: 12, Diff: 3, Command: "/bin/backup"}}
+	m.AddPattern(ExcludeList, "backup")
+
+	srv := httptest.NewServer(m.apiHandler())
+	defer srv.Close()
+	_, r := dialStream(t, srv)
+	waitForClients(t, m)
+
+	m.pushUI(0)
+
+	op, payload, err := readWSFrame(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op != wsOpText {
+		t.Fatalf("opcode = %d, want text", op)
+	}
+	var msg streamMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("invalid JSON %q: %v", payload, err)
+	}
+	if msg.Status != "Running. test" || msg.FrameIndex != 4 {
+		t.Errorf("message = %+v, want status and frame 4", msg)
+	}
+	// hideSmall defaults to on and backup is excluded, so only the busy
+	// process is streamed, as in the table.
+	var rows []jsonRow
+	if err := json.Unmarshal(msg.Rows, &rows); err != nil {
+		t.Fatalf("invalid rows %s: %v", msg.Rows, err)
+	}
+	if len(rows) != 1 || rows[0].PID != 10 || rows[0].Command != "/bin/busy" {
+		t.Errorf("rows = %+v, want only PID 10", rows)
+	}
+}
+
+func TestStreamClosedOnStop(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetProcessSource(&busySource{})
+	srv := httptest.NewServer(m.apiHandler())
+	defer srv.Close()
+	if err := m.Start(60); err != nil {
+		t.Fatal(err)
+	}
+	_, r := dialStream(t, srv)
+	waitForClients(t, m)
+
+	m.Stop()
+	if m.streams.active() {
+		t.Error("stream client still subscribed after Stop")
+	}
+	// The client gets the final update, then the connection is closed.
+	var last streamMessage
+	for {
+		op, payload, err := readWSFrame(r)
+		if err != nil {
+			t.Fatalf("stream ended without a close frame: %v", err)
+		}
+		if op == wsOpClose {
+			break
+		}
+		if err := json.Unmarshal(payload, &last); err != nil {
+			t.Fatalf("invalid JSON %q: %v", payload, err)
+		}
+	}
+	if last.Status != "Monitoring stopped." || last.Running {
+		t.Errorf("last message = %+v, want the stopped status", last)
+	}
+}
+
+func TestStreamHubDropsSlowConsumer(t *testing.T) {
+	h := newStreamHub[[]byte]()
+	slow := h.subscribe()
+	fast := h.subscribe()
+
+	for i := 0; i <= streamClientBuffer; i++ {
+		h.publish([]byte("x"))
+		<-fast
+	}
+
+	if _, ok := h.clients[slow]; ok {
+		t.Fatal("slow consumer was not dropped")
+	}
+	if _, ok := h.clients[fast]; !ok {
+		t.Fatal("fast consumer was dropped")
+	}
+	for range slow {
+	}
+}
+
+func TestStreamRejectsPlainRequest(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	srv := httptest.NewServer(m.apiHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,58 @@
+package framescope
+
+// Theme selects the light or dark colours of the visuals the monitor renders
+// itself, such as the exported chart, so they match the window. The values
+// are part of the cgo bridge (GoSetTheme) and the config file, so they must
+// not be renumbered.
+type Theme int
+
+const (
+	// ThemeSystem follows the system appearance, as last reported by
+	// SetSystemAppearance.
+	ThemeSystem Theme = iota
+
+	// ThemeLight always uses the light colours.
+	ThemeLight
+
+	// ThemeDark always uses the dark colours.
+	ThemeDark
+)
+
+// validTheme reports whether t is one of the defined Theme values.
+func validTheme(t Theme) bool {
+	return t == ThemeSystem || t == ThemeLight || t == ThemeDark
+}
+
+// SetTheme sets the colours of the visuals the monitor renders itself. Unknown
+// values are ignored. The new setting is persisted to disk immediately.
+func (m *Monitor) SetTheme(t Theme) {
+	if !validTheme(t) {
+		return
+	}
+	m.mu.Lock()
+	m.theme = t
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// Theme returns the theme chosen with SetTheme.
+func (m *Monitor) Theme() Theme {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.theme
+}
+
+// SetSystemAppearance records whether the system appearance is dark, which
+// ThemeSystem follows. The UI reports it at launch and whenever it changes;
+// until then it is taken to be light. It is not persisted.
+func (m *Monitor) SetSystemAppearance(dark bool) {
+	m.mu.Lock()
+	m.systemDark = dark
+	m.mu.Unlock()
+}
+
+// darkLocked reports whether the theme resolves to the dark colours. Must be
+// called with m.mu held.
+func (m *Monitor) darkLocked() bool {
+	return m.theme == ThemeDark || (m.theme == ThemeSystem && m.systemDark)
+}
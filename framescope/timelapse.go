@@ -0,0 +1,96 @@
+package framescope
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// chartSnapshot is a time-lapse chart to be written off the sampling
+// goroutine: the history up to and including the frame it is named after.
+type chartSnapshot struct {
+	path    string
+	history []FrameRecord
+	colors  chartColors
+}
+
+// chartSnapshotName returns the file name of the time-lapse chart written
+// when frame index completes, zero-padded so the files sort in order.
+func chartSnapshotName(index int) string {
+	return fmt.Sprintf("frame-%06d.png", index)
+}
+
+// chartSnapshotLocked returns the time-lapse chart due now that frame, the
+// newest of history, has completed, and false if none is: no export
+// directory is set, or frame is not a multiple of the interval (see
+// SetChartExport). Must be called with m.mu held.
+func (m *Monitor) chartSnapshotLocked(frame FrameRecord, history []FrameRecord) (chartSnapshot, bool) {
+	if m.chartExportEvery <= 0 || m.chartExportDir == "" || frame.Index%m.chartExportEvery != 0 {
+		return chartSnapshot{}, false
+	}
+	colors := lightChart
+	if m.darkLocked() {
+		colors = darkChart
+	}
+	return chartSnapshot{
+		path:    filepath.Join(m.chartExportDir, chartSnapshotName(frame.Index)),
+		history: append([]FrameRecord(nil), history...),
+		colors:  colors,
+	}, true
+}
+
+// writeChartSnapshot renders and writes snap in its own goroutine, so that
+// a slow disk does not stall sampling, creating the directory if it is
+// missing. The first failure of a run is shown in the status bar until the
+// frame in progress completes; later ones are not, so that a full disk does
+// not nag every frame. Shutdown waits for the writes in flight.
+func (m *Monitor) writeChartSnapshot(runID int64, snap chartSnapshot) {
+	m.chartWrites.Add(1)
+	go func() {
+		defer m.chartWrites.Done()
+		err := os.MkdirAll(filepath.Dir(snap.path), 0755)
+		if err == nil {
+			err = writeChartPNG(snap.path, renderChart(snap.history, snap.colors))
+		}
+		if err == nil {
+			return
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.runID != runID || m.chartSnapshotFailed {
+			return
+		}
+		m.chartSnapshotFailed = true
+		m.chartSnapshotErr = fmt.Sprintf("time-lapse chart not saved: %v", err)
+	}()
+}
+
+// SetChartExport makes every run save a chart of its history so far, as
+// ExportChart does, into dir every time a multiple of every frames has
+// completed, for assembling a time-lapse of a session. The files are named
+// after the frame, e.g. frame-000010.png, replacing any of the same name,
+// and dir is created if missing. Only the main frame length is charted.
+// every 0 or an empty dir turns it off; a negative every is ignored. The
+// new setting is persisted to disk immediately.
+func (m *Monitor) SetChartExport(every int, dir string) {
+	if every < 0 {
+		return
+	}
+	m.mu.Lock()
+	m.chartExportEvery = every
+	m.chartExportDir = strings.TrimSpace(dir)
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// ChartExport returns the time-lapse interval in frames and directory set
+// with SetChartExport; 0 means off.
+func (m *Monitor) ChartExport() (every int, dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.chartExportDir == "" {
+		return 0, ""
+	}
+	return m.chartExportEvery, m.chartExportDir
+}
@@ -0,0 +1,91 @@
+package framescope
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChartExportEveryNFrames(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	dir := filepath.Join(t.TempDir(), "timelapse")
+	m.SetChartExport(2, dir)
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("run did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for frames := 1; frames <= 5; frames++ {
+		clock.Advance(5 * time.Second)
+		waitForFrames(t, m, frames, 5*time.Second)
+	}
+	m.Stop()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if got := strings.Join(names, " "); got != "frame-000002.png frame-000004.png" {
+		t.Fatalf("files = %q, want frames 2 and 4", got)
+	}
+	f, err := os.Open(filepath.Join(dir, names[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := png.Decode(f); err != nil {
+		t.Errorf("%s is not a PNG: %v", names[1], err)
+	}
+}
+
+func TestChartExportFailureShownOnce(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	m.SetChartExport(1, filepath.Join(file, "charts"))
+
+	history := []FrameRecord{{Index: 1}}
+	write := func() string {
+		t.Helper()
+		m.mu.Lock()
+		snap, due := m.chartSnapshotLocked(history[0], history)
+		m.mu.Unlock()
+		if !due {
+			t.Fatal("no chart due with an interval of 1")
+		}
+		m.writeChartSnapshot(0, snap)
+		m.chartWrites.Wait()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.chartSnapshotErr
+	}
+
+	if note := write(); !strings.HasPrefix(note, "time-lapse chart not saved: ") {
+		t.Errorf("status note after the first failure = %q", note)
+	}
+	// The note lasts until the frame completes, and is not raised again.
+	m.mu.Lock()
+	m.chartSnapshotErr = ""
+	m.mu.Unlock()
+	if note := write(); note != "" {
+		t.Errorf("status note after a second failure = %q, want none", note)
+	}
+}
@@ -0,0 +1,55 @@
+package framescope
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// maxRows caps the rows of every table payload, to keep consumers responsive.
+const maxRows = 500
+
+// topIndices returns the indices of the k elements of an n-element slice that
+// sort first by less, in that order: what sort.Slice followed by a cap at k
+// would leave, as indices into the unsorted slice. It keeps the best k seen
+// so far in a heap, so it takes O(n log k) instead of the O(n log n) of
+// sorting every element, which matters on a host with thousands of processes
+// and tables capped at maxRows. As with sort.Slice, the result is only
+// unique if less orders every pair of distinct elements, which the tables'
+// tiebreakers do.
+func topIndices(n, k int, less func(i, j int) bool) []int {
+	if n == 0 || k <= 0 {
+		return nil
+	}
+	h := &indexHeap{indices: make([]int, 0, min(n, k)), less: less}
+	for i := range n {
+		switch {
+		case len(h.indices) < k:
+			heap.Push(h, i)
+		case less(i, h.indices[0]):
+			h.indices[0] = i
+			heap.Fix(h, 0)
+		}
+	}
+	top := h.indices
+	sort.Slice(top, func(a, b int) bool { return less(top[a], top[b]) })
+	return top
+}
+
+// indexHeap is the heap behind topIndices: indices into the caller's slice,
+// with the one that sorts last by less at the root, so that it is the first
+// to make way for a better element.
+type indexHeap struct {
+	indices []int
+	less    func(i, j int) bool
+}
+
+func (h *indexHeap) Len() int           { return len(h.indices) }
+func (h *indexHeap) Less(a, b int) bool { return h.less(h.indices[b], h.indices[a]) }
+func (h *indexHeap) Swap(a, b int)      { h.indices[a], h.indices[b] = h.indices[b], h.indices[a] }
+func (h *indexHeap) Push(x any)         { h.indices = append(h.indices, x.(int)) }
+
+func (h *indexHeap) Pop() any {
+	last := h.indices[len(h.indices)-1]
+	h.indices = h.indices[:len(h.indices)-1]
+	return last
+}
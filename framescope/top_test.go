@@ -0,0 +1,64 @@
+package framescope
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// syntheticAggregates returns n summary rows with few distinct totals, so that
+// most ordering is decided by the tiebreaker.
+func syntheticAggregates(n int, seed int64) []aggregateRow {
+	r := rand.New(rand.NewSource(seed))
+	rows := make([]aggregateRow, n)
+	for i := range rows {
+		rows[i] = aggregateRow{
+			PID:        r.Intn(n / 2), // reused PIDs
+			Total:      float64(r.Intn(20)) / 2,
+			Command:    fmt.Sprintf("/bin/p%d", r.Intn(50)),
+			CreateTime: int64(i),
+		}
+	}
+	return rows
+}
+
+func TestTopIndicesMatchesSortThenCap(t *testing.T) {
+	for _, tiebreak := range []Tiebreaker{TiebreakPID, TiebreakCommand, TiebreakCreateTime} {
+		for _, k := range []int{0, 1, 7, 500, 5000} {
+			rows := syntheticAggregates(1000, int64(k))
+			naive := append([]aggregateRow(nil), rows...)
+			sort.Slice(naive, byTotal(naive, tiebreak))
+			naive = naive[:min(k, len(naive))]
+
+			top := topIndices(len(rows), k, byTotal(rows, tiebreak))
+			if len(top) != len(naive) {
+				t.Fatalf("tiebreaker %d, k %d: got %d rows, want %d", tiebreak, k, len(top), len(naive))
+			}
+			for n, i := range top {
+				if rows[i] != naive[n] {
+					t.Fatalf("tiebreaker %d, k %d: row %d = %+v, want %+v", tiebreak, k, n, rows[i], naive[n])
+				}
+			}
+		}
+	}
+	if got := topIndices(0, maxRows, nil); got != nil {
+		t.Errorf("topIndices of nothing = %v, want nil", got)
+	}
+}
+
+func BenchmarkTopRows(b *testing.B) {
+	rows := syntheticAggregates(5000, 1)
+	b.Run("heap", func(b *testing.B) {
+		for b.Loop() {
+			topIndices(len(rows), maxRows, byTotal(rows, TiebreakPID))
+		}
+	})
+	b.Run("sort", func(b *testing.B) {
+		sorted := make([]aggregateRow, len(rows))
+		for b.Loop() {
+			copy(sorted, rows)
+			sort.Slice(sorted, byTotal(sorted, TiebreakPID))
+		}
+	})
+}
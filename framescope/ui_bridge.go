@@ -0,0 +1,217 @@
+package framescope
+
+// UISink is the destination for rendered UI payloads. The macOS app's
+// implementation (cocoaSink in package main) forwards each call to the Cocoa
+// layer via cgo; tests substitute a recording fake so payloads can be asserted
+// without AppKit.
+type UISink interface {
+	// UpdateResults delivers a complete UI refresh.
+	UpdateResults(u UIUpdate)
+
+	// ShowErrorMessage replaces the status bar text and clears all tables.
+	ShowErrorMessage(message string)
+}
+
+// Notifier is implemented by UISinks that can show a user notification
+// outside the window, such as the CPU budget alert (see Monitor.SetCPUBudget).
+// Sinks without it do not receive notifications.
+type Notifier interface {
+	ShowNotification(title, body string)
+}
+
+// AttentionRequester is implemented by UISinks that can draw the user back
+// to the app, such as by bouncing its Dock icon, when a run completes (see
+// Monitor.SetCompletionAlert). Sinks without it only receive the
+// notification.
+type AttentionRequester interface {
+	RequestUserAttention()
+}
+
+// StatusItemUpdater is implemented by UISinks that can show a compact live
+// indicator outside the window, such as a menu bar item (see
+// Monitor.SetMenuBarItem). Sinks without it do not receive the indicator.
+type StatusItemUpdater interface {
+	// UpdateStatusItem sets the indicator text; an empty text hides it.
+	UpdateStatusItem(text string)
+}
+
+// BinaryTableUpdater is implemented by UISinks that can read the
+// current-frame and summary tables as length-prefixed binary buffers (see
+// encodeTablePayload) rather than tab-separated text. Once enabled with
+// SetBinaryPayload, such sinks receive every refresh through
+// UpdateResultsBinary instead of UpdateResults, with the Table and Summary
+// fields of u left empty.
+type BinaryTableUpdater interface {
+	UpdateResultsBinary(u UIUpdate, table, summary []byte)
+}
+
+// UIUpdate is one complete set of rendered payloads for the UI. See
+// cocoa_bridge.h in the app for the format of each field.
+type UIUpdate struct {
+	Status        string // status bar text
+	Table         string // current-frame table payload (RenderTable)
+	Summary       string // summary table payload (RenderSummaryTable)
+	SummaryTitle  string // summary pane header text (rangeTitle)
+	Stats         string // session statistics line above the summary (renderSessionStats)
+	Commands      string // by-command table payload (renderCommandTable)
+	Users         string // by-user table payload (renderUserTable)
+	Comparison    string // baseline comparison payload (renderComparisonTable)
+	History       string // newline-separated history popup labels
+	SelectedIndex int    // popup item to select; -1 for none
+
+	// MachineCPU is the whole machine's CPU use over the last tick as a
+	// percentage of all cores (0–100), independent of frames; -1 while it
+	// is unknown: before a run's second tick, while stopped, or if the
+	// machine's CPU times cannot be read.
+	MachineCPU float64
+}
+
+// DiscardSink is a UISink that drops every update, for callers that only read
+// results through other means (Frames, the HTTP API).
+type DiscardSink struct{}
+
+func (DiscardSink) UpdateResults(u UIUpdate)        {}
+func (DiscardSink) ShowErrorMessage(message string) {}
+
+// pushUI snapshots the current application state (under the mutex), renders
+// the table, summary, session statistics, and by-command payloads, and calls
+// postUpdate to deliver them to the Cocoa layer on the main thread. Current
+// updates are also published to WebSocket clients of the HTTP API, and frames
+// completed since the last refresh to gRPC watchers (see frameService). Passing
+// runID = 0 bypasses the stale-run check and always delivers the update (used
+// after user-initiated actions such as Stop or frame selection).
+func (m *Monitor) pushUI(runID int64) {
+	m.mu.Lock()
+	status := m.status
+	hidePaths := m.hidePaths
+	avgMode := m.averageMode
+	showSparklines := m.showSparklines
+	opts := m.tableOptionsLocked()
+	summaryOpts := m.summaryOptionsLocked()
+	frameIndex := m.frameIndex
+	running := m.running
+	rows := m.currentRowsLocked()
+	history := append([]FrameRecord(nil), m.history...)
+	completed := m.unwatchedFramesLocked()
+	summaryRange := m.summaryRange
+	baseline := m.baseline
+	historyText, selectedIndex := m.historyPayloadLocked()
+	statusItem := m.statusItemTextLocked()
+	cores := m.coreCount()
+	columns := m.columns
+	noise := m.noiseLocked()
+	markTiny := m.markTiny
+	machineCPU := machineCPUUnknown
+	if running {
+		machineCPU = m.machineCPU
+	}
+	m.mu.Unlock()
+
+	// Skip rendering entirely for stale runs; postUpdate repeats the check.
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	if showSparklines {
+		opts.Sparks = sparklines(history, sparklineWindow)
+	}
+
+	summarized := framesInRange(history, summaryRange)
+
+	m.publishStream(status, frameIndex, running, rows, opts)
+	m.publishFrames(completed)
+	m.postUpdate(runID, UIUpdate{
+		Status:        status,
+		Table:         RenderTable(rows, opts, TabFormatter{Columns: columns, MarkTiny: markTiny}),
+		Summary:       RenderSummaryTable(summarized, summaryOpts),
+		SummaryTitle:  rangeTitle(avgMode, summaryRange, len(summarized)) + collectionNote(summarized),
+		Stats:         renderSessionStats(computeSessionStats(summarized, cores, noise), hidePaths),
+		Commands:      renderCommandTable(summarized),
+		Users:         renderUserTable(summarized),
+		Comparison:    renderComparisonTable(baseline, summarized),
+		History:       historyText,
+		SelectedIndex: selectedIndex,
+		MachineCPU:    machineCPU,
+	})
+	m.postStatusItem(runID, statusItem)
+}
+
+// postUpdate passes rendered payloads to the monitor's UISink, with the two
+// row tables encoded as binary if SetBinaryPayload is on and the sink is a
+// BinaryTableUpdater. The call is a no-op if runID refers to a stale
+// monitoring run.
+func (m *Monitor) postUpdate(runID int64, u UIUpdate) {
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	if b, ok := m.ui.(BinaryTableUpdater); ok && m.binaryPayload {
+		table, summary := encodeTablePayload(u.Table), encodeTablePayload(u.Summary)
+		u.Table, u.Summary = "", ""
+		b.UpdateResultsBinary(u, table, summary)
+		return
+	}
+	m.ui.UpdateResults(u)
+}
+
+// postError passes an error message string to the monitor's UISink. The message
+// replaces the status bar text and clears all tables. The call is a no-op if
+// runID refers to a stale monitoring run.
+func (m *Monitor) postError(runID int64, message string) {
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	m.ui.ShowErrorMessage(message)
+}
+
+// postNotification shows a notification through the monitor's UISink if it
+// is a Notifier. The call is a no-op if runID refers to a stale monitoring
+// run.
+func (m *Monitor) postNotification(runID int64, title, body string) {
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	if n, ok := m.ui.(Notifier); ok {
+		n.ShowNotification(title, body)
+	}
+}
+
+// postAttention asks the monitor's UISink for the user's attention if it is
+// an AttentionRequester. The call is a no-op if runID refers to a stale
+// monitoring run.
+func (m *Monitor) postAttention(runID int64) {
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	if a, ok := m.ui.(AttentionRequester); ok {
+		a.RequestUserAttention()
+	}
+}
+
+// postStatusItem passes the menu bar indicator text to the monitor's UISink if
+// it is a StatusItemUpdater. The call is a no-op if runID refers to a stale
+// monitoring run.
+func (m *Monitor) postStatusItem(runID int64, text string) {
+	if !m.isCurrentRun(runID) {
+		return
+	}
+	if u, ok := m.ui.(StatusItemUpdater); ok {
+		u.UpdateStatusItem(text)
+	}
+}
+
+// ReportError shows message in place of the current results, as if it had been
+// raised by the monitor itself.
+func (m *Monitor) ReportError(message string) {
+	m.postError(0, message)
+}
+
+// isCurrentRun reports whether runID still matches the active monitoring run.
+// A runID of 0 is a sentinel meaning "always current", used for UI updates
+// that are not tied to a specific monitoring session.
+func (m *Monitor) isCurrentRun(runID int64) bool {
+	if runID == 0 {
+		return true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runID == runID
+}
@@ -0,0 +1,429 @@
+package framescope
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink is a UISink that stores every payload it receives so tests
+// can assert on what would have been shown in the UI.
+type recordingSink struct {
+	mu            sync.Mutex
+	updates       []UIUpdate
+	errors        []string
+	notifications []string
+	attention     int
+}
+
+func (s *recordingSink) UpdateResults(u UIUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, u)
+}
+
+func (s *recordingSink) ShowErrorMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, message)
+}
+
+func (s *recordingSink) ShowNotification(title, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, title+": "+body)
+}
+
+func (s *recordingSink) RequestUserAttention() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attention++
+}
+
+// snapshotNotifications returns a copy of the recorded notifications, each
+// as "title: body".
+func (s *recordingSink) snapshotNotifications() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.notifications...)
+}
+
+// snapshotUpdates returns a copy of the recorded updates and errors.
+func (s *recordingSink) snapshotUpdates() ([]UIUpdate, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]UIUpdate(nil), s.updates...), append([]string(nil), s.errors...)
+}
+
+// newTestMonitor returns a Monitor that records its UI payloads. The user
+// config directory is redirected to a temporary directory so that Start and
+// the Set* methods do not touch the real settings file.
+func newTestMonitor(t *testing.T) (*Monitor, *recordingSink) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	rec := &recordingSink{}
+	return NewMonitor(rec), rec
+}
+
+// waitForFrames polls until m has at least n completed frames or fails the
+// test after timeout.
+func waitForFrames(t *testing.T, m *Monitor, n int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		m.mu.Lock()
+		completed := len(m.history)
+		m.mu.Unlock()
+		if completed >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatalf("%d frame(s) did not complete within %v", n, timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestPostErrorSkipsStaleRun(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.runID = 2
+
+	m.postError(1, "stale")
+	m.postError(2, "current")
+	m.postError(0, "always")
+
+	_, errs := rec.snapshotUpdates()
+	if got, want := strings.Join(errs, ","), "current,always"; got != want {
+		t.Fatalf("errors = %q, want %q", got, want)
+	}
+}
+
+func TestStartRejectsNonPositiveFrame(t *testing.T) {
+	m, rec := newTestMonitor(t)
+
+	m.Start(0)
+
+	_, errs := rec.snapshotUpdates()
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want one rejection", errs)
+	}
+	if m.running {
+		t.Fatal("monitor is running after rejected start")
+	}
+}
+
+func TestStartClampsFrameLength(t *testing.T) {
+	m, rec := newTestMonitor(t)
+
+	m.Start(1e9)
+	m.Stop()
+
+	if got := m.FrameSeconds(); got != maxFrameSeconds {
+		t.Errorf("frame seconds = %g, want %g", got, maxFrameSeconds)
+	}
+	updates, errs := rec.snapshotUpdates()
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if want := "Frame length 1e+09s is outside 0.1s–24h; using 86400s."; !strings.Contains(updates[0].Status, want) {
+		t.Errorf("status = %q, want it to contain %q", updates[0].Status, want)
+	}
+}
+
+func TestStatusFollowsStartAndStop(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetProcessSource(&busySource{})
+
+	m.Start(5)
+	if got := m.Status(); !strings.HasPrefix(got, "Running. Frame 1 of 5.0s started.") {
+		t.Errorf("Status() after Start = %q", got)
+	}
+	m.Stop()
+	if got := m.Status(); got != "Monitoring stopped." {
+		t.Errorf("Status() after Stop = %q", got)
+	}
+	// Reading the status renders nothing.
+	before, _ := rec.snapshotUpdates()
+	m.Status()
+	if after, _ := rec.snapshotUpdates(); len(after) != len(before) {
+		t.Errorf("Status() posted %d updates", len(after)-len(before))
+	}
+}
+
+func TestMonitorPostsFramePayloads(t *testing.T) {
+	m, rec := newTestMonitor(t)
+
+	m.Start(0.5)
+	waitForFrames(t, m, 1, 5*time.Second)
+	m.Stop()
+
+	updates, errs := rec.snapshotUpdates()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(updates) == 0 {
+		t.Fatal("no updates were posted")
+	}
+
+	var sawLive, sawCompleted, sawStopped bool
+	for _, u := range updates {
+		if u.Status == "Monitoring stopped." {
+			sawStopped = true
+		}
+		if strings.Contains(u.History, "Current Frame 1 (in progress)") {
+			sawLive = true
+		}
+		if strings.HasPrefix(u.History, "Frame 1") && u.SelectedIndex == 0 {
+			sawCompleted = true
+		}
+	}
+	if !sawLive {
+		t.Error("no update showed frame 1 in progress")
+	}
+	if !sawCompleted {
+		t.Error("no update selected completed frame 1")
+	}
+	if !sawStopped {
+		t.Error("no update reported that monitoring stopped")
+	}
+}
+
+func TestSelectFrameIgnoresOutOfRange(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{{Index: 1}, {Index: 2}}
+
+	m.SelectFrame(5)
+	if updates, _ := rec.snapshotUpdates(); len(updates) != 0 {
+		t.Fatalf("out-of-range selection posted %d update(s)", len(updates))
+	}
+
+	m.SelectFrame(0)
+	if m.viewingCurrent || m.selectedHistoryIdx != 0 || m.autoFollowLatestComplete {
+		t.Fatalf("after selecting frame 0: viewingCurrent=%v idx=%d follow=%v",
+			m.viewingCurrent, m.selectedHistoryIdx, m.autoFollowLatestComplete)
+	}
+}
+
+func TestSetAverageModeUpdatesSummaryTitle(t *testing.T) {
+	m, rec := newTestMonitor(t)
+
+	m.SetAverageMode(AverageAppearedFrames)
+	m.SetAverageMode(AverageMode(7))
+
+	updates, _ := rec.snapshotUpdates()
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1 (unknown mode must be ignored)", len(updates))
+	}
+	if got, want := updates[0].SummaryTitle, summaryTitle(AverageAppearedFrames); got != want {
+		t.Errorf("summary title = %q, want %q", got, want)
+	}
+	if m.AverageMode() != AverageAppearedFrames {
+		t.Errorf("average mode = %v, want appeared frames", m.AverageMode())
+	}
+}
+
+// fakeClock is a manually advanced clock for Monitor.clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestFrameDurationMatchesClockDelta(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	m.clock = clock.Now
+
+	m.Start(5)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.mu.Lock()
+		started := !m.frameStart.IsZero()
+		m.mu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("monitor did not start its first frame")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	clock.Advance(7300 * time.Millisecond)
+	waitForFrames(t, m, 1, 5*time.Second)
+	m.Stop()
+
+	m.mu.Lock()
+	frame := m.history[0]
+	m.mu.Unlock()
+	if want := 7300 * time.Millisecond; frame.Duration != want {
+		t.Fatalf("stored duration = %v, want %v", frame.Duration, want)
+	}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !frame.StartedAt.Equal(start) || !frame.EndedAt.Equal(start.Add(7300*time.Millisecond)) {
+		t.Errorf("frame span = %v – %v, want it to follow the clock", frame.StartedAt, frame.EndedAt)
+	}
+
+	updates, _ := rec.snapshotUpdates()
+	last := updates[len(updates)-1]
+	if !strings.HasPrefix(last.History, "Frame 1 · 7.3s") {
+		t.Errorf("history label = %q, want prefix %q", last.History, "Frame 1 · 7.3s")
+	}
+}
+
+func TestFrameCount(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	source := &busySource{}
+	m.SetProcessSource(source)
+	check := func(when string, wantCompleted int, wantInProgress bool) {
+		t.Helper()
+		if completed, inProgress := m.FrameCount(); completed != wantCompleted || inProgress != wantInProgress {
+			t.Errorf("%s: FrameCount() = %d, %v; want %d, %v", when, completed, inProgress, wantCompleted, wantInProgress)
+		}
+	}
+
+	check("before starting", 0, false)
+	if err := m.Start(10); err != nil {
+		t.Fatal(err)
+	}
+	check("after starting", 0, true)
+	// Wait for the baseline and first update, so that the first frame starts
+	// before the clock is advanced.
+	deadline := time.Now().Add(5 * time.Second)
+	for source.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("monitor did not start its first frame")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for n := 1; n <= 3; n++ {
+		clock.Advance(10 * time.Second)
+		waitForFrames(t, m, n, 5*time.Second)
+		check(fmt.Sprintf("after frame %d", n), n, true)
+	}
+
+	// Stopping discards the frame in progress.
+	m.Stop()
+	check("after stopping", 3, false)
+}
+
+func TestSummaryMinTotalIndependentOfHideSmall(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.history = []FrameRecord{
+		{Index: 1, Duration: time.Second, Rows: []ResultRow{{PID: 1, Diff: 0.6, Command: "/bin/a"}, {PID: 2, Diff: 3, Command: "/bin/b"}}},
+		{Index: 2, Duration: time.Second, Rows: []ResultRow{{PID: 1, Diff: 0.6, Command: "/bin/a"}}},
+	}
+	m.selectedHistoryIdx = 1
+
+	lastUpdate := func() UIUpdate {
+		t.Helper()
+		updates, _ := rec.snapshotUpdates()
+		if len(updates) == 0 {
+			t.Fatal("no updates were posted")
+		}
+		return updates[len(updates)-1]
+	}
+
+	// PID 1 used under 1 CPU-second in each frame but 1.2 in total: the
+	// frame table hides it while the summary, filtering on totals, keeps it.
+	m.SetHideSmall(true)
+	u := lastUpdate()
+	if u.Table != "" {
+		t.Errorf("frame table = %q, want PID 1 hidden", u.Table)
+	}
+	if rows := splitPayload(u.Summary); len(rows) != 2 {
+		t.Errorf("summary = %q, want both processes", u.Summary)
+	}
+
+	// Raising the summary threshold leaves the frame table alone.
+	m.SetHideSmall(false)
+	m.SetSummaryMinTotal(2)
+	u = lastUpdate()
+	if rows := splitPayload(u.Table); len(rows) != 1 || rows[0][0] != "1" {
+		t.Errorf("frame table = %q, want PID 1 shown", u.Table)
+	}
+	if rows := splitPayload(u.Summary); len(rows) != 1 || rows[0][0] != "2" {
+		t.Errorf("summary = %q, want only PID 2", u.Summary)
+	}
+
+	m.SetSummaryMinTotal(-1)
+	if got := m.SummaryMinTotal(); got != 2 {
+		t.Errorf("summary min total = %v after a negative value, want 2", got)
+	}
+}
+
+func TestConfigPersistsBothFilters(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.LoadConfig()
+	m.SetHideSmall(false)
+	m.SetSummaryMinTotal(5)
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if loaded.HideSmall() || loaded.SummaryMinTotal() != 5 {
+		t.Errorf("loaded hideSmall=%v summaryMinTotal=%v, want false and 5",
+			loaded.HideSmall(), loaded.SummaryMinTotal())
+	}
+}
+
+func TestMonitorWithoutLoadConfigDoesNotPersist(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	m.SetSummaryMinTotal(7)
+
+	loaded := NewMonitor(DiscardSink{})
+	loaded.LoadConfig()
+	if got := loaded.SummaryMinTotal(); got != defaultSummaryMinTotal {
+		t.Errorf("summary min total = %v, want the default %v", got, defaultSummaryMinTotal)
+	}
+}
+
+func TestCopyRowFieldSelectsField(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.hidePaths = true
+	m.history = []FrameRecord{{Index: 1, Duration: time.Second, Rows: []ResultRow{
+		{PID: 412, Diff: 3, Command: "/usr/bin/python3 -m http.server 8000"},
+		{PID: 77, Diff: 0.2, Command: "/usr/sbin/cfprefsd agent"},
+	}}}
+	m.selectedHistoryIdx = 0
+
+	if got, err := m.CopyRowField(412, FieldPID); err != nil || got != "412" {
+		t.Errorf("PID field = %q, %v; want 412", got, err)
+	}
+	// The command is copied in full even though the table shows basenames.
+	got, err := m.CopyRowField(412, FieldCommand)
+	if err != nil || got != "/usr/bin/python3 -m http.server 8000" {
+		t.Errorf("command field = %q, %v; want the full command line", got, err)
+	}
+	updates, _ := rec.snapshotUpdates()
+	if status := updates[len(updates)-1].Status; status != "Copied the command of PID 412." {
+		t.Errorf("status = %q", status)
+	}
+
+	// PID 77 is hidden by Hide <1s, so it cannot be copied from this view.
+	if _, err := m.CopyRowField(77, FieldPID); err == nil {
+		t.Error("copied a PID that is not displayed")
+	}
+	updates, _ = rec.snapshotUpdates()
+	if status := updates[len(updates)-1].Status; !strings.HasPrefix(status, "Copy failed") {
+		t.Errorf("status = %q, want the failure reported", status)
+	}
+}
@@ -0,0 +1,39 @@
+package framescope
+
+import (
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+// unknownUser labels CPU from processes whose owner could not be read.
+const unknownUser = "unknown"
+
+// usernameCache maps UIDs to usernames. A lookup may read the user database
+// or ask a directory service, so each UID is looked up once per session,
+// however many processes it owns; a machine has far fewer users than
+// processes.
+type usernameCache struct {
+	mu    sync.Mutex
+	names map[int32]string
+}
+
+// usernames is shared by every SystemSource and BatchSource.
+var usernames = &usernameCache{names: make(map[int32]string)}
+
+// name returns the username of uid, or the UID itself as a decimal string if
+// it has none (e.g. a container user with no passwd entry).
+func (c *usernameCache) name(uid int32) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name, ok := c.names[uid]; ok {
+		return name
+	}
+	id := strconv.Itoa(int(uid))
+	name := id
+	if u, err := user.LookupId(id); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	c.names[uid] = name
+	return name
+}
@@ -0,0 +1,67 @@
+package framescope
+
+import (
+	"fmt"
+	"math"
+)
+
+// summaryGrowthHighlights is how many of the processes that grew the most
+// since the summary was last viewed are marked in its since_viewed column.
+const summaryGrowthHighlights = 5
+
+// summaryTotals returns the total of each summary row by its aggregateKey.
+func summaryTotals(rows []aggregateRow) map[aggregateKey]float64 {
+	totals := make(map[aggregateKey]float64, len(rows))
+	for _, row := range rows {
+		totals[row.key] = row.Total
+	}
+	return totals
+}
+
+// sinceViewed returns the since_viewed column of rows: how much each row's
+// total grew since viewed, the totals when the summary was last viewed, e.g.
+// "+12.5". A process absent from viewed grew by its whole total. The
+// summaryGrowthHighlights rows that grew the most, if they grew at all, are
+// marked "+12.5 ▲". The result is indexed like rows. Returns nil, leaving the
+// column empty, if viewed is nil because the summary has not been viewed
+// before.
+func sinceViewed(rows []aggregateRow, viewed map[aggregateKey]float64) []string {
+	if viewed == nil {
+		return nil
+	}
+	growth := make([]float64, len(rows))
+	for i, row := range rows {
+		growth[i] = row.Total - viewed[row.key]
+	}
+	out := make([]string, len(rows))
+	for i, g := range growth {
+		out[i] = fmt.Sprintf("%+.1f", g)
+	}
+	top := topIndices(len(rows), summaryGrowthHighlights, func(i, j int) bool {
+		if growth[i] == growth[j] {
+			return TiebreakPID.less(rows[i].tieKey(), rows[j].tieKey())
+		}
+		return growth[i] > growth[j]
+	})
+	for _, i := range top {
+		// Growth that rounds to "+0.0" is not worth pointing out.
+		if math.Round(growth[i]*10) > 0 {
+			out[i] += " ▲"
+		}
+	}
+	return out
+}
+
+// MarkSummaryViewed records that the user is looking at the summary, as when
+// its tab is selected: from now on its since_viewed column shows how much
+// each process's total grew since the view before this one, and marks the
+// processes that grew the most. The first view has nothing to compare with
+// and leaves the column empty. Views are tracked per frame length and reset
+// by Start.
+func (m *Monitor) MarkSummaryViewed() {
+	m.mu.Lock()
+	totals := summaryTotals(aggregateHistory(framesInRange(m.history, m.summaryRange), m.averageMode, m.mergeReusedPIDs))
+	m.summarySince, m.summaryViewed = m.summaryViewed, totals
+	m.mu.Unlock()
+	m.pushUI(0)
+}
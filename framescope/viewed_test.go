@@ -0,0 +1,74 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSinceViewed(t *testing.T) {
+	rows := []aggregateRow{
+		{PID: 1, Total: 10, key: aggregateKey{PID: 1}},
+		{PID: 2, Total: 4, key: aggregateKey{PID: 2}},
+		{PID: 3, Total: 2.5, key: aggregateKey{PID: 3}}, // new since
+		{PID: 4, Total: 7, key: aggregateKey{PID: 4}},
+		{PID: 5, Total: 1.02, key: aggregateKey{PID: 5}},
+		{PID: 6, Total: 9, key: aggregateKey{PID: 6}},
+		{PID: 7, Total: 3, key: aggregateKey{PID: 7}},
+	}
+	if got := sinceViewed(rows, nil); got != nil {
+		t.Errorf("first view = %q, want no column", got)
+	}
+
+	viewed := map[aggregateKey]float64{
+		{PID: 1}: 4,   // +6
+		{PID: 2}: 4,   // unchanged
+		{PID: 4}: 6,   // +1
+		{PID: 5}: 1,   // +0.02, shown as +0.0
+		{PID: 6}: 8,   // +1, ties PID 4
+		{PID: 7}: 2.5, // +0.5
+		{PID: 9}: 3,   // gone since
+	}
+	got := sinceViewed(rows, viewed)
+	want := []string{"+6.0 ▲", "+0.0", "+2.5 ▲", "+1.0 ▲", "+0.0", "+1.0 ▲", "+0.5 ▲"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("since viewed = %q, want %q", got, want)
+	}
+}
+
+func TestMarkSummaryViewed(t *testing.T) {
+	m, rec := newTestMonitor(t)
+	m.SetSummaryColumns([]string{"pid", "since_viewed"})
+	frame := func(index int, rows ...ResultRow) {
+		m.mu.Lock()
+		m.history = append(m.history, FrameRecord{Index: index, Duration: 10 * time.Second, Rows: rows})
+		m.mu.Unlock()
+	}
+	summary := func() string {
+		updates, _ := rec.snapshotUpdates()
+		return joinRows(splitPayload(updates[len(updates)-1].Summary))
+	}
+
+	frame(1, ResultRow{PID: 1, Diff: 5, CreateTime: 100}, ResultRow{PID: 2, Diff: 3, CreateTime: 200})
+	m.MarkSummaryViewed()
+	if got, want := summary(), "1 |2 "; got != want {
+		t.Errorf("first view = %q, want %q", got, want)
+	}
+
+	frame(2, ResultRow{PID: 1, Diff: 1, CreateTime: 100}, ResultRow{PID: 2, Diff: 4, CreateTime: 200})
+	m.MarkSummaryViewed()
+	if got, want := summary(), "2 +4.0 ▲|1 +1.0 ▲"; got != want {
+		t.Errorf("second view = %q, want %q", got, want)
+	}
+
+	// Deltas stay relative to the previous view until the next one.
+	frame(3, ResultRow{PID: 1, Diff: 2, CreateTime: 100})
+	m.pushUI(0)
+	if got, want := summary(), "1 +3.0 ▲|2 +4.0 ▲"; got != want {
+		t.Errorf("between views = %q, want %q", got, want)
+	}
+	m.MarkSummaryViewed()
+	if got, want := summary(), "1 +2.0 ▲|2 +0.0"; got != want {
+		t.Errorf("third view = %q, want %q", got, want)
+	}
+}
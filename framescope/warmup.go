@@ -0,0 +1,39 @@
+package framescope
+
+import (
+	"math"
+	"time"
+)
+
+// frameWarmup returns the warm-up a frame of the given duration uses: warmup,
+// clamped so that at least minFrameSeconds of the frame is still measured.
+func frameWarmup(warmup, duration time.Duration) time.Duration {
+	longest := duration - time.Duration(minFrameSeconds*float64(time.Second))
+	return max(min(warmup, longest), 0)
+}
+
+// SetFrameWarmup sets how many seconds at the start of every frame are
+// discarded, to skip the transient cost of whatever started with it and
+// measure the steady state: the frame's baseline is retaken once the warm-up
+// has passed, so a frame measures its length minus the warm-up. The frame's
+// duration, %CPU, and utilization all follow the measured window, and the
+// status line counts the warm-up down. A warm-up as long as a frame is
+// shortened to leave 0.1 seconds measured. 0 turns it off. Negative or
+// non-finite values are ignored. The new setting is persisted to disk
+// immediately and applies from the next Start.
+func (m *Monitor) SetFrameWarmup(seconds float64) {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return
+	}
+	m.mu.Lock()
+	m.frameWarmup = time.Duration(seconds * float64(time.Second))
+	m.mu.Unlock()
+	m.saveConfig()
+}
+
+// FrameWarmup returns the warm-up discarded from each frame, in seconds.
+func (m *Monitor) FrameWarmup() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.frameWarmup.Seconds()
+}
@@ -0,0 +1,91 @@
+package framescope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameWarmupRetakesBaseline(t *testing.T) {
+	m, _ := newTestMonitor(t)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m.clock = clock.Now
+	m.cores = 1
+	m.running = true
+	m.frameView = newFrameView(10)
+	start := clock.Now()
+	m.frameStart = start
+
+	cpu := func(seconds float64) map[int]ProcessSample {
+		return map[int]ProcessSample{1: {CPUSeconds: seconds, Command: "/bin/build"}}
+	}
+	track := &frameTrack{length: 10, duration: 10 * time.Second, warmup: 2 * time.Second, baseline: cpu(0), start: start}
+
+	// During the warm-up the frame is diffed against its start.
+	clock.Advance(time.Second)
+	if _, completed := m.advanceTrack(track, cpu(1), clock.Now(), false, TiebreakPID); completed {
+		t.Fatal("frame completed during the warm-up")
+	}
+	m.mu.Lock()
+	status, frameStart := m.status, m.frameStart
+	m.mu.Unlock()
+	if !frameStart.Equal(start) || !strings.Contains(status, "warming up, measuring in 1.0s") {
+		t.Errorf("during warm-up: frameStart = %v, status = %q", frameStart, status)
+	}
+
+	// At its end the baseline is retaken, and the live frame starts over.
+	clock.Advance(time.Second)
+	warmedAt := clock.Now()
+	m.advanceTrack(track, cpu(3), warmedAt, false, TiebreakPID)
+	m.mu.Lock()
+	frameStart = m.frameStart
+	live := m.liveRows
+	m.mu.Unlock()
+	if !frameStart.Equal(warmedAt) || len(live) != 1 || live[0].Diff != 0 {
+		t.Errorf("after warm-up: frameStart = %v, want %v; live rows = %+v", frameStart, warmedAt, live)
+	}
+
+	// The frame still ends 10 s after it began, measuring the last 8 s.
+	clock.Advance(8 * time.Second)
+	frame, completed := m.advanceTrack(track, cpu(7), clock.Now(), false, TiebreakPID)
+	if !completed {
+		t.Fatal("frame did not complete at its length")
+	}
+	if frame.Rows[0].Diff != 4 {
+		t.Errorf("frame CPU = %v, want 4 since the warm-up", frame.Rows[0].Diff)
+	}
+	if frame.Duration != 8*time.Second || !frame.StartedAt.Equal(warmedAt) || !frame.EndedAt.Equal(start.Add(10*time.Second)) {
+		t.Errorf("frame window = %v from %v to %v, want 8s from %v", frame.Duration, frame.StartedAt, frame.EndedAt, warmedAt)
+	}
+	if frame.Utilization != 50 {
+		t.Errorf("utilization = %v, want 50 over the measured window", frame.Utilization)
+	}
+
+	// The next frame warms up again.
+	if !track.warming() || !track.start.Equal(clock.Now()) {
+		t.Errorf("next frame: warming = %v, start = %v", track.warming(), track.start)
+	}
+}
+
+func TestFrameWarmupClamped(t *testing.T) {
+	tests := []struct {
+		warmup, duration, want time.Duration
+	}{
+		{2 * time.Second, 10 * time.Second, 2 * time.Second},
+		{10 * time.Second, 10 * time.Second, 9900 * time.Millisecond},
+		{time.Second, 100 * time.Millisecond, 0},
+		{0, 10 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := frameWarmup(tt.warmup, tt.duration); got != tt.want {
+			t.Errorf("frameWarmup(%v, %v) = %v, want %v", tt.warmup, tt.duration, got, tt.want)
+		}
+	}
+
+	m, _ := newTestMonitor(t)
+	m.SetFrameWarmup(1.5)
+	m.SetFrameWarmup(-1)
+	if got := m.FrameWarmup(); got != 1.5 {
+		t.Errorf("FrameWarmup() = %v, want 1.5 with the negative value ignored", got)
+	}
+}
@@ -0,0 +1,274 @@
+// frames.proto — typed streaming API for FrameScope frames.
+//
+// FrameService sits beside the HTTP API (api.go) behind the -grpc flag and is
+// fed from the same state snapshots as pushUI (see framescope/grpc.go). The Go
+// message types and stubs in framescopepb are generated from this file:
+//
+//   protoc --go_out=. --go_opt=module=monitor_cpu \
+//     --go-grpc_out=. --go-grpc_opt=module=monitor_cpu proto/frames.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: proto/frames.proto
+
+package framescopepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_frames_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_frames_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_frames_proto_rawDescGZIP(), []int{0}
+}
+
+// Frame mirrors FrameRecord: one completed measurement window.
+type Frame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // 1-based frame number
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	Rows          []*Row                 `protobuf:"bytes,4,rep,name=rows,proto3" json:"rows,omitempty"` // sorted by cpu_seconds, descending
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_proto_frames_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_frames_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_proto_frames_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Frame) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Frame) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Frame) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *Frame) GetRows() []*Row {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+// Row mirrors ResultRow: one process's CPU use within a frame.
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int64                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	CpuSeconds    float64                `protobuf:"fixed64,2,opt,name=cpu_seconds,json=cpuSeconds,proto3" json:"cpu_seconds,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_proto_frames_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_frames_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_proto_frames_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Row) GetPid() int64 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *Row) GetCpuSeconds() float64 {
+	if x != nil {
+		return x.CpuSeconds
+	}
+	return 0
+}
+
+func (x *Row) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+var File_proto_frames_proto protoreflect.FileDescriptor
+
+const file_proto_frames_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/frames.proto\x12\rframescope.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0e\n" +
+	"\fWatchRequest\"\xb7\x01\n" +
+	"\x05Frame\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x129\n" +
+	"\n" +
+	"started_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bended_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\x12&\n" +
+	"\x04rows\x18\x04 \x03(\v2\x12.framescope.v1.RowR\x04rows\"R\n" +
+	"\x03Row\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x03R\x03pid\x12\x1f\n" +
+	"\vcpu_seconds\x18\x02 \x01(\x01R\n" +
+	"cpuSeconds\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand2L\n" +
+	"\fFrameService\x12<\n" +
+	"\x05Watch\x12\x1b.framescope.v1.WatchRequest\x1a\x14.framescope.v1.Frame0\x01B\x1aZ\x18monitor_cpu/framescopepbb\x06proto3"
+
+var (
+	file_proto_frames_proto_rawDescOnce sync.Once
+	file_proto_frames_proto_rawDescData []byte
+)
+
+func file_proto_frames_proto_rawDescGZIP() []byte {
+	file_proto_frames_proto_rawDescOnce.Do(func() {
+		file_proto_frames_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_frames_proto_rawDesc), len(file_proto_frames_proto_rawDesc)))
+	})
+	return file_proto_frames_proto_rawDescData
+}
+
+var file_proto_frames_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_frames_proto_goTypes = []any{
+	(*WatchRequest)(nil),          // 0: framescope.v1.WatchRequest
+	(*Frame)(nil),                 // 1: framescope.v1.Frame
+	(*Row)(nil),                   // 2: framescope.v1.Row
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_proto_frames_proto_depIdxs = []int32{
+	3, // 0: framescope.v1.Frame.started_at:type_name -> google.protobuf.Timestamp
+	3, // 1: framescope.v1.Frame.ended_at:type_name -> google.protobuf.Timestamp
+	2, // 2: framescope.v1.Frame.rows:type_name -> framescope.v1.Row
+	0, // 3: framescope.v1.FrameService.Watch:input_type -> framescope.v1.WatchRequest
+	1, // 4: framescope.v1.FrameService.Watch:output_type -> framescope.v1.Frame
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_proto_frames_proto_init() }
+func file_proto_frames_proto_init() {
+	if File_proto_frames_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_frames_proto_rawDesc), len(file_proto_frames_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_frames_proto_goTypes,
+		DependencyIndexes: file_proto_frames_proto_depIdxs,
+		MessageInfos:      file_proto_frames_proto_msgTypes,
+	}.Build()
+	File_proto_frames_proto = out.File
+	file_proto_frames_proto_goTypes = nil
+	file_proto_frames_proto_depIdxs = nil
+}
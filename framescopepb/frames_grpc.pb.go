@@ -0,0 +1,143 @@
+// frames.proto — typed streaming API for FrameScope frames.
+//
+// FrameService sits beside the HTTP API (api.go) behind the -grpc flag and is
+// fed from the same state snapshots as pushUI (see framescope/grpc.go). The Go
+// message types and stubs in framescopepb are generated from this file:
+//
+//   protoc --go_out=. --go_opt=module=monitor_cpu \
+//     --go-grpc_out=. --go-grpc_opt=module=monitor_cpu proto/frames.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/frames.proto
+
+package framescopepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FrameService_Watch_FullMethodName = "/framescope.v1.FrameService/Watch"
+)
+
+// FrameServiceClient is the client API for FrameService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FrameService streams completed frames to programmatic consumers.
+type FrameServiceClient interface {
+	// Watch yields every frame that completes after the call is made, until
+	// the client cancels or the server stops. A slow watcher never blocks the
+	// monitor: one that falls too far behind is ended with RESOURCE_EXHAUSTED.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error)
+}
+
+type frameServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFrameServiceClient(cc grpc.ClientConnInterface) FrameServiceClient {
+	return &frameServiceClient{cc}
+}
+
+func (c *frameServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FrameService_ServiceDesc.Streams[0], FrameService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Frame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FrameService_WatchClient = grpc.ServerStreamingClient[Frame]
+
+// FrameServiceServer is the server API for FrameService service.
+// All implementations must embed UnimplementedFrameServiceServer
+// for forward compatibility.
+//
+// FrameService streams completed frames to programmatic consumers.
+type FrameServiceServer interface {
+	// Watch yields every frame that completes after the call is made, until
+	// the client cancels or the server stops. A slow watcher never blocks the
+	// monitor: one that falls too far behind is ended with RESOURCE_EXHAUSTED.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Frame]) error
+	mustEmbedUnimplementedFrameServiceServer()
+}
+
+// UnimplementedFrameServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFrameServiceServer struct{}
+
+func (UnimplementedFrameServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Frame]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedFrameServiceServer) mustEmbedUnimplementedFrameServiceServer() {}
+func (UnimplementedFrameServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeFrameServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FrameServiceServer will
+// result in compilation errors.
+type UnsafeFrameServiceServer interface {
+	mustEmbedUnimplementedFrameServiceServer()
+}
+
+func RegisterFrameServiceServer(s grpc.ServiceRegistrar, srv FrameServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFrameServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FrameService_ServiceDesc, srv)
+}
+
+func _FrameService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FrameServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Frame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FrameService_WatchServer = grpc.ServerStreamingServer[Frame]
+
+// FrameService_ServiceDesc is the grpc.ServiceDesc for FrameService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FrameService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "framescope.v1.FrameService",
+	HandlerType: (*FrameServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _FrameService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/frames.proto",
+}
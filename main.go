@@ -1,3 +1,5 @@
+//go:build darwin
+
 // FrameScope is a macOS CPU profiler that measures per-process CPU consumption
 // across configurable fixed-length time windows ("frames"). Unlike point-in-time
 // monitors it shows how many CPU-seconds each process actually consumed during a
@@ -5,8 +7,9 @@
 //
 // The application is built with Go and a native AppKit UI embedded via cgo. The
 // Cocoa layer lives in cocoa_bridge.m and calls back into Go through the exported
-// functions in controls.go. All shared mutable state is in the global monitorState
-// struct (model.go), protected by a sync.Mutex.
+// functions in controls_darwin.go, which delegate to a framescope.Monitor. All
+// sampling, rendering, and state logic lives in the framescope package; this
+// package holds only the cgo glue and the entry point.
 package main
 
 /*
@@ -18,14 +21,153 @@ package main
 import "C"
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
 	"runtime"
+	"time"
 	"unsafe"
+
+	"monitor_cpu/framescope"
+)
+
+// httpAddr enables the HTTP API (see framescope/api.go) on the given address,
+// e.g. "127.0.0.1:7788". The API is disabled when empty.
+var httpAddr = flag.String("http", "", "serve the HTTP API on this address (disabled if empty)")
+
+// grpcAddr enables the gRPC FrameService (see framescope/grpc.go and
+// proto/frames.proto) on the given address, e.g. ":7789". It is disabled when
+// empty.
+var grpcAddr = flag.String("grpc", "", "serve the gRPC FrameService on this address (disabled if empty)")
+
+// benchmark runs the sampling path back to back for benchmarkTime without the
+// UI, prints its cost to stdout, and exits.
+var (
+	benchmark     = flag.Bool("benchmark", false, "measure the cost of process snapshots and exit")
+	benchmarkTime = flag.Duration("benchmark-time", 5*time.Second, "how long -benchmark samples")
+)
+
+// report samples one frame of reportTime without the UI, prints the top
+// processes to stdout as a table, and exits (see framescope.RunReport).
+var (
+	reportTime      = flag.Duration("report", 0, "print the top CPU consumers over this duration and exit (disabled if 0)")
+	reportTop       = flag.Int("top", 10, "how many processes -report prints (0 for all)")
+	reportHidePaths = flag.Bool("hide-paths", false, "show only executable names in -report")
+	reportMinCPU    = flag.Float64("min-cpu", 0, "omit processes below this many CPU-seconds from -report")
+)
+
+// processTimeout bounds how long one process may take to answer during a
+// snapshot before it is recorded with partial data; maxInFlight caps the
+// process reads open at once (see framescope.SystemSource).
+var (
+	processTimeout = flag.Duration("process-timeout", 200*time.Millisecond, "per-process limit for reading process attributes")
+	maxInFlight    = flag.Int("max-in-flight", 16, "maximum concurrent process reads (each may hold a file descriptor)")
+)
+
+// batched reads the process table in one libproc pass instead of per-process
+// gopsutil calls (see framescope.BatchSource), falling back to gopsutil where
+// that path is not built.
+var batched = flag.Bool("batched", false, "read all processes' CPU times in one batched call (macOS)")
+
+// importNDJSON loads a recorded session into the history at startup for
+// browsing without monitoring (see framescope.Monitor.ImportNDJSON).
+var importNDJSON = flag.String("import-ndjson", "", "load the frames recorded in this NDJSON file for viewing")
+
+// recordPath appends every completed frame to an NDJSON file while the app
+// runs (see framescope.NDJSONRecorder); recorder is the open file, closed by
+// GoWillTerminate.
+var (
+	recordPath = flag.String("record", "", "append each completed frame to this NDJSON file")
+	recorder   *framescope.NDJSONRecorder
 )
 
+// retryLimit is how many snapshots in a row may fail before monitoring stops
+// (see framescope.Monitor.SetRetryLimit).
+var retryLimit = flag.Int("retry-limit", 10, "consecutive failed snapshots tolerated before monitoring stops")
+
+// binaryUI delivers the current-frame and summary tables to the Cocoa layer
+// as binary buffers rather than tab-separated text (see
+// framescope.Monitor.SetBinaryPayload), which is cheaper for large tables.
+var binaryUI = flag.Bool("binary-ui", false, "pass the frame and summary tables to the UI in a binary layout instead of text")
+
 func main() {
+	flag.Parse()
+	var source framescope.ProcessSource = framescope.SystemSource{Timeout: *processTimeout, MaxInFlight: *maxInFlight}
+	if *batched {
+		source = framescope.BatchSource{Fallback: source}
+	}
+
+	if *benchmark {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		report, err := framescope.RunBenchmark(ctx, source, *benchmarkTime)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(report)
+		return
+	}
+
+	if *reportTime > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		report, err := framescope.RunReport(ctx, source, framescope.ReportOptions{
+			Duration:  *reportTime,
+			Top:       *reportTop,
+			HidePaths: *reportHidePaths,
+			MinCPU:    *reportMinCPU,
+		})
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "report failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(report)
+		return
+	}
+
+	monitor.SetProcessSource(source)
+	monitor.SetRetryLimit(*retryLimit)
+	monitor.SetBinaryPayload(*binaryUI)
+	if *recordPath != "" {
+		var err error
+		if recorder, err = framescope.NewNDJSONRecorder(*recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot record: %v\n", err)
+			os.Exit(1)
+		}
+		monitor.SetRecorder(recorder)
+	}
+
 	// Load persisted settings before the UI initialises so toolbar controls
 	// reflect the saved values from the first draw.
-	initializeConfig()
+	monitor.LoadConfig()
+
+	if *importNDJSON != "" {
+		warnings, err := monitor.ImportNDJSON(*importNDJSON)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "import: skipping %v\n", w)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		}
+	}
+
+	if *httpAddr != "" {
+		go func() {
+			if err := monitor.ServeAPI(context.Background(), *httpAddr); err != nil {
+				monitor.ReportError(fmt.Sprintf("HTTP API failed: %v", err))
+			}
+		}()
+	}
+	if *grpcAddr != "" {
+		go func() {
+			if err := monitor.ServeGRPC(context.Background(), *grpcAddr); err != nil {
+				monitor.ReportError(fmt.Sprintf("gRPC service failed: %v", err))
+			}
+		}()
+	}
 
 	// Push the build version into the Cocoa layer so it can be shown in the
 	// window title before RunApp() starts the event loop.
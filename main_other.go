@@ -0,0 +1,16 @@
+//go:build !darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// main exits immediately on platforms without AppKit. The sampling, rendering,
+// and state logic in package framescope still builds and can be tested
+// anywhere Go runs; examples/headless captures frames without the UI.
+func main() {
+	fmt.Fprintln(os.Stderr, "FrameScope requires macOS.")
+	os.Exit(1)
+}
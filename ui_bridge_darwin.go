@@ -0,0 +1,99 @@
+package main
+
+/*
+#include <stdlib.h>
+#include "cocoa_bridge.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"monitor_cpu/framescope"
+)
+
+// cocoaSink is the app's framescope.UISink. It copies each Go string into a
+// C string, passes it to the Cocoa bridge (which dispatches to the main queue
+// asynchronously), and frees it immediately afterwards.
+type cocoaSink struct{}
+
+// UpdateResults forwards a UI refresh to the Cocoa UpdateResults function.
+func (cocoaSink) UpdateResults(u framescope.UIUpdate) {
+	cStatus := C.CString(u.Status)
+	cTable := C.CString(u.Table)
+	cSummary := C.CString(u.Summary)
+	cSummaryTitle := C.CString(u.SummaryTitle)
+	cStats := C.CString(u.Stats)
+	cCommands := C.CString(u.Commands)
+	cUsers := C.CString(u.Users)
+	cComparison := C.CString(u.Comparison)
+	cHistory := C.CString(u.History)
+	C.UpdateResults(cStatus, cTable, cSummary, cSummaryTitle, cStats, cCommands, cUsers, cComparison, cHistory, C.int(u.SelectedIndex), C.double(u.MachineCPU))
+	C.free(unsafe.Pointer(cStatus))
+	C.free(unsafe.Pointer(cTable))
+	C.free(unsafe.Pointer(cSummary))
+	C.free(unsafe.Pointer(cSummaryTitle))
+	C.free(unsafe.Pointer(cStats))
+	C.free(unsafe.Pointer(cCommands))
+	C.free(unsafe.Pointer(cUsers))
+	C.free(unsafe.Pointer(cComparison))
+	C.free(unsafe.Pointer(cHistory))
+}
+
+// UpdateResultsBinary forwards a UI refresh whose row tables are binary
+// buffers to the Cocoa UpdateResultsBinary function, making cocoaSink a
+// framescope.BinaryTableUpdater. The buffers are passed without a copy: the
+// bridge reads them before returning, and they are never empty, since even
+// an empty table has its 8-byte header.
+func (cocoaSink) UpdateResultsBinary(u framescope.UIUpdate, table, summary []byte) {
+	cStatus := C.CString(u.Status)
+	cSummaryTitle := C.CString(u.SummaryTitle)
+	cStats := C.CString(u.Stats)
+	cCommands := C.CString(u.Commands)
+	cUsers := C.CString(u.Users)
+	cComparison := C.CString(u.Comparison)
+	cHistory := C.CString(u.History)
+	C.UpdateResultsBinary(cStatus,
+		(*C.uchar)(unsafe.Pointer(&table[0])), C.size_t(len(table)),
+		(*C.uchar)(unsafe.Pointer(&summary[0])), C.size_t(len(summary)),
+		cSummaryTitle, cStats, cCommands, cUsers, cComparison, cHistory, C.int(u.SelectedIndex), C.double(u.MachineCPU))
+	C.free(unsafe.Pointer(cStatus))
+	C.free(unsafe.Pointer(cSummaryTitle))
+	C.free(unsafe.Pointer(cStats))
+	C.free(unsafe.Pointer(cCommands))
+	C.free(unsafe.Pointer(cUsers))
+	C.free(unsafe.Pointer(cComparison))
+	C.free(unsafe.Pointer(cHistory))
+}
+
+// ShowNotification forwards a notification to the Cocoa ShowNotification
+// function, making cocoaSink a framescope.Notifier.
+func (cocoaSink) ShowNotification(title, body string) {
+	cTitle := C.CString(title)
+	cBody := C.CString(body)
+	C.ShowNotification(cTitle, cBody)
+	C.free(unsafe.Pointer(cTitle))
+	C.free(unsafe.Pointer(cBody))
+}
+
+// RequestUserAttention forwards to the Cocoa RequestUserAttention function,
+// making cocoaSink a framescope.AttentionRequester.
+func (cocoaSink) RequestUserAttention() {
+	C.RequestUserAttention()
+}
+
+// UpdateStatusItem forwards the menu bar indicator text to the Cocoa
+// UpdateStatusItem function, making cocoaSink a framescope.StatusItemUpdater.
+func (cocoaSink) UpdateStatusItem(text string) {
+	cText := C.CString(text)
+	C.UpdateStatusItem(cText)
+	C.free(unsafe.Pointer(cText))
+}
+
+// ShowErrorMessage forwards an error message to the Cocoa ShowErrorMessage
+// function.
+func (cocoaSink) ShowErrorMessage(message string) {
+	cMessage := C.CString(message)
+	C.ShowErrorMessage(cMessage)
+	C.free(unsafe.Pointer(cMessage))
+}